@@ -0,0 +1,72 @@
+/*
+ * blobpoc.h - C interface to the KZG blob functions of kzg-blob-poc.
+ *
+ * Build the library with
+ *
+ *     go build -buildmode=c-shared -o libblobpoc.so ./capi
+ *
+ * and include this header rather than the libblobpoc.h that go build
+ * writes next to it, which changes with the Go toolchain.
+ *
+ * Every symbol carries the ABI version it belongs to, such as
+ * blob_commit_v1. The unversioned names below are macros for the current
+ * version, so a program built against this header keeps linking to the
+ * functions it was written for when a later library adds a v2.
+ *
+ * The functions are safe to call from several threads at once. Output
+ * buffers are written only on success.
+ */
+#ifndef BLOBPOC_H
+#define BLOBPOC_H
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+#define BLOBPOC_ABI_VERSION 1
+
+/* Sizes in bytes. */
+#define BLOB_SIZE 131072
+#define BLOB_COMMITMENT_SIZE 48
+#define BLOB_PROOF_SIZE 48
+#define BLOB_VERSIONED_HASH_SIZE 32
+
+/* Return codes, the same as the blob-poc command's exit codes. */
+#define BLOB_OK 0
+#define BLOB_ERR_FAILURE 1                /* any other failure */
+#define BLOB_ERR_INVALID_ARGUMENT 2       /* a required pointer is NULL */
+#define BLOB_ERR_VERIFICATION_FAILED 3    /* the proof does not verify */
+#define BLOB_ERR_INVALID_FIELD_ELEMENT 5  /* the blob holds a value >= the BLS12-381 modulus */
+
+/* blob_abi_version returns the highest ABI version the library provides. */
+uint32_t blob_abi_version(void);
+
+/*
+ * blob_commit_v1 computes the KZG commitment of blob (BLOB_SIZE bytes) into
+ * commitment and, unless versioned_hash is NULL, its EIP-4844 versioned hash.
+ */
+int blob_commit_v1(const uint8_t *blob, uint8_t *commitment, uint8_t *versioned_hash);
+
+/*
+ * blob_prove_v1 computes the commitment and KZG blob proof of blob and,
+ * unless versioned_hash is NULL, the versioned hash.
+ */
+int blob_prove_v1(const uint8_t *blob, uint8_t *commitment, uint8_t *proof, uint8_t *versioned_hash);
+
+/*
+ * blob_verify_v1 returns BLOB_OK if proof binds blob to commitment and
+ * BLOB_ERR_VERIFICATION_FAILED if it does not.
+ */
+int blob_verify_v1(const uint8_t *blob, const uint8_t *commitment, const uint8_t *proof);
+
+#define blob_commit blob_commit_v1
+#define blob_prove blob_prove_v1
+#define blob_verify blob_verify_v1
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif /* BLOBPOC_H */
@@ -0,0 +1,133 @@
+//go:build cgo
+
+// Command capi exports the KZG functions of pkg/blob through a C ABI, so
+// services in other languages can link against them instead of running the
+// blob-poc binary. Build it with
+//
+//	go build -buildmode=c-shared -o libblobpoc.so ./capi
+//
+// and include blobpoc.h, which documents the functions and their return
+// codes. Symbols carry their ABI version; a change to a signature or its
+// meaning gets a new symbol, and the old one stays.
+package main
+
+// #include <stdint.h>
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// abiVersion is BLOBPOC_ABI_VERSION of blobpoc.h.
+const abiVersion = 1
+
+// Return codes of blobpoc.h, the same as the command's exit codes.
+const (
+	codeOK                  = 0
+	codeFailure             = 1
+	codeInvalidArgument     = 2
+	codeVerificationFailed  = 3
+	codeInvalidFieldElement = 5
+)
+
+func main() {}
+
+//export blob_abi_version
+func blob_abi_version() C.uint32_t {
+	return abiVersion
+}
+
+//export blob_commit_v1
+func blob_commit_v1(blobPtr, commitmentOut, versionedHashOut *C.uint8_t) (code C.int) {
+	defer recoverCode(&code)
+	if blobPtr == nil || commitmentOut == nil {
+		return codeInvalidArgument
+	}
+	b := readBlob(blobPtr)
+	commitment, err := blob.Commit(b)
+	if err != nil {
+		return errorCode(err)
+	}
+	write(commitmentOut, commitment[:])
+	if versionedHashOut != nil {
+		vh := blob.VersionedHash(commitment)
+		write(versionedHashOut, vh[:])
+	}
+	return codeOK
+}
+
+//export blob_prove_v1
+func blob_prove_v1(blobPtr, commitmentOut, proofOut, versionedHashOut *C.uint8_t) (code C.int) {
+	defer recoverCode(&code)
+	if blobPtr == nil || commitmentOut == nil || proofOut == nil {
+		return codeInvalidArgument
+	}
+	a, err := blob.NewArtifacts(readBlob(blobPtr), false)
+	if err != nil {
+		return errorCode(err)
+	}
+	write(commitmentOut, a.Commitment[:])
+	write(proofOut, a.Proof[:])
+	if versionedHashOut != nil {
+		write(versionedHashOut, a.VersionedHash[:])
+	}
+	return codeOK
+}
+
+//export blob_verify_v1
+func blob_verify_v1(blobPtr, commitmentPtr, proofPtr *C.uint8_t) (code C.int) {
+	defer recoverCode(&code)
+	if blobPtr == nil || commitmentPtr == nil || proofPtr == nil {
+		return codeInvalidArgument
+	}
+	var (
+		commitment kzg4844.Commitment
+		proof      kzg4844.Proof
+	)
+	copy(commitment[:], bytesAt(commitmentPtr, len(commitment)))
+	copy(proof[:], bytesAt(proofPtr, len(proof)))
+	return errorCode(blob.Verify(readBlob(blobPtr), commitment, proof))
+}
+
+// readBlob copies the blob at p into Go memory, so the caller may reuse its
+// buffer as soon as the call returns.
+func readBlob(p *C.uint8_t) *kzg4844.Blob {
+	b := new(kzg4844.Blob)
+	copy(b[:], bytesAt(p, len(b)))
+	return b
+}
+
+func bytesAt(p *C.uint8_t, n int) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(p)), n)
+}
+
+func write(p *C.uint8_t, data []byte) {
+	copy(bytesAt(p, len(data)), data)
+}
+
+// errorCode maps an error of pkg/blob to its return code.
+func errorCode(err error) C.int {
+	switch {
+	case err == nil:
+		return codeOK
+	case errors.Is(err, blob.ErrProofMismatch):
+		return codeVerificationFailed
+	case errors.Is(err, blob.ErrInvalidFieldElement):
+		return codeInvalidFieldElement
+	default:
+		return codeFailure
+	}
+}
+
+// recoverCode turns a panic into codeFailure, since a panic must not unwind
+// into C.
+func recoverCode(code *C.int) {
+	if recover() != nil {
+		*code = codeFailure
+	}
+}
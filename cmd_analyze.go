@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"time"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/fee"
+)
+
+// analysis is the JSON output of the analyze command.
+type analysis struct {
+	blob.Utilization
+	Compression      string   `json:"compression,omitempty"`
+	OriginalSize     int      `json:"original_size,omitempty"`
+	CompressionRatio float64  `json:"compression_ratio,omitempty"`
+	BlobGas          uint64   `json:"blob_gas"`
+	Transactions     int      `json:"transactions"`
+	Network          string   `json:"network,omitempty"`
+	Blocks           int      `json:"blocks,omitempty"`
+	BlobBaseFee      *big.Int `json:"blob_base_fee,omitempty"`
+	Cost             *big.Int `json:"cost_wei,omitempty"`
+	CostPerKiB       *big.Int `json:"cost_per_kib_wei,omitempty"`
+}
+
+func runAnalyze(args []string) error {
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	in := fs.String("in", "", "payload file")
+	compress := fs.String("compress", "none", "analyze the payload after compression: none, zlib, brotli or zstd")
+	blobBaseFee := new(bigFlag)
+	fs.Var(blobBaseFee, "blob-base-fee", "blob base fee in wei to price the payload at")
+	rpcURL := fs.String("rpc-url", "", "query the current blob base fee from this JSON-RPC endpoint")
+	timeout := fs.Duration("timeout", 30*time.Second, "RPC timeout")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc analyze [--blob-base-fee wei | --rpc-url url] [flags] <payload>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	c, err := blob.ParseCompression(*compress)
+	if err != nil {
+		return err
+	}
+	payload, err := readInputFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read payload: %w", err)
+	}
+
+	baseFee := blobBaseFee.Int
+	if *rpcURL != "" {
+		if baseFee != nil {
+			return fmt.Errorf("--blob-base-fee and --rpc-url are mutually exclusive")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		client, err := dialRPC(ctx, *rpcURL)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		if baseFee, err = fee.BlobBaseFee(ctx, client); err != nil {
+			return err
+		}
+	}
+
+	var a analysis
+	if c != blob.CompressionNone {
+		compressed, err := blob.Compress(c, payload)
+		if err != nil {
+			return err
+		}
+		a.Compression, a.OriginalSize = c.String(), len(payload)
+		a.CompressionRatio = float64(len(payload)) / float64(max(len(compressed), 1))
+		payload = compressed
+	}
+	a.Utilization = blob.Analyze(len(payload))
+	a.BlobGas = fee.BlobGas(a.Blobs)
+	perTx := maxBlobsPerTx()
+	a.Transactions = (a.Blobs + perTx - 1) / perTx
+	if network != nil {
+		a.Network = network.Name
+		perBlock := network.Latest().Blobs.Max
+		a.Blocks = (a.Blobs + perBlock - 1) / perBlock
+	}
+
+	if a.Compression != "" {
+		o.Printf("Original Size: %d bytes\n", a.OriginalSize)
+		o.Printf("Compressed Size (%s): %d bytes (%.2fx)\n", a.Compression, a.PayloadSize, a.CompressionRatio)
+	} else {
+		o.Printf("Payload Size: %d bytes\n", a.PayloadSize)
+	}
+	o.Printf("Blobs Needed: %d (%d bytes)\n", a.Blobs, a.BlobBytes)
+	o.Printf("Field Elements Used: %d of %d (%d encoded bytes)\n", a.FieldElements, a.Blobs*blob.FieldElementsPerBlob, a.EncodedBytes)
+	o.Printf("Utilization: %.2f%% of blob space\n", 100*a.Efficiency)
+	o.Printf("Blob Gas: %d\n", a.BlobGas)
+	o.Printf("Transactions Needed: %d (at most %d blobs each)\n", a.Transactions, perTx)
+	if a.Network != "" {
+		o.Printf("Blocks Needed on %s: at least %d (at most %d blobs each)\n", a.Network, a.Blocks, network.Latest().Blobs.Max)
+	}
+	if baseFee != nil {
+		a.BlobBaseFee = baseFee
+		a.Cost = fee.BlobCost(a.Blobs, baseFee)
+		// Price the user's data, which is the size before compression.
+		size := a.PayloadSize
+		if a.Compression != "" {
+			size = a.OriginalSize
+		}
+		if size > 0 {
+			a.CostPerKiB = new(big.Int).Div(new(big.Int).Mul(a.Cost, big.NewInt(1024)), big.NewInt(int64(size)))
+		}
+		o.Printf("Blob Base Fee: %s wei (%s gwei)\n", baseFee, formatGwei(baseFee))
+		o.Printf("Estimated Blob Cost: %s wei (%s gwei)\n", a.Cost, formatGwei(a.Cost))
+		if a.CostPerKiB != nil {
+			o.Printf("Cost per KiB of payload: %s wei (%s gwei)\n", a.CostPerKiB, formatGwei(a.CostPerKiB))
+		}
+	}
+	return o.emit(a)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/archive"
+	"kzg-blob-poc/pkg/blob"
+)
+
+// archivedBlob is the JSON report of one blob stored in or read from the
+// archive.
+type archivedBlob struct {
+	VersionedHash common.Hash        `json:"versioned_hash"`
+	Commitment    kzg4844.Commitment `json:"commitment"`
+	Proof         kzg4844.Proof      `json:"proof"`
+	CID           string             `json:"cid,omitempty"`
+	File          string             `json:"file,omitempty"`
+}
+
+// requireArchive opens the archive for the archive-* commands, which need
+// one.
+func requireArchive(url string) (*archive.Archive, error) {
+	if url == "" {
+		return nil, errors.New("--archive is required")
+	}
+	return openArchive(url)
+}
+
+func runArchivePut(args []string) error {
+	fs := flag.NewFlagSet("archive-put", flag.ExitOnError)
+	archiveURL := addArchiveFlag(fs, "blob archive to store the blobs in")
+	timeout := fs.Duration("timeout", 5*time.Minute, "overall timeout")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc archive-put --archive <url> [flags] <blob>...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return errors.New("at least one blob file is required")
+	}
+	a, err := requireArchive(*archiveURL)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	reports := make([]archivedBlob, fs.NArg())
+	for i, path := range fs.Args() {
+		b, err := readBlobFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		art, err := blob.NewArtifacts(&b, false)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if _, err := a.Put(ctx, &b, art); err != nil {
+			return err
+		}
+		r := archivedBlob{VersionedHash: art.VersionedHash, Commitment: art.Commitment, Proof: art.Proof, File: path}
+		if cid, ok := a.CID(art.VersionedHash); ok {
+			r.CID = cid
+			o.Printf("Archived %s as %s (CID %s)\n", path, art.VersionedHash, cid)
+		} else {
+			o.Printf("Archived %s as %s\n", path, art.VersionedHash)
+		}
+		reports[i] = r
+	}
+	return o.emit(reports)
+}
+
+func runArchiveGet(args []string) error {
+	fs := flag.NewFlagSet("archive-get", flag.ExitOnError)
+	archiveURL := addArchiveFlag(fs, "blob archive to read the blob from")
+	versionedHash := fs.String("versioned-hash", "", "versioned hash of the blob to retrieve")
+	out := fs.String("out", "", "write the raw blob to this file")
+	timeout := fs.Duration("timeout", time.Minute, "overall timeout")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc archive-get --archive <url> --out <file> [flags] (--versioned-hash <hash> | <hash>)")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	arg := *versionedHash
+	if arg == "" && fs.NArg() == 1 {
+		arg = fs.Arg(0)
+	}
+	if arg == "" {
+		return errors.New("--versioned-hash or a versioned hash argument is required")
+	}
+	hash, err := parseHexFixed("versioned hash", arg, common.HashLength)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("--out is required")
+	}
+	a, err := requireArchive(*archiveURL)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	b, art, err := a.Get(ctx, common.BytesToHash(hash))
+	if err != nil {
+		return err
+	}
+	if err := writeBinary(*out, b[:]); err != nil {
+		return err
+	}
+	r := archivedBlob{VersionedHash: art.VersionedHash, Commitment: art.Commitment, Proof: art.Proof, File: *out}
+	r.CID, _ = a.CID(art.VersionedHash)
+	o.Printf("Blob %s written to %s\n", art.VersionedHash, *out)
+	o.Printf("  KZG Commitment: %x\n", art.Commitment[:])
+	o.Printf("  KZG Proof: %x\n", art.Proof[:])
+	if r.CID != "" {
+		o.Printf("  CID: %s\n", r.CID)
+	}
+	return o.emit(r)
+}
+
+func runArchiveList(args []string) error {
+	fs := flag.NewFlagSet("archive-list", flag.ExitOnError)
+	archiveURL := addArchiveFlag(fs, "blob archive to list")
+	timeout := fs.Duration("timeout", time.Minute, "overall timeout")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc archive-list --archive <url> [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	a, err := requireArchive(*archiveURL)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	hashes, err := a.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, h := range hashes {
+		o.Println(h.Hex())
+	}
+	if hashes == nil {
+		hashes = []common.Hash{}
+	}
+	return o.emit(hashes)
+}
+
+// archiveBlobs stores blobs with their commitments and proofs in a. A nil
+// a archives nothing.
+func archiveBlobs(ctx context.Context, a *archive.Archive, blobs []kzg4844.Blob, commitments []kzg4844.Commitment, proofs []kzg4844.Proof) error {
+	if a == nil {
+		return nil
+	}
+	for i := range blobs {
+		art := &blob.BlobArtifacts{Commitment: commitments[i], Proof: proofs[i], VersionedHash: blob.VersionedHash(commitments[i])}
+		if _, err := a.Put(ctx, &blobs[i], art); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/batch"
+	"kzg-blob-poc/pkg/db"
+)
+
+func runBatch(args []string) error {
+	flags := flag.NewFlagSet("batch", flag.ExitOnError)
+	manifest := flags.String("manifest", "", "file listing one blob path per line, relative to the manifest")
+	pattern := flags.String("pattern", "*", "only process files in the directory whose name matches this glob")
+	workers := flags.Int("workers", runtime.NumCPU(), "number of concurrent workers")
+	format := flags.String("format", "", "report format: json or csv (default: from --out extension, else json)")
+	out := flags.String("out", "", "write the report to this file instead of stdout")
+	cacheDir := addCacheFlag(flags)
+	noProof := addNoProofFlag(flags)
+	blobTimeout := addBlobTimeoutFlag(flags)
+	pf := addProgressFlags(flags)
+	validateOnly := flags.Bool("validate-only", false, "only check that every field element of every blob is canonical, without KZG work")
+	metricsListen := flags.String("metrics-listen", "", "serve Prometheus /metrics on this address while the batch runs")
+	failFast := flags.Bool("fail-fast", false, "stop at the first blob that fails, instead of reporting every failure at the end")
+	failuresPath := flags.String("failures", "", "write the index, name and reason of each failed blob as JSON to this file")
+	o := addOutputFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: blob-poc batch [flags] (<dir> | --manifest <file>)")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	var (
+		paths []string
+		err   error
+	)
+	switch {
+	case *manifest != "":
+		paths, err = readManifestPaths(*manifest)
+	case flags.NArg() > 0:
+		paths, err = walkBlobDir(flags.Arg(0), *pattern)
+	default:
+		return errors.New("a directory or --manifest is required")
+	}
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return errors.New("no blob files found")
+	}
+
+	if *validateOnly {
+		return runValidateOnly(o, *out, paths, *failFast)
+	}
+
+	if o.json {
+		if *format == "csv" {
+			return errors.New("--json cannot be combined with --format csv")
+		}
+		*format = "json"
+	}
+	if *format == "" {
+		*format = "json"
+		if strings.EqualFold(filepath.Ext(*out), ".csv") {
+			*format = "csv"
+		}
+	}
+	if *format != "json" && *format != "csv" {
+		return fmt.Errorf("unknown report format %q", *format)
+	}
+
+	if *metricsListen != "" {
+		stop, err := serveMetrics(*metricsListen)
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+
+	jobs := make([]batch.Job, len(paths))
+	for i, path := range paths {
+		jobs[i] = batch.Job{
+			Name: path,
+			Load: func(dst *kzg4844.Blob) error {
+				return readBlobFileInto(path, dst)
+			},
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Allocation and GC counts show how much garbage the run produced. Blobs
+	// are decoded into pooled buffers, so what remains per blob is the file
+	// read and the KZG library's own.
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	c, err := openCache(*cacheDir)
+	if err != nil {
+		return err
+	}
+	pipeline := batch.NewPipeline(*workers)
+	pipeline.Cache = c
+	pipeline.NoProof = *noProof
+	pipeline.Progress = pf.start("batch", int64(len(jobs)))
+	pipeline.KeepGoing = !*failFast
+	pipeline.Timeout = *blobTimeout
+	var results []batch.Result
+	err = pipeline.Run(ctx, slices.Values(jobs), func(r batch.Result) error {
+		results = append(results, r)
+		return nil
+	})
+	pipeline.Progress.Finish()
+	var failed *batch.FailedError
+	if errors.As(err, &failed) {
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+	duration := time.Since(start)
+	runtime.ReadMemStats(&after)
+	slog.Info("Processed blobs", "blobs", len(results), "failed", len(jobs)-len(results), "duration", duration.Round(time.Millisecond), "workers", *workers,
+		"alloc_bytes_per_blob", (after.TotalAlloc-before.TotalAlloc)/uint64(max(len(results), 1)), "gc_cycles", after.NumGC-before.NumGC)
+
+	recs := make([]db.Record, len(results))
+	for i, r := range results {
+		recs[i] = db.Record{
+			VersionedHash: r.VersionedHash,
+			Commitment:    r.Commitment,
+			Proof:         r.Proof,
+			Source:        "batch",
+			File:          r.Name,
+		}
+	}
+	recordBlobs(ctx, recs...)
+
+	var buf bytes.Buffer
+	if *format == "csv" {
+		err = batch.WriteCSV(&buf, results)
+	} else {
+		err = batch.WriteJSON(&buf, results)
+	}
+	if err != nil {
+		return err
+	}
+	if err := writeOutput(*out, buf.Bytes()); err != nil {
+		return err
+	}
+	if failed != nil {
+		return reportFailures(failed, *failuresPath)
+	}
+	return nil
+}
+
+// reportFailures prints the summary of a batch run in which some blobs
+// failed to stderr, writes the failures as JSON to path unless it is empty,
+// and returns failed.
+func reportFailures(failed *batch.FailedError, path string) error {
+	fmt.Fprint(os.Stderr, failed.Summary())
+	if path != "" {
+		if err := writeJSON(path, failed.Failures); err != nil {
+			return err
+		}
+	}
+	return failed
+}
+
+// walkBlobDir returns every regular file under dir whose base name matches
+// pattern, in lexical order.
+func walkBlobDir(dir, pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid --pattern: %w", err)
+	}
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if ok, _ := filepath.Match(pattern, d.Name()); ok {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// readManifestPaths reads a manifest listing one blob path per line. Blank
+// lines and lines starting with '#' are ignored, and relative paths are
+// resolved against the manifest's directory.
+func readManifestPaths(manifest string) ([]string, error) {
+	f, err := os.Open(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer f.Close()
+
+	var paths []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(filepath.Dir(manifest), line)
+		}
+		paths = append(paths, line)
+	}
+	return paths, scanner.Err()
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/bench"
+	"kzg-blob-poc/pkg/config"
+)
+
+// benchColumns labels the table column of each operation.
+var benchColumns = map[string]string{
+	bench.OpCommit: "Commits/s",
+	bench.OpProve:  "Proofs/s",
+	bench.OpVerify: "Verifies/s",
+}
+
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	duration := fs.Duration("duration", 2*time.Second, "how long to measure each operation at each step")
+	maxGoroutines := fs.Int("max-goroutines", runtime.NumCPU(), "measure on 1, 2, 4, ... up to this many goroutines")
+	goroutineList := fs.String("goroutines", "", "comma-separated goroutine counts to measure instead, such as 1,8,32")
+	backendList := fs.String("backends", "", "comma-separated KZG libraries: gokzg, ckzg (default: both, skipping ckzg unless built with -tags ckzg)")
+	opList := fs.String("ops", strings.Join(bench.Ops, ","), "comma-separated operations: commit, prove, verify")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc bench [flags]")
+		fmt.Fprintln(fs.Output(), "Measures KZG throughput on this machine for sizing blob-publishing hosts.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	steps := bench.GoroutineSteps(*maxGoroutines)
+	if *goroutineList != "" {
+		steps = nil
+		for _, s := range strings.Split(*goroutineList, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid goroutine count %q", s)
+			}
+			steps = append(steps, n)
+		}
+	} else if *maxGoroutines < 1 {
+		return errors.New("--max-goroutines must be at least 1")
+	}
+	ops := strings.Split(*opList, ",")
+	for i, op := range ops {
+		ops[i] = strings.TrimSpace(op)
+		if _, ok := benchColumns[ops[i]]; !ok {
+			return fmt.Errorf("unknown operation %q (want %s)", op, strings.Join(bench.Ops, ", "))
+		}
+	}
+
+	// Without --backends, a library the binary was built without is left
+	// out; naming it is an error.
+	backends := []string{config.BackendGoKZG, config.BackendCKZG}
+	explicit := *backendList != ""
+	if explicit {
+		backends = strings.Split(*backendList, ",")
+	}
+	var available []string
+	for _, name := range backends {
+		name = strings.TrimSpace(name)
+		if err := useBackend(name); err != nil {
+			if explicit {
+				return err
+			}
+			slog.Warn("Skipping KZG backend", "backend", name, "err", err)
+			continue
+		}
+		available = append(available, name)
+	}
+	if len(available) == 0 {
+		return errors.New("no KZG backend available")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	b, err := bench.New(slices.Max(steps))
+	if err != nil {
+		return err
+	}
+	var results []bench.Result
+	for _, backend := range available {
+		if err := useBackend(backend); err != nil {
+			return err
+		}
+		for _, n := range steps {
+			for _, op := range ops {
+				r, err := b.Measure(ctx, backend, op, n, *duration)
+				if err != nil {
+					return fmt.Errorf("%s: %w", backend, err)
+				}
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				slog.Info("Measured", "backend", backend, "goroutines", n, "op", op, "per_second", fmt.Sprintf("%.1f", r.Rate))
+				results = append(results, r)
+			}
+		}
+	}
+
+	printBenchTable(o, ops, results)
+	return o.emit(results)
+}
+
+// useBackend switches the KZG library used for commitments and proofs. It
+// fails for ckzg unless the binary was built with -tags ckzg and cgo.
+func useBackend(name string) error {
+	switch name {
+	case config.BackendGoKZG:
+		return kzg4844.UseCKZG(false)
+	case config.BackendCKZG:
+		return kzg4844.UseCKZG(true)
+	default:
+		return fmt.Errorf("unknown backend %q (want %s or %s)", name, config.BackendGoKZG, config.BackendCKZG)
+	}
+}
+
+// printBenchTable prints a row per backend and goroutine count, with a
+// column per operation giving its rate and its speedup over the first
+// goroutine count, normally one.
+func printBenchTable(o *output, ops []string, results []bench.Result) {
+	tw := tabwriter.NewWriter(o.text(), 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "Backend\tGoroutines\t")
+	for _, op := range ops {
+		fmt.Fprintf(tw, "%s\t", benchColumns[op])
+	}
+	fmt.Fprintln(tw)
+	base := make(map[string]float64) // rate of each backend and op on the first step
+	for i := 0; i < len(results); i += len(ops) {
+		row := results[i : i+len(ops)]
+		fmt.Fprintf(tw, "%s\t%d\t", row[0].Backend, row[0].Goroutines)
+		for _, r := range row {
+			key := r.Backend + "/" + r.Op
+			if _, ok := base[key]; !ok {
+				base[key] = r.Rate
+			}
+			if base[key] == 0 {
+				fmt.Fprintf(tw, "%.1f\t", r.Rate)
+				continue
+			}
+			fmt.Fprintf(tw, "%.1f (%.1fx)\t", r.Rate, r.Rate/base[key])
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"kzg-blob-poc/pkg/metrics"
+	"kzg-blob-poc/pkg/tx"
+)
+
+func runBump(args []string) error {
+	fs := flag.NewFlagSet("bump", flag.ExitOnError)
+	rpcURL := fs.String("rpc-url", cfg.RPCURL, "execution client JSON-RPC endpoint")
+	txHash := fs.String("tx", "", "hash of the pending blob transaction to replace")
+	kf := addKeyFlags(fs)
+	percent := fs.Uint64("percent", tx.DefaultPriceBump, "raise the tip and both fee caps by this many percent")
+	wait := fs.Bool("wait", false, "wait for the replacement's receipt")
+	timeout := fs.Duration("timeout", 5*time.Minute, "overall timeout, including --wait and --wait-below-cap")
+	cf := addFeeCapFlags(fs)
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc bump --rpc-url <url> --tx <hash> (--key-file | --keystore | --mnemonic-file | --signer-url) <...> [flags] <blob>...")
+		fmt.Fprintln(fs.Output(), "The blobs must be those of the original transaction, as nodes do not return them.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *rpcURL == "" {
+		return errors.New("--rpc-url is required")
+	}
+	hash, err := parseHexFixed("tx hash", *txHash, common.HashLength)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("the blob files of the original transaction are required")
+	}
+	caps, err := cf.caps()
+	if err != nil {
+		return err
+	}
+	blobs := make([]kzg4844.Blob, fs.NArg())
+	for i, path := range fs.Args() {
+		if blobs[i], err = readBlobFile(path); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	sidecar, err := tx.NewSidecar(blobs)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	client, err := dialEth(ctx, *rpcURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	old, pending, err := client.TransactionByHash(ctx, common.BytesToHash(hash))
+	if err != nil {
+		return fmt.Errorf("failed to get transaction %x: %w", hash, err)
+	}
+	if !pending {
+		return fmt.Errorf("transaction %s is already included in a block", old.Hash())
+	}
+	oldSender, err := types.Sender(types.NewCancunSigner(old.ChainId()), old)
+	if err != nil {
+		return fmt.Errorf("failed to recover sender: %w", err)
+	}
+
+	signer, err := kf.signer(ctx)
+	if err != nil {
+		return err
+	}
+	if signer.Address() != oldSender {
+		return fmt.Errorf("transaction was sent by %s, but the key is for %s", oldSender, signer.Address())
+	}
+
+	params, err := replacementParams(ctx, client, old, *percent, caps, *cf.waitBelowCap)
+	if err != nil {
+		return err
+	}
+	unsigned, err := tx.Replace(old, params, sidecar)
+	if err != nil {
+		return err
+	}
+	signed, err := signer.SignTx(ctx, unsigned)
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	o.Printf("Replacing %s (nonce %d)\n", old.Hash(), old.Nonce())
+	o.Printf("  Max Priority Fee: %s -> %s gwei\n", formatGwei(old.GasTipCap()), formatGwei(signed.GasTipCap()))
+	o.Printf("  Max Fee: %s -> %s gwei\n", formatGwei(old.GasFeeCap()), formatGwei(signed.GasFeeCap()))
+	o.Printf("  Max Blob Fee: %s -> %s gwei\n", formatGwei(old.BlobGasFeeCap()), formatGwei(signed.BlobGasFeeCap()))
+	summary := newTxSummary(signed)
+	summary.print(o)
+
+	if err := client.SendTransaction(ctx, signed); err != nil && !tx.IsAlreadyKnown(err) {
+		metrics.RPCError("eth_sendRawTransaction")
+		return fmt.Errorf("failed to send transaction: %w", err)
+	}
+	o.Println("Replacement submitted")
+	if *wait {
+		receipt, err := tx.WaitMined(ctx, client, signed.Hash(), 2*time.Second)
+		if err != nil {
+			return err
+		}
+		o.Printf("Included in block %d (status %d)\n", receipt.BlockNumber, receipt.Status)
+		summary.BlockNumber = receipt.BlockNumber
+		summary.Status = &receipt.Status
+	}
+	return o.emit(summary)
+}
+
+// replacementParams returns the parameters of old's replacement, with its
+// fees bumped by percent and lowered to caps. With waitBelowCap, prices
+// above the caps are waited out instead of failing; caps too low for the
+// pool to accept the replacement fail at once, since waiting cannot help.
+func replacementParams(ctx context.Context, client *ethclient.Client, old *types.Transaction, percent uint64, caps *tx.FeeCaps, waitBelowCap bool) (tx.Params, error) {
+	waiting := false
+	for {
+		params, err := tx.ReplacementParams(ctx, client, old, percent)
+		if err != nil {
+			return params, err
+		}
+		err = caps.Apply(ctx, client, &params, len(old.BlobHashes()))
+		if err == nil {
+			return params, tx.CheckReplacement(old, params)
+		}
+		if !waitBelowCap || !errors.Is(err, tx.ErrFeeCap) {
+			return params, err
+		}
+		if !waiting {
+			slog.Info("Waiting for fees to fall below the caps", "reason", err)
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			return params, fmt.Errorf("%w (%w)", ctx.Err(), err)
+		case <-time.After(tx.DefaultSchedulePoll):
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+
+	"kzg-blob-poc/pkg/chain"
+	"kzg-blob-poc/pkg/fee"
+)
+
+// blobFeeCalculation is the JSON output of the calc-blob-fee command.
+type blobFeeCalculation struct {
+	*fee.BlobFees
+	Fork      string           `json:"fork"`
+	Blobs     chain.BlobParams `json:"blobs"`
+	NextFork  string           `json:"next_fork"`
+	NextBlobs chain.BlobParams `json:"next_blobs"`
+}
+
+func runCalcBlobFee(args []string) error {
+	fs := flag.NewFlagSet("calc-blob-fee", flag.ExitOnError)
+	headerPath := fs.String("header", "", "read the blob gas fields and fork from this block header JSON, as returned by eth_getBlockByNumber (- for stdin)")
+	excess := fs.Uint64("excess-blob-gas", 0, "excessBlobGas of the block")
+	used := fs.Uint64("blob-gas-used", 0, "blobGasUsed of the block")
+	forkName := fs.String("fork", "", "blob schedule to apply: cancun or prague (default: the fork of --header, else the latest of --network, else prague)")
+	target := fs.Int("target", 0, "override the target blobs per block")
+	maxBlobs := fs.Int("max", 0, "override the max blobs per block")
+	fraction := fs.Uint64("update-fraction", 0, "override the blob base fee update fraction")
+	expectNext := new(bigFlag)
+	fs.Var(expectNext, "expect-next", "fail unless the next blob base fee is this many wei, such as a node's eth_blobBaseFee")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc calc-blob-fee (--header <file> | --excess-blob-gas n --blob-gas-used n) [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if *headerPath != "" && (set["excess-blob-gas"] || set["blob-gas-used"]) {
+		return errors.New("--header and --excess-blob-gas or --blob-gas-used are mutually exclusive")
+	}
+
+	// The block's own fee uses its schedule and its child's excess the
+	// child's, which differ across a fork.
+	var fork, next chain.Fork
+	switch {
+	case *forkName != "":
+		f, err := chain.LookupFork(*forkName)
+		if err != nil {
+			return err
+		}
+		fork, next = f, f
+	case network != nil && *headerPath == "":
+		fork = network.Latest()
+		next = fork
+	default:
+		fork = chain.Fork{Name: chain.Prague, Blobs: chain.PragueBlobs}
+		next = fork
+	}
+	if *headerPath != "" {
+		h, err := readHeader(*headerPath)
+		if err != nil {
+			return err
+		}
+		if h.ExcessBlobGas == nil || h.BlobGasUsed == nil {
+			return fmt.Errorf("block %d has no blob gas fields: it is before Cancun", h.Number)
+		}
+		*excess, *used = *h.ExcessBlobGas, *h.BlobGasUsed
+		if *forkName == "" {
+			var ok bool
+			if fork, ok = headerFork(h); !ok {
+				return fmt.Errorf("block %d (time %d) is before Cancun on %s", h.Number, h.Time, network.Name)
+			}
+			next, _ = nextFork(h)
+		}
+	}
+	for _, bp := range []*chain.BlobParams{&fork.Blobs, &next.Blobs} {
+		if *target > 0 {
+			bp.Target = *target
+		}
+		if *maxBlobs > 0 {
+			bp.Max = *maxBlobs
+		}
+		if *fraction > 0 {
+			bp.UpdateFraction = *fraction
+		}
+	}
+
+	fees, err := fee.CalcBlobFees(fork.Blobs, next.Blobs, *excess, *used)
+	if err != nil {
+		return err
+	}
+	c := blobFeeCalculation{BlobFees: fees, Fork: fork.Name, Blobs: fork.Blobs, NextFork: next.Name, NextBlobs: next.Blobs}
+
+	o.Printf("Fork: %s (target %d, max %d blobs per block, update fraction %d)\n", fork.Name, fork.Blobs.Target, fork.Blobs.Max, fork.Blobs.UpdateFraction)
+	o.Printf("Excess Blob Gas: %d\n", fees.ExcessBlobGas)
+	o.Printf("Blob Gas Used: %d (%d blobs)\n", fees.BlobGasUsed, fees.BlobGasUsed/params.BlobTxBlobGasPerBlob)
+	o.Printf("Blob Base Fee: %s wei (%s gwei)\n", fees.BlobBaseFee, formatGwei(fees.BlobBaseFee))
+	if next != fork {
+		o.Printf("Next Fork: %s (target %d, max %d blobs per block, update fraction %d)\n", next.Name, next.Blobs.Target, next.Blobs.Max, next.Blobs.UpdateFraction)
+	}
+	o.Printf("Next Excess Blob Gas: %d\n", fees.NextExcessBlobGas)
+	o.Printf("Next Blob Base Fee: %s wei (%s gwei)\n", fees.NextBlobBaseFee, formatGwei(fees.NextBlobBaseFee))
+	if err := o.emit(c); err != nil {
+		return err
+	}
+	if expectNext.Int != nil && expectNext.Cmp(fees.NextBlobBaseFee) != 0 {
+		return fmt.Errorf("next blob base fee is %s wei, expected %s", fees.NextBlobBaseFee, expectNext)
+	}
+	return nil
+}
+
+// readHeader reads a block header in the JSON-RPC encoding from path, or
+// stdin for -. A whole JSON-RPC response is accepted too.
+func readHeader(path string) (*types.Header, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if json.Unmarshal(data, &resp) == nil && len(resp.Result) > 0 {
+		data = resp.Result
+	}
+	h := new(types.Header)
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	return h, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// cellsFile is the JSON document written by cells and read by the other
+// cell commands.
+type cellsFile struct {
+	Commitment kzg4844.Commitment `json:"commitment"`
+	Cells      []cellEntry        `json:"cells"`
+}
+
+// cellEntry is a single cell of an extended blob and, optionally, its proof.
+type cellEntry struct {
+	Index uint64         `json:"index"`
+	Cell  blob.Cell      `json:"cell"`
+	Proof *kzg4844.Proof `json:"proof,omitempty"`
+}
+
+func readCellsFile(path string) (*cellsFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cells file: %w", err)
+	}
+	var cf cellsFile
+	if err := json.Unmarshal(data, &cf); err != nil {
+		return nil, fmt.Errorf("failed to parse cells file: %w", err)
+	}
+	return &cf, nil
+}
+
+func runCells(args []string) error {
+	fs := flag.NewFlagSet("cells", flag.ExitOnError)
+	in := fs.String("in", "", "input blob file (hex text or raw binary)")
+	out := fs.String("out", "", "output cells JSON file")
+	noProofs := fs.Bool("no-proofs", false, "only compute the cells, skipping the cell proofs")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc cells --out <cells.json> [flags] <blob>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("--out is required")
+	}
+	b, err := readBlobFile(path)
+	if err != nil {
+		return err
+	}
+
+	commitment, err := blob.Commit(&b)
+	if err != nil {
+		return fmt.Errorf("failed to generate KZG commitment: %w", err)
+	}
+
+	var (
+		cells  []blob.Cell
+		proofs []kzg4844.Proof
+	)
+	if *noProofs {
+		cells, err = blob.ComputeCells(&b)
+	} else {
+		cells, proofs, err = blob.ComputeCellsAndKZGProofs(&b)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to compute cells: %w", err)
+	}
+
+	cf := cellsFile{Commitment: commitment, Cells: make([]cellEntry, len(cells))}
+	for i := range cells {
+		cf.Cells[i] = cellEntry{Index: uint64(i), Cell: cells[i]}
+		if proofs != nil {
+			cf.Cells[i].Proof = &proofs[i]
+		}
+	}
+	if err := writeJSON(*out, cf); err != nil {
+		return err
+	}
+
+	o.Printf("KZG Commitment: %x\n", commitment[:])
+	o.Printf("Wrote %d cells of %d bytes to %s\n", len(cells), blob.BytesPerCell, *out)
+	return o.emit(struct {
+		Commitment kzg4844.Commitment `json:"commitment"`
+		Cells      int                `json:"cells"`
+		Proofs     bool               `json:"proofs"`
+		File       string             `json:"file"`
+	}{commitment, len(cells), proofs != nil, *out})
+}
+
+func runVerifyCells(args []string) error {
+	fs := flag.NewFlagSet("verify-cells", flag.ExitOnError)
+	in := fs.String("in", "", "cells JSON file written by the cells command")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc verify-cells [flags] <cells.json>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	cf, err := readCellsFile(path)
+	if err != nil {
+		return err
+	}
+	if len(cf.Cells) == 0 {
+		return errors.New("cells file contains no cells")
+	}
+
+	var (
+		commitments = make([]kzg4844.Commitment, len(cf.Cells))
+		indices     = make([]uint64, len(cf.Cells))
+		cells       = make([]blob.Cell, len(cf.Cells))
+		proofs      = make([]kzg4844.Proof, len(cf.Cells))
+	)
+	for i, c := range cf.Cells {
+		if c.Proof == nil {
+			return fmt.Errorf("cell %d has no proof", c.Index)
+		}
+		commitments[i] = cf.Commitment
+		indices[i] = c.Index
+		cells[i] = c.Cell
+		proofs[i] = *c.Proof
+	}
+
+	err = blob.VerifyCellKZGProofBatch(commitments, indices, cells, proofs)
+	if emitErr := o.emit(newVerifyResult(err)); emitErr != nil {
+		return emitErr
+	}
+	if err != nil {
+		return fmt.Errorf("cell %w", err)
+	}
+	o.Printf("✅ %d cell proofs verified against commitment %x\n", len(cells), cf.Commitment[:])
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"kzg-blob-poc/pkg/manifest"
+)
+
+func runProveChunk(args []string) error {
+	fs := flag.NewFlagSet("prove-chunk", flag.ExitOnError)
+	manifestPath := fs.String("manifest", "", "artifact manifest with a chunk root")
+	index := fs.Int("index", -1, "index of the chunk to prove (default: the entry whose file is --blob)")
+	blobPath := fs.String("blob", "", "blob file whose manifest entry to prove, instead of --index")
+	out := fs.String("out", "", "write the proof to this file instead of stdout")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc prove-chunk --manifest <file> (--index n | --blob <file>) [--out proof.json]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *manifestPath == "" {
+		return errors.New("--manifest is required")
+	}
+	if (*index < 0) == (*blobPath == "") {
+		return errors.New("exactly one of --index and --blob is required")
+	}
+	m, err := manifest.Read(*manifestPath)
+	if err != nil {
+		return err
+	}
+	if *index < 0 {
+		if *index = manifestIndex(*manifestPath, m, *blobPath); *index < 0 {
+			return fmt.Errorf("%s is not listed in %s; pass --index", *blobPath, *manifestPath)
+		}
+	}
+	proof, err := m.ProveChunk(*index)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(*out, proof); err != nil {
+		return err
+	}
+	if *out != "" {
+		o.Printf("Wrote the proof of chunk %d of %d under chunk root %s to %s\n", proof.Index, proof.Count, m.ChunkRoot, *out)
+	}
+	return nil
+}
+
+func runVerifyChunk(args []string) error {
+	fs := flag.NewFlagSet("verify-chunk", flag.ExitOnError)
+	rootHex := fs.String("root", "", "trusted chunk root of the payload, as in its manifest's chunk_root")
+	proofPath := fs.String("proof", "", "chunk proof written by prove-chunk")
+	blobPath := fs.String("blob", "", "blob file (hex text or raw binary)")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc verify-chunk --root <hex> --proof <file> --blob <file>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *rootHex == "" || *proofPath == "" || *blobPath == "" {
+		return errors.New("--root, --proof and --blob are required")
+	}
+	root, err := parseHex32("root", *rootHex)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(*proofPath)
+	if err != nil {
+		return fmt.Errorf("failed to read chunk proof: %w", err)
+	}
+	proof := new(manifest.ChunkProof)
+	if err := json.Unmarshal(data, proof); err != nil {
+		return fmt.Errorf("invalid chunk proof: %w", err)
+	}
+	b, err := readBlobFile(*blobPath)
+	if err != nil {
+		return err
+	}
+
+	err = manifest.VerifyChunk(common.Hash(root), &b, proof)
+	if emitErr := o.emit(newVerifyResult(err)); emitErr != nil {
+		return emitErr
+	}
+	if err != nil {
+		return err
+	}
+	o.Printf("✅ Blob holds chunk %d of %d (%d bytes at offset %d) of the payload with chunk root %s\n",
+		proof.Index, proof.Count, proof.Size, proof.Offset, common.Hash(root))
+	return nil
+}
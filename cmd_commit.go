@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/cache"
+	"kzg-blob-poc/pkg/db"
+)
+
+func runCommit(args []string) error {
+	fs := flag.NewFlagSet("commit", flag.ExitOnError)
+	in := fs.String("in", "", "input blob file (hex text or raw binary)")
+	out := fs.String("out", "", "write the result to this file instead of stdout")
+	cacheDir := addCacheFlag(fs)
+	validateOnly := fs.Bool("validate-only", false, "only check that every field element is canonical, without KZG work")
+	hf := addHashFlags(fs)
+	tf := addTxBlobFlags(fs)
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc commit [flags] (<file> | --from-tx <hash> --rpc-url <url> --beacon-url <url>)")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if tf.txHash != "" {
+		if *in != "" || fs.NArg() > 0 || *validateOnly {
+			return errors.New("--from-tx cannot be combined with a blob file or --validate-only")
+		}
+		return commitFromTx(o, tf, hf, *cacheDir, *out)
+	}
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *validateOnly {
+		return runValidateOnly(o, *out, []string{path}, true)
+	}
+	b, err := readBlobFile(path)
+	if err != nil {
+		return err
+	}
+
+	c, err := openCache(*cacheDir)
+	if err != nil {
+		return err
+	}
+	commitment, err := cachedCommit(c, &b)
+	if err != nil {
+		return err
+	}
+	versionedHash, err := hf.versionedHash(commitment)
+	if err != nil {
+		return err
+	}
+
+	recordBlobs(context.Background(), db.Record{
+		VersionedHash: blob.VersionedHash(commitment),
+		Commitment:    commitment,
+		Source:        "commit",
+		File:          path,
+	})
+
+	text := fmt.Sprintf("KZG Commitment: %x\nVersioned Hash: %x\n", commitment[:], versionedHash[:])
+	return o.report(*out, text, struct {
+		Commitment    kzg4844.Commitment `json:"commitment"`
+		VersionedHash common.Hash        `json:"versioned_hash"`
+	}{commitment, versionedHash})
+}
+
+// committedBlob is the JSON output of commit --from-tx for each blob.
+type committedBlob struct {
+	Index         int                `json:"index"`
+	Commitment    kzg4844.Commitment `json:"commitment"`
+	VersionedHash common.Hash        `json:"versioned_hash"`
+	Match         bool               `json:"match"`
+}
+
+// commitFromTx commits to the blobs of the --from-tx transaction and checks
+// each commitment against the transaction's versioned hash.
+func commitFromTx(o *output, tf *txBlobFlags, hf *hashFlags, cacheDir, out string) error {
+	blobs, hashes, err := tf.load(o)
+	if err != nil {
+		return err
+	}
+	c, err := openCache(cacheDir)
+	if err != nil {
+		return err
+	}
+	var (
+		text       strings.Builder
+		committed  = make([]committedBlob, len(blobs))
+		mismatched int
+	)
+	for i := range blobs {
+		commitment, err := cachedCommit(c, &blobs[i])
+		if err != nil {
+			return fmt.Errorf("blob %d: %w", i, err)
+		}
+		versionedHash, err := hf.versionedHash(commitment)
+		if err != nil {
+			return err
+		}
+		// The transaction's hashes are always EIP-4844 ones, whatever
+		// --hash and --hash-version report.
+		match := blob.VersionedHash(commitment) == hashes[i]
+		committed[i] = committedBlob{Index: i, Commitment: commitment, VersionedHash: versionedHash, Match: match}
+		fmt.Fprintf(&text, "Blob %d:\n  KZG Commitment: %x\n  Versioned Hash: %x\n", i, commitment[:], versionedHash[:])
+		if !match {
+			mismatched++
+			fmt.Fprintf(&text, "  ❌ does not match the transaction's versioned hash %x\n", hashes[i][:])
+		}
+	}
+	if err := o.report(out, text.String(), committed); err != nil {
+		return err
+	}
+	if mismatched > 0 {
+		return fmt.Errorf("%w: %d of %d blobs do not commit to the transaction's versioned hashes", blob.ErrVersionedHashMismatch, mismatched, len(blobs))
+	}
+	return nil
+}
+
+// cachedCommit returns the commitment of b from c, or computes it. A cache
+// miss is not filled in, since that would also mean computing the proof.
+func cachedCommit(c *cache.Cache, b *kzg4844.Blob) (kzg4844.Commitment, error) {
+	if a, ok := c.Get(b); ok {
+		return a.Commitment, nil
+	}
+	commitment, err := blob.Commit(b)
+	if err != nil {
+		return commitment, fmt.Errorf("failed to generate KZG commitment: %w", err)
+	}
+	return commitment, nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/db"
+)
+
+// blobDBPath is the blob database set with --db or db_path. Empty records
+// nothing.
+var blobDBPath string
+
+// openDB opens the blob database once per process. It returns nil when none
+// is configured.
+var openDB = sync.OnceValues(func() (*db.DB, error) {
+	if blobDBPath == "" {
+		return nil, nil
+	}
+	return db.Open(blobDBPath)
+})
+
+// recordBlobs adds recs to the blob database, if one is configured. Failures
+// are logged rather than returned: the database indexes work that has
+// already been done, such as a transaction that was already sent.
+func recordBlobs(ctx context.Context, recs ...db.Record) {
+	d, err := openDB()
+	if err == nil {
+		err = d.Record(ctx, recs...)
+	}
+	if err != nil {
+		slog.Warn("Failed to record blobs in the database", "db", blobDBPath, "blobs", len(recs), "err", err)
+	}
+}
+
+// requireDB opens the blob database for the db-* commands, which need one.
+func requireDB() (*db.DB, error) {
+	if blobDBPath == "" {
+		return nil, errors.New("no blob database configured: use --db <file> before the command, or db_path in the config")
+	}
+	return openDB()
+}
+
+func runDBList(args []string) error {
+	fs := flag.NewFlagSet("db-list", flag.ExitOnError)
+	limit := fs.Int("limit", 50, "most blobs to list, most recent first (0 lists all)")
+	source := fs.String("source", "", "only list blobs first recorded by this command, e.g. split or fetch")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc --db <file> db-list [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	d, err := requireDB()
+	if err != nil {
+		return err
+	}
+	blobs, err := d.Find(context.Background(), db.Query{Source: *source, Limit: *limit})
+	if err != nil {
+		return err
+	}
+	printDBBlobs(o, blobs)
+	return o.emit(nonNil(blobs))
+}
+
+func runDBShow(args []string) error {
+	fs := flag.NewFlagSet("db-show", flag.ExitOnError)
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc --db <file> db-show [flags] <versioned hash>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("exactly one versioned hash is required")
+	}
+	hash, err := parseHexFixed("versioned hash", fs.Arg(0), common.HashLength)
+	if err != nil {
+		return err
+	}
+	d, err := requireDB()
+	if err != nil {
+		return err
+	}
+	b, err := d.Get(context.Background(), common.BytesToHash(hash))
+	if err != nil {
+		return err
+	}
+	printDBBlob(o, b)
+	o.Printf("  KZG Commitment: %x\n", b.Commitment[:])
+	o.Printf("  KZG Proof: %x\n", b.Proof[:])
+	return o.emit(b)
+}
+
+func runDBSearch(args []string) error {
+	fs := flag.NewFlagSet("db-search", flag.ExitOnError)
+	payload := fs.String("payload", "", "find the blobs carrying this payload file")
+	payloadHash := fs.String("payload-hash", "", "find the blobs carrying the payload with this SHA-256")
+	txHash := fs.String("tx", "", "find the blobs carried by this transaction")
+	block := fs.Uint64("block", 0, "find the blobs included in this block")
+	commitment := fs.String("commitment", "", "find the blob with this KZG commitment")
+	limit := fs.Int("limit", 0, "most blobs to return, most recent first (0 returns all)")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc --db <file> db-search (--payload <file> | --payload-hash <hash> | --tx <hash> | --block <n> | --commitment <hex>) [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	q := db.Query{BlockNumber: *block, Limit: *limit}
+	if *payload != "" && *payloadHash != "" {
+		return errors.New("--payload and --payload-hash are mutually exclusive")
+	}
+	if *payload != "" {
+		h, err := hashFile(*payload)
+		if err != nil {
+			return err
+		}
+		q.PayloadHash = h
+	}
+	if *payloadHash != "" {
+		h, err := parseHexFixed("payload hash", *payloadHash, common.HashLength)
+		if err != nil {
+			return err
+		}
+		q.PayloadHash = common.BytesToHash(h)
+	}
+	if *txHash != "" {
+		h, err := parseHexFixed("tx hash", *txHash, common.HashLength)
+		if err != nil {
+			return err
+		}
+		q.TxHash = common.BytesToHash(h)
+	}
+	if *commitment != "" {
+		c, err := parseHexFixed("commitment", *commitment, len(kzg4844.Commitment{}))
+		if err != nil {
+			return err
+		}
+		q.Commitment = (*kzg4844.Commitment)(c)
+	}
+	if q.PayloadHash == (common.Hash{}) && q.TxHash == (common.Hash{}) && q.BlockNumber == 0 && q.Commitment == nil {
+		return errors.New("at least one of --payload, --payload-hash, --tx, --block or --commitment is required")
+	}
+
+	d, err := requireDB()
+	if err != nil {
+		return err
+	}
+	blobs, err := d.Find(context.Background(), q)
+	if err != nil {
+		return err
+	}
+	if len(blobs) == 0 {
+		o.Println("No matching blobs")
+	}
+	printDBBlobs(o, blobs)
+	return o.emit(nonNil(blobs))
+}
+
+func printDBBlobs(o *output, blobs []*db.Blob) {
+	for _, b := range blobs {
+		printDBBlob(o, b)
+	}
+}
+
+// printDBBlob prints the summary of a recorded blob shared by the db-*
+// commands.
+func printDBBlob(o *output, b *db.Blob) {
+	o.Printf("%s (%s, %s)\n", b.VersionedHash, b.Source, b.CreatedAt.Local().Format(time.DateTime))
+	if b.PayloadHash != nil {
+		o.Printf("  Payload SHA-256: %s\n", b.PayloadHash)
+	}
+	if b.File != "" {
+		o.Printf("  File: %s\n", b.File)
+	}
+	for _, inc := range b.Inclusions {
+		if inc.BlockNumber != nil {
+			o.Printf("  Transaction %s in block %d\n", inc.TxHash, *inc.BlockNumber)
+		} else {
+			o.Printf("  Transaction %s\n", inc.TxHash)
+		}
+	}
+}
+
+// nonNil makes an empty result encode as [] rather than null.
+func nonNil(blobs []*db.Blob) []*db.Blob {
+	if blobs == nil {
+		return []*db.Blob{}
+	}
+	return blobs
+}
+
+// hashFile returns the SHA-256 of the file at path.
+func hashFile(path string) (common.Hash, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(h.Sum(nil)), nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/codec"
+	"kzg-blob-poc/pkg/manifest"
+)
+
+func runDecode(args []string) error {
+	fs := flag.NewFlagSet("decode", flag.ExitOnError)
+	in := fs.String("in", "", "input blob file (hex text or raw binary)")
+	out := fs.String("out", "", "output payload file")
+	raw := fs.Bool("raw", false, "read an unframed blob; the output includes the zero padding")
+	auto := fs.Bool("auto", false, "detect the layout and compression of a blob from an unknown producer")
+	manifestPath := fs.String("manifest", "", "decode the blobs listed in this artifact manifest and check the payload against it")
+	ef := addEncryptionFlags(fs, "decrypt an encrypted payload")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc decode --out <payload> [flags] (<blob> | --manifest <file>)")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *manifestPath != "" {
+		if *out == "" {
+			return errors.New("--out is required")
+		}
+		if *raw || *auto || *in != "" || fs.NArg() > 0 || ef.keyFile != "" || ef.passphraseFile != "" {
+			return errors.New("--manifest cannot be combined with a blob, --raw, --auto or decryption")
+		}
+		return decodeManifest(o, *manifestPath, *out)
+	}
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("--out is required")
+	}
+	if *auto && *raw {
+		return errors.New("--auto cannot be combined with --raw")
+	}
+	secret, err := ef.secret()
+	if err != nil {
+		return err
+	}
+	if secret != nil && (*raw || *auto) {
+		return errors.New("decryption needs a frame header and cannot be combined with --raw or --auto")
+	}
+	b, err := readBlobFile(path)
+	if err != nil {
+		return err
+	}
+	if *auto {
+		return decodeAuto(o, &b, *out)
+	}
+
+	var (
+		payload []byte
+		res     = fileResult{File: *out}
+	)
+	if *raw {
+		payload, err = blob.Unpack(&b, blob.MaxPackedSize)
+	} else {
+		var frame blob.Frame
+		if frame, err = blob.ReadFrame(&b); err == nil {
+			payload, err = blob.DecodeFramedEncrypted(b, secret)
+		}
+		if errors.Is(err, blob.ErrEncrypted) {
+			err = fmt.Errorf("%w: give --encryption-key-file or --passphrase-file", err)
+		}
+		if err == nil && frame.Encrypted {
+			res.Encrypted, res.StoredSize = true, frame.StoredSize
+			o.Printf("Decrypted %d bytes with AES-256-GCM\n", frame.StoredSize)
+		}
+		if err == nil && frame.Compression != blob.CompressionNone {
+			res.Compression, res.StoredSize = frame.Compression.String(), frame.StoredSize
+			o.Printf("Decompressed with %s\n", frame.Compression)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode blob: %w", err)
+	}
+	res.PayloadSize = len(payload)
+	if err := writeBinary(*out, payload); err != nil {
+		return err
+	}
+	o.Printf("Recovered %d bytes\n", len(payload))
+	return o.emit(res)
+}
+
+// decodeResult is the --json output of decode --auto.
+type decodeResult struct {
+	fileResult
+	Scheme string `json:"scheme"`
+}
+
+// decodeAuto sniffs the layout and compression of b and writes the payload
+// of the first decode chain that succeeds.
+func decodeAuto(o *output, b *kzg4844.Blob, out string) error {
+	r, err := blob.Sniff(b)
+	if err != nil {
+		return fmt.Errorf("failed to decode blob: %w", err)
+	}
+	if err := writeBinary(out, r.Payload); err != nil {
+		return err
+	}
+	o.Printf("Detected scheme %s\n", r.Scheme())
+	o.Printf("Recovered %d bytes\n", len(r.Payload))
+	res := decodeResult{fileResult: fileResult{PayloadSize: len(r.Payload), File: out}, Scheme: r.Scheme()}
+	if len(r.Codecs) > 0 {
+		res.Compression = r.Codecs[len(r.Codecs)-1]
+	}
+	return o.emit(res)
+}
+
+// decodeManifest reassembles the payload described by the manifest at path
+// from its blob files and writes it to out once it matches the recorded
+// size and SHA-256. The blobs' artifacts are not checked.
+func decodeManifest(o *output, path, out string) error {
+	m, err := manifest.Read(path)
+	if err != nil {
+		return err
+	}
+	blobs := make([]kzg4844.Blob, len(m.Blobs))
+	for i, entry := range m.Blobs {
+		if entry.File == "" {
+			return fmt.Errorf("blob %d: the manifest names no file", i)
+		}
+		if blobs[i], err = readBlobFile(manifestBlobPath(filepath.Dir(path), entry)); err != nil {
+			return fmt.Errorf("blob %d: %w", i, err)
+		}
+	}
+	payload, err := codec.Decode(m, blobs)
+	if err != nil {
+		return err
+	}
+	if err := writeBinary(out, payload); err != nil {
+		return err
+	}
+	o.Printf("Recovered %d bytes from %d blobs (SHA-256 %x)\n", len(payload), len(blobs), m.Payload.SHA256[:])
+	return o.emit(fileResult{PayloadSize: len(payload), File: out, Compression: m.Encoding.Compression})
+}
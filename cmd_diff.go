@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// diffReport is the JSON output of diff.
+type diffReport struct {
+	A string `json:"a"`
+	B string `json:"b"`
+	*blob.BlobDiff
+	Equal bool `json:"equal"`
+	// The commitments, with --commit.
+	CommitmentA *kzg4844.Commitment `json:"commitment_a,omitempty"`
+	CommitmentB *kzg4844.Commitment `json:"commitment_b,omitempty"`
+}
+
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	maxListed := fs.Int("max", 64, "list at most this many differing field elements (0 for all)")
+	commit := fs.Bool("commit", false, "also compute and compare the KZG commitments of both blobs")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc diff [flags] <a> <b>")
+		fmt.Fprintln(fs.Output(), "Reports the field elements at which two blobs differ; exits with 3 if they do.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return errors.New("two blob files are required")
+	}
+	pathA, pathB := fs.Arg(0), fs.Arg(1)
+	a, err := readBlobFile(pathA)
+	if err != nil {
+		return fmt.Errorf("%s: %w", pathA, err)
+	}
+	b, err := readBlobFile(pathB)
+	if err != nil {
+		return fmt.Errorf("%s: %w", pathB, err)
+	}
+
+	d := blob.DiffBlobs(&a, &b)
+	report := diffReport{A: pathA, B: pathB, BlobDiff: d, Equal: d.Equal()}
+	if *commit {
+		report.CommitmentA = diffCommit(o, pathA, &a)
+		report.CommitmentB = diffCommit(o, pathB, &b)
+	}
+	if d.Equal() {
+		o.Printf("✅ %s and %s are identical\n", pathA, pathB)
+		return o.emit(report)
+	}
+
+	o.Printf("❌ %d of %d field elements differ (%d bytes): elements %s\n",
+		len(d.Elements), blob.FieldElementsPerBlob, d.DifferingBytes, d.Ranges())
+	invalidA, invalidB := nonCanonical(&a), nonCanonical(&b)
+	for i, e := range d.Elements {
+		if *maxListed > 0 && i == *maxListed {
+			o.Printf("... and %d more elements (raise --max to list them)\n", len(d.Elements)-i)
+			break
+		}
+		var notes []string
+		if slices.Contains(invalidA, e.Index) {
+			notes = append(notes, "a is not canonical")
+		}
+		if slices.Contains(invalidB, e.Index) {
+			notes = append(notes, "b is not canonical")
+		}
+		o.Println()
+		o.Printf("%4d  %06x  a %x\n", e.Index, e.Offset, []byte(e.A))
+		o.Printf("%4s  %6s  b %x\n", "", "", []byte(e.B))
+		o.Printf("%4s  %6s    %s bytes %s\n", "", "", carets(e.Bytes), e.Ranges())
+		if len(notes) > 0 {
+			o.Printf("%4s  %6s    %s\n", "", "", strings.Join(notes, ", "))
+		}
+	}
+	if err := o.emit(report); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %d field elements", blob.ErrBlobMismatch, len(d.Elements))
+}
+
+// diffCommit prints and returns the commitment of b, or prints why it has
+// none, such as a non-canonical field element.
+func diffCommit(o *output, path string, b *kzg4844.Blob) *kzg4844.Commitment {
+	c, err := blob.Commit(b)
+	if err != nil {
+		o.Printf("%s: no commitment: %v\n", path, err)
+		return nil
+	}
+	o.Printf("%s: commitment %x\n", path, c)
+	return &c
+}
+
+// nonCanonical returns the indices of the non-canonical field elements of b.
+func nonCanonical(b *kzg4844.Blob) []int {
+	var ferr *blob.FieldElementError
+	if errors.As(blob.ValidateBlob(b), &ferr) {
+		return ferr.Indices
+	}
+	return nil
+}
+
+// carets marks the given byte positions of a hex-encoded field element.
+func carets(positions []int) string {
+	marks := []byte(strings.Repeat(" ", 2*blob.BytesPerFieldElement))
+	for _, p := range positions {
+		marks[2*p], marks[2*p+1] = '^', '^'
+	}
+	return string(marks)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"crypto/sha256"
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/manifest"
+)
+
+func runEncode(args []string) error {
+	fs := flag.NewFlagSet("encode", flag.ExitOnError)
+	in := fs.String("in", "", "raw payload file")
+	out := fs.String("out", "", "output blob file")
+	asHex := fs.Bool("hex", false, "write the blob as hex text instead of raw binary")
+	raw := fs.Bool("raw", false, "pack the payload without a frame header (decode with decode --raw)")
+	compress := fs.String("compress", "none", "compress the payload first: none, zlib, brotli or zstd")
+	manifestPath := fs.String("manifest", "", "also write an artifact manifest of the payload and blob to this file")
+	ef := addEncryptionFlags(fs, "encrypt the payload, after compressing it,")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc encode --out <blob> [flags] <payload>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("--out is required")
+	}
+	c, err := blob.ParseCompression(*compress)
+	if err != nil {
+		return err
+	}
+	if *raw && c != blob.CompressionNone {
+		return errors.New("--compress needs a frame header and cannot be combined with --raw")
+	}
+	secret, err := ef.secret()
+	if err != nil {
+		return err
+	}
+	if secret != nil && *raw {
+		return errors.New("encryption needs a frame header and cannot be combined with --raw")
+	}
+	if secret != nil && *manifestPath != "" {
+		// A manifest records the payload's hash in the clear and is meant
+		// to be reproducible, which a randomly encrypted blob is not.
+		return errors.New("--manifest cannot be combined with encryption")
+	}
+	payload, err := readInputFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read payload: %w", err)
+	}
+
+	var b kzg4844.Blob
+	if *raw {
+		b, err = blob.Pack(payload)
+	} else {
+		b, err = blob.EncodeFramedEncrypted(payload, c, secret)
+	}
+	if err != nil {
+		return fmt.Errorf("%w (use split for multi-blob payloads)", err)
+	}
+
+	data := encodeOutput(b[:])
+	if *asHex {
+		data = []byte(fmt.Sprintf("0x%x\n", b[:]))
+	}
+	if err := writeOutput(*out, data); err != nil {
+		return err
+	}
+	res := fileResult{PayloadSize: len(payload), File: *out}
+	if !*raw && (c != blob.CompressionNone || secret != nil) {
+		frame, err := blob.ReadFrame(&b)
+		if err != nil {
+			return err
+		}
+		res.StoredSize, res.Encrypted = frame.StoredSize, frame.Encrypted
+		if c != blob.CompressionNone {
+			compressed := frame.StoredSize
+			if secret != nil {
+				compressed -= secret.Overhead()
+			}
+			res.Compression = c.String()
+			o.Printf("Compressed %d bytes to %d with %s (%.2fx, %.1f%% of blob capacity)\n",
+				len(payload), compressed, c, float64(len(payload))/float64(max(compressed, 1)),
+				100*float64(compressed)/float64(blob.MaxFramedPayloadSize))
+		}
+		if secret != nil {
+			o.Printf("Encrypted with AES-256-GCM: %d bytes stored\n", frame.StoredSize)
+		}
+	}
+	o.Printf("Encoded %d bytes into %s\n", len(payload), *out)
+	if *manifestPath != "" {
+		if err := writeEncodeManifest(*manifestPath, *out, payload, &b, *raw, c); err != nil {
+			return err
+		}
+		o.Printf("Wrote manifest %s\n", *manifestPath)
+	}
+	return o.emit(res)
+}
+
+// writeEncodeManifest writes the artifact manifest of a payload encoded into
+// the single blob b, stored at blobPath.
+func writeEncodeManifest(path, blobPath string, payload []byte, b *kzg4844.Blob, raw bool, c blob.Compression) error {
+	a, err := blob.NewArtifacts(b, false)
+	if err != nil {
+		return err
+	}
+	// A raw blob is the chunked layout with a single chunk.
+	enc := manifest.NewEncoding(manifest.LayoutFramed, c.String())
+	if raw {
+		enc = manifest.NewEncoding(manifest.LayoutChunked, "")
+	}
+	file, err := filepath.Rel(filepath.Dir(path), blobPath)
+	if err != nil {
+		file = blobPath
+	}
+	m := &manifest.Manifest{
+		Version:  manifest.Version,
+		Tool:     toolInfo(),
+		Encoding: enc,
+		Payload:  manifest.NewPayload(payload),
+		Blobs: []manifest.Blob{{
+			Chunk:         blob.Chunk{Size: len(payload)},
+			File:          filepath.ToSlash(file),
+			Commitment:    a.Commitment,
+			Proof:         a.Proof,
+			VersionedHash: a.VersionedHash,
+		}},
+	}
+	if raw {
+		m.Blobs[0].ChunkHash = sha256.Sum256(payload)
+		m.ChunkRoot = manifest.ChunkRoot(m.Blobs)
+	}
+	return m.Write(path)
+}
+
+// fileResult is the JSON output of commands that convert between a payload
+// and the file(s) holding it.
+type fileResult struct {
+	PayloadSize int    `json:"payload_size"`
+	File        string `json:"file"`
+	Compression string `json:"compression,omitempty"`
+	Encrypted   bool   `json:"encrypted,omitempty"`
+	StoredSize  int    `json:"stored_size,omitempty"`
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"kzg-blob-poc/pkg/calldata"
+)
+
+// callFlags are the flags of the commands that call a contract in their
+// blob transactions.
+type callFlags struct {
+	signature *string
+	args      listFlag
+}
+
+func addCallFlags(fs *flag.FlagSet) *callFlags {
+	f := &callFlags{
+		signature: fs.String("call", "", "call this function of --to, such as 'registerBlobs(bytes32[])', with each transaction's data"),
+	}
+	fs.Var(&f.args, "call-arg", "argument of --call, in order; repeat for each. Arrays and tuples are JSON arrays, and "+calldata.BlobHashes+", "+calldata.BlobHash+"<i> and "+calldata.BlobCount+" stand for the transaction's blobs")
+	return f
+}
+
+// parse returns the call of the flags, or nil if --call is not set. data
+// is the --data of the command, which a call replaces.
+func (f *callFlags) parse(data string) (*calldata.Call, error) {
+	if *f.signature == "" {
+		if len(f.args) > 0 {
+			return nil, errors.New("--call-arg requires --call")
+		}
+		return nil, nil
+	}
+	if data != "" {
+		return nil, errors.New("--call and --data are mutually exclusive")
+	}
+	return parseCall(*f.signature, f.args)
+}
+
+// parseCall parses a function signature and its command-line arguments.
+func parseCall(signature string, args []string) (*calldata.Call, error) {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		var err error
+		if values[i], err = calldata.ParseArg(arg); err != nil {
+			return nil, err
+		}
+	}
+	return calldata.Parse(signature, values)
+}
+
+// encodedCall is the JSON output of encode-call.
+type encodedCall struct {
+	Signature string        `json:"signature"`
+	Selector  hexutil.Bytes `json:"selector"`
+	Data      hexutil.Bytes `json:"data"`
+}
+
+func runEncodeCall(args []string) error {
+	fs := flag.NewFlagSet("encode-call", flag.ExitOnError)
+	var blobHashes listFlag
+	fs.Var(&blobHashes, "blob-hash", "versioned hash the blob placeholders stand for; repeat for each blob")
+	out := fs.String("out", "", "write the calldata hex to this file")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc encode-call [--blob-hash hash]... <signature> [arg]...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return errors.New("a function signature is required")
+	}
+	call, err := parseCall(fs.Arg(0), fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	hashes := make([]common.Hash, len(blobHashes))
+	for i, s := range blobHashes {
+		b, err := hexutil.Decode(ensureHexPrefix(s))
+		if err != nil || len(b) != common.HashLength {
+			return fmt.Errorf("invalid --blob-hash %q", s)
+		}
+		hashes[i] = common.BytesToHash(b)
+	}
+	data, err := call.Encode(hashes)
+	if err != nil {
+		return err
+	}
+
+	o.Printf("Function: %s\n", call.Signature())
+	o.Printf("Selector: %s\n", hexutil.Encode(call.Selector()))
+	if *out != "" {
+		if err := writeOutput(*out, []byte(hexutil.Encode(data)+"\n")); err != nil {
+			return err
+		}
+		o.Printf("Calldata written to %s\n", *out)
+	} else {
+		o.Printf("Calldata: %s\n", hexutil.Encode(data))
+	}
+	return o.emit(encodedCall{Signature: call.Signature(), Selector: call.Selector(), Data: data})
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+func runProveEquivalence(args []string) error {
+	fs := flag.NewFlagSet("prove-equivalence", flag.ExitOnError)
+	in := fs.String("in", "", "raw payload file, packed as by encode --raw")
+	out := fs.String("out", "", "write the proof to this file instead of stdout")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc prove-equivalence [--out proof.json] <payload>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	payload, err := readInputFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read payload: %w", err)
+	}
+	p, err := blob.ProveEquivalence(payload)
+	if err != nil {
+		return fmt.Errorf("failed to prove equivalence: %w", err)
+	}
+	if err := writeJSON(*out, p); err != nil {
+		return err
+	}
+	if *out != "" {
+		o.Printf("Payload keccak256 %s is committed to by versioned hash %s\n", p.PayloadHash, p.VersionedHash)
+		o.Printf("Wrote the equivalence proof at z = %s to %s\n", p.Z, *out)
+	}
+	return nil
+}
+
+func runVerifyEquivalence(args []string) error {
+	fs := flag.NewFlagSet("verify-equivalence", flag.ExitOnError)
+	proofPath := fs.String("proof", "", "equivalence proof written by prove-equivalence")
+	payloadPath := fs.String("payload", "", "payload file; without it only the challenge and KZG opening are checked")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc verify-equivalence --proof <file> [--payload <file>]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *proofPath == "" {
+		return errors.New("--proof is required")
+	}
+	data, err := os.ReadFile(*proofPath)
+	if err != nil {
+		return fmt.Errorf("failed to read equivalence proof: %w", err)
+	}
+	p := new(blob.EquivalenceProof)
+	if err := json.Unmarshal(data, p); err != nil {
+		return fmt.Errorf("invalid equivalence proof: %w", err)
+	}
+
+	if *payloadPath == "" {
+		err = blob.VerifyEquivalenceOpening(p)
+	} else {
+		payload, readErr := readInputFile(*payloadPath)
+		if readErr != nil {
+			return fmt.Errorf("failed to read payload: %w", readErr)
+		}
+		err = blob.VerifyEquivalence(payload, p)
+	}
+	if emitErr := o.emit(newVerifyResult(err)); emitErr != nil {
+		return emitErr
+	}
+	if err != nil {
+		return err
+	}
+	if *payloadPath == "" {
+		o.Printf("✅ Commitment opens to y at the challenge of payload keccak256 %s\n", p.PayloadHash)
+		o.Printf("   Precompile input: %x\n", blob.PointEvaluationInput(p.Commitment, kzg4844.Point(p.Z), kzg4844.Claim(p.Y), p.Proof))
+	} else {
+		o.Printf("✅ Payload with keccak256 %s is the one committed to by versioned hash %s\n", p.PayloadHash, p.VersionedHash)
+	}
+	return nil
+}
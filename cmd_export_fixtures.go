@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+func runExportFixtures(args []string) error {
+	fs := flag.NewFlagSet("export-fixtures", flag.ExitOnError)
+	seed := fs.Uint64("seed", 0, "seed of the random blob and evaluation points; the same seed gives the same corpus")
+	out := fs.String("out", "", "write the corpus to this file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc export-fixtures [--seed s] [--out file.json]")
+		fmt.Fprintln(fs.Output(), "Writes a deterministic JSON corpus of edge-case blobs with their commitments, proofs, versioned hashes")
+		fmt.Fprintln(fs.Output(), "and openings (z, y, proof), for differential tests against other KZG implementations.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	// The values only hold for the setup they were computed with, so the
+	// corpus names it.
+	setupHash := loadedSetup.hash
+	if loadedSetup.path == "" {
+		if err := blob.CheckLoadedSetup(); err != nil {
+			return err
+		}
+		setupHash = blob.CeremonySetupHash
+	}
+	f, err := blob.NewFixtures(*seed)
+	if err != nil {
+		return err
+	}
+	f.SetupHash = setupHash
+	return writeJSON(*out, f)
+}
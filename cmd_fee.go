@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"kzg-blob-poc/pkg/fee"
+	"kzg-blob-poc/pkg/metrics"
+)
+
+// feeEstimate is the JSON output of the fee command.
+type feeEstimate struct {
+	*fee.Estimate
+	// Fork and its limits are those of the next block.
+	Fork        string `json:"fork"`
+	TargetBlobs int    `json:"target_blobs_per_block"`
+	MaxBlobs    int    `json:"max_blobs_per_block"`
+	// HeadroomBlocks is how many consecutive full blocks MaxFeePerBlobGas
+	// outlasts.
+	HeadroomBlocks float64 `json:"headroom_blocks"`
+	// ComputedBlobBaseFee is the blob base fee of the next block computed
+	// from the latest header with the fork's schedule.
+	ComputedBlobBaseFee *big.Int `json:"computed_blob_base_fee"`
+}
+
+func runFee(args []string) error {
+	fs := flag.NewFlagSet("fee", flag.ExitOnError)
+	rpcURL := fs.String("rpc-url", cfg.RPCURL, "execution client JSON-RPC endpoint")
+	multiplier := fs.Float64("multiplier", fee.DefaultEstimator.Multiplier, "safety multiplier applied to the observed blob base fee")
+	headroom := fs.Float64("headroom-blocks", 0, "instead of --multiplier, size the cap to outlast this many consecutive full blocks under the current fork")
+	blocks := fs.Uint64("blocks", fee.DefaultEstimator.Blocks, "number of recent blocks to consider")
+	timeout := fs.Duration("timeout", 30*time.Second, "RPC timeout")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc fee --rpc-url <url> [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *rpcURL == "" {
+		return errors.New("--rpc-url is required")
+	}
+	if *multiplier <= 0 {
+		return errors.New("--multiplier must be positive")
+	}
+	if *headroom < 0 {
+		return errors.New("--headroom-blocks must not be negative")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	client, err := dialEth(ctx, *rpcURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	// The schedule of the next block sets how fast its fee can move.
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		metrics.RPCError("eth_getBlockByNumber")
+		return fmt.Errorf("failed to get latest header: %w", err)
+	}
+	if head.ExcessBlobGas == nil || head.BlobGasUsed == nil {
+		return errors.New("latest block has no blob gas fields: chain is not on Cancun yet")
+	}
+	fork, ok := nextFork(head)
+	if !ok {
+		return fmt.Errorf("next block after %d (time %d) is before Cancun on %s", head.Number, head.Time, network.Name)
+	}
+
+	estimator := fee.Estimator{Multiplier: *multiplier, Blocks: *blocks}
+	if *headroom > 0 {
+		estimator.Multiplier = fee.MultiplierFor(fork.Blobs, *headroom)
+	}
+	estimate, err := estimator.Estimate(ctx, client.Client())
+	if err != nil {
+		return err
+	}
+	e := feeEstimate{
+		Estimate:            estimate,
+		Fork:                fork.Name,
+		TargetBlobs:         fork.Blobs.Target,
+		MaxBlobs:            fork.Blobs.Max,
+		HeadroomBlocks:      fee.HeadroomBlocks(fork.Blobs, estimator.Multiplier),
+		ComputedBlobBaseFee: fee.BlobBaseFeeAt(fork.Blobs, fee.ExcessBlobGas(fork.Blobs, *head.ExcessBlobGas, *head.BlobGasUsed)),
+	}
+	if e.ComputedBlobBaseFee.Cmp(estimate.BlobBaseFee) != 0 {
+		slog.Warn("Node's blob base fee differs from the one computed for the fork", "fork", fork.Name, "node", estimate.BlobBaseFee, "computed", e.ComputedBlobBaseFee)
+	}
+
+	o.Printf("Fork: %s (target %d, max %d blobs per block)\n", e.Fork, e.TargetBlobs, e.MaxBlobs)
+	o.Printf("Blob Base Fee: %s wei (%s gwei)\n", estimate.BlobBaseFee, formatGwei(estimate.BlobBaseFee))
+	o.Printf("Peak Blob Base Fee (last %d blocks): %s wei (%s gwei)\n", *blocks, estimate.PeakBlobBaseFee, formatGwei(estimate.PeakBlobBaseFee))
+	o.Printf("Recommended maxFeePerBlobGas (x%.4g): %s wei (%s gwei)\n", estimate.Multiplier, estimate.MaxFeePerBlobGas, formatGwei(estimate.MaxFeePerBlobGas))
+	o.Printf("Headroom: %.1f consecutive full blocks\n", e.HeadroomBlocks)
+	return o.emit(e)
+}
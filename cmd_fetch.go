@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/arbitrum"
+	"kzg-blob-poc/pkg/beacon"
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/db"
+	"kzg-blob-poc/pkg/fetch"
+	"kzg-blob-poc/pkg/opstack"
+)
+
+// fetchedBlob is the JSON report of one fetched blob. Index, the position
+// of the sidecar in its block, is unknown for a blob from a --fallback
+// source, which Source names instead.
+type fetchedBlob struct {
+	Index         *uint64     `json:"index,omitempty"`
+	VersionedHash common.Hash `json:"versioned_hash"`
+	Source        string      `json:"source,omitempty"`
+	Valid         bool        `json:"valid"`
+	Error         string      `json:"error,omitempty"`
+	File          string      `json:"file,omitempty"`
+	Archived      bool        `json:"archived,omitempty"`
+}
+
+// decodedFetch is the JSON report of fetch --decode.
+type decodedFetch struct {
+	Blobs   []fetchedBlob `json:"blobs"`
+	Decoder string        `json:"decoder"`
+	Decoded any           `json:"decoded"`
+}
+
+// fetchDecoders are the rollup batch formats fetch --decode accepts. Each
+// prints a summary of the blobs' content and returns it for --json.
+var fetchDecoders = map[string]func(o *output, blobs []*kzg4844.Blob) (any, error){
+	"op-stack": decodeOPStack,
+	"arbitrum": decodeArbitrum,
+}
+
+func runFetch(args []string) error {
+	fs := flag.NewFlagSet("fetch", flag.ExitOnError)
+	beaconURL := fs.String("beacon-url", cfg.BeaconURL, "beacon node API endpoint")
+	rpcURL := fs.String("rpc-url", cfg.RPCURL, "execution client JSON-RPC endpoint (required with --tx)")
+	txHash := fs.String("tx", "", "blob transaction hash whose blobs to fetch")
+	blockID := fs.String("block", "", "beacon block ID (slot, root, head, finalized) whose blobs to fetch")
+	outDir := fs.String("out-dir", cfg.OutputDir, "write each fetched blob to this directory")
+	decode := fs.String("decode", "", "decode the blobs as rollup batch data: op-stack or arbitrum")
+	archiveURL := addArchiveFlag(fs, "store each verified blob and its artifacts in this archive")
+	fallback := fs.String("fallback", cfg.BlobFallback, "with --tx, comma-separated sources of blobs the beacon node pruned: blobscan, a Blobscan API URL, or an archive URL or directory")
+	timeout := fs.Duration("timeout", time.Minute, "overall timeout")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc fetch --beacon-url <url> (--tx <hash> --rpc-url <url> | --block <id>) [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *beaconURL == "" {
+		return errors.New("--beacon-url is required")
+	}
+	if (*txHash == "") == (*blockID == "") {
+		return errors.New("exactly one of --tx or --block is required")
+	}
+	decoder, ok := fetchDecoders[*decode]
+	if *decode != "" && !ok {
+		return fmt.Errorf("unknown --decode %q (want op-stack or arbitrum)", *decode)
+	}
+
+	a, err := openArchive(*archiveURL)
+	if err != nil {
+		return err
+	}
+	sources, err := openBlobSources(*fallback)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	cl, err := newBeaconClient(*beaconURL)
+	if err != nil {
+		return err
+	}
+
+	var (
+		sidecars []*beacon.BlobSidecar
+		sourced  []*fetch.SourcedBlob // blobs from --fallback, where sidecars has nil
+		record   db.Record            // the transaction and block recorded with each blob
+	)
+	if *txHash != "" {
+		if *rpcURL == "" {
+			return errors.New("--rpc-url is required with --tx")
+		}
+		hash, err := parseHexFixed("tx hash", *txHash, common.HashLength)
+		if err != nil {
+			return err
+		}
+		el, err := dialEth(ctx, *rpcURL)
+		if err != nil {
+			return err
+		}
+		defer el.Close()
+
+		res, err := fetch.BlobsForTx(ctx, el, cl, common.BytesToHash(hash), sources...)
+		if err != nil {
+			return err
+		}
+		o.Printf("Transaction %s included in block %d (slot %d)\n", res.Tx.Hash(), res.Block.Number, res.Slot)
+		pruned := 0
+		for _, sc := range res.Sidecars {
+			if sc == nil {
+				pruned++
+			}
+		}
+		if pruned > 0 {
+			o.Printf("%d of %d blobVersionedHashes found in beacon sidecars, %d in fallback sources\n", len(res.Sidecars)-pruned, len(res.Sidecars), pruned)
+		} else {
+			o.Printf("All %d blobVersionedHashes found in beacon sidecars\n", len(res.Sidecars))
+		}
+		sidecars, sourced = res.Sidecars, res.Sourced
+		record.TxHash, record.BlockNumber = res.Tx.Hash(), res.Block.Number.Uint64()
+	} else {
+		if len(sources) > 0 {
+			return errors.New("--fallback needs --tx, whose versioned hashes the blobs are checked against")
+		}
+		var err error
+		if sidecars, err = cl.BlobSidecars(ctx, *blockID); err != nil {
+			return err
+		}
+		o.Printf("Block %s has %d blob sidecars\n", *blockID, len(sidecars))
+	}
+
+	if *outDir != "" {
+		if err := os.MkdirAll(*outDir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	var failed int
+	reports := make([]fetchedBlob, len(sidecars))
+	blobs := make([]*kzg4844.Blob, len(sidecars))
+	arts := make([]*blob.BlobArtifacts, len(sidecars))
+	for i, sc := range sidecars {
+		var r fetchedBlob
+		if sc == nil {
+			// FromSources only returns blobs that match their versioned
+			// hash, so this one is already verified.
+			s := sourced[i]
+			r = fetchedBlob{VersionedHash: s.VersionedHash, Source: s.Source, Valid: true}
+			blobs[i] = s.Blob
+			arts[i] = &blob.BlobArtifacts{Commitment: s.Commitment, Proof: s.Proof, VersionedHash: s.VersionedHash}
+			o.Printf("✅ Blob %s: retrieved from %s, commitment matches the versioned hash\n", r.VersionedHash, s.Source)
+		} else {
+			r = fetchedBlob{Index: &sc.Index, VersionedHash: sc.VersionedHash(), Valid: true}
+			blobs[i] = &sc.Blob
+			arts[i] = &blob.BlobArtifacts{Commitment: sc.KZGCommitment, Proof: sc.KZGProof, VersionedHash: r.VersionedHash}
+			err := sc.Verify()
+			if err == nil {
+				err = sc.VerifyInclusionProof()
+			}
+			if err != nil {
+				r.Valid, r.Error = false, err.Error()
+				failed++
+				o.Printf("❌ Blob %d %s: %v\n", sc.Index, r.VersionedHash, err)
+			} else {
+				o.Printf("✅ Blob %d %s: commitment, proof and inclusion verified\n", sc.Index, r.VersionedHash)
+			}
+		}
+		if r.Valid && a != nil {
+			if _, err := a.Put(ctx, blobs[i], arts[i]); err != nil {
+				return err
+			}
+			r.Archived = true
+		}
+		if *outDir != "" {
+			r.File = filepath.Join(*outDir, fmt.Sprintf("blob-%s.bin", r.VersionedHash.Hex()))
+			if err := os.WriteFile(r.File, encodeOutput(blobs[i][:]), 0o644); err != nil {
+				return err
+			}
+		}
+		reports[i] = r
+	}
+
+	var recs []db.Record
+	for i, r := range reports {
+		if r.Valid {
+			rec := record
+			rec.VersionedHash, rec.Commitment, rec.Proof = r.VersionedHash, arts[i].Commitment, arts[i].Proof
+			rec.Source, rec.File = "fetch", r.File
+			recs = append(recs, rec)
+		}
+	}
+	recordBlobs(ctx, recs...)
+
+	if decoder == nil {
+		if err := o.emit(reports); err != nil {
+			return err
+		}
+	} else {
+		decoded, err := decoder(o, blobs)
+		if err != nil {
+			return err
+		}
+		if err := o.emit(decodedFetch{Blobs: reports, Decoder: *decode, Decoded: decoded}); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d blobs failed verification", failed, len(sidecars))
+	}
+	return nil
+}
+
+// openBlobSources returns the sources of --fallback: "blobscan" for the
+// Blobscan API of the selected network, an http(s) URL for another Blobscan
+// API, and anything else for a blob archive as in --archive.
+func openBlobSources(list string) ([]fetch.Source, error) {
+	var sources []fetch.Source
+	for _, s := range strings.Split(list, ",") {
+		switch s = strings.TrimSpace(s); {
+		case s == "":
+		case s == "blobscan":
+			url := fetch.DefaultBlobscanURL
+			if network != nil {
+				if url = network.BlobscanURL; url == "" {
+					return nil, fmt.Errorf("network %s has no Blobscan API; give its URL in --fallback", network.Name)
+				}
+			}
+			sources = append(sources, fetch.NewBlobscan(url, netPolicy.Client()))
+		case strings.HasPrefix(s, "http://"), strings.HasPrefix(s, "https://"):
+			sources = append(sources, fetch.NewBlobscan(s, netPolicy.Client()))
+		default:
+			a, err := openArchive(s)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, fetch.ArchiveSource(s, a))
+		}
+	}
+	return sources, nil
+}
+
+// txBlobFlags take the blobs of a command from a mined blob transaction
+// instead of files, fetched as fetch --tx does.
+type txBlobFlags struct {
+	txHash    string
+	rpcURL    string
+	beaconURL string
+	fallback  string
+	timeout   time.Duration
+}
+
+// addTxBlobFlags registers --from-tx and the endpoints it fetches from on fs.
+func addTxBlobFlags(fs *flag.FlagSet) *txBlobFlags {
+	f := new(txBlobFlags)
+	fs.StringVar(&f.txHash, "from-tx", "", "take the blobs of this mined blob transaction hash, fetched from --beacon-url, instead of files")
+	fs.StringVar(&f.rpcURL, "rpc-url", cfg.RPCURL, "with --from-tx, execution client JSON-RPC endpoint")
+	fs.StringVar(&f.beaconURL, "beacon-url", cfg.BeaconURL, "with --from-tx, beacon node API endpoint")
+	fs.StringVar(&f.fallback, "fallback", cfg.BlobFallback, "with --from-tx, comma-separated sources of blobs the beacon node pruned, as for fetch")
+	fs.DurationVar(&f.timeout, "timeout", time.Minute, "with --from-tx, timeout of fetching the blobs")
+	return f
+}
+
+// load fetches the blobs of the --from-tx transaction and returns them with
+// the transaction's versioned hashes, in order. The inclusion proof of each
+// beacon sidecar is checked, but not its commitment, which the caller
+// recomputes and checks against the versioned hash; blobs from --fallback
+// sources already match theirs.
+func (f *txBlobFlags) load(o *output) ([]kzg4844.Blob, []common.Hash, error) {
+	if f.rpcURL == "" {
+		return nil, nil, errors.New("--rpc-url is required with --from-tx")
+	}
+	if f.beaconURL == "" {
+		return nil, nil, errors.New("--beacon-url is required with --from-tx")
+	}
+	hash, err := parseHexFixed("tx hash", f.txHash, common.HashLength)
+	if err != nil {
+		return nil, nil, err
+	}
+	sources, err := openBlobSources(f.fallback)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	defer cancel()
+	cl, err := newBeaconClient(f.beaconURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	el, err := dialEth(ctx, f.rpcURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer el.Close()
+	res, err := fetch.BlobsForTx(ctx, el, cl, common.BytesToHash(hash), sources...)
+	if err != nil {
+		return nil, nil, err
+	}
+	o.Printf("Transaction %s included in block %d (slot %d) with %d blobs\n", res.Tx.Hash(), res.Block.Number, res.Slot, len(res.Sidecars))
+
+	blobs := make([]kzg4844.Blob, len(res.Sidecars))
+	for i, sc := range res.Sidecars {
+		if sc == nil {
+			blobs[i] = *res.Sourced[i].Blob
+			o.Printf("Blob %d retrieved from %s\n", i, res.Sourced[i].Source)
+			continue
+		}
+		if err := sc.VerifyInclusionProof(); err != nil {
+			return nil, nil, fmt.Errorf("blob %d %s: %w", i, sc.VersionedHash(), err)
+		}
+		blobs[i] = sc.Blob
+	}
+	return blobs, res.Tx.BlobHashes(), nil
+}
+
+func decodeOPStack(o *output, blobs []*kzg4844.Blob) (any, error) {
+	res, err := opstack.Decode(blobs)
+	if err != nil {
+		return nil, err
+	}
+	for _, skipped := range res.Skipped {
+		o.Printf("Skipped %s\n", skipped)
+	}
+	o.Printf("OP Stack: %d frames in %d channels\n", res.Frames, len(res.Channels))
+	for _, c := range res.Channels {
+		if !c.Complete {
+			o.Printf("Channel %s: %d frames, %d bytes, incomplete\n", c.ID, c.Frames, c.Size)
+			continue
+		}
+		o.Printf("Channel %s: %d frames, %d bytes %s, %d batches\n", c.ID, c.Frames, c.Size, c.Compression, len(c.Batches))
+		for _, b := range c.Batches {
+			if b.Span != nil {
+				s := b.Span
+				o.Printf("  span batch: %d blocks, %d txs, L1 origin %d (%d changes), timestamp genesis+%d\n",
+					s.BlockCount, s.TxCount, s.L1OriginNumber, s.OriginChanges, s.RelTimestamp)
+			} else {
+				s := b.Singular
+				o.Printf("  singular batch: epoch %d, timestamp %d, %d txs, parent %s\n", s.EpochNumber, s.Timestamp, s.TxCount, s.ParentHash)
+			}
+		}
+		if c.Error != "" {
+			o.Printf("  ❌ %s\n", c.Error)
+		}
+	}
+	return res, nil
+}
+
+func decodeArbitrum(o *output, blobs []*kzg4844.Blob) (any, error) {
+	res, err := arbitrum.Decode(blobs)
+	if err != nil {
+		return nil, err
+	}
+	for _, skipped := range res.Skipped {
+		o.Printf("Skipped %s\n", skipped)
+	}
+	o.Printf("Arbitrum: %d batches\n", len(res.Batches))
+	for _, b := range res.Batches {
+		o.Printf("Batch in blobs %d-%d: %d bytes %s, %d bytes decompressed, %d segments\n",
+			b.FirstBlob, b.FirstBlob+b.Blobs-1, b.Size, b.Compression, b.DecompressedSize, b.Segments)
+		o.Printf("  %d L2 messages with %d txs, %d delayed messages, timestamp +%ds, L1 block +%d\n",
+			b.L2Messages, b.Transactions, b.DelayedMessages, b.TimestampAdvance, b.L1BlockAdvance)
+	}
+	return res, nil
+}
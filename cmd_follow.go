@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"kzg-blob-poc/pkg/beacon"
+	"kzg-blob-poc/pkg/db"
+	"kzg-blob-poc/pkg/failover"
+	"kzg-blob-poc/pkg/fetch"
+)
+
+// maxBackfill is the most skipped slots follow fetches when the head jumps,
+// such as after reconnecting to the event stream.
+const maxBackfill = 64
+
+// followedBlob is the --json line follow emits for each blob.
+type followedBlob struct {
+	Slot          uint64         `json:"slot"`
+	BlockRoot     common.Hash    `json:"block_root"`
+	BlockNumber   uint64         `json:"block_number"`
+	Index         uint64         `json:"index"`
+	VersionedHash common.Hash    `json:"versioned_hash"`
+	Tx            common.Hash    `json:"tx,omitzero"`
+	From          common.Address `json:"from,omitzero"`
+	Valid         bool           `json:"valid"`
+	Error         string         `json:"error,omitempty"`
+}
+
+// errFollowDone stops the event stream once --blocks blocks are processed.
+var errFollowDone = errors.New("done")
+
+func runFollow(args []string) error {
+	fs := flag.NewFlagSet("follow", flag.ExitOnError)
+	beaconURL := fs.String("beacon-url", cfg.BeaconURL, "beacon node API endpoint")
+	from := fs.String("from", "", "only report blobs sent by these comma-separated addresses")
+	hashPrefix := fs.String("hash-prefix", "", "only report blobs whose versioned hash starts with one of these comma-separated hex prefixes")
+	blocks := fs.Int("blocks", 0, "stop after this many blocks (0 follows until interrupted)")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc follow --beacon-url <url> [--from <addr,...>] [--hash-prefix <hex,...>] [flags]")
+		fmt.Fprintln(fs.Output(), "With --json, one JSON object per blob is written to stdout.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *beaconURL == "" {
+		return errors.New("--beacon-url is required")
+	}
+	filter, err := newBlobFilter(*from, *hashPrefix)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cl, err := newBeaconClient(*beaconURL)
+	if err != nil {
+		return err
+	}
+	f := &follower{
+		cl:     cl,
+		filter: filter,
+		o:      o,
+		limit:  *blocks,
+	}
+	// The event stream stays open, so it gets a client without netPolicy's
+	// request timeout; dropped streams are reopened below instead, on the
+	// next healthy endpoint of a list.
+	streamURL, streamClient, err := endpointClient(*beaconURL, failover.BeaconProbe, nil)
+	if err != nil {
+		return err
+	}
+	stream := beacon.NewClient(streamURL, streamClient)
+	for attempt := 0; ; attempt++ {
+		err := stream.Events(ctx, []string{"head"}, func(ev beacon.Event) error {
+			attempt = 0
+			var head beacon.HeadEvent
+			if err := json.Unmarshal(ev.Data, &head); err != nil {
+				return fmt.Errorf("invalid head event: %w", err)
+			}
+			return f.head(ctx, head)
+		})
+		if errors.Is(err, errFollowDone) || ctx.Err() != nil {
+			break
+		}
+		if err != nil && !isStreamError(err) {
+			return err
+		}
+		delay := netPolicy.Backoff(attempt)
+		slog.Warn("Beacon event stream failed, reconnecting", "err", err, "delay", delay)
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	slog.Info("Stopped following", "blocks", f.blocks, "blobs", f.blobs, "failed", f.failed)
+	if f.failed > 0 {
+		return fmt.Errorf("%d of %d blobs failed verification", f.failed, f.blobs)
+	}
+	return nil
+}
+
+// isStreamError reports whether err came from the event stream rather than
+// from processing an event, so reconnecting may help.
+func isStreamError(err error) bool {
+	var emitErr *emitError
+	return !errors.As(err, &emitErr)
+}
+
+// emitError is a failure to write follow's output, which ends the command.
+type emitError struct{ err error }
+
+func (e *emitError) Error() string { return e.err.Error() }
+func (e *emitError) Unwrap() error { return e.err }
+
+// follower processes the head blocks announced by the event stream.
+type follower struct {
+	cl     *beacon.Client
+	filter *blobFilter
+	o      *output
+	limit  int
+
+	lastSlot uint64
+	lastRoot common.Hash
+	blocks   int
+	blobs    int
+	failed   int
+}
+
+// head processes a new head block, after the blocks of any slots skipped
+// since the last one. A head at or before the last slot is a reorg and is
+// processed too.
+func (f *follower) head(ctx context.Context, head beacon.HeadEvent) error {
+	if head.Block == f.lastRoot {
+		return nil
+	}
+	if f.lastSlot != 0 && head.Slot > f.lastSlot+1 {
+		first := f.lastSlot + 1
+		if head.Slot-first > maxBackfill {
+			slog.Warn("Head jumped too far, skipping slots", "from", first, "to", head.Slot-maxBackfill-1)
+			first = head.Slot - maxBackfill
+		}
+		for slot := first; slot < head.Slot; slot++ {
+			if err := f.block(ctx, strconv.FormatUint(slot, 10)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := f.block(ctx, head.Block.Hex()); err != nil {
+		return err
+	}
+	f.lastSlot, f.lastRoot = head.Slot, head.Block
+	return nil
+}
+
+// block fetches the blobs of a block and reports those that pass the
+// filter. Blocks that cannot be fetched are logged and skipped, so a flaky
+// node does not stop the monitor.
+func (f *follower) block(ctx context.Context, blockID string) error {
+	res, err := fetch.BlobsForBlock(ctx, f.cl, blockID)
+	switch {
+	case errors.Is(err, beacon.ErrNotFound):
+		slog.Debug("No block", "block", blockID)
+		return nil
+	case err != nil:
+		if ctx.Err() != nil {
+			return nil
+		}
+		slog.Warn("Failed to fetch block blobs", "block", blockID, "err", err)
+		return nil
+	}
+	slog.Debug("Processed block", "slot", res.Slot, "root", res.Root, "blobs", len(res.Blobs))
+
+	var recs []db.Record
+	for _, b := range res.Blobs {
+		sc := b.Sidecar
+		vh := sc.VersionedHash()
+		if !f.filter.match(vh, b.From) {
+			continue
+		}
+		r := followedBlob{
+			Slot:          res.Slot,
+			BlockRoot:     res.Root,
+			BlockNumber:   res.BlockNumber,
+			Index:         sc.Index,
+			VersionedHash: vh,
+			Tx:            b.Tx,
+			From:          b.From,
+			Valid:         true,
+		}
+		err := sc.Verify()
+		if err == nil {
+			err = sc.VerifyInclusionProof()
+		}
+		f.blobs++
+		sender := ""
+		if b.Tx != (common.Hash{}) {
+			sender = fmt.Sprintf(" (tx %s from %s)", b.Tx, b.From)
+		}
+		if err != nil {
+			r.Valid, r.Error = false, err.Error()
+			f.failed++
+			f.o.Printf("❌ Slot %d blob %d %s%s: %v\n", res.Slot, sc.Index, vh, sender, err)
+		} else {
+			f.o.Printf("✅ Slot %d blob %d %s%s: proof and inclusion verified\n", res.Slot, sc.Index, vh, sender)
+			recs = append(recs, db.Record{
+				VersionedHash: vh,
+				Commitment:    sc.KZGCommitment,
+				Proof:         sc.KZGProof,
+				Source:        "follow",
+				TxHash:        b.Tx,
+				BlockNumber:   res.BlockNumber,
+			})
+		}
+		if err := f.o.emitLine(r); err != nil {
+			return &emitError{err}
+		}
+	}
+	recordBlobs(ctx, recs...)
+
+	f.blocks++
+	if f.limit > 0 && f.blocks >= f.limit {
+		return errFollowDone
+	}
+	return nil
+}
+
+// blobFilter selects blobs by sender and versioned hash prefix. A blob must
+// match both, and an empty list matches every blob.
+type blobFilter struct {
+	senders  map[common.Address]bool
+	prefixes []string // lowercase hex without 0x
+}
+
+// newBlobFilter parses the comma-separated --from and --hash-prefix lists.
+func newBlobFilter(from, hashPrefix string) (*blobFilter, error) {
+	f := &blobFilter{senders: make(map[common.Address]bool)}
+	for _, s := range strings.Split(from, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		if !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("invalid --from address %q", s)
+		}
+		f.senders[common.HexToAddress(s)] = true
+	}
+	for _, s := range strings.Split(hashPrefix, ",") {
+		s = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(s), "0x"))
+		if s == "" {
+			continue
+		}
+		// Pad an odd prefix to check it is hex; it is matched by nibble.
+		if _, err := hex.DecodeString(s + strings.Repeat("0", len(s)%2)); err != nil || len(s) > 2*common.HashLength {
+			return nil, fmt.Errorf("invalid --hash-prefix %q", s)
+		}
+		f.prefixes = append(f.prefixes, s)
+	}
+	return f, nil
+}
+
+func (f *blobFilter) match(vh common.Hash, from common.Address) bool {
+	if len(f.senders) > 0 && !f.senders[from] {
+		return false
+	}
+	if len(f.prefixes) == 0 {
+		return true
+	}
+	h := hex.EncodeToString(vh[:])
+	for _, p := range f.prefixes {
+		if strings.HasPrefix(h, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/batch"
+	"kzg-blob-poc/pkg/blob"
+)
+
+// genArtifactsFileName is the report written next to the generated blobs,
+// in the format of the batch command, which verify-batch reads.
+const genArtifactsFileName = "artifacts.json"
+
+func runGen(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	pattern := fs.String("pattern", "random", "blob contents: random, zeros, incrementing or max-field-element")
+	count := fs.Int("count", 1, "number of blobs to generate")
+	seed := fs.Uint64("seed", 0, "seed of the random pattern; the same seed gives the same blobs")
+	outDir := fs.String("out-dir", cfg.OutputDir, "directory for the blob files and "+genArtifactsFileName)
+	workers := fs.Int("workers", runtime.NumCPU(), "number of blobs to commit to and prove concurrently")
+	cacheDir := addCacheFlag(fs)
+	pf := addProgressFlags(fs)
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc gen --out-dir <dir> [--pattern p] [--count n] [--seed s] [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	p, err := blob.ParsePattern(*pattern)
+	if err != nil {
+		return err
+	}
+	if *count <= 0 {
+		return errors.New("--count must be positive")
+	}
+	if *outDir == "" {
+		return errors.New("--out-dir is required")
+	}
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		return err
+	}
+	c, err := openCache(*cacheDir)
+	if err != nil {
+		return err
+	}
+
+	jobs := func(yield func(batch.Job) bool) {
+		for i := range *count {
+			name := fmt.Sprintf("blob-%04d.bin", i)
+			job := batch.Job{
+				Name: name,
+				Load: func(dst *kzg4844.Blob) (err error) {
+					if *dst, err = blob.GenerateBlob(p, *seed, i); err != nil {
+						return err
+					}
+					return os.WriteFile(filepath.Join(*outDir, name), encodeOutput(dst[:]), 0o644)
+				},
+			}
+			if !yield(job) {
+				return
+			}
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pipeline := batch.NewPipeline(*workers)
+	pipeline.Cache = c
+	pipeline.Progress = pf.start("gen", int64(*count))
+	var results []batch.Result
+	err = pipeline.Run(ctx, jobs, func(r batch.Result) error {
+		results = append(results, r)
+		o.Printf("%s: %x\n", r.Name, r.VersionedHash[:])
+		return nil
+	})
+	pipeline.Progress.Finish()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := batch.WriteJSON(&buf, results); err != nil {
+		return err
+	}
+	if err := writeOutput(filepath.Join(*outDir, genArtifactsFileName), buf.Bytes()); err != nil {
+		return err
+	}
+	o.Printf("Generated %d %s blobs in %s\n", len(results), p, *outDir)
+	return o.emit(results)
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"kzg-blob-poc/pkg/fetch"
+	"kzg-blob-poc/pkg/inclusion"
+)
+
+// verifiedInclusion is the JSON output of verify-inclusion.
+type verifiedInclusion struct {
+	TxHash          common.Hash   `json:"tx_hash"`
+	BlockNumber     uint64        `json:"block_number"`
+	BlockHash       common.Hash   `json:"block_hash"`
+	TrustedNumber   uint64        `json:"trusted_number"`
+	TrustedHash     common.Hash   `json:"trusted_hash"`
+	VersionedHashes []common.Hash `json:"versioned_hashes"`
+	BlobsVerified   bool          `json:"blobs_verified"`
+}
+
+func runProveInclusion(args []string) error {
+	fs := flag.NewFlagSet("prove-inclusion", flag.ExitOnError)
+	rpcURL := fs.String("rpc-url", cfg.RPCURL, "execution client JSON-RPC endpoint")
+	beaconURL := fs.String("beacon-url", cfg.BeaconURL, "beacon node API endpoint to take the blob artifacts from (optional)")
+	txHash := fs.String("tx", "", "hash of the mined blob transaction")
+	to := fs.String("to", "latest", "trusted block the header chain ends at: a block number, latest, safe or finalized")
+	maxHeaders := fs.Int("max-headers", inclusion.DefaultMaxHeaders, "refuse to build a header chain longer than this")
+	withBlobs := fs.Bool("with-blobs", false, "include the blobs themselves, so the verifier can check their KZG proofs")
+	out := fs.String("out", "", "write the proof to this file instead of stdout")
+	timeout := fs.Duration("timeout", 2*time.Minute, "overall timeout")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc prove-inclusion --rpc-url <url> [--beacon-url <url>] [--to block] [--out file] (--tx <hash> | <hash>)")
+		fmt.Fprintln(fs.Output(), "Proves a blob transaction is part of the chain ending at a trusted block.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	hashHex := *txHash
+	if hashHex == "" && fs.NArg() > 0 {
+		hashHex = fs.Arg(0)
+	}
+	if hashHex == "" {
+		return errors.New("a transaction hash is required")
+	}
+	hash, err := parseHexFixed("tx hash", hashHex, common.HashLength)
+	if err != nil {
+		return err
+	}
+	if *rpcURL == "" {
+		return errors.New("--rpc-url is required")
+	}
+	if *withBlobs && *beaconURL == "" {
+		return errors.New("--with-blobs requires --beacon-url")
+	}
+	if *maxHeaders <= 0 {
+		return errors.New("--max-headers must be positive")
+	}
+	toNumber, err := parseBlockTag(*to)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	el, err := dialEth(ctx, *rpcURL)
+	if err != nil {
+		return err
+	}
+	defer el.Close()
+
+	p, err := inclusion.Build(ctx, el, common.BytesToHash(hash), inclusion.Options{To: toNumber, MaxHeaders: *maxHeaders})
+	if err != nil {
+		return err
+	}
+	if *beaconURL != "" {
+		cl, err := newBeaconClient(*beaconURL)
+		if err != nil {
+			return err
+		}
+		res, err := fetch.BlobsForTx(ctx, el, cl, p.TxHash)
+		if err != nil {
+			return err
+		}
+		if err := p.AttachSidecars(res.Sidecars, *withBlobs); err != nil {
+			return err
+		}
+	}
+
+	head, err := p.Head()
+	if err != nil {
+		return err
+	}
+	if _, err := p.Verify(head.Hash()); err != nil {
+		return fmt.Errorf("built an invalid proof: %w", err)
+	}
+	if err := writeJSON(*out, p); err != nil {
+		return err
+	}
+	if *out != "" {
+		o.Printf("Wrote the inclusion proof of %s, with %d headers up to block %d (%s) and %d blob artifacts, to %s\n",
+			p.TxHash, len(p.Headers), head.Number, head.Hash(), len(p.Blobs), *out)
+	}
+	return nil
+}
+
+func runVerifyInclusion(args []string) error {
+	fs := flag.NewFlagSet("verify-inclusion", flag.ExitOnError)
+	trustedHex := fs.String("trusted-hash", "", "hash of the trusted block the proof must end at")
+	rpcURL := fs.String("rpc-url", "", "trust the block this node has at the proof's last height, instead of --trusted-hash")
+	var versionedHashes listFlag
+	fs.Var(&versionedHashes, "versioned-hash", "require the transaction to carry this versioned hash (repeatable)")
+	timeout := fs.Duration("timeout", 30*time.Second, "RPC timeout")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc verify-inclusion (--trusted-hash <hash> | --rpc-url <url>) [--versioned-hash h]... <proof.json>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("exactly one proof file is required")
+	}
+	if (*trustedHex == "") == (*rpcURL == "") {
+		return errors.New("exactly one of --trusted-hash or --rpc-url is required")
+	}
+	want := make([]common.Hash, len(versionedHashes))
+	for i, s := range versionedHashes {
+		h, err := parseHexFixed("versioned hash", s, common.HashLength)
+		if err != nil {
+			return err
+		}
+		want[i] = common.BytesToHash(h)
+	}
+	p, err := inclusion.Read(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var trusted common.Hash
+	if *trustedHex != "" {
+		h, err := parseHexFixed("trusted hash", *trustedHex, common.HashLength)
+		if err != nil {
+			return err
+		}
+		trusted = common.BytesToHash(h)
+	} else {
+		// Ask the node for the canonical block at the height the proof
+		// claims to end at.
+		head, err := p.Head()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		el, err := dialEth(ctx, *rpcURL)
+		if err != nil {
+			return err
+		}
+		defer el.Close()
+		h, err := el.HeaderByNumber(ctx, head.Number)
+		if err != nil {
+			return fmt.Errorf("failed to get block %d: %w", head.Number, err)
+		}
+		trusted = h.Hash()
+	}
+
+	v, err := p.Verify(trusted)
+	if err == nil {
+		hashes := v.Tx.BlobHashes()
+		for _, h := range want {
+			if !slices.Contains(hashes, h) {
+				err = fmt.Errorf("%w: the transaction does not carry versioned hash %s", inclusion.ErrProofMismatch, h)
+				break
+			}
+		}
+	}
+	if err != nil {
+		if emitErr := o.emit(newVerifyResult(err)); emitErr != nil {
+			return emitErr
+		}
+		return err
+	}
+
+	blobsVerified := len(p.Blobs) > 0
+	for _, a := range p.Blobs {
+		blobsVerified = blobsVerified && a.BlobHex != ""
+	}
+	if err := o.emit(verifiedInclusion{
+		TxHash:          p.TxHash,
+		BlockNumber:     v.Block.Number.Uint64(),
+		BlockHash:       v.Block.Hash(),
+		TrustedNumber:   v.Head.Number.Uint64(),
+		TrustedHash:     trusted,
+		VersionedHashes: v.Tx.BlobHashes(),
+		BlobsVerified:   blobsVerified,
+	}); err != nil {
+		return err
+	}
+	o.Printf("✅ Transaction %s is in block %d, %d blocks before trusted block %d\n", p.TxHash, v.Block.Number, len(p.Headers)-1, v.Head.Number)
+	for i, h := range v.Tx.BlobHashes() {
+		o.Printf("   Blob %d: %s\n", i, h)
+	}
+	switch {
+	case blobsVerified:
+		o.Println("✅ Blob commitments and KZG proofs verified")
+	case len(p.Blobs) > 0:
+		o.Println("✅ Blob commitments match the versioned hashes (blobs not included, KZG proofs unchecked)")
+	}
+	return nil
+}
+
+// parseBlockTag parses a block number or one of the tags latest, safe and
+// finalized, which it returns as the negative numbers ethclient sends as
+// tags. Latest is nil.
+func parseBlockTag(s string) (*big.Int, error) {
+	switch s {
+	case "latest":
+		return nil, nil
+	case "safe":
+		return big.NewInt(int64(rpc.SafeBlockNumber)), nil
+	case "finalized":
+		return big.NewInt(int64(rpc.FinalizedBlockNumber)), nil
+	}
+	n, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block %q: want a number, latest, safe or finalized", s)
+	}
+	return new(big.Int).SetUint64(n), nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/opstack"
+)
+
+// layoutOPStack is the scheme inspect reports for the OP Stack blob
+// encoding, which Sniff does not know.
+const layoutOPStack = "op-stack"
+
+// inspectElement is one field element in the JSON output of inspect.
+type inspectElement struct {
+	Index     int    `json:"index"`
+	Offset    int    `json:"offset"`
+	Value     string `json:"value"`
+	Canonical bool   `json:"canonical"`
+	Note      string `json:"note,omitempty"`
+}
+
+// inspectReport is the JSON output of inspect.
+type inspectReport struct {
+	File string `json:"file"`
+	*blob.Inspection
+	Elements []inspectElement `json:"elements"`
+}
+
+func runInspect(args []string) error {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	in := fs.String("in", "", "blob file (hex text or raw binary)")
+	all := fs.Bool("all", false, "list the zero padding too, instead of summarizing it")
+	start := fs.Int("start", 0, "first field element to list")
+	count := fs.Int("count", 0, "number of field elements to list (default: up to the padding, or all with --all)")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc inspect [flags] <blob>")
+		fmt.Fprintln(fs.Output(), "Lists the field elements of a blob, flags non-canonical ones and shows its padding and framing.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *start < 0 || *start >= blob.FieldElementsPerBlob || *count < 0 {
+		return fmt.Errorf("--start must be below %d and --count not negative", blob.FieldElementsPerBlob)
+	}
+	b, err := readBlobFile(path)
+	if err != nil {
+		return err
+	}
+
+	return o.emit(printInspection(o, path, &b, *all, *start, *count))
+}
+
+// printInspection lists the field elements of b, named name, as inspect
+// does: from start, count elements or up to the padding unless all is set.
+func printInspection(o *output, name string, b *kzg4844.Blob, all bool, start, count int) inspectReport {
+	insp := blob.Inspect(b)
+	notes := inspectNotes(b, insp)
+	end := insp.PaddingStart
+	if all {
+		end = blob.FieldElementsPerBlob
+	}
+	if count > 0 {
+		end = start + count
+	}
+	end = min(max(end, start), blob.FieldElementsPerBlob)
+
+	report := inspectReport{File: name, Inspection: insp, Elements: []inspectElement{}}
+	o.Printf("Blob: %s\n", name)
+	if insp.Scheme != "" {
+		o.Printf("Layout: %s", insp.Scheme)
+		if insp.PayloadSize > 0 {
+			o.Printf(", %d-byte payload", insp.PayloadSize)
+		}
+		o.Println()
+	}
+	if len(insp.NonCanonical) > 0 {
+		o.Printf("Field elements: %d of %d not canonical, so the KZG functions reject this blob\n", len(insp.NonCanonical), blob.FieldElementsPerBlob)
+	} else {
+		o.Printf("Field elements: %d, all canonical\n", blob.FieldElementsPerBlob)
+	}
+	switch insp.PaddingStart {
+	case 0:
+		o.Println("Data: none, the blob is all zeros")
+	case blob.FieldElementsPerBlob:
+		o.Printf("Data: ends at byte %d, no zero padding\n", insp.DataEnd)
+	default:
+		o.Printf("Data: ends at byte %d; zero padding from element %d (%d elements)\n",
+			insp.DataEnd, insp.PaddingStart, blob.FieldElementsPerBlob-insp.PaddingStart)
+	}
+	o.Println()
+
+	for i := start; i < end; i++ {
+		off := i * blob.BytesPerFieldElement
+		elem := b[off : off+blob.BytesPerFieldElement]
+		canonical := !slices.Contains(insp.NonCanonical, i)
+		note := notes[i]
+		if !canonical {
+			note = strings.TrimPrefix(note+"; NOT CANONICAL", "; ")
+		}
+		line := fmt.Sprintf("%4d  %06x  %x  |%s|  %s", i, off, elem, printable(elem), note)
+		o.Println(strings.TrimRight(line, " "))
+		report.Elements = append(report.Elements, inspectElement{
+			Index: i, Offset: off, Value: "0x" + hex.EncodeToString(elem), Canonical: canonical, Note: note,
+		})
+	}
+	if !all && count == 0 && end < blob.FieldElementsPerBlob {
+		o.Printf("%4d-%d  zero padding\n", end, blob.FieldElementsPerBlob-1)
+	}
+	return report
+}
+
+// inspectNotes annotates the field elements holding a framing header, and
+// the one where the data ends, by index. It also fills in the scheme of an
+// OP Stack blob, which blob.Inspect does not recognize.
+func inspectNotes(b *kzg4844.Blob, insp *blob.Inspection) map[int]string {
+	notes := make(map[int]string)
+	switch {
+	case insp.Frame != nil:
+		f := insp.Frame
+		note := fmt.Sprintf("frame header: magic %q, version %d, %s", blob.FrameMagic[:], f.Version, f.Compression)
+		if f.Encrypted {
+			note += ", encrypted"
+		}
+		notes[0] = fmt.Sprintf("%s, %d bytes stored", note, f.StoredSize)
+	case strings.HasPrefix(insp.Scheme, blob.LayoutLengthPrefixed):
+		packed, _ := blob.Unpack(b, blob.LengthPrefixSize)
+		notes[0] = fmt.Sprintf("length prefix: %d bytes", binary.BigEndian.Uint32(packed))
+	default:
+		if data, err := opstack.DecodeBlob(b); err == nil && len(data) > 0 {
+			insp.Scheme, insp.PayloadSize = layoutOPStack, len(data)
+			notes[0] = fmt.Sprintf("OP Stack header: version %d, %d bytes", b[1], len(data))
+		}
+	}
+	if insp.DataEnd > 0 && insp.DataEnd%blob.BytesPerFieldElement != 0 {
+		last := insp.DataEnd / blob.BytesPerFieldElement
+		notes[last] = strings.TrimPrefix(notes[last]+"; data ends at byte "+fmt.Sprint(insp.DataEnd), "; ")
+	}
+	return notes
+}
+
+// printable returns data with bytes outside printable ASCII shown as '.'.
+func printable(data []byte) string {
+	out := make([]byte, len(data))
+	for i, c := range data {
+		if c < 0x20 || c > 0x7e {
+			c = '.'
+		}
+		out[i] = c
+	}
+	return string(out)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+func runLocate(args []string) error {
+	fs := flag.NewFlagSet("locate", flag.ExitOnError)
+	layout := fs.String("layout", blob.LayoutFramed, "how the payload was stored: "+blob.LayoutFramed+" (encode), "+blob.LayoutPacked+" (encode --raw, split), "+blob.LayoutLengthPrefixed+" or "+blob.LayoutRaw)
+	blobPath := fs.String("blob", "", "also prove the element at its evaluation point in this blob, the one holding the byte")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc locate [--layout name] [--blob file] <payload offset>")
+		fmt.Fprintln(fs.Output(), "Reports the blob, field element and evaluation point z holding a byte of the payload.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("exactly one payload offset is required")
+	}
+	offset, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid payload offset %q", fs.Arg(0))
+	}
+	loc, err := blob.LocatePayloadByte(offset, *layout)
+	if err != nil {
+		return err
+	}
+	o.Printf("Payload byte %d: blob %d, field element %d, byte %d\nz: %x\n", loc.Offset, loc.Blob, loc.Element, loc.Byte, loc.Z[:])
+	if *blobPath == "" {
+		return o.emit(loc)
+	}
+
+	b, err := readBlobFile(*blobPath)
+	if err != nil {
+		return err
+	}
+	commitment, err := blob.Commit(&b)
+	if err != nil {
+		return fmt.Errorf("failed to generate KZG commitment: %w", err)
+	}
+	z := kzg4844.Point(loc.Z)
+	proof, y, err := blob.ProveAt(&b, z)
+	if err != nil {
+		return fmt.Errorf("failed to generate KZG proof: %w", err)
+	}
+	o.Printf("y: %x\nKZG Commitment: %x\nKZG Proof: %x\n", y[:], commitment[:], proof[:])
+	return o.emit(struct {
+		blob.PayloadByte
+		Y               hexutil.Bytes      `json:"y"`
+		Commitment      kzg4844.Commitment `json:"commitment"`
+		Proof           kzg4844.Proof      `json:"proof"`
+		PrecompileInput hexutil.Bytes      `json:"precompile_input"`
+	}{loc, y[:], commitment, proof, blob.PointEvaluationInput(commitment, z, y, proof)})
+}
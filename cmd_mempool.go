@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"kzg-blob-poc/pkg/fee"
+)
+
+// pendingBlobTx is the --json line mempool emits for each blob transaction.
+type pendingBlobTx struct {
+	Hash             common.Hash    `json:"hash"`
+	From             common.Address `json:"from"`
+	Nonce            uint64         `json:"nonce"`
+	Blobs            int            `json:"blobs"`
+	VersionedHashes  []common.Hash  `json:"versioned_hashes"`
+	MaxFeePerBlobGas *hexutil.Big   `json:"max_fee_per_blob_gas"`
+	MaxFeePerGas     *hexutil.Big   `json:"max_fee_per_gas"`
+	MaxPriorityFee   *hexutil.Big   `json:"max_priority_fee_per_gas"`
+	// BlobBaseFee is the node's blob base fee for the next block when the
+	// transaction was seen, and BlobFeeRatio the bid as a multiple of it.
+	BlobBaseFee  *hexutil.Big `json:"blob_base_fee,omitempty"`
+	BlobFeeRatio float64      `json:"blob_fee_ratio,omitempty"`
+}
+
+func runMempool(args []string) error {
+	fs := flag.NewFlagSet("mempool", flag.ExitOnError)
+	rpcURL := fs.String("rpc-url", cfg.RPCURL, "execution client websocket (ws:// or wss://) or IPC endpoint")
+	from := fs.String("from", "", "only report transactions sent by these comma-separated addresses")
+	hashPrefix := fs.String("hash-prefix", "", "only report transactions carrying a versioned hash that starts with one of these comma-separated hex prefixes")
+	count := fs.Int("count", 0, "stop after this many transactions (0 watches until interrupted)")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc mempool --rpc-url <ws-url> [--from <addr,...>] [--hash-prefix <hex,...>] [flags]")
+		fmt.Fprintln(fs.Output(), "With --json, one JSON object per transaction is written to stdout.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *rpcURL == "" {
+		return errors.New("--rpc-url is required")
+	}
+	// Subscriptions need a connection that stays open, so neither plain
+	// HTTP nor a failover list will do.
+	if strings.Contains(*rpcURL, ",") || strings.HasPrefix(*rpcURL, "http://") || strings.HasPrefix(*rpcURL, "https://") {
+		return fmt.Errorf("--rpc-url %s: mempool needs a single websocket or IPC endpoint", *rpcURL)
+	}
+	filter, err := newBlobFilter(*from, *hashPrefix)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	w := &mempoolWatcher{filter: filter, o: o, limit: *count}
+	for attempt := 0; ; attempt++ {
+		err := w.watch(ctx, *rpcURL, func() { attempt = 0 })
+		if errors.Is(err, errFollowDone) || ctx.Err() != nil {
+			break
+		}
+		var (
+			emitErr  *emitError
+			chainErr *wrongChainError
+		)
+		if errors.As(err, &emitErr) || errors.As(err, &chainErr) {
+			return err
+		}
+		delay := netPolicy.Backoff(attempt)
+		slog.Warn("Pending transaction subscription failed, reconnecting", "err", err, "delay", delay)
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	slog.Info("Stopped watching the mempool", "txs", w.txs, "blobs", w.blobs)
+	return nil
+}
+
+// wrongChainError is a node on another chain than the selected network,
+// which reconnecting would not fix.
+type wrongChainError struct{ err error }
+
+func (e *wrongChainError) Error() string { return e.err.Error() }
+func (e *wrongChainError) Unwrap() error { return e.err }
+
+// mempoolWatcher reports the blob transactions announced by a node.
+type mempoolWatcher struct {
+	filter *blobFilter
+	o      *output
+	limit  int
+
+	txs   int
+	blobs int
+}
+
+// watch subscribes to pending transactions and new heads on url and reports
+// blob transactions until the subscription fails, ctx is done or the limit
+// is reached. connected is called on each notification, once the
+// subscriptions are known to work.
+func (w *mempoolWatcher) watch(ctx context.Context, url string, connected func()) error {
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", url, err)
+	}
+	defer client.Close()
+
+	var chainID hexutil.Big
+	if err := client.CallContext(ctx, &chainID, "eth_chainId"); err != nil {
+		return fmt.Errorf("failed to get chain ID of %s: %w", url, err)
+	}
+	if network != nil {
+		if err := network.CheckChainID(chainID.ToInt().Uint64()); err != nil {
+			return &wrongChainError{fmt.Errorf("%s: %w", url, err)}
+		}
+	}
+	signer := types.LatestSignerForChainID(chainID.ToInt())
+
+	heads := make(chan *types.Header, 16)
+	headSub, err := client.EthSubscribe(ctx, heads, "newHeads")
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to new heads: %w", err)
+	}
+	defer headSub.Unsubscribe()
+
+	// Nodes that cannot send full transactions, including some that accept
+	// the flag asking for them, announce hashes instead, and each is then
+	// fetched.
+	pending := make(chan json.RawMessage, 256)
+	txSub, err := client.EthSubscribe(ctx, pending, "newPendingTransactions", true)
+	if err != nil {
+		slog.Debug("Full pending transactions not supported, fetching by hash", "err", err)
+		if txSub, err = client.EthSubscribe(ctx, pending, "newPendingTransactions"); err != nil {
+			return fmt.Errorf("failed to subscribe to pending transactions: %w", err)
+		}
+	}
+	defer txSub.Unsubscribe()
+	slog.Info("Watching pending blob transactions", "rpc", url)
+
+	baseFee, err := fee.BlobBaseFee(ctx, client)
+	if err != nil {
+		slog.Warn("Failed to get blob base fee", "err", err)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-headSub.Err():
+			return err
+		case err := <-txSub.Err():
+			return err
+		case h := <-heads:
+			if f, err := fee.BlobBaseFee(ctx, client); err == nil {
+				baseFee = f
+			} else if ctx.Err() == nil {
+				slog.Warn("Failed to get blob base fee", "block", h.Number, "err", err)
+			}
+		case msg := <-pending:
+			connected()
+			tx := pendingTx(ctx, client, msg)
+			if tx == nil {
+				continue
+			}
+			if err := w.tx(tx, signer, baseFee); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// pendingTx decodes a pending transaction notification, which is either a
+// transaction or the hash of one to fetch. It returns nil for a transaction
+// it cannot decode, such as one of a type unknown to go-ethereum, or one
+// gone by the time it is fetched, as a replaced or mined one may be.
+func pendingTx(ctx context.Context, client *rpc.Client, msg json.RawMessage) *types.Transaction {
+	var hash common.Hash
+	if err := json.Unmarshal(msg, &hash); err != nil {
+		tx := new(types.Transaction)
+		if err := json.Unmarshal(msg, tx); err != nil {
+			slog.Debug("Skipping undecodable pending transaction", "err", err)
+			return nil
+		}
+		return tx
+	}
+	var tx *types.Transaction
+	if err := client.CallContext(ctx, &tx, "eth_getTransactionByHash", hash); err != nil {
+		slog.Debug("Failed to fetch pending transaction", "tx", hash, "err", err)
+		return nil
+	}
+	return tx
+}
+
+// tx reports tx if it is a blob transaction that passes the filter. It
+// matches if its sender does and any of its versioned hashes does.
+func (w *mempoolWatcher) tx(tx *types.Transaction, signer types.Signer, baseFee *big.Int) error {
+	if tx.Type() != types.BlobTxType {
+		return nil
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		slog.Debug("Invalid blob transaction signature", "tx", tx.Hash(), "err", err)
+		return nil
+	}
+	hashes := tx.BlobHashes()
+	matched := false
+	for _, vh := range hashes {
+		if w.filter.match(vh, from) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return nil
+	}
+
+	r := pendingBlobTx{
+		Hash:             tx.Hash(),
+		From:             from,
+		Nonce:            tx.Nonce(),
+		Blobs:            len(hashes),
+		VersionedHashes:  hashes,
+		MaxFeePerBlobGas: (*hexutil.Big)(tx.BlobGasFeeCap()),
+		MaxFeePerGas:     (*hexutil.Big)(tx.GasFeeCap()),
+		MaxPriorityFee:   (*hexutil.Big)(tx.GasTipCap()),
+	}
+	bid := ""
+	if baseFee != nil && baseFee.Sign() > 0 {
+		r.BlobBaseFee = (*hexutil.Big)(baseFee)
+		r.BlobFeeRatio, _ = new(big.Rat).SetFrac(tx.BlobGasFeeCap(), baseFee).Float64()
+		bid = fmt.Sprintf(" (%.2fx base fee %s gwei)", r.BlobFeeRatio, formatGwei(baseFee))
+	}
+	w.o.Printf("📨 %s from %s nonce %d: %d blobs, max blob fee %s gwei%s, max fee %s gwei, tip %s gwei\n",
+		r.Hash, from, r.Nonce, r.Blobs, formatGwei(tx.BlobGasFeeCap()), bid, formatGwei(tx.GasFeeCap()), formatGwei(tx.GasTipCap()))
+	for _, vh := range hashes {
+		w.o.Printf("   %s\n", vh)
+	}
+	if err := w.o.emitLine(r); err != nil {
+		return &emitError{err}
+	}
+
+	w.txs++
+	w.blobs += len(hashes)
+	if w.limit > 0 && w.txs >= w.limit {
+		return errFollowDone
+	}
+	return nil
+}
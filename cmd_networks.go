@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"kzg-blob-poc/pkg/chain"
+)
+
+func runNetworks(args []string) error {
+	fs := flag.NewFlagSet("networks", flag.ExitOnError)
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc [--network <name>] networks [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	for _, p := range chain.Profiles {
+		selected := ""
+		if p == network {
+			selected = " (selected)"
+		}
+		chainID := "any"
+		if p.ChainID != 0 {
+			chainID = fmt.Sprint(p.ChainID)
+		}
+		o.Printf("%s%s\n", p.Name, selected)
+		o.Printf("  Chain ID: %s\n", chainID)
+		if len(p.Forks) == 0 {
+			o.Println("  Forks: detected from block headers")
+		}
+		for _, f := range p.Forks {
+			o.Printf("  %s (from %s): target %d, max %d blobs per block (update fraction %d)\n",
+				f.Name, time.Unix(int64(f.Time), 0).UTC().Format(time.DateTime), f.Blobs.Target, f.Blobs.Max, f.Blobs.UpdateFraction)
+		}
+		o.Printf("  RPC: %s\n", p.RPCURL)
+		o.Printf("  Beacon: %s\n", p.BeaconURL)
+		if p.BlobscanURL != "" {
+			o.Printf("  Blobscan: %s\n", p.BlobscanURL)
+		}
+	}
+	return o.emit(chain.Profiles)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+func runPrecompileInput(args []string) error {
+	fs := flag.NewFlagSet("precompile-input", flag.ExitOnError)
+	in := fs.String("in", "", "input blob file (hex text or raw binary)")
+	zHex := fs.String("z", "", "evaluation point as a big-endian field element in hex")
+	out := fs.String("out", "", "write the input hex to this file instead of stdout")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc precompile-input --z <hex> [flags] <blob>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *zHex == "" {
+		return errors.New("--z is required")
+	}
+	z, err := parseHex32("z", *zHex)
+	if err != nil {
+		return err
+	}
+	b, err := readBlobFile(path)
+	if err != nil {
+		return err
+	}
+
+	commitment, err := blob.Commit(&b)
+	if err != nil {
+		return fmt.Errorf("failed to generate KZG commitment: %w", err)
+	}
+	proof, y, err := blob.ProveAt(&b, z)
+	if err != nil {
+		return fmt.Errorf("failed to generate KZG proof: %w", err)
+	}
+
+	input := blob.PointEvaluationInput(commitment, z, y, proof)
+	return o.report(*out, hexutil.Encode(input)+"\n", struct {
+		Input hexutil.Bytes `json:"input"`
+	}{input})
+}
+
+func runPrecompileVerify(args []string) error {
+	fs := flag.NewFlagSet("precompile-verify", flag.ExitOnError)
+	in := fs.String("in", "", "file containing the hex-encoded precompile input")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc precompile-verify [--in <file>] [<input hex>]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var inputHex string
+	switch {
+	case *in != "":
+		data, err := os.ReadFile(*in)
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		inputHex = strings.TrimSpace(string(data))
+	case fs.NArg() > 0:
+		inputHex = fs.Arg(0)
+	default:
+		return errors.New("no precompile input given")
+	}
+	input, err := hexutil.Decode(ensureHexPrefix(inputHex))
+	if err != nil {
+		return fmt.Errorf("invalid input: %w", err)
+	}
+
+	output, err := blob.RunPointEvaluation(input)
+	result := struct {
+		Accepted bool          `json:"accepted"`
+		Output   hexutil.Bytes `json:"output,omitempty"`
+		Error    string        `json:"error,omitempty"`
+	}{Accepted: err == nil, Output: output}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if emitErr := o.emit(result); emitErr != nil {
+		return emitErr
+	}
+	if err != nil {
+		return fmt.Errorf("precompile rejected input: %w", err)
+	}
+	o.Println("✅ Precompile accepted input")
+	o.Printf("Output: %s\n", hexutil.Encode(output))
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/db"
+)
+
+func runProve(args []string) error {
+	fs := flag.NewFlagSet("prove", flag.ExitOnError)
+	in := fs.String("in", "", "input blob file (hex text or raw binary)")
+	out := fs.String("out", "", "write the result to this file instead of stdout")
+	includeBlob := fs.Bool("include-blob", false, "include the blob hex in the JSON output")
+	cacheDir := addCacheFlag(fs)
+	validateOnly := fs.Bool("validate-only", false, "only check that every field element is canonical, without KZG work")
+	hf := addHashFlags(fs)
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc prove [flags] <file>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *validateOnly {
+		return runValidateOnly(o, *out, []string{path}, true)
+	}
+	b, err := readBlobFile(path)
+	if err != nil {
+		return err
+	}
+
+	c, err := openCache(*cacheDir)
+	if err != nil {
+		return err
+	}
+	artifacts, err := c.Artifacts(&b, *includeBlob)
+	if err != nil {
+		return err
+	}
+	if artifacts.VersionedHash, err = hf.versionedHash(artifacts.Commitment); err != nil {
+		return err
+	}
+
+	recordBlobs(context.Background(), db.Record{
+		VersionedHash: blob.VersionedHash(artifacts.Commitment),
+		Commitment:    artifacts.Commitment,
+		Proof:         artifacts.Proof,
+		Source:        "prove",
+		File:          path,
+	})
+
+	text := fmt.Sprintf("KZG Commitment: %x\nKZG Proof: %x\nVersioned Hash: %x\n",
+		artifacts.Commitment[:], artifacts.Proof[:], artifacts.VersionedHash[:])
+	return o.report(*out, text, artifacts)
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"kzg-blob-poc/internal/atomicfile"
+	"kzg-blob-poc/pkg/archive"
+	"kzg-blob-poc/pkg/batch"
+	"kzg-blob-poc/pkg/beacon"
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/fetch"
+	"kzg-blob-poc/pkg/manifest"
+	"kzg-blob-poc/pkg/tx"
+)
+
+// publishFileName is the manifest publish writes into its output directory.
+const publishFileName = "publish.json"
+
+// publishManifest is the final report of publish: where the payload went
+// and how each stage ended.
+type publishManifest struct {
+	Payload      string          `json:"payload"`
+	PayloadSize  int             `json:"payload_size"`
+	PayloadHash  common.Hash     `json:"payload_hash"` // SHA-256
+	OutDir       string          `json:"out_dir"`
+	Blobs        []publishedBlob `json:"blobs"`
+	Transactions []*txSummary    `json:"transactions"`
+	// DryRun means the transactions were signed but not broadcast.
+	DryRun bool `json:"dry_run,omitempty"`
+	// Verified means every transaction succeeded and carries the versioned
+	// hashes of its blobs on chain, and with BeaconVerified, that the beacon
+	// node serves the same blobs with valid proofs.
+	Verified       bool      `json:"verified"`
+	BeaconVerified bool      `json:"beacon_verified"`
+	Archive        string    `json:"archive,omitempty"`
+	PublishedAt    time.Time `json:"published_at"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// publishedBlob is one blob of a published payload.
+type publishedBlob struct {
+	chunkEntry
+	Transaction common.Hash `json:"transaction"`
+	CID         string      `json:"cid,omitempty"`
+}
+
+// publishState is the progress of a publish run, kept in --state so that
+// an interrupted run can resume.
+type publishState struct {
+	path string
+	// Codec is the codec the payload is encoded with; a run resumes only
+	// with the same one.
+	Codec string `json:"codec"`
+	// Encoded describes the blobs once the payload is encoded into them.
+	Encoded *chunkFile `json:"encoded,omitempty"`
+	// Sent are the transactions broadcast so far, and whether they were
+	// mined.
+	Sent []tx.SentTx `json:"sent,omitempty"`
+}
+
+// openPublishState reads the state at path, or returns a new one if the
+// file does not exist yet. It returns nil if path is empty.
+func openPublishState(path, codecName string) (*publishState, error) {
+	if path == "" {
+		return nil, nil
+	}
+	st := &publishState{path: path, Codec: codecName}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %w", path, err)
+	}
+	if st.Codec != codecName {
+		return nil, fmt.Errorf("%s is the state of a run with --codec %s, not %s", path, st.Codec, codecName)
+	}
+	return st, nil
+}
+
+// save writes st to its file, replacing it atomically.
+func (st *publishState) save() error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.Write(st.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return nil
+}
+
+func (st *publishState) encoded() bool { return st != nil && st.Encoded != nil }
+
+// setEncoded records that the payload was encoded into the blobs of meta.
+func (st *publishState) setEncoded(meta chunkFile) error {
+	if st == nil {
+		return nil
+	}
+	st.Encoded = &meta
+	return st.save()
+}
+
+// resumeEncoded returns the blobs recorded as encoded, after checking that
+// p is the payload they were encoded from.
+func (st *publishState) resumeEncoded(p *payload) (chunkFile, error) {
+	meta := *st.Encoded
+	size, hash, err := p.hash()
+	if err != nil {
+		return chunkFile{}, err
+	}
+	if size != meta.PayloadSize || hash != meta.PayloadHash {
+		return chunkFile{}, fmt.Errorf("%s is the state of another payload (%d bytes, SHA-256 %s)", st.path, meta.PayloadSize, meta.PayloadHash)
+	}
+	return meta, nil
+}
+
+// checkpoint returns a checkpoint that keeps the sent transactions in st.
+func (st *publishState) checkpoint() *tx.Checkpoint {
+	return tx.NewCheckpoint(st.Sent, func(sent []tx.SentTx) error {
+		st.Sent = sent
+		return st.save()
+	})
+}
+
+func runPublish(args []string) error {
+	fs := flag.NewFlagSet("publish", flag.ExitOnError)
+	in := fs.String("in", "", "raw payload file, or - for stdin")
+	outDir := fs.String("out-dir", cfg.OutputDir, "directory for the blob files, "+chunksFileName+" and the manifest")
+	manifestPath := fs.String("manifest", "", "write the manifest to this file (default: <out-dir>/"+publishFileName+")")
+	statePath := fs.String("state", "", "record progress in this file, and resume from it if it exists without encoding the payload or sending any blob again")
+	workers := fs.Int("workers", runtime.NumCPU(), "number of blobs to commit to and prove concurrently")
+	useMmap := addMmapFlag(fs)
+	cacheDir := addCacheFlag(fs)
+	codecName := addCodecFlag(fs)
+	pf := addProgressFlags(fs)
+	rpcURL := fs.String("rpc-url", cfg.RPCURL, "execution client JSON-RPC endpoint")
+	kf := addKeyFlags(fs)
+	signScheme := addSignSchemeFlag(fs, "sign-manifest", "", "sign "+manifest.FileName+" with the sending key before sending")
+	to := fs.String("to", "", "recipient address of the blob transactions")
+	callf := addCallFlags(fs)
+	maxBlobs := fs.Int("max-blobs-per-tx", maxBlobsPerTx(), "most blobs per transaction")
+	beaconURL := fs.String("beacon-url", cfg.BeaconURL, "also check the published blobs and their proofs against this beacon node")
+	archiveURL := addArchiveFlag(fs, "store the blobs and their artifacts in this archive before broadcasting")
+	df := addDryRunFlags(fs)
+	sf := addScheduleFlags(fs)
+	cf := addFeeCapFlags(fs)
+	timeout := fs.Duration("timeout", 10*time.Minute, "overall timeout")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc publish --out-dir <dir> --rpc-url <url> <key flags> --to <address> [flags] <payload>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *outDir == "" {
+		return errors.New("--out-dir is required")
+	}
+	if *rpcURL == "" {
+		return errors.New("--rpc-url is required")
+	}
+	if !common.IsHexAddress(*to) {
+		return fmt.Errorf("invalid --to address %q", *to)
+	}
+	call, err := callf.parse("")
+	if err != nil {
+		return err
+	}
+	if *manifestPath == "" {
+		*manifestPath = filepath.Join(*outDir, publishFileName)
+	}
+	if *statePath != "" && *sf.checkpoint != "" {
+		return errors.New("--state already records sent transactions: --checkpoint is not needed")
+	}
+	cdc, err := openCodec(*codecName)
+	if err != nil {
+		return err
+	}
+	st, err := openPublishState(*statePath, *codecName)
+	if err != nil {
+		return err
+	}
+	if st != nil && *cacheDir == "" {
+		// Blobs committed to before an interruption are not committed to
+		// again when encoding restarts.
+		*cacheDir = filepath.Join(*outDir, ".cache")
+	}
+	p, err := openPayload(path, *useMmap)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	c, err := openCache(*cacheDir)
+	if err != nil {
+		return err
+	}
+	a, err := openArchive(*archiveURL)
+	if err != nil {
+		return err
+	}
+	client, err := dialEth(ctx, *rpcURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	var cl *beacon.Client
+	if *beaconURL != "" {
+		if cl, err = newBeaconClient(*beaconURL); err != nil {
+			return err
+		}
+	}
+	var (
+		signer tx.Signer
+		key    *ecdsa.PrivateKey
+	)
+	if *signScheme != "" {
+		if key, err = kf.privateKey("--sign-manifest"); err != nil {
+			return err
+		}
+		signer = tx.NewKeySigner(key)
+	} else if signer, err = kf.signer(ctx); err != nil {
+		return err
+	}
+
+	// Encode, commit and prove.
+	var meta chunkFile
+	if st.encoded() {
+		if meta, err = st.resumeEncoded(p); err != nil {
+			return err
+		}
+		o.Printf("Resuming from %s: %d blobs already encoded, %d transactions sent\n", *statePath, len(meta.Chunks), len(st.Sent))
+	} else {
+		pipeline := batch.NewPipeline(*workers)
+		pipeline.Cache = c
+		pipeline.Progress = pf.start("publish", p.blobs())
+		meta, err = encodePayload(ctx, p, *outDir, pipeline, cdc)
+		pipeline.Progress.Finish()
+		if err != nil {
+			return err
+		}
+		if err := st.setEncoded(meta); err != nil {
+			return err
+		}
+	}
+	if len(meta.Chunks) == 0 {
+		return errors.New("payload is empty")
+	}
+	recordBlobs(ctx, chunkRecords("publish", *outDir, meta)...)
+	o.Printf("Encoded %d bytes into %d blobs in %s\n", meta.PayloadSize, len(meta.Chunks), *outDir)
+	if key != nil {
+		path := filepath.Join(*outDir, manifest.FileName)
+		if _, err := signManifestFile(path, path, key, *signScheme); err != nil {
+			return err
+		}
+		o.Printf("Signed %s as %s\n", path, signer.Address())
+	}
+
+	blobs := make([]kzg4844.Blob, len(meta.Chunks))
+	commitments := make([]kzg4844.Commitment, len(meta.Chunks))
+	proofs := make([]kzg4844.Proof, len(meta.Chunks))
+	for i, entry := range meta.Chunks {
+		if blobs[i], err = readBlobFile(filepath.Join(*outDir, entry.File)); err != nil {
+			return err
+		}
+		commitments[i], proofs[i] = entry.Commitment, entry.Proof
+	}
+
+	// Archive, send and wait for inclusion.
+	s := &blobSender{
+		client: client, signer: signer, nonces: tx.NewNonceTracker(client), maxBlobs: *maxBlobs, archive: a,
+		commitments: commitments, proofs: proofs, call: call,
+	}
+	if err := df.apply(ctx, s); err != nil {
+		return err
+	}
+	if err := sf.apply(s); err != nil {
+		return err
+	}
+	if err := cf.apply(s); err != nil {
+		return err
+	}
+	if st != nil {
+		s.checkpoint = st.checkpoint()
+	}
+	plan, summaries, err := s.send(ctx, o, tx.Params{To: common.HexToAddress(*to)}, blobs)
+	if err != nil {
+		return err
+	}
+
+	m := publishManifest{
+		Payload:      path,
+		PayloadSize:  meta.PayloadSize,
+		PayloadHash:  meta.PayloadHash,
+		OutDir:       *outDir,
+		Blobs:        make([]publishedBlob, len(meta.Chunks)),
+		Transactions: summaries,
+		DryRun:       s.dryRun,
+		Archive:      *archiveURL,
+		PublishedAt:  time.Now().UTC(),
+	}
+	if s.dryRun {
+		m.Archive = ""
+	}
+	for i, p := range plan {
+		for j := p.FirstBlob; j < p.FirstBlob+p.Blobs; j++ {
+			m.Blobs[j] = publishedBlob{chunkEntry: meta.Chunks[j], Transaction: summaries[i].Hash}
+			m.Blobs[j].CID = archivedCID(a, meta.Chunks[j].VersionedHash)
+		}
+	}
+
+	// Verify what landed on chain.
+	if s.dryRun {
+		err = df.writeRaw(o, summaries)
+	} else {
+		err = verifyPublished(ctx, o, client, cl, summaries)
+		if err == nil {
+			m.Verified, m.BeaconVerified = true, cl != nil
+		} else {
+			m.Error = err.Error()
+		}
+	}
+
+	if writeErr := writeJSON(*manifestPath, m); writeErr != nil {
+		return writeErr
+	}
+	o.Printf("Manifest written to %s\n", *manifestPath)
+	if emitErr := o.emit(m); emitErr != nil {
+		return emitErr
+	}
+	return err
+}
+
+// verifyPublished checks that each transaction succeeded and that the chain
+// holds it with the versioned hashes of its blobs. With a beacon client, it
+// also downloads the sidecars matching those hashes and checks the blobs'
+// proofs and commitment inclusion proofs.
+func verifyPublished(ctx context.Context, o *output, client *ethclient.Client, cl *beacon.Client, summaries []*txSummary) error {
+	for _, summary := range summaries {
+		if *summary.Status != types.ReceiptStatusSuccessful {
+			return fmt.Errorf("transaction %s reverted", summary.Hash)
+		}
+		onChain, pending, err := client.TransactionByHash(ctx, summary.Hash)
+		if err != nil {
+			return fmt.Errorf("failed to get transaction %s: %w", summary.Hash, err)
+		}
+		if pending {
+			return fmt.Errorf("transaction %s is pending again, possibly after a reorg", summary.Hash)
+		}
+		if !slices.Equal(onChain.BlobHashes(), summary.BlobHashes) {
+			return fmt.Errorf("%w: transaction %s carries %v on chain, expected %v", blob.ErrVersionedHashMismatch, summary.Hash, onChain.BlobHashes(), summary.BlobHashes)
+		}
+		if cl == nil {
+			o.Printf("✅ %s: versioned hashes of %d blobs verified on chain\n", summary.Hash, len(summary.BlobHashes))
+			continue
+		}
+
+		res, err := fetch.BlobsForTx(ctx, client, cl, summary.Hash)
+		if err != nil {
+			return fmt.Errorf("transaction %s: %w", summary.Hash, err)
+		}
+		for j, sc := range res.Sidecars {
+			if err := sc.Verify(); err != nil {
+				return fmt.Errorf("transaction %s blob %d: %w", summary.Hash, j, err)
+			}
+			if err := sc.VerifyInclusionProof(); err != nil {
+				return fmt.Errorf("transaction %s blob %d: %w", summary.Hash, j, err)
+			}
+		}
+		o.Printf("✅ %s: versioned hashes of %d blobs verified on chain, sidecars verified at slot %d\n", summary.Hash, len(summary.BlobHashes), res.Slot)
+	}
+	return nil
+}
+
+// archivedCID returns the content identifier of an archived blob, if the
+// archive is content-addressed.
+func archivedCID(a *archive.Archive, h common.Hash) string {
+	if a == nil {
+		return ""
+	}
+	cid, _ := a.CID(h)
+	return cid
+}
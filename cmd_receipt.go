@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/fee"
+)
+
+// receiptReport is the JSON output of receipt.
+type receiptReport struct {
+	TxHash      common.Hash `json:"tx_hash"`
+	BlockNumber uint64      `json:"block_number"`
+	Fork        string      `json:"fork"`
+	*fee.ReceiptCost
+	// PayloadSize is the size of --payload or --payload-size, or else the
+	// packed capacity of the blobs, which PayloadKnown tells apart.
+	PayloadSize      int     `json:"payload_size"`
+	PayloadKnown     bool    `json:"payload_known"`
+	BlobCostPerByte  float64 `json:"blob_cost_per_byte_wei"`
+	TotalCostPerByte float64 `json:"total_cost_per_byte_wei"`
+}
+
+func runReceipt(args []string) error {
+	fs := flag.NewFlagSet("receipt", flag.ExitOnError)
+	rpcURL := fs.String("rpc-url", cfg.RPCURL, "execution client JSON-RPC endpoint")
+	txHash := fs.String("tx", "", "hash of the mined blob transaction")
+	payload := fs.String("payload", "", "payload file the blobs carry, to price per payload byte")
+	payloadSize := fs.Int("payload-size", 0, "payload size in bytes, instead of --payload")
+	timeout := fs.Duration("timeout", 30*time.Second, "RPC timeout")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc receipt --rpc-url <url> [--payload file | --payload-size n] (--tx <hash> | <hash>)")
+		fmt.Fprintln(fs.Output(), "Reports the blob and execution cost a mined blob transaction paid.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	hashHex := *txHash
+	if hashHex == "" && fs.NArg() > 0 {
+		hashHex = fs.Arg(0)
+	}
+	if hashHex == "" {
+		return errors.New("a transaction hash is required")
+	}
+	hash, err := parseHexFixed("tx hash", hashHex, common.HashLength)
+	if err != nil {
+		return err
+	}
+	if *rpcURL == "" {
+		return errors.New("--rpc-url is required")
+	}
+	if *payload != "" && *payloadSize != 0 {
+		return errors.New("--payload and --payload-size are mutually exclusive")
+	}
+	if *payloadSize < 0 {
+		return errors.New("--payload-size must not be negative")
+	}
+	size := *payloadSize
+	if *payload != "" {
+		data, err := readInputFile(*payload)
+		if err != nil {
+			return fmt.Errorf("failed to read payload: %w", err)
+		}
+		size = len(data)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	client, err := dialEth(ctx, *rpcURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	receipt, err := client.TransactionReceipt(ctx, common.Hash(hash))
+	if err != nil {
+		return fmt.Errorf("failed to get receipt: %w", err)
+	}
+	header, err := client.HeaderByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return fmt.Errorf("failed to get block header: %w", err)
+	}
+	fork, ok := headerFork(header)
+	if !ok {
+		return fmt.Errorf("block %d (time %d) is before Cancun", header.Number, header.Time)
+	}
+	cost, err := fee.ReceiptCosts(receipt, header, fork.Blobs)
+	if err != nil {
+		return fmt.Errorf("transaction %s: %w", receipt.TxHash, err)
+	}
+
+	r := receiptReport{
+		TxHash: receipt.TxHash, BlockNumber: header.Number.Uint64(), Fork: fork.Name, ReceiptCost: cost,
+		PayloadSize: size, PayloadKnown: size > 0,
+	}
+	if !r.PayloadKnown {
+		r.PayloadSize = cost.Blobs * blob.MaxPackedSize
+	}
+	r.BlobCostPerByte = fee.PerByte(cost.BlobCost, r.PayloadSize)
+	r.TotalCostPerByte = fee.PerByte(cost.TotalCost, r.PayloadSize)
+	if cost.BlobGasPrice.Cmp(cost.BlockBlobBaseFee) != 0 {
+		slog.Warn("Receipt blob gas price differs from the blob base fee of the block header; the fork schedule may not be the chain's",
+			"blob_gas_price", cost.BlobGasPrice, "computed", cost.BlockBlobBaseFee, "fork", fork.Name)
+	}
+
+	o.Printf("Transaction %s in block %d (%s)\n", r.TxHash, r.BlockNumber, r.Fork)
+	o.Printf("Blobs: %d (%d blob gas; block used %d, excess %d)\n", cost.Blobs, cost.BlobGasUsed, cost.BlockBlobGasUsed, cost.ExcessBlobGas)
+	o.Printf("Blob Gas Price: %s wei (%s gwei)\n", cost.BlobGasPrice, formatGwei(cost.BlobGasPrice))
+	o.Printf("Blob Cost: %s wei (%s gwei), all burned\n", cost.BlobCost, formatGwei(cost.BlobCost))
+	o.Printf("Execution Gas: %d at %s gwei (base fee %s gwei)\n", cost.GasUsed, formatGwei(cost.EffectiveGasPrice), formatGwei(cost.BaseFee))
+	o.Printf("Execution Cost: %s wei (%s gwei), %s gwei of it priority fee\n", cost.ExecutionCost, formatGwei(cost.ExecutionCost), formatGwei(cost.PriorityFee))
+	o.Printf("Total Cost: %s wei (%s gwei), %.2f%% of it for blobs\n", cost.TotalCost, formatGwei(cost.TotalCost), 100*cost.BlobShare)
+	if r.PayloadKnown {
+		o.Printf("Cost per payload byte (%d bytes): %.4f wei for blobs, %.4f wei in total\n", r.PayloadSize, r.BlobCostPerByte, r.TotalCostPerByte)
+	} else {
+		o.Printf("Cost per byte of blob capacity (%d bytes; give --payload for the payload): %.4f wei for blobs, %.4f wei in total\n",
+			r.PayloadSize, r.BlobCostPerByte, r.TotalCostPerByte)
+	}
+	return o.emit(r)
+}
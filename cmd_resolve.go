@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/db"
+	"kzg-blob-poc/pkg/fetch"
+)
+
+// resolveReport is the JSON output of resolve.
+type resolveReport struct {
+	VersionedHash common.Hash      `json:"versioned_hash"`
+	Locations     []fetch.Location `json:"locations"`
+	// Blob is the fetched blob, with --fetch.
+	Blob *fetchedBlob `json:"blob,omitempty"`
+}
+
+func runResolve(args []string) error {
+	fs := flag.NewFlagSet("resolve", flag.ExitOnError)
+	rpcURL := fs.String("rpc-url", cfg.RPCURL, "execution client JSON-RPC endpoint to scan blocks and confirm indexer results with")
+	indexer := fs.String("indexer", "", "ask this indexer first: blobscan, or a Blobscan API URL")
+	blocks := fs.Uint64("blocks", 1024, "number of recent blocks to scan")
+	toBlock := fs.Uint64("to-block", 0, "newest block to scan (default: the head)")
+	doFetch := fs.Bool("fetch", false, "also fetch the blob from the beacon node and verify it")
+	beaconURL := fs.String("beacon-url", cfg.BeaconURL, "beacon node API endpoint, with --fetch")
+	fallback := fs.String("fallback", cfg.BlobFallback, "with --fetch, comma-separated sources of blobs the beacon node pruned, as for fetch")
+	out := fs.String("out", "", "with --fetch, write the blob to this file")
+	timeout := fs.Duration("timeout", 5*time.Minute, "overall timeout")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc resolve [flags] <versioned hash>")
+		fmt.Fprintln(fs.Output(), "Finds the blob transaction that carried a versioned hash, with its block and sender.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("a versioned hash is required")
+	}
+	hb, err := parseHexFixed("versioned hash", fs.Arg(0), common.HashLength)
+	if err != nil {
+		return err
+	}
+	h := common.Hash(hb)
+	if h[0] != blob.VersionKZG {
+		return fmt.Errorf("versioned hash %s has version %#x, want %#x", h, h[0], blob.VersionKZG)
+	}
+	if *indexer == "" && *rpcURL == "" {
+		return errors.New("--rpc-url or --indexer is required")
+	}
+	if *doFetch && (*rpcURL == "" || *beaconURL == "") {
+		return errors.New("--fetch needs --rpc-url and --beacon-url")
+	}
+	if *out != "" && !*doFetch {
+		return errors.New("--out needs --fetch")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	report := resolveReport{VersionedHash: h}
+
+	if *indexer != "" {
+		ix, err := openIndexer(*indexer)
+		if err != nil {
+			return err
+		}
+		locs, err := ix.Locate(ctx, h)
+		if err != nil && !errors.Is(err, fetch.ErrHashNotFound) {
+			return err
+		}
+		if err != nil && *rpcURL != "" {
+			o.Printf("%s does not know %s; scanning blocks instead\n", ix.Name(), h)
+		}
+		report.Locations = locs
+	}
+
+	if *rpcURL != "" {
+		el, err := dialEth(ctx, *rpcURL)
+		if err != nil {
+			return err
+		}
+		defer el.Close()
+
+		// An indexer's answer stands once the chain confirms it.
+		confirmed := report.Locations[:0]
+		for _, loc := range report.Locations {
+			if err := fetch.ConfirmLocation(ctx, el, &loc); err != nil {
+				slog.Warn("Indexer reported a transaction the chain does not confirm", "indexer", loc.Source, "tx", loc.TxHash, "err", err)
+				continue
+			}
+			confirmed = append(confirmed, loc)
+		}
+		report.Locations = confirmed
+
+		if len(report.Locations) == 0 {
+			to := *toBlock
+			if to == 0 {
+				if to, err = el.BlockNumber(ctx); err != nil {
+					return fmt.Errorf("failed to get block number: %w", err)
+				}
+			}
+			from := to - min(to, max(*blocks, 1)-1)
+			o.Printf("Scanning blocks %d-%d for %s\n", from, to, h)
+			loc, err := fetch.ScanBlocks(ctx, el, h, from, to)
+			if err != nil {
+				return err
+			}
+			report.Locations = []fetch.Location{*loc}
+		}
+
+		if *doFetch {
+			if report.Blob, err = resolveFetch(ctx, el, report.Locations[0], *beaconURL, *fallback, *out); err != nil {
+				return err
+			}
+		}
+	}
+	if len(report.Locations) == 0 {
+		return fmt.Errorf("%w %s", fetch.ErrHashNotFound, h)
+	}
+
+	for _, loc := range report.Locations {
+		o.Printf("✅ %s\n", h)
+		o.Printf("   Transaction: %s (blob %d)\n", loc.TxHash, loc.Index)
+		o.Printf("   Block:       %d %s\n", loc.BlockNumber, loc.BlockHash)
+		if loc.From != (common.Address{}) {
+			o.Printf("   Sender:      %s\n", loc.From)
+		}
+		switch {
+		case loc.Source == fetch.SourceChain:
+			o.Println("   Source:      found by scanning blocks")
+		case loc.Confirmed:
+			o.Printf("   Source:      %s, confirmed on chain\n", loc.Source)
+		default:
+			o.Printf("   Source:      %s, not confirmed (no --rpc-url)\n", loc.Source)
+		}
+	}
+	if r := report.Blob; r != nil && r.Valid {
+		o.Printf("✅ Blob fetched and verified\n")
+		if r.File != "" {
+			o.Printf("   Written to %s\n", r.File)
+		}
+	}
+	return o.emit(report)
+}
+
+// openIndexer returns the indexer of --indexer: "blobscan" for the Blobscan
+// API of the selected network, or an http(s) URL for another Blobscan API.
+func openIndexer(s string) (fetch.Indexer, error) {
+	switch {
+	case s == "blobscan":
+		url := fetch.DefaultBlobscanURL
+		if network != nil {
+			if url = network.BlobscanURL; url == "" {
+				return nil, fmt.Errorf("network %s has no Blobscan API; give its URL in --indexer", network.Name)
+			}
+		}
+		return fetch.NewBlobscan(url, netPolicy.Client()), nil
+	case strings.HasPrefix(s, "http://"), strings.HasPrefix(s, "https://"):
+		return fetch.NewBlobscan(s, netPolicy.Client()), nil
+	default:
+		return nil, fmt.Errorf("unknown --indexer %q (want blobscan or a Blobscan API URL)", s)
+	}
+}
+
+// resolveFetch fetches the blob at loc, as fetch --tx does, verifies it and
+// writes it to out unless that is empty.
+func resolveFetch(ctx context.Context, el fetch.ExecutionClient, loc fetch.Location, beaconURL, fallback, out string) (*fetchedBlob, error) {
+	sources, err := openBlobSources(fallback)
+	if err != nil {
+		return nil, err
+	}
+	cl, err := newBeaconClient(beaconURL)
+	if err != nil {
+		return nil, err
+	}
+	res, err := fetch.BlobsForTx(ctx, el, cl, loc.TxHash, sources...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		b   *kzg4844.Blob
+		art blob.BlobArtifacts
+		r   = &fetchedBlob{VersionedHash: loc.VersionedHash, Valid: true}
+	)
+	if sc := res.Sidecars[loc.Index]; sc != nil {
+		if err := sc.Verify(); err != nil {
+			return nil, fmt.Errorf("blob %s: %w", loc.VersionedHash, err)
+		}
+		if err := sc.VerifyInclusionProof(); err != nil {
+			return nil, fmt.Errorf("blob %s: %w", loc.VersionedHash, err)
+		}
+		r.Index, b = &sc.Index, &sc.Blob
+		art = blob.BlobArtifacts{Commitment: sc.KZGCommitment, Proof: sc.KZGProof, VersionedHash: loc.VersionedHash}
+	} else {
+		// Checked against the versioned hash by FromSources.
+		s := res.Sourced[loc.Index]
+		r.Source, b = s.Source, s.Blob
+		art = blob.BlobArtifacts{Commitment: s.Commitment, Proof: s.Proof, VersionedHash: loc.VersionedHash}
+	}
+	if out != "" {
+		if err := os.WriteFile(out, encodeOutput(b[:]), 0o644); err != nil {
+			return nil, err
+		}
+		r.File = out
+	}
+	recordBlobs(ctx, db.Record{
+		VersionedHash: loc.VersionedHash, Commitment: art.Commitment, Proof: art.Proof,
+		Source: "resolve", File: r.File, TxHash: loc.TxHash, BlockNumber: loc.BlockNumber,
+	})
+	return r, nil
+}
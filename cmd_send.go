@@ -0,0 +1,526 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+
+	"kzg-blob-poc/pkg/archive"
+	"kzg-blob-poc/pkg/calldata"
+	"kzg-blob-poc/pkg/db"
+	"kzg-blob-poc/pkg/metrics"
+	"kzg-blob-poc/pkg/tx"
+)
+
+func runSend(args []string) error {
+	fs := flag.NewFlagSet("send", flag.ExitOnError)
+	rpcURL := fs.String("rpc-url", cfg.RPCURL, "execution client JSON-RPC endpoint")
+	kf := addKeyFlags(fs)
+	to := fs.String("to", "", "recipient address")
+	data := fs.String("data", "", "hex-encoded calldata")
+	callf := addCallFlags(fs)
+	value := newBigFlag(0)
+	fs.Var(value, "value", "value to transfer in wei")
+	maxBlobs := fs.Int("max-blobs-per-tx", maxBlobsPerTx(), "most blobs per transaction; more blobs are sent in several transactions")
+	timeout := fs.Duration("timeout", 5*time.Minute, "how long to wait for the receipts")
+	archiveURL := addArchiveFlag(fs, "store the blobs and their artifacts in this archive before broadcasting")
+	df := addDryRunFlags(fs)
+	sf := addScheduleFlags(fs)
+	cf := addFeeCapFlags(fs)
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc send --rpc-url <url> (--key-file | --keystore | --mnemonic-file | --signer-url) <...> --to <address> [flags] <blob>...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return errors.New("at least one blob file is required")
+	}
+	if *rpcURL == "" {
+		return errors.New("--rpc-url is required")
+	}
+	if !common.IsHexAddress(*to) {
+		return fmt.Errorf("invalid --to address %q", *to)
+	}
+	input, err := hexutil.Decode(ensureHexPrefix(*data))
+	if err != nil {
+		return fmt.Errorf("invalid --data: %w", err)
+	}
+	call, err := callf.parse(*data)
+	if err != nil {
+		return err
+	}
+	blobs := make([]kzg4844.Blob, fs.NArg())
+	for i, path := range fs.Args() {
+		if blobs[i], err = readBlobFile(path); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	client, err := dialEth(ctx, *rpcURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	signer, err := kf.signer(ctx)
+	if err != nil {
+		return err
+	}
+	a, err := openArchive(*archiveURL)
+	if err != nil {
+		return err
+	}
+
+	base := tx.Params{To: common.HexToAddress(*to), Value: value.Int, Data: input}
+	s := &blobSender{client: client, signer: signer, nonces: tx.NewNonceTracker(client), maxBlobs: *maxBlobs, archive: a, call: call}
+	if err := df.apply(ctx, s); err != nil {
+		return err
+	}
+	if err := sf.apply(s); err != nil {
+		return err
+	}
+	if err := cf.apply(s); err != nil {
+		return err
+	}
+	plan, summaries, err := s.send(ctx, o, base, blobs)
+	if err != nil {
+		return err
+	}
+	if s.dryRun {
+		if err := df.writeRaw(o, summaries); err != nil {
+			return err
+		}
+	}
+	reverted := 0
+	for _, summary := range summaries {
+		if summary.Status != nil && *summary.Status != types.ReceiptStatusSuccessful {
+			reverted++
+		}
+	}
+
+	// A single transaction keeps the output of the unpacked command.
+	var result any = summaries[0]
+	if len(plan) > 1 {
+		result = packedSend{Plan: plan, Transactions: summaries}
+	}
+	if err := o.emit(result); err != nil {
+		return err
+	}
+	if reverted > 0 && len(summaries) == 1 {
+		return errors.New("transaction reverted")
+	}
+	if reverted > 0 {
+		return fmt.Errorf("%d of %d transactions reverted", reverted, len(summaries))
+	}
+	return nil
+}
+
+// dryRunFlags are the flags of the commands that can stop short of
+// broadcasting.
+type dryRunFlags struct {
+	dryRun      *bool
+	simulate    *bool
+	simulateURL *string
+	rawOut      *string
+}
+
+func addDryRunFlags(fs *flag.FlagSet) *dryRunFlags {
+	return &dryRunFlags{
+		dryRun:      fs.Bool("dry-run", false, "fill in, estimate gas for and sign the transactions, but do not archive, broadcast or wait for them"),
+		simulate:    fs.Bool("simulate", false, "run each transaction as an eth_call before signing it"),
+		simulateURL: fs.String("simulate-url", "", "endpoint to run --simulate on, such as a fork of the chain (default: --rpc-url)"),
+		rawOut:      fs.String("raw-out", "", "with --dry-run, write the raw signed transactions to this file, one hex line each"),
+	}
+}
+
+// apply configures s for the flags.
+func (f *dryRunFlags) apply(ctx context.Context, s *blobSender) error {
+	if *f.rawOut != "" && !*f.dryRun {
+		return errors.New("--raw-out requires --dry-run")
+	}
+	s.dryRun = *f.dryRun
+	if !*f.simulate && *f.simulateURL == "" {
+		return nil
+	}
+	s.simulator = s.client
+	if *f.simulateURL != "" {
+		client, err := dialEth(ctx, *f.simulateURL)
+		if err != nil {
+			return err
+		}
+		s.simulator = client
+	}
+	return nil
+}
+
+// writeRaw reports the raw encoding of the transactions signed in a dry
+// run: to --raw-out if given, and printed otherwise.
+func (f *dryRunFlags) writeRaw(o *output, summaries []*txSummary) error {
+	if *f.rawOut != "" {
+		var lines []byte
+		for _, summary := range summaries {
+			lines = append(lines, hexutil.Encode(summary.Raw)+"\n"...)
+		}
+		if err := writeOutput(*f.rawOut, lines); err != nil {
+			return err
+		}
+		o.Printf("Raw transactions written to %s\n", *f.rawOut)
+	} else {
+		for _, summary := range summaries {
+			o.Printf("Raw Transaction %s: %s\n", summary.Hash, hexutil.Encode(summary.Raw))
+		}
+	}
+	return nil
+}
+
+// scheduleFlags are the flags pacing the transactions of a large send.
+type scheduleFlags struct {
+	maxBlobsPerBlock *int
+	interval         *time.Duration
+	maxBlobBaseFee   *bigFlag
+	checkpoint       *string
+}
+
+func addScheduleFlags(fs *flag.FlagSet) *scheduleFlags {
+	f := &scheduleFlags{
+		maxBlobsPerBlock: fs.Int("max-blobs-per-block", 0, "submit at most this many blobs while the head stays at one block (0: no cap)"),
+		interval:         fs.Duration("tx-interval", 0, "least time between two transactions"),
+		maxBlobBaseFee:   newBigFlag(0),
+		checkpoint:       fs.String("checkpoint", "", "record sent transactions in this file and, when resuming, skip those already sent"),
+	}
+	fs.Var(f.maxBlobBaseFee, "max-blob-base-fee", "wait while the blob base fee is above this many wei (0: never wait)")
+	return f
+}
+
+// apply configures s for the flags.
+func (f *scheduleFlags) apply(s *blobSender) error {
+	if *f.maxBlobsPerBlock < 0 || *f.interval < 0 || f.maxBlobBaseFee.Sign() < 0 {
+		return errors.New("--max-blobs-per-block, --tx-interval and --max-blob-base-fee must not be negative")
+	}
+	if *f.maxBlobsPerBlock > 0 || *f.interval > 0 || f.maxBlobBaseFee.Sign() > 0 {
+		s.schedule = &tx.Scheduler{
+			MaxBlobsPerBlock: *f.maxBlobsPerBlock,
+			Interval:         *f.interval,
+			OnWait:           func(reason string) { slog.Info("Holding transaction back", "reason", reason) },
+		}
+		if f.maxBlobBaseFee.Sign() > 0 {
+			s.schedule.MaxBlobBaseFee = f.maxBlobBaseFee.Int
+		}
+	}
+	if *f.checkpoint != "" {
+		var err error
+		if s.checkpoint, err = tx.OpenCheckpoint(*f.checkpoint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// feeCapFlags are the flags limiting the fees of each transaction.
+type feeCapFlags struct {
+	maxFeePerGas     *bigFlag
+	maxFeePerBlobGas *bigFlag
+	maxTotalCost     *bigFlag
+	waitBelowCap     *bool
+}
+
+func addFeeCapFlags(fs *flag.FlagSet) *feeCapFlags {
+	f := &feeCapFlags{
+		maxFeePerGas:     newBigFlag(0),
+		maxFeePerBlobGas: newBigFlag(0),
+		maxTotalCost:     newBigFlag(0),
+		waitBelowCap:     fs.Bool("wait-below-cap", false, "wait for fees to fall below the caps instead of failing"),
+	}
+	fs.Var(f.maxFeePerGas, "max-fee-per-gas", "never pay more than this many wei per gas; fail if the base fee plus tip is above it (0: no cap)")
+	fs.Var(f.maxFeePerBlobGas, "max-fee-per-blob-gas", "never pay more than this many wei per blob gas; fail if the blob base fee is above it (0: no cap)")
+	fs.Var(f.maxTotalCost, "max-total-cost", "fail if a transaction may cost more than this many wei in fees (0: no cap)")
+	return f
+}
+
+// apply configures s for the flags.
+func (f *feeCapFlags) apply(s *blobSender) error {
+	caps, err := f.caps()
+	if err != nil {
+		return err
+	}
+	s.caps, s.waitBelowCap = caps, *f.waitBelowCap
+	return nil
+}
+
+// caps returns the limits the flags set, or nil if they set none.
+func (f *feeCapFlags) caps() (*tx.FeeCaps, error) {
+	if f.maxFeePerGas.Sign() < 0 || f.maxFeePerBlobGas.Sign() < 0 || f.maxTotalCost.Sign() < 0 {
+		return nil, errors.New("--max-fee-per-gas, --max-fee-per-blob-gas and --max-total-cost must not be negative")
+	}
+	caps := &tx.FeeCaps{}
+	if f.maxFeePerGas.Sign() > 0 {
+		caps.MaxFeePerGas = f.maxFeePerGas.Int
+	}
+	if f.maxFeePerBlobGas.Sign() > 0 {
+		caps.MaxFeePerBlobGas = f.maxFeePerBlobGas.Int
+	}
+	if f.maxTotalCost.Sign() > 0 {
+		caps.MaxTotalCost = f.maxTotalCost.Int
+	}
+	if *caps == (tx.FeeCaps{}) {
+		if *f.waitBelowCap {
+			return nil, errors.New("--wait-below-cap needs --max-fee-per-gas, --max-fee-per-blob-gas or --max-total-cost")
+		}
+		return nil, nil
+	}
+	return caps, nil
+}
+
+// packedSend is the JSON output of send when the blobs span several
+// transactions.
+type packedSend struct {
+	Plan         []tx.PackedTx `json:"plan"`
+	Transactions []*txSummary  `json:"transactions"`
+}
+
+// blobSender sends blobs in as many transactions as they need and waits
+// for the receipts.
+type blobSender struct {
+	client *ethclient.Client
+	signer tx.Signer
+	// nonces hands out consecutive nonces, since the node's pending nonce
+	// only advances once it has accepted each transaction.
+	nonces   *tx.NonceTracker
+	maxBlobs int
+	// archive, if set, receives the blobs of each transaction before it is
+	// broadcast, so no published blob is missing from it.
+	archive *archive.Archive
+	// simulator, if set, runs each transaction as an eth_call before it is
+	// signed.
+	simulator *ethclient.Client
+	// dryRun stops short of archiving and broadcasting: the signed
+	// transactions are returned with their raw encoding and without a
+	// receipt status.
+	dryRun bool
+	// commitments and proofs, if set, are those of the blobs given to send,
+	// which then does not compute them again.
+	commitments []kzg4844.Commitment
+	proofs      []kzg4844.Proof
+	// schedule, if set, paces the broadcasts.
+	schedule *tx.Scheduler
+	// checkpoint, if set, records each broadcast transaction; one it
+	// already holds is waited for instead of sent again.
+	checkpoint *tx.Checkpoint
+	// caps, if set, limit the fees of each transaction. A transaction
+	// whose prices are above them is not sent and fails the send, or with
+	// waitBelowCap waits until they fall.
+	caps         *tx.FeeCaps
+	waitBelowCap bool
+	// call, if set, is the contract call each transaction makes, encoded
+	// with its versioned hashes into its data.
+	call *calldata.Call
+}
+
+// send packs blobs into transactions based on base, signs and broadcasts
+// them, and waits until all are mined. The returned summaries hold the
+// receipt status, or in a dry run the raw transaction instead.
+func (s *blobSender) send(ctx context.Context, o *output, base tx.Params, blobs []kzg4844.Blob) ([]tx.PackedTx, []*txSummary, error) {
+	var (
+		plan     []tx.PackedTx
+		sidecars []*types.BlobTxSidecar
+		err      error
+	)
+	if s.commitments != nil {
+		plan, sidecars, err = tx.PackComputed(blobs, s.commitments, s.proofs, s.maxBlobs)
+	} else {
+		plan, sidecars, err = tx.Pack(ctx, blobs, s.maxBlobs)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(plan) > 1 {
+		o.Printf("Packing %d blobs into %d transactions (at most %d per transaction)\n", len(blobs), len(plan), s.maxBlobs)
+	}
+
+	from := s.signer.Address()
+	summaries := make([]*txSummary, len(plan))
+	for i, p := range plan {
+		if len(plan) > 1 {
+			o.Printf("Transaction %d: blobs %d-%d\n", i, p.FirstBlob, p.FirstBlob+p.Blobs-1)
+		}
+		if !s.dryRun {
+			resumed, err := s.resume(ctx, o, sidecars[i].BlobHashes())
+			if err != nil {
+				return nil, nil, err
+			}
+			if resumed != nil {
+				summaries[i] = resumed
+				continue
+			}
+			if err := s.schedule.Wait(ctx, s.client, p.Blobs); err != nil {
+				return nil, nil, err
+			}
+		}
+		txBase := base
+		if s.call != nil {
+			if txBase.Data, err = s.call.Encode(sidecars[i].BlobHashes()); err != nil {
+				return nil, nil, fmt.Errorf("transaction %d: %w", i, err)
+			}
+		}
+		params, err := s.fill(ctx, txBase, p.Blobs, sidecars[i].BlobHashes())
+		if err != nil {
+			return nil, nil, err
+		}
+		if s.simulator != nil {
+			if err := tx.Simulate(ctx, s.simulator, from, params, sidecars[i].BlobHashes()); err != nil {
+				s.nonces.Release(from, params.Nonce)
+				return nil, nil, err
+			}
+		}
+		unsigned, err := tx.NewBlobTx(params, sidecars[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		signed, err := s.signer.SignTx(ctx, unsigned)
+		if err != nil {
+			s.nonces.Release(from, params.Nonce)
+			return nil, nil, fmt.Errorf("failed to sign transaction: %w", err)
+		}
+		summaries[i] = newTxSummary(signed)
+		summaries[i].print(o)
+		if s.dryRun {
+			if summaries[i].Raw, err = signed.MarshalBinary(); err != nil {
+				return nil, nil, fmt.Errorf("failed to encode transaction: %w", err)
+			}
+			o.Printf("Gas: %d, fee caps in wei: tip %s, gas %s, blob gas %s\n", params.Gas, params.GasTipCap, params.GasFeeCap, params.BlobFeeCap)
+			continue
+		}
+		if err := archiveBlobs(ctx, s.archive, sidecars[i].Blobs, sidecars[i].Commitments, sidecars[i].Proofs); err != nil {
+			s.nonces.Release(from, params.Nonce)
+			return nil, nil, err
+		}
+
+		if err := s.client.SendTransaction(ctx, signed); err != nil && !tx.IsAlreadyKnown(err) {
+			metrics.RPCError("eth_sendRawTransaction")
+			s.nonces.Release(from, params.Nonce)
+			return nil, nil, fmt.Errorf("failed to send transaction: %w", err)
+		}
+		sent := tx.SentTx{BlobHashes: summaries[i].BlobHashes, Hash: summaries[i].Hash, Nonce: params.Nonce}
+		if err := s.checkpoint.Record(sent); err != nil {
+			return nil, nil, fmt.Errorf("failed to write checkpoint: %w", err)
+		}
+	}
+	if s.dryRun {
+		o.Printf("Dry run: %d signed transactions not broadcast\n", len(summaries))
+		return plan, summaries, nil
+	}
+	o.Println("Submitted, waiting for receipts...")
+
+	for _, summary := range summaries {
+		if summary.Status != nil {
+			continue // mined before resuming
+		}
+		receipt, err := tx.WaitMined(ctx, s.client, summary.Hash, 2*time.Second)
+		if err != nil {
+			return nil, nil, err
+		}
+		o.Printf("%s included in block %d (status %d)\n", summary.Hash, receipt.BlockNumber, receipt.Status)
+		summary.BlockNumber = receipt.BlockNumber
+		summary.Status = &receipt.Status
+		if err := s.checkpoint.Mine(summary.Hash, receipt.BlockNumber.Uint64(), receipt.Status); err != nil {
+			return nil, nil, fmt.Errorf("failed to write checkpoint: %w", err)
+		}
+	}
+
+	var recs []db.Record
+	for i, sidecar := range sidecars {
+		for j, h := range summaries[i].BlobHashes {
+			recs = append(recs, db.Record{
+				VersionedHash: h,
+				Commitment:    sidecar.Commitments[j],
+				Proof:         sidecar.Proofs[j],
+				Source:        "send",
+				TxHash:        summaries[i].Hash,
+				BlockNumber:   summaries[i].BlockNumber.Uint64(),
+			})
+		}
+	}
+	recordBlobs(ctx, recs...)
+	return plan, summaries, nil
+}
+
+// fill fills in the parameters of a transaction with blobs blobs from
+// base, within s.caps.
+func (s *blobSender) fill(ctx context.Context, base tx.Params, blobs int, blobHashes []common.Hash) (tx.Params, error) {
+	from := s.signer.Address()
+	waiting := false
+	for {
+		params := base
+		if err := tx.Fill(ctx, s.nonces, from, &params, blobHashes); err != nil {
+			return params, err
+		}
+		err := s.caps.Apply(ctx, s.client, &params, blobs)
+		if err == nil {
+			return params, nil
+		}
+		s.nonces.Release(from, params.Nonce)
+		if !s.waitBelowCap || !errors.Is(err, tx.ErrFeeCap) {
+			return params, err
+		}
+		if !waiting {
+			slog.Info("Waiting for fees to fall below the caps", "reason", err)
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			return params, fmt.Errorf("%w (%w)", ctx.Err(), err)
+		case <-time.After(tx.DefaultSchedulePoll):
+		}
+	}
+}
+
+// resume returns the summary of the transaction the checkpoint holds for
+// blobHashes, or nil if they were not sent yet or their transaction has
+// left the node's pool unmined, so they must be sent again. A transaction
+// the checkpoint saw mined is not looked up again.
+func (s *blobSender) resume(ctx context.Context, o *output, blobHashes []common.Hash) (*txSummary, error) {
+	sent, ok, err := s.checkpoint.Lookup(blobHashes)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if sent.Mined() {
+		o.Printf("Transaction %s was mined in block %d before; not sending it again\n", sent.Hash, sent.BlockNumber)
+		return &txSummary{
+			Hash:        sent.Hash,
+			Sender:      s.signer.Address(),
+			BlobHashes:  sent.BlobHashes,
+			BlobGas:     uint64(len(sent.BlobHashes)) * params.BlobTxBlobGasPerBlob,
+			BlockNumber: new(big.Int).SetUint64(sent.BlockNumber),
+			Status:      sent.Status,
+		}, nil
+	}
+	prev, _, err := s.client.TransactionByHash(ctx, sent.Hash)
+	if errors.Is(err, ethereum.NotFound) {
+		o.Printf("Checkpointed transaction %s is unknown to the node; sending its blobs again\n", sent.Hash)
+		return nil, nil
+	}
+	if err != nil {
+		metrics.RPCError("eth_getTransactionByHash")
+		return nil, fmt.Errorf("failed to get checkpointed transaction %s: %w", sent.Hash, err)
+	}
+	summary := newTxSummary(prev)
+	o.Printf("Transaction %s was sent before (nonce %d); waiting for it instead of sending again\n", sent.Hash, sent.Nonce)
+	return summary, nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"syscall"
+	"time"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/metrics"
+	"kzg-blob-poc/pkg/server"
+)
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := fs.String("listen", ":8080", "address for the HTTP API (empty to disable)")
+	grpcListen := fs.String("grpc-listen", "", "address for the gRPC BlobService (empty to disable)")
+	metricsListen := fs.String("metrics-listen", "", "separate address for the Prometheus /metrics endpoint, which --listen also serves")
+	blobTimeout := addBlobTimeoutFlag(fs)
+	maxConcurrent := fs.Int("max-concurrent", runtime.NumCPU(), "KZG operations run at once")
+	maxQueue := fs.Int("max-queue", 64, "requests that wait for a free operation slot before further ones get 429 (gRPC: RESOURCE_EXHAUSTED)")
+	maxBlobs := fs.Int("max-upload-blobs", server.DefaultMaxBlobs, "blobs a multipart upload to /commit or /prove may carry")
+	jwtSecret := fs.String("jwt-secret", cfg.JWTSecretFile, "file holding the hex HMAC secret of the JWTs to accept as bearer tokens")
+	// Load has validated the rate settings of the config.
+	defRate, _ := strconv.ParseFloat(cmp.Or(cfg.RateLimit, "0"), 64)
+	defBurst, _ := strconv.Atoi(cmp.Or(cfg.RateBurst, "0"))
+	rateLimit := fs.Float64("rate-limit", defRate, "requests per second allowed per API key or JWT subject without a limit of its own (0 for none)")
+	rateBurst := fs.Int("rate-burst", defBurst, "requests beyond --rate-limit a key may make at once")
+	prewarm := fs.Bool("prewarm", false, "load the KZG trusted setup and run one commitment and proof before listening, instead of on the first request")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc serve [--listen addr] [--grpc-listen addr] [--metrics-listen addr] [--blob-timeout d] [--max-concurrent n] [--max-queue n] [--max-upload-blobs n] [--jwt-secret file] [--rate-limit r] [--prewarm]")
+		fmt.Fprintln(fs.Output(), "HTTP endpoints: POST /commit, /prove, /verify, /encode; GET /metrics")
+		fmt.Fprintln(fs.Output(), "gRPC service: blobpoc.v1.BlobService (see proto/blob.proto)")
+		fmt.Fprintln(fs.Output(), "API keys are read from api_keys in the config file; with keys or --jwt-secret every request needs one.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *listen == "" && *grpcListen == "" {
+		return errors.New("at least one of --listen and --grpc-listen is required")
+	}
+	if *maxConcurrent <= 0 || *maxQueue < 0 {
+		return errors.New("--max-concurrent must be positive and --max-queue not negative")
+	}
+	if *maxBlobs <= 0 {
+		return errors.New("--max-upload-blobs must be positive")
+	}
+	opts := server.Options{Timeout: *blobTimeout, Limiter: server.NewLimiter(*maxConcurrent, *maxQueue), MaxBlobs: *maxBlobs}
+	var err error
+	if opts.Auth, err = newServerAuth(*jwtSecret, *rateLimit, *rateBurst); err != nil {
+		return err
+	}
+
+	if *prewarm {
+		d, err := blob.Prewarm()
+		if err != nil {
+			return err
+		}
+		slog.Info("KZG context initialized", "duration", d.Round(time.Millisecond))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errc := make(chan error, 2)
+
+	if *metricsListen != "" {
+		stop, err := serveMetrics(*metricsListen)
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+
+	if *listen != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/", server.NewHandler(opts))
+		mux.Handle("GET /metrics", metrics.Handler())
+		srv := &http.Server{
+			Addr:              *listen,
+			Handler:           logRequests(mux),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			slog.Info("HTTP API listening", "addr", *listen)
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errc <- fmt.Errorf("http: %w", err)
+			}
+		}()
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			srv.Shutdown(shutdownCtx)
+		}()
+	}
+
+	if *grpcListen != "" {
+		lis, err := net.Listen("tcp", *grpcListen)
+		if err != nil {
+			return fmt.Errorf("grpc: %w", err)
+		}
+		srv := server.NewGRPCServer(opts)
+		go func() {
+			slog.Info("gRPC BlobService listening", "addr", lis.Addr().String())
+			if err := srv.Serve(lis); err != nil {
+				errc <- fmt.Errorf("grpc: %w", err)
+			}
+		}()
+		defer srv.GracefulStop()
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errc:
+		return err
+	}
+}
+
+// newServerAuth returns the authentication of serve: the API keys of the
+// config and the JWTs signed with the secret in jwtSecretFile, each limited
+// to rateLimit requests per second unless it has a limit of its own. It
+// returns nil, for an open API, when there are neither keys nor a secret.
+func newServerAuth(jwtSecretFile string, rateLimit float64, rateBurst int) (*server.Auth, error) {
+	if rateLimit < 0 || rateBurst < 0 {
+		return nil, errors.New("--rate-limit and --rate-burst must not be negative")
+	}
+	if len(cfg.APIKeys) == 0 && jwtSecretFile == "" {
+		if rateLimit > 0 {
+			return nil, errors.New("--rate-limit needs api_keys in the config or --jwt-secret")
+		}
+		slog.Warn("Serving without authentication; set api_keys in the config or --jwt-secret on shared infrastructure")
+		return nil, nil
+	}
+	var (
+		secret []byte
+		err    error
+	)
+	if jwtSecretFile != "" {
+		if secret, err = server.ReadJWTSecret(jwtSecretFile); err != nil {
+			return nil, err
+		}
+	}
+	keys := make([]server.Key, len(cfg.APIKeys))
+	for i, k := range cfg.APIKeys {
+		keys[i] = server.Key{Name: k.Name, Key: k.Key, Rate: cmp.Or(k.RateLimit, rateLimit), Burst: cmp.Or(k.Burst, rateBurst)}
+	}
+	a, err := server.NewAuth(keys, secret, rateLimit, rateBurst)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Authentication enabled", "api_keys", len(keys), "jwt", secret != nil, "rate_limit", rateLimit)
+	return a, nil
+}
+
+// serveMetrics serves the Prometheus /metrics endpoint on addr until the
+// returned function is called.
+func serveMetrics(addr string) (stop func(), err error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("metrics: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		slog.Info("Metrics listening", "addr", lis.Addr().String())
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics server failed", "err", err)
+		}
+	}()
+	return func() { srv.Close() }, nil
+}
+
+// logRequests logs every request handled by h at debug level.
+func logRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(sw, r)
+		slog.Debug("HTTP request", "method", r.Method, "path", r.URL.Path, "status", sw.status,
+			"duration", time.Since(start), "remote", r.RemoteAddr)
+	})
+}
+
+// statusWriter records the status code written to a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
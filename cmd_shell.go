@@ -0,0 +1,403 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// shellBlob is a blob held in memory by the shell, with the artifacts
+// computed for it so far. They are computed at most once, as a blob is
+// only ever replaced, never changed.
+type shellBlob struct {
+	blob   kzg4844.Blob
+	source string
+	// commitment and proof are nil until computed.
+	commitment *kzg4844.Commitment
+	proof      *kzg4844.Proof
+}
+
+// shell is the state of an interactive session: its blobs by name.
+type shell struct {
+	o     *output
+	blobs map[string]*shellBlob
+}
+
+// shellCommand is a command of the shell.
+type shellCommand struct {
+	name    string
+	args    string
+	summary string
+	run     func(sh *shell, fs *flag.FlagSet, args []string) error
+}
+
+var shellCommands = []shellCommand{
+	{"load", "<name> <file>", "Read a blob file into blob <name>", (*shell).load},
+	{"encode", "[--raw] [--compress c] <name> <payload>", "Encode a payload file into blob <name>, as encode does", (*shell).encode},
+	{"commit", "<name>", "Show the KZG commitment and versioned hash of a blob", (*shell).commit},
+	{"prove", "<name>", "Show the KZG commitment, proof and versioned hash of a blob", (*shell).prove},
+	{"verify", "[--commitment hex --proof hex] <name>", "Verify a commitment and proof against a blob (default: its own)", (*shell).verify},
+	{"inspect", "[--all] [--start n] [--count n] <name>", "List the field elements of a blob", (*shell).inspect},
+	{"save", "<name> <file>", "Write a blob to a file", (*shell).save},
+	{"list", "", "List the blobs in memory", (*shell).list},
+	{"drop", "<name>", "Forget a blob", (*shell).drop},
+}
+
+func runShell(args []string) error {
+	fs := flag.NewFlagSet("shell", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc shell [script]")
+		fmt.Fprintln(fs.Output(), "Runs commands on named in-memory blobs, from the prompt or a script; type help for a list.")
+		fmt.Fprintln(fs.Output(), "A script, or commands piped in, stops at the first failing command.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	in := io.Reader(os.Stdin)
+	interactive := isTerminal(os.Stdin)
+	if fs.NArg() > 0 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in, interactive = f, false
+	}
+
+	sh := &shell{o: new(output), blobs: make(map[string]*shellBlob)}
+	sc := bufio.NewScanner(in)
+	for line := 1; ; line++ {
+		if interactive {
+			fmt.Fprint(os.Stderr, "blob-poc> ")
+		}
+		if !sc.Scan() {
+			break
+		}
+		words, err := shellWords(sc.Text())
+		if err == nil {
+			err = sh.exec(words)
+		}
+		if errors.Is(err, errShellExit) {
+			return nil
+		}
+		if err != nil {
+			if !interactive {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+	}
+	if interactive {
+		fmt.Fprintln(os.Stderr)
+	}
+	return sc.Err()
+}
+
+// errShellExit ends the session.
+var errShellExit = errors.New("exit")
+
+// exec runs one command line, split into words.
+func (sh *shell) exec(words []string) error {
+	if len(words) == 0 || strings.HasPrefix(words[0], "#") {
+		return nil
+	}
+	switch words[0] {
+	case "exit", "quit":
+		return errShellExit
+	case "help", "?":
+		sh.help()
+		return nil
+	}
+	i := slices.IndexFunc(shellCommands, func(c shellCommand) bool { return c.name == words[0] })
+	if i < 0 {
+		return fmt.Errorf("unknown command %q; type help for a list", words[0])
+	}
+	c := shellCommands[i]
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s\n", c.name, c.args)
+		fs.PrintDefaults()
+	}
+	return c.run(sh, fs, words[1:])
+}
+
+func (sh *shell) help() {
+	width := 0
+	for _, c := range shellCommands {
+		width = max(width, len(c.name+" "+c.args))
+	}
+	for _, c := range shellCommands {
+		sh.o.Printf("  %-*s  %s\n", width, strings.TrimSpace(c.name+" "+c.args), c.summary)
+	}
+	sh.o.Printf("  %-*s  %s\n", width, "help", "Show this list")
+	sh.o.Printf("  %-*s  %s\n", width, "exit", "Leave the shell")
+}
+
+// parseArgs parses the flags of a shell command and checks that n
+// positional arguments follow them.
+func parseArgs(fs *flag.FlagSet, args []string, n int) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != n {
+		fs.Usage()
+		return fmt.Errorf("%s takes %d arguments, got %d", fs.Name(), n, fs.NArg())
+	}
+	return nil
+}
+
+// get returns the blob called name.
+func (sh *shell) get(name string) (*shellBlob, error) {
+	b, ok := sh.blobs[name]
+	if !ok {
+		return nil, fmt.Errorf("no blob %q; load or encode one first", name)
+	}
+	return b, nil
+}
+
+func (sh *shell) load(fs *flag.FlagSet, args []string) error {
+	if err := parseArgs(fs, args, 2); err != nil {
+		return err
+	}
+	name, path := fs.Arg(0), fs.Arg(1)
+	b, err := readBlobFile(path)
+	if err != nil {
+		return err
+	}
+	sh.blobs[name] = &shellBlob{blob: b, source: path}
+	sh.o.Printf("%s: loaded %s\n", name, path)
+	return nil
+}
+
+func (sh *shell) encode(fs *flag.FlagSet, args []string) error {
+	raw := fs.Bool("raw", false, "pack the payload without a frame header")
+	compress := fs.String("compress", "none", "compress the payload first: none, zlib, brotli or zstd")
+	if err := parseArgs(fs, args, 2); err != nil {
+		return err
+	}
+	name, path := fs.Arg(0), fs.Arg(1)
+	c, err := blob.ParseCompression(*compress)
+	if err != nil {
+		return err
+	}
+	if *raw && c != blob.CompressionNone {
+		return errors.New("--compress needs a frame header and cannot be combined with --raw")
+	}
+	payload, err := readInputFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read payload: %w", err)
+	}
+	var b kzg4844.Blob
+	if *raw {
+		b, err = blob.Pack(payload)
+	} else {
+		b, err = blob.EncodeFramedCompressed(payload, c)
+	}
+	if err != nil {
+		return err
+	}
+	sh.blobs[name] = &shellBlob{blob: b, source: path}
+	sh.o.Printf("%s: encoded %d bytes of %s\n", name, len(payload), path)
+	return nil
+}
+
+// artifacts returns the commitment of b and, if withProof, its proof,
+// computing what it does not have yet.
+func (b *shellBlob) artifacts(withProof bool) (kzg4844.Commitment, kzg4844.Proof, error) {
+	if b.commitment == nil {
+		commitment, err := blob.Commit(&b.blob)
+		if err != nil {
+			return kzg4844.Commitment{}, kzg4844.Proof{}, fmt.Errorf("failed to generate KZG commitment: %w", err)
+		}
+		b.commitment = &commitment
+	}
+	if withProof && b.proof == nil {
+		proof, err := blob.Prove(&b.blob, *b.commitment)
+		if err != nil {
+			return kzg4844.Commitment{}, kzg4844.Proof{}, fmt.Errorf("failed to generate KZG proof: %w", err)
+		}
+		b.proof = &proof
+	}
+	var proof kzg4844.Proof
+	if b.proof != nil {
+		proof = *b.proof
+	}
+	return *b.commitment, proof, nil
+}
+
+func (sh *shell) commit(fs *flag.FlagSet, args []string) error {
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	b, err := sh.get(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	commitment, _, err := b.artifacts(false)
+	if err != nil {
+		return err
+	}
+	sh.o.Printf("KZG Commitment: %x\nVersioned Hash: %s\n", commitment[:], blob.VersionedHash(commitment))
+	return nil
+}
+
+func (sh *shell) prove(fs *flag.FlagSet, args []string) error {
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	b, err := sh.get(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	commitment, proof, err := b.artifacts(true)
+	if err != nil {
+		return err
+	}
+	sh.o.Printf("KZG Commitment: %x\nKZG Proof: %x\nVersioned Hash: %s\n", commitment[:], proof[:], blob.VersionedHash(commitment))
+	return nil
+}
+
+func (sh *shell) verify(fs *flag.FlagSet, args []string) error {
+	commitmentHex := fs.String("commitment", "", "hex-encoded 48-byte KZG commitment (default: the blob's own)")
+	proofHex := fs.String("proof", "", "hex-encoded 48-byte KZG proof (default: the blob's own)")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	b, err := sh.get(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	commitment, proof, err := b.artifacts(*commitmentHex == "" || *proofHex == "")
+	if err != nil {
+		return err
+	}
+	if *commitmentHex != "" {
+		data, err := parseHexFixed("commitment", *commitmentHex, len(commitment))
+		if err != nil {
+			return err
+		}
+		commitment = kzg4844.Commitment(data)
+	}
+	if *proofHex != "" {
+		data, err := parseHexFixed("proof", *proofHex, len(proof))
+		if err != nil {
+			return err
+		}
+		proof = kzg4844.Proof(data)
+	}
+	if err := blob.Verify(&b.blob, commitment, proof); err != nil {
+		return err
+	}
+	sh.o.Println("✅ KZG proof verification successful!")
+	return nil
+}
+
+func (sh *shell) inspect(fs *flag.FlagSet, args []string) error {
+	all := fs.Bool("all", false, "list the zero padding too, instead of summarizing it")
+	start := fs.Int("start", 0, "first field element to list")
+	count := fs.Int("count", 0, "number of field elements to list (default: up to the padding, or all with --all)")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	if *start < 0 || *start >= blob.FieldElementsPerBlob || *count < 0 {
+		return fmt.Errorf("--start must be below %d and --count not negative", blob.FieldElementsPerBlob)
+	}
+	b, err := sh.get(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	printInspection(sh.o, fs.Arg(0), &b.blob, *all, *start, *count)
+	return nil
+}
+
+func (sh *shell) save(fs *flag.FlagSet, args []string) error {
+	if err := parseArgs(fs, args, 2); err != nil {
+		return err
+	}
+	b, err := sh.get(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := writeOutput(fs.Arg(1), encodeOutput(b.blob[:])); err != nil {
+		return err
+	}
+	sh.o.Printf("%s: wrote %s\n", fs.Arg(0), fs.Arg(1))
+	return nil
+}
+
+func (sh *shell) list(fs *flag.FlagSet, args []string) error {
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(sh.blobs))
+	for name := range sh.blobs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		b := sh.blobs[name]
+		state := "no artifacts yet"
+		switch {
+		case b.proof != nil:
+			state = "committed and proven"
+		case b.commitment != nil:
+			state = "committed"
+		}
+		sh.o.Printf("%s\t%s\t%s\n", name, b.source, state)
+	}
+	return nil
+}
+
+func (sh *shell) drop(fs *flag.FlagSet, args []string) error {
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	if _, err := sh.get(fs.Arg(0)); err != nil {
+		return err
+	}
+	delete(sh.blobs, fs.Arg(0))
+	return nil
+}
+
+// shellWords splits a command line into words at spaces, except within
+// double quotes.
+func shellWords(line string) ([]string, error) {
+	var (
+		words  []string
+		word   strings.Builder
+		inWord bool
+		quoted bool
+	)
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted, inWord = !quoted, true
+		case !quoted && (r == ' ' || r == '\t'):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/beacon"
+	"kzg-blob-poc/pkg/blob"
+)
+
+func runSidecar(args []string) error {
+	fs := flag.NewFlagSet("sidecar", flag.ExitOnError)
+	in := fs.String("in", "", "input blob file (hex text or raw binary)")
+	out := fs.String("out", "", "output SSZ sidecar file")
+	index := fs.Uint64("index", 0, "index of the blob within its block")
+	blockFile := fs.String("block", "", "beacon block JSON (as served by /eth/v2/beacon/blocks) to take the header and inclusion proof from")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc sidecar --out <file.ssz> [flags] <blob>")
+		fmt.Fprintln(fs.Output(), "Without --block the block header and inclusion proof are left zeroed.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("--out is required")
+	}
+	b, err := readBlobFile(path)
+	if err != nil {
+		return err
+	}
+	artifacts, err := blob.NewArtifacts(&b, false)
+	if err != nil {
+		return err
+	}
+
+	sc := &beacon.BlobSidecar{
+		Index:         *index,
+		Blob:          b,
+		KZGCommitment: artifacts.Commitment,
+		KZGProof:      artifacts.Proof,
+	}
+	if *blockFile != "" {
+		block, err := readBlockFile(*blockFile)
+		if err != nil {
+			return err
+		}
+		if sc, err = beacon.NewBlobSidecar(block, int(*index), &b, artifacts.Commitment, artifacts.Proof); err != nil {
+			return err
+		}
+		o.Printf("Block body root: %x\n", sc.SignedBlockHeader.Message.BodyRoot[:])
+	}
+	if err := writeBinary(*out, sc.MarshalSSZ()); err != nil {
+		return err
+	}
+	o.Printf("Wrote %d-byte BlobSidecar to %s\n", beacon.BlobSidecarSSZSize, *out)
+	return o.emit(newSidecarInfo(sc))
+}
+
+func runSidecarRead(args []string) error {
+	fs := flag.NewFlagSet("sidecar-read", flag.ExitOnError)
+	in := fs.String("in", "", "input SSZ sidecar file")
+	requireInclusion := fs.Bool("require-inclusion", false, "fail if the commitment inclusion proof does not match the header's body root")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc sidecar-read [flags] <file.ssz>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	data, err := readInputFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read sidecar: %w", err)
+	}
+	sc := new(beacon.BlobSidecar)
+	if err := sc.UnmarshalSSZ(data); err != nil {
+		return err
+	}
+
+	info := newSidecarInfo(sc)
+	verifyErr := blob.Verify(&sc.Blob, sc.KZGCommitment, sc.KZGProof)
+	info.Valid = verifyErr == nil
+	inclusionErr := sc.VerifyInclusionProof()
+
+	header := sc.SignedBlockHeader.Message
+	o.Printf("Index: %d\n", info.Index)
+	o.Printf("Slot: %d (proposer %d)\n", header.Slot, header.ProposerIndex)
+	o.Printf("KZG Commitment: %x\n", info.Commitment[:])
+	o.Printf("KZG Proof: %x\n", info.Proof[:])
+	o.Printf("Versioned Hash: %x\n", info.VersionedHash[:])
+	if err := o.emit(info); err != nil {
+		return err
+	}
+	if verifyErr != nil {
+		return verifyErr
+	}
+	o.Println("✅ Proof verification successful!")
+	if inclusionErr != nil {
+		if *requireInclusion {
+			return inclusionErr
+		}
+		o.Println("⚠️  Commitment inclusion proof does not match the block header")
+	} else {
+		o.Println("✅ Commitment inclusion proof verified against block body root")
+	}
+	return nil
+}
+
+// readBlockFile reads a signed beacon block, either bare or wrapped in the
+// beacon API's {"version": ..., "data": ...} response envelope. A bare
+// block has no version, so its layout tells the fork: Electra added
+// execution_requests to the body, which Fulu kept.
+func readBlockFile(path string) (*beacon.SignedBeaconBlock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read block file: %w", err)
+	}
+	var envelope struct {
+		Version string          `json:"version"`
+		Data    json.RawMessage `json:"data"`
+	}
+	version := ""
+	if err := json.Unmarshal(data, &envelope); err == nil && envelope.Data != nil {
+		data, version = envelope.Data, envelope.Version
+	}
+	if version == "" {
+		var layout struct {
+			Message struct {
+				Body struct {
+					ExecutionRequests json.RawMessage `json:"execution_requests"`
+				} `json:"body"`
+			} `json:"message"`
+		}
+		if err := json.Unmarshal(data, &layout); err != nil {
+			return nil, fmt.Errorf("failed to parse block file: %w", err)
+		}
+		version = beacon.ForkDeneb
+		if layout.Message.Body.ExecutionRequests != nil {
+			version = beacon.ForkElectra
+		}
+	}
+	block, err := beacon.DecodeSignedBeaconBlock(version, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse block file: %w", err)
+	}
+	return block, nil
+}
+
+// sidecarInfo is the JSON summary of a BlobSidecar.
+type sidecarInfo struct {
+	Index          uint64             `json:"index"`
+	Slot           uint64             `json:"slot"`
+	ProposerIndex  uint64             `json:"proposer_index"`
+	Commitment     kzg4844.Commitment `json:"commitment"`
+	Proof          kzg4844.Proof      `json:"proof"`
+	VersionedHash  common.Hash        `json:"versioned_hash"`
+	Valid          bool               `json:"valid"`
+	InclusionValid bool               `json:"inclusion_valid"`
+}
+
+func newSidecarInfo(sc *beacon.BlobSidecar) *sidecarInfo {
+	return &sidecarInfo{
+		Index:          sc.Index,
+		Slot:           sc.SignedBlockHeader.Message.Slot,
+		ProposerIndex:  sc.SignedBlockHeader.Message.ProposerIndex,
+		Commitment:     sc.KZGCommitment,
+		Proof:          sc.KZGProof,
+		VersionedHash:  blob.VersionedHash(sc.KZGCommitment),
+		Valid:          true,
+		InclusionValid: sc.VerifyInclusionProof() == nil,
+	}
+}
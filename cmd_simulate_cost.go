@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"time"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/fee"
+)
+
+// costSimulation is the JSON output of the simulate-cost command.
+type costSimulation struct {
+	*fee.CostStats
+	PayloadSize  int    `json:"payload_size"`
+	Compression  string `json:"compression,omitempty"`
+	OriginalSize int    `json:"original_size,omitempty"`
+}
+
+func runSimulateCost(args []string) error {
+	fs := flag.NewFlagSet("simulate-cost", flag.ExitOnError)
+	in := fs.String("in", "", "payload file")
+	rpcURL := fs.String("rpc-url", cfg.RPCURL, "execution client JSON-RPC endpoint")
+	blocks := fs.Uint64("blocks", 7200, "number of recent blocks to replay")
+	compress := fs.String("compress", "none", "price the payload after compression: none, zlib, brotli or zstd")
+	timeout := fs.Duration("timeout", time.Minute, "RPC timeout")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc simulate-cost --rpc-url <url> [--blocks n] [flags] <payload>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *rpcURL == "" {
+		return errors.New("--rpc-url is required")
+	}
+	if *blocks == 0 {
+		return errors.New("--blocks must be positive")
+	}
+	c, err := blob.ParseCompression(*compress)
+	if err != nil {
+		return err
+	}
+	payload, err := readInputFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read payload: %w", err)
+	}
+
+	var s costSimulation
+	if c != blob.CompressionNone {
+		compressed, err := blob.Compress(c, payload)
+		if err != nil {
+			return err
+		}
+		s.Compression, s.OriginalSize = c.String(), len(payload)
+		payload = compressed
+	}
+	s.PayloadSize = len(payload)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	client, err := dialRPC(ctx, *rpcURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	history, err := fee.FetchHistory(ctx, client, *blocks)
+	if err != nil {
+		return err
+	}
+	if s.CostStats, err = fee.SimulateCost(history, blob.Analyze(len(payload)).Blobs); err != nil {
+		return err
+	}
+
+	if s.Compression != "" {
+		o.Printf("Original Size: %d bytes\n", s.OriginalSize)
+		o.Printf("Compressed Size (%s): %d bytes\n", s.Compression, s.PayloadSize)
+	} else {
+		o.Printf("Payload Size: %d bytes\n", s.PayloadSize)
+	}
+	o.Printf("Blobs: %d (blob gas %d)\n", s.Blobs, s.BlobGas)
+	o.Printf("Blocks: %d to %d (%d blocks)\n", s.FromBlock, s.ToBlock, s.ToBlock-s.FromBlock+1)
+	for _, row := range []struct {
+		name string
+		wei  *big.Int
+	}{
+		{"Min", s.Min},
+		{"Median", s.Median},
+		{"P95", s.P95},
+		{"Max", s.Max},
+		{"Mean", s.Mean},
+	} {
+		o.Printf("%-7s %s wei (%s gwei)\n", row.name+":", row.wei, formatGwei(row.wei))
+	}
+	return o.emit(s)
+}
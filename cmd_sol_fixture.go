@@ -0,0 +1,220 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/calldata"
+)
+
+// solIdentifier matches a valid Solidity library name.
+var solIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// fixtureBlob is what a Solidity fixture holds for one blob.
+type fixtureBlob struct {
+	File            string             `json:"file"`
+	VersionedHash   common.Hash        `json:"versioned_hash"`
+	Commitment      kzg4844.Commitment `json:"commitment"`
+	Z               common.Hash        `json:"z"`
+	Y               common.Hash        `json:"y"`
+	Proof           kzg4844.Proof      `json:"proof"`
+	PrecompileInput hexutil.Bytes      `json:"precompile_input"`
+	Calldata        hexutil.Bytes      `json:"calldata,omitempty"`
+}
+
+// solFixture is the JSON output of sol-fixture.
+type solFixture struct {
+	Name             string        `json:"name"`
+	Blobs            []fixtureBlob `json:"blobs"`
+	PrecompileOutput hexutil.Bytes `json:"precompile_output"`
+	Call             string        `json:"call,omitempty"`
+}
+
+func runSolFixture(args []string) error {
+	fs := flag.NewFlagSet("sol-fixture", flag.ExitOnError)
+	name := fs.String("name", "BlobFixture", "name of the generated Solidity library")
+	pragma := fs.String("pragma", "^0.8.24", "Solidity version pragma; blobhash needs 0.8.24 or later")
+	zHex := fs.String("z", "", "evaluation point of every blob, as a big-endian field element in hex (default: keccak256 of each versioned hash, reduced into the field)")
+	out := fs.String("out", "", "write the Solidity source to this file instead of stdout")
+	callf := addCallFlags(fs)
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc sol-fixture [--name Name] [--z hex] [--call sig --call-arg arg...] [--out file.sol] <blob>...")
+		fmt.Fprintln(fs.Output(), "The blobs are taken as those of one transaction, in order. Besides the --call-arg placeholders")
+		fmt.Fprintln(fs.Output(), "of send, @index, @z, @y, @commitment, @proof and @input stand for each blob's own values.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return errors.New("at least one blob file is required")
+	}
+	if !solIdentifier.MatchString(*name) {
+		return fmt.Errorf("invalid --name %q: not a Solidity identifier", *name)
+	}
+	if *callf.signature == "" && len(callf.args) > 0 {
+		return errors.New("--call-arg requires --call")
+	}
+	var fixedZ *kzg4844.Point
+	if *zHex != "" {
+		z, err := parseHex32("z", *zHex)
+		if err != nil {
+			return err
+		}
+		if new(big.Int).SetBytes(z[:]).Cmp(blob.BLSModulus.Big()) >= 0 {
+			return errors.New("invalid z: not below the BLS12-381 scalar field modulus")
+		}
+		fixedZ = (*kzg4844.Point)(&z)
+	}
+
+	f := solFixture{Name: *name, PrecompileOutput: blob.PointEvaluationOutput(), Blobs: make([]fixtureBlob, fs.NArg())}
+	hashes := make([]common.Hash, fs.NArg())
+	for i, path := range fs.Args() {
+		b, err := readBlobFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		commitment, err := blob.Commit(&b)
+		if err != nil {
+			return fmt.Errorf("%s: failed to generate KZG commitment: %w", path, err)
+		}
+		vh := blob.VersionedHash(commitment)
+		z := blob.VersionedHashChallenge(vh)
+		if fixedZ != nil {
+			z = *fixedZ
+		}
+		proof, y, err := blob.ProveAt(&b, z)
+		if err != nil {
+			return fmt.Errorf("%s: failed to generate KZG proof: %w", path, err)
+		}
+		hashes[i] = vh
+		f.Blobs[i] = fixtureBlob{
+			File:            filepath.Base(path),
+			VersionedHash:   vh,
+			Commitment:      commitment,
+			Z:               common.Hash(z),
+			Y:               common.Hash(y),
+			Proof:           proof,
+			PrecompileInput: blob.PointEvaluationInput(commitment, z, y, proof),
+		}
+	}
+
+	if *callf.signature != "" {
+		for i := range f.Blobs {
+			call, err := parseFixtureCall(*callf.signature, callf.args, i, &f.Blobs[i])
+			if err != nil {
+				return err
+			}
+			if f.Blobs[i].Calldata, err = call.Encode(hashes); err != nil {
+				return err
+			}
+			f.Call = call.Signature()
+		}
+	}
+	return o.report(*out, f.solidity(*pragma, fs.Args()), f)
+}
+
+// parseFixtureCall parses --call for blob i of a fixture, whose own values
+// replace the fixture placeholders among args.
+func parseFixtureCall(signature string, args []string, i int, b *fixtureBlob) (*calldata.Call, error) {
+	vars := map[string]string{
+		"@index":      fmt.Sprint(i),
+		"@z":          b.Z.Hex(),
+		"@y":          b.Y.Hex(),
+		"@commitment": hexutil.Encode(b.Commitment[:]),
+		"@proof":      hexutil.Encode(b.Proof[:]),
+		"@input":      hexutil.Encode(b.PrecompileInput),
+	}
+	var bind func(v any) any
+	bind = func(v any) any {
+		switch v := v.(type) {
+		case string:
+			if s, ok := vars[v]; ok {
+				return s
+			}
+		case []any:
+			for j := range v {
+				v[j] = bind(v[j])
+			}
+		}
+		return v
+	}
+	values := make([]any, len(args))
+	for j, arg := range args {
+		v, err := calldata.ParseArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		values[j] = bind(v)
+	}
+	return calldata.Parse(signature, values)
+}
+
+// solidity returns the fixture as the source of a Solidity library.
+func (f *solFixture) solidity(pragma string, files []string) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "// SPDX-License-Identifier: MIT\n")
+	fmt.Fprintf(&s, "// Generated by blob-poc sol-fixture from %s. Do not edit.\n", strings.Join(files, ", "))
+	fmt.Fprintf(&s, "pragma solidity %s;\n\n", pragma)
+	fmt.Fprintf(&s, "/// @notice KZG test vectors of %d blobs, taken as those of one transaction in\n", len(f.Blobs))
+	fmt.Fprintf(&s, "/// order: blobhash(i) returns versionedHash(i), e.g. after\n")
+	fmt.Fprintf(&s, "/// vm.blobhashes(%s.versionedHashes()) in Foundry.\n", f.Name)
+	fmt.Fprintf(&s, "library %s {\n", f.Name)
+	fmt.Fprintf(&s, "    uint256 internal constant BLOB_COUNT = %d;\n", len(f.Blobs))
+	fmt.Fprintf(&s, "    address internal constant POINT_EVALUATION = address(0x0a);\n")
+	fmt.Fprintf(&s, "    uint256 internal constant FIELD_ELEMENTS_PER_BLOB = %d;\n", blob.FieldElementsPerBlob)
+	fmt.Fprintf(&s, "    uint256 internal constant BLS_MODULUS = %s;\n", blob.BLSModulus.Hex())
+	fmt.Fprintf(&s, "\n    /// @notice What the point evaluation precompile returns for a valid input.\n")
+	fmt.Fprintf(&s, "    bytes internal constant PRECOMPILE_OUTPUT = hex\"%x\";\n", []byte(f.PrecompileOutput))
+
+	values := func(get func(b *fixtureBlob) string) []string {
+		v := make([]string, len(f.Blobs))
+		for i := range f.Blobs {
+			v[i] = get(&f.Blobs[i])
+		}
+		return v
+	}
+	bytes32 := func(h common.Hash) string { return h.Hex() }
+	hexBytes := func(b []byte) string { return fmt.Sprintf("hex\"%x\"", b) }
+
+	solGetter(&s, "versionedHash", "bytes32", "The versioned hash of blob i, as blobhash(i) returns it.", values(func(b *fixtureBlob) string { return bytes32(b.VersionedHash) }))
+	solGetter(&s, "commitment", "bytes memory", "The 48-byte KZG commitment of blob i.", values(func(b *fixtureBlob) string { return hexBytes(b.Commitment[:]) }))
+	solGetter(&s, "z", "bytes32", "The evaluation point of blob i.", values(func(b *fixtureBlob) string { return bytes32(b.Z) }))
+	solGetter(&s, "y", "bytes32", "The evaluation of blob i's polynomial at z(i).", values(func(b *fixtureBlob) string { return bytes32(b.Y) }))
+	solGetter(&s, "proof", "bytes memory", "The 48-byte KZG proof that blob i evaluates to y(i) at z(i).", values(func(b *fixtureBlob) string { return hexBytes(b.Proof[:]) }))
+	solGetter(&s, "precompileInput", "bytes memory", "The 192-byte point evaluation precompile input of blob i.", values(func(b *fixtureBlob) string { return hexBytes(b.PrecompileInput) }))
+	if f.Call != "" {
+		solGetter(&s, "callData", "bytes memory", "The calldata of "+f.Call+" for blob i.", values(func(b *fixtureBlob) string { return hexBytes(b.Calldata) }))
+	}
+
+	fmt.Fprintf(&s, "\n    /// @notice The versioned hashes of all blobs, in order.\n")
+	fmt.Fprintf(&s, "    function versionedHashes() internal pure returns (bytes32[] memory hashes) {\n")
+	fmt.Fprintf(&s, "        hashes = new bytes32[](BLOB_COUNT);\n")
+	fmt.Fprintf(&s, "        for (uint256 i = 0; i < BLOB_COUNT; i++) {\n")
+	fmt.Fprintf(&s, "            hashes[i] = versionedHash(i);\n")
+	fmt.Fprintf(&s, "        }\n")
+	fmt.Fprintf(&s, "    }\n")
+	fmt.Fprintf(&s, "}\n")
+	return s.String()
+}
+
+// solGetter writes a library function returning the i-th of values.
+func solGetter(s *strings.Builder, name, typ, doc string, values []string) {
+	fmt.Fprintf(s, "\n    /// @notice %s\n", doc)
+	fmt.Fprintf(s, "    function %s(uint256 i) internal pure returns (%s) {\n", name, typ)
+	for i, v := range values {
+		fmt.Fprintf(s, "        if (i == %d) return %s;\n", i, v)
+	}
+	fmt.Fprintf(s, "        revert(\"no such blob\");\n")
+	fmt.Fprintf(s, "    }\n")
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"kzg-blob-poc/pkg/spectest"
+)
+
+// specTestSummary is the JSON output of the spec-test command.
+type specTestSummary struct {
+	Passed  int               `json:"passed"`
+	Failed  int               `json:"failed"`
+	Skipped int               `json:"skipped"`
+	Results []spectest.Result `json:"results"`
+}
+
+func runSpecTest(args []string) error {
+	fs := flag.NewFlagSet("spec-test", flag.ExitOnError)
+	run := fs.String("run", "", "only run cases whose handler/name matches this regular expression")
+	verbose := fs.Bool("v", false, "also list passing and skipped cases")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc spec-test [flags] <dir>...")
+		fmt.Fprintln(fs.Output(), "Runs the KZG reference test vectors (data.yaml files) under each directory.")
+		fmt.Fprintf(fs.Output(), "Handlers: %s\n", strings.Join(spectest.Handlers(), ", "))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("at least one test vector directory is required")
+	}
+	var filter *regexp.Regexp
+	if *run != "" {
+		var err error
+		if filter, err = regexp.Compile(*run); err != nil {
+			return fmt.Errorf("invalid --run: %w", err)
+		}
+	}
+
+	var cases []spectest.Case
+	for _, dir := range fs.Args() {
+		found, err := spectest.Discover(dir)
+		if err != nil {
+			return err
+		}
+		cases = append(cases, found...)
+	}
+
+	var summary specTestSummary
+	for _, c := range cases {
+		id := c.Handler + "/" + c.Name
+		if filter != nil && !filter.MatchString(id) {
+			continue
+		}
+		r := spectest.Run(c)
+		summary.Results = append(summary.Results, r)
+		switch r.Status {
+		case spectest.StatusPass:
+			summary.Passed++
+			if *verbose {
+				o.Printf("ok    %s\n", id)
+			}
+		case spectest.StatusSkip:
+			summary.Skipped++
+			if *verbose {
+				o.Printf("skip  %s\n", id)
+			}
+		default:
+			summary.Failed++
+			o.Printf("FAIL  %s: %s\n", id, r.Error)
+		}
+	}
+	if len(summary.Results) == 0 {
+		return fmt.Errorf("no test cases found")
+	}
+
+	o.Printf("%d passed, %d failed, %d skipped\n", summary.Passed, summary.Failed, summary.Skipped)
+	if err := o.emit(summary); err != nil {
+		return err
+	}
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d spec test cases failed", summary.Failed, summary.Passed+summary.Failed)
+	}
+	return nil
+}
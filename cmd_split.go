@@ -0,0 +1,430 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/batch"
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/codec"
+	"kzg-blob-poc/pkg/db"
+	"kzg-blob-poc/pkg/manifest"
+	"kzg-blob-poc/pkg/mmap"
+)
+
+// chunksFileName is the metadata file written next to the blobs by split.
+const chunksFileName = "chunks.json"
+
+// chunkFile is the on-disk description of a payload split across blobs.
+type chunkFile struct {
+	PayloadSize int          `json:"payload_size"`
+	PayloadHash common.Hash  `json:"payload_hash"` // SHA-256
+	Chunks      []chunkEntry `json:"chunks"`
+}
+
+// chunkEntry describes one blob of a split payload and its KZG artifacts.
+type chunkEntry struct {
+	blob.Chunk
+	File          string             `json:"file"`
+	Commitment    kzg4844.Commitment `json:"commitment"`
+	Proof         kzg4844.Proof      `json:"proof,omitzero"`
+	VersionedHash common.Hash        `json:"versioned_hash"`
+	ChunkHash     common.Hash        `json:"chunk_sha256"` // SHA-256 of the chunk's bytes
+}
+
+func runSplit(args []string) error {
+	fs := flag.NewFlagSet("split", flag.ExitOnError)
+	in := fs.String("in", "", "raw payload file, or - for stdin")
+	outDir := fs.String("out-dir", cfg.OutputDir, "directory for the blob files, "+chunksFileName+" and "+manifest.FileName)
+	workers := fs.Int("workers", runtime.NumCPU(), "number of blobs to commit to and prove concurrently")
+	useMmap := addMmapFlag(fs)
+	cacheDir := addCacheFlag(fs)
+	noProof := addNoProofFlag(fs)
+	codecName := addCodecFlag(fs)
+	pf := addProgressFlags(fs)
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc split --out-dir <dir> [flags] <payload>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *outDir == "" {
+		return errors.New("--out-dir is required")
+	}
+	cdc, err := openCodec(*codecName)
+	if err != nil {
+		return err
+	}
+	p, err := openPayload(path, *useMmap)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		return err
+	}
+
+	c, err := openCache(*cacheDir)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pipeline := batch.NewPipeline(*workers)
+	pipeline.Cache = c
+	pipeline.NoProof = *noProof
+	pipeline.Progress = pf.start("split", p.blobs())
+
+	meta, err := encodePayload(ctx, p, *outDir, pipeline, cdc)
+	pipeline.Progress.Finish()
+	if err != nil {
+		return err
+	}
+	recordBlobs(ctx, chunkRecords("split", *outDir, meta)...)
+	for _, entry := range meta.Chunks {
+		o.Printf("Blob %d (%d bytes at offset %d): %s\n", entry.Index, entry.Size, entry.Offset, entry.File)
+		o.Printf("  KZG Commitment: %x\n", entry.Commitment[:])
+		if !*noProof {
+			o.Printf("  KZG Proof: %x\n", entry.Proof[:])
+		}
+		o.Printf("  Versioned Hash: %x\n", entry.VersionedHash[:])
+	}
+	return o.emit(meta)
+}
+
+// payload is the input of split, publish and watch: a file or stdin read
+// as a stream, or a memory-mapped file.
+type payload struct {
+	stream *blob.BlobStream
+	reader io.Reader // what stream reads, unless mapped
+	mapped *mmap.File
+	closer io.Closer
+	size   int64 // -1 when not known in advance
+}
+
+// addMmapFlag registers --mmap on a command that splits a payload.
+func addMmapFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("mmap", false, "memory-map the payload file and pack blobs straight from the mapping, releasing its pages as they are packed")
+}
+
+// openPayload opens the payload at path, or stdin for -. With useMmap the
+// file is memory-mapped instead of read, which needs a regular file.
+func openPayload(path string, useMmap bool) (*payload, error) {
+	if useMmap {
+		if path == "-" {
+			return nil, errors.New("--mmap needs a payload file, not stdin")
+		}
+		if inputFormat != "" && inputFormat != formatRaw {
+			return nil, fmt.Errorf("--mmap needs a raw payload, not %s", inputFormat)
+		}
+		m, err := mmap.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payload: %w", err)
+		}
+		return &payload{stream: blob.NewBlobStreamFromBytes(m.Bytes()), mapped: m, closer: m, size: int64(m.Len())}, nil
+	}
+	p := new(payload)
+	f := os.Stdin
+	if path != "-" {
+		var err error
+		if f, err = os.Open(path); err != nil {
+			return nil, fmt.Errorf("failed to read payload: %w", err)
+		}
+		p.closer = f
+	}
+	p.reader = inputReader(f)
+	p.stream, p.size = blob.NewBlobStream(p.reader), fileSize(f)
+	if inputFormat != "" && inputFormat != formatRaw {
+		// The file holds text, so its size is not the payload's.
+		p.size = -1
+	}
+	return p, nil
+}
+
+// fileSize returns the size of f if it is a regular file, else -1.
+func fileSize(f *os.File) int64 {
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return -1
+	}
+	return info.Size()
+}
+
+// blobs returns how many blobs the payload fills, or 0 when its size is not
+// known in advance.
+func (p *payload) blobs() int64 {
+	if p.size < 0 {
+		return 0
+	}
+	return int64(len(blob.ChunkLayout(int(p.size))))
+}
+
+// readAll returns the whole payload, for a codec that encodes it at once.
+// The stream is then exhausted.
+func (p *payload) readAll() ([]byte, error) {
+	if p.mapped != nil {
+		return p.mapped.Bytes(), nil
+	}
+	data, err := io.ReadAll(p.reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payload: %w", err)
+	}
+	return data, nil
+}
+
+// hash returns the size and SHA-256 of the whole payload, reading it to the
+// end.
+func (p *payload) hash() (int, common.Hash, error) {
+	if p.mapped != nil {
+		return len(p.mapped.Bytes()), sha256.Sum256(p.mapped.Bytes()), nil
+	}
+	h := sha256.New()
+	n, err := io.Copy(h, p.reader)
+	if err != nil {
+		return 0, common.Hash{}, fmt.Errorf("failed to read payload: %w", err)
+	}
+	return int(n), common.BytesToHash(h.Sum(nil)), nil
+}
+
+// Close closes the payload file or unmaps it. Stdin is left open.
+func (p *payload) Close() error {
+	if p.closer == nil {
+		return nil
+	}
+	return p.closer.Close()
+}
+
+// splitPayload packs p into blobs, writes them to outDir with their
+// artifacts in chunks.json, and returns that metadata. outDir must exist.
+// It stops reading p once ctx is done.
+func splitPayload(ctx context.Context, p *payload, outDir string, pipeline *batch.Pipeline) (chunkFile, error) {
+	// The stream is read on the pipeline's producer goroutine, which records
+	// each chunk for the collector and leaves writing the blob to a worker.
+	hasher := sha256.New()
+	var (
+		stream = p.stream
+		mu     sync.Mutex
+		chunks []chunkEntry
+	)
+	jobs := func(yield func(batch.Job) bool) {
+		for stream.Next() {
+			// The stream reuses its blob, so the job gets a pooled copy.
+			b, c := blob.GetBlob(), stream.Chunk()
+			*b = *stream.Blob()
+			hasher.Write(stream.Payload())
+			if p.mapped != nil {
+				p.mapped.ReleaseTo(int(stream.BytesRead()))
+			}
+			mu.Lock()
+			chunks = append(chunks, chunkEntry{Chunk: c, ChunkHash: sha256.Sum256(stream.Payload())})
+			mu.Unlock()
+
+			name := fmt.Sprintf("blob-%04d.bin", c.Index)
+			job := batch.Job{
+				Name: name,
+				Load: func(dst *kzg4844.Blob) error {
+					*dst = *b
+					blob.PutBlob(b)
+					return os.WriteFile(filepath.Join(outDir, name), encodeOutput(dst[:]), 0o644)
+				},
+			}
+			if !yield(job) {
+				return
+			}
+		}
+	}
+
+	var meta chunkFile
+	err := pipeline.Run(ctx, jobs, func(r batch.Result) error {
+		mu.Lock()
+		entry := chunks[len(meta.Chunks)]
+		mu.Unlock()
+		entry.File = r.Name
+		entry.Commitment, entry.Proof, entry.VersionedHash = r.Commitment, r.Proof, r.VersionedHash
+		meta.Chunks = append(meta.Chunks, entry)
+		return nil
+	})
+	if err == nil {
+		err = stream.Err()
+	}
+	if err != nil {
+		return chunkFile{}, err
+	}
+	meta.PayloadSize = int(stream.BytesRead())
+	meta.PayloadHash = common.BytesToHash(hasher.Sum(nil))
+	if err := writeJSON(filepath.Join(outDir, chunksFileName), meta); err != nil {
+		return chunkFile{}, err
+	}
+	return meta, chunkManifest(meta).Write(filepath.Join(outDir, manifest.FileName))
+}
+
+// encodePayload encodes p into blobs in outDir like splitPayload, or with
+// codec c if it is not nil. A codec's blobs are described by the manifest
+// alone, without chunks.json, as join only reassembles packed chunks.
+func encodePayload(ctx context.Context, p *payload, outDir string, pipeline *batch.Pipeline, c codec.Codec) (chunkFile, error) {
+	if c == nil {
+		return splitPayload(ctx, p, outDir, pipeline)
+	}
+	data, err := p.readAll()
+	if err != nil {
+		return chunkFile{}, err
+	}
+	blobs, m, err := c.Encode(data)
+	if err != nil {
+		return chunkFile{}, fmt.Errorf("%s codec: %w", c.Name(), err)
+	}
+	pipeline.Progress.SetTotal(int64(len(blobs)))
+
+	jobs := func(yield func(batch.Job) bool) {
+		for i := range blobs {
+			name := fmt.Sprintf("blob-%04d.bin", i)
+			job := batch.Job{
+				Name: name,
+				Load: func(dst *kzg4844.Blob) error {
+					*dst = blobs[i]
+					return os.WriteFile(filepath.Join(outDir, name), encodeOutput(dst[:]), 0o644)
+				},
+			}
+			if !yield(job) {
+				return
+			}
+		}
+	}
+	meta := chunkFile{PayloadSize: m.Payload.Size, PayloadHash: m.Payload.SHA256}
+	err = pipeline.Run(ctx, jobs, func(r batch.Result) error {
+		entry := &m.Blobs[len(meta.Chunks)]
+		entry.File = r.Name
+		entry.Commitment, entry.Proof, entry.VersionedHash = r.Commitment, r.Proof, r.VersionedHash
+		meta.Chunks = append(meta.Chunks, chunkEntry{
+			Chunk: entry.Chunk, File: entry.File, Commitment: entry.Commitment, Proof: entry.Proof,
+			VersionedHash: entry.VersionedHash, ChunkHash: entry.ChunkHash,
+		})
+		return nil
+	})
+	if err != nil {
+		return chunkFile{}, err
+	}
+	m.Tool = toolInfo()
+	return meta, m.Write(filepath.Join(outDir, manifest.FileName))
+}
+
+// chunkManifest returns the artifact manifest of a split payload, whose
+// blob files sit next to it.
+func chunkManifest(meta chunkFile) *manifest.Manifest {
+	m := &manifest.Manifest{
+		Version:  manifest.Version,
+		Tool:     toolInfo(),
+		Encoding: manifest.NewEncoding(manifest.LayoutChunked, ""),
+		Payload:  manifest.Payload{Size: meta.PayloadSize, SHA256: meta.PayloadHash},
+		Blobs:    make([]manifest.Blob, len(meta.Chunks)),
+	}
+	for i, entry := range meta.Chunks {
+		m.Blobs[i] = manifest.Blob{
+			Chunk:         entry.Chunk,
+			File:          entry.File,
+			Commitment:    entry.Commitment,
+			Proof:         entry.Proof,
+			VersionedHash: entry.VersionedHash,
+			ChunkHash:     entry.ChunkHash,
+		}
+	}
+	m.ChunkRoot = manifest.ChunkRoot(m.Blobs)
+	return m
+}
+
+// chunkRecords returns the database records of the blobs of a payload split
+// into dir by source.
+func chunkRecords(source, dir string, meta chunkFile) []db.Record {
+	recs := make([]db.Record, len(meta.Chunks))
+	for i, entry := range meta.Chunks {
+		recs[i] = db.Record{
+			VersionedHash: entry.VersionedHash,
+			Commitment:    entry.Commitment,
+			Proof:         entry.Proof,
+			PayloadHash:   meta.PayloadHash,
+			Source:        source,
+			File:          filepath.Join(dir, entry.File),
+		}
+	}
+	return recs
+}
+
+func runJoin(args []string) error {
+	fs := flag.NewFlagSet("join", flag.ExitOnError)
+	in := fs.String("in", "", "directory written by split")
+	out := fs.String("out", "", "output payload file")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc join --out <payload> [flags] <dir>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	dir, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("--out is required")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, chunksFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		if _, statErr := os.Stat(filepath.Join(dir, manifest.FileName)); statErr == nil {
+			return fmt.Errorf("%s has no %s, as split --codec writes only %s: use decode --manifest", dir, chunksFileName, manifest.FileName)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read chunk metadata: %w", err)
+	}
+	var meta chunkFile
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return fmt.Errorf("failed to parse chunk metadata: %w", err)
+	}
+
+	blobs := make([]kzg4844.Blob, len(meta.Chunks))
+	chunks := make([]blob.Chunk, len(meta.Chunks))
+	for i, entry := range meta.Chunks {
+		if blobs[i], err = readBlobFile(filepath.Join(dir, entry.File)); err != nil {
+			return fmt.Errorf("blob %d: %w", i, err)
+		}
+		chunks[i] = entry.Chunk
+	}
+
+	payload, err := blob.JoinBlobs(blobs, chunks)
+	if err != nil {
+		return err
+	}
+	if len(payload) != meta.PayloadSize {
+		return fmt.Errorf("reassembled %d bytes, expected %d", len(payload), meta.PayloadSize)
+	}
+	// chunks.json files from before the hash was recorded have none.
+	if h := sha256.Sum256(payload); meta.PayloadHash != (common.Hash{}) && common.Hash(h) != meta.PayloadHash {
+		return fmt.Errorf("%w: reassembled payload has SHA-256 %s, expected %s", manifest.ErrPayloadMismatch, common.Hash(h), meta.PayloadHash)
+	}
+	if err := writeBinary(*out, payload); err != nil {
+		return err
+	}
+	o.Printf("Reassembled %d bytes from %d blobs\n", len(payload), len(blobs))
+	return o.emit(fileResult{PayloadSize: len(payload), File: *out})
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"kzg-blob-poc/pkg/fee"
+	"kzg-blob-poc/pkg/fetch"
+)
+
+func runStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	rpcURL := fs.String("rpc-url", cfg.RPCURL, "execution client JSON-RPC endpoint")
+	fromBlock := fs.Uint64("from-block", 0, "first block of the range")
+	toBlock := fs.Uint64("to-block", 0, "last block of the range (default: the head)")
+	blocks := fs.Uint64("blocks", 0, "analyze this many blocks ending at --to-block, instead of --from-block")
+	top := fs.Int("top", 10, "number of top blob senders to report (0 reports all)")
+	format := fs.String("format", "", "output format: text, json or csv (default: from --out extension, else text)")
+	table := fs.String("table", "blocks", "table written with --format csv: blocks or senders")
+	out := fs.String("out", "", "write the output to this file instead of stdout")
+	timeout := fs.Duration("timeout", 10*time.Minute, "RPC timeout")
+	pf := addProgressFlags(fs)
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc stats --rpc-url <url> (--from-block <n> | --blocks <n>) [--to-block <n>] [flags]")
+		fmt.Fprintln(fs.Output(), "Reports the blob usage, blob gas against the target, fees and top senders of a block range.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *rpcURL == "" {
+		return errors.New("--rpc-url is required")
+	}
+	if (*fromBlock == 0) == (*blocks == 0) {
+		return errors.New("exactly one of --from-block and --blocks is required")
+	}
+	if o.json {
+		if *format != "" && *format != "json" {
+			return fmt.Errorf("--json cannot be combined with --format %s", *format)
+		}
+		*format = "json"
+	}
+	if *format == "" {
+		*format = "text"
+		switch strings.ToLower(filepath.Ext(*out)) {
+		case ".json":
+			*format = "json"
+		case ".csv":
+			*format = "csv"
+		}
+	}
+	if *format != "text" && *format != "json" && *format != "csv" {
+		return fmt.Errorf("unknown output format %q", *format)
+	}
+	if *table != "blocks" && *table != "senders" {
+		return fmt.Errorf("unknown --table %q", *table)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	client, err := dialEth(ctx, *rpcURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	to := *toBlock
+	if to == 0 {
+		if to, err = client.BlockNumber(ctx); err != nil {
+			return fmt.Errorf("failed to get block number: %w", err)
+		}
+	}
+	from := *fromBlock
+	if *blocks > 0 {
+		from = to - min(to, *blocks-1)
+	}
+	if from > to {
+		return fmt.Errorf("--from-block %d is after --to-block %d", from, to)
+	}
+
+	var s fee.UsageStats
+	tracker := pf.start("stats", int64(to-from+1))
+	err = fetch.Blocks(ctx, client, from, to, func(b *types.Block) error {
+		fork, ok := headerFork(b.Header())
+		if !ok {
+			return fmt.Errorf("block %d (time %d) is before Cancun", b.NumberU64(), b.Time())
+		}
+		tracker.Add(1)
+		return s.Add(b, fork)
+	})
+	tracker.Finish()
+	if err != nil {
+		return err
+	}
+	if err := s.Finish(*top); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	switch {
+	case *format == "json":
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(&s)
+	case *format == "csv" && *table == "senders":
+		err = s.WriteSendersCSV(&buf)
+	case *format == "csv":
+		err = s.WriteBlocksCSV(&buf)
+	default:
+		writeStatsText(&buf, &s)
+	}
+	if err != nil {
+		return err
+	}
+	return writeOutput(*out, buf.Bytes())
+}
+
+// writeStatsText writes the summary of s and its top senders as text.
+func writeStatsText(w *bytes.Buffer, s *fee.UsageStats) {
+	n := len(s.Blocks)
+	first, last := s.Blocks[0], s.Blocks[n-1]
+	fmt.Fprintf(w, "Blocks: %d to %d (%d blocks, %s to %s)\n", s.FromBlock, s.ToBlock, n,
+		time.Unix(int64(first.Time), 0).UTC().Format(time.DateTime), time.Unix(int64(last.Time), 0).UTC().Format(time.DateTime))
+	fmt.Fprintf(w, "Blob Transactions: %d from %d senders\n", s.BlobTxs, s.SenderCount)
+	fmt.Fprintf(w, "Blobs: %d (%.2f per block)\n", s.Blobs, float64(s.Blobs)/float64(n))
+	fmt.Fprintf(w, "Blob Gas: %d used of %d target (%.1f%%)\n", s.BlobGasUsed, s.TargetBlobGas, 100*s.UsedTargetRatio())
+	fmt.Fprintf(w, "Blocks Above Target: %d (%.1f%%), full: %d (%.1f%%)\n",
+		s.AboveTarget, 100*float64(s.AboveTarget)/float64(n), s.Full, 100*float64(s.Full)/float64(n))
+	fmt.Fprintf(w, "Blob Base Fee: %s -> %s gwei (min %s, median %s, max %s)\n",
+		formatGwei(first.BlobBaseFee), formatGwei(last.BlobBaseFee), formatGwei(s.MinBlobBaseFee), formatGwei(s.MedianBlobBaseFee), formatGwei(s.MaxBlobBaseFee))
+	fmt.Fprintf(w, "Base Fee: %s -> %s gwei\n", formatGwei(first.BaseFee), formatGwei(last.BaseFee))
+	fmt.Fprintf(w, "Blob Fees Burned: %s gwei\n", formatGwei(s.BlobFees))
+	if len(s.Senders) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nTop Senders:\n")
+	for i, su := range s.Senders {
+		fmt.Fprintf(w, "%3d. %s  %5d blobs (%5.1f%%) in %4d txs, %s gwei\n",
+			i+1, su.From, su.Blobs, 100*float64(su.Blobs)/float64(max(s.Blobs, 1)), su.Txs, formatGwei(su.BlobFees))
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/tx"
+)
+
+func runTx(args []string) error {
+	fs := flag.NewFlagSet("tx", flag.ExitOnError)
+	kf := addKeyFlags(fs)
+	to := fs.String("to", "", "recipient address")
+	nonce := fs.Uint64("nonce", 0, "sender nonce")
+	gas := fs.Uint64("gas", 21000, "execution gas limit")
+	data := fs.String("data", "", "hex-encoded calldata")
+	callf := addCallFlags(fs)
+	chainID := newBigFlag(1)
+	if network != nil && network.ChainID != 0 {
+		chainID = newBigFlag(int64(network.ChainID))
+	}
+	value := newBigFlag(0)
+	tipCap := newBigFlag(1_000_000_000)
+	feeCap := newBigFlag(30_000_000_000)
+	blobFeeCap := newBigFlag(1_000_000_000)
+	fs.Var(chainID, "chain-id", "chain ID")
+	fs.Var(value, "value", "value to transfer in wei")
+	fs.Var(tipCap, "tip-cap", "max priority fee per gas in wei")
+	fs.Var(feeCap, "fee-cap", "max fee per gas in wei")
+	fs.Var(blobFeeCap, "blob-fee-cap", "max fee per blob gas in wei")
+	out := fs.String("out", "", "write the raw transaction hex to this file")
+	noSidecar := fs.Bool("no-sidecar", false, "encode the canonical transaction without blobs")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc tx (--key-file | --keystore | --mnemonic-file | --signer-url) <...> --to <address> [flags] <blob>...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return errors.New("at least one blob file is required")
+	}
+	if !common.IsHexAddress(*to) {
+		return fmt.Errorf("invalid --to address %q", *to)
+	}
+	input, err := hexutil.Decode(ensureHexPrefix(*data))
+	if err != nil {
+		return fmt.Errorf("invalid --data: %w", err)
+	}
+	call, err := callf.parse(*data)
+	if err != nil {
+		return err
+	}
+	signer, err := kf.signer(context.Background())
+	if err != nil {
+		return err
+	}
+
+	blobs := make([]kzg4844.Blob, fs.NArg())
+	for i, path := range fs.Args() {
+		if blobs[i], err = readBlobFile(path); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	sidecar, err := tx.NewSidecar(blobs)
+	if err != nil {
+		return err
+	}
+	if call != nil {
+		if input, err = call.Encode(sidecar.BlobHashes()); err != nil {
+			return err
+		}
+	}
+
+	unsigned, err := tx.NewBlobTx(tx.Params{
+		ChainID:    chainID.Int,
+		Nonce:      *nonce,
+		To:         common.HexToAddress(*to),
+		Value:      value.Int,
+		Data:       input,
+		Gas:        *gas,
+		GasTipCap:  tipCap.Int,
+		GasFeeCap:  feeCap.Int,
+		BlobFeeCap: blobFeeCap.Int,
+	}, sidecar)
+	if err != nil {
+		return err
+	}
+	signed, err := signer.SignTx(context.Background(), unsigned)
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	summary := newTxSummary(signed)
+	summary.print(o)
+
+	if *noSidecar {
+		signed = signed.WithoutBlobTxSidecar()
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	if *out != "" {
+		if err := writeOutput(*out, []byte(hexutil.Encode(raw)+"\n")); err != nil {
+			return err
+		}
+	} else {
+		o.Printf("Raw Transaction: %s\n", hexutil.Encode(raw))
+		summary.Raw = raw
+	}
+	return o.emit(summary)
+}
+
+// txSummary holds the identifying fields of a signed blob transaction.
+type txSummary struct {
+	Hash        common.Hash    `json:"hash"`
+	Sender      common.Address `json:"sender"`
+	BlobHashes  []common.Hash  `json:"blob_hashes"`
+	BlobGas     uint64         `json:"blob_gas"`
+	Raw         hexutil.Bytes  `json:"raw,omitempty"`
+	BlockNumber *big.Int       `json:"block_number,omitempty"`
+	Status      *uint64        `json:"status,omitempty"`
+}
+
+func newTxSummary(signed *types.Transaction) *txSummary {
+	sender, _ := types.Sender(types.NewCancunSigner(signed.ChainId()), signed)
+	return &txSummary{
+		Hash:       signed.Hash(),
+		Sender:     sender,
+		BlobHashes: signed.BlobHashes(),
+		BlobGas:    signed.BlobGas(),
+	}
+}
+
+// print writes the summary as human-readable text.
+func (s *txSummary) print(o *output) {
+	o.Printf("Transaction Hash: %s\n", s.Hash)
+	o.Printf("Sender: %s\n", s.Sender)
+	o.Printf("Blobs: %d (blob gas %d)\n", len(s.BlobHashes), s.BlobGas)
+	for i, h := range s.BlobHashes {
+		o.Printf("  Versioned Hash %d: %x\n", i, h[:])
+	}
+}
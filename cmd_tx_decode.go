@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/tx"
+)
+
+// txTypeNames names the transaction types.
+var txTypeNames = map[uint8]string{
+	types.LegacyTxType:     "legacy",
+	types.AccessListTxType: "access list",
+	types.DynamicFeeTxType: "dynamic fee",
+	types.BlobTxType:       "blob",
+	types.SetCodeTxType:    "set code",
+}
+
+func runTxDecode(args []string) error {
+	fs := flag.NewFlagSet("tx-decode", flag.ExitOnError)
+	in := fs.String("in", "", "raw transaction, as a file of hex or raw bytes or 0x-prefixed hex")
+	out := fs.String("out", "", "write the re-encoded transaction hex to this file")
+	noSidecar := fs.Bool("no-sidecar", false, "drop the sidecar: re-encode in the canonical encoding and leave blobs out of --json")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc tx-decode [flags] <file|hex>")
+		fmt.Fprintln(fs.Output(), "Prints every field of a raw transaction, in the canonical or the network encoding, and checks its sidecar.")
+		fmt.Fprintln(fs.Output(), "With --json, the transaction is written in the JSON form tx-encode reads.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	arg, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	raw, err := readRawTx(arg)
+	if err != nil {
+		return err
+	}
+	t := new(types.Transaction)
+	if err := t.UnmarshalBinary(raw); err != nil {
+		return fmt.Errorf("invalid transaction: %w", err)
+	}
+
+	// Decoding is strict RLP, so re-encoding should give back the input;
+	// a difference would be a bug in one of the two.
+	reencoded, err := t.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to re-encode transaction: %w", err)
+	}
+	if !bytes.Equal(reencoded, raw) {
+		slog.Warn("Re-encoding the transaction does not reproduce the input", "input_bytes", len(raw), "reencoded_bytes", len(reencoded))
+	}
+
+	var sidecarErr error
+	if t.BlobTxSidecar() != nil {
+		sidecarErr = tx.CheckSidecar(t)
+	}
+	printTx(o, t, len(raw))
+	if t.BlobTxSidecar() != nil {
+		if sidecarErr != nil {
+			o.Printf("❌ Sidecar: %v\n", sidecarErr)
+		} else {
+			o.Printf("✅ Sidecar matches the versioned hashes and its proofs verify\n")
+		}
+	}
+	if bytes.Equal(reencoded, raw) {
+		o.Printf("Round Trip: re-encoding reproduces the input\n")
+	}
+
+	if *noSidecar {
+		t = t.WithoutBlobTxSidecar()
+		if reencoded, err = t.MarshalBinary(); err != nil {
+			return fmt.Errorf("failed to re-encode transaction: %w", err)
+		}
+	}
+	if *out != "" {
+		if err := writeOutput(*out, []byte(hexutil.Encode(reencoded)+"\n")); err != nil {
+			return err
+		}
+	}
+	if o.json {
+		doc, err := txDocument(t, len(raw), sidecarErr)
+		if err != nil {
+			return err
+		}
+		if err := o.emit(doc); err != nil {
+			return err
+		}
+	}
+	return sidecarErr
+}
+
+func runTxEncode(args []string) error {
+	fs := flag.NewFlagSet("tx-encode", flag.ExitOnError)
+	in := fs.String("in", "", "transaction JSON, as tx-decode --json writes it")
+	out := fs.String("out", "", "write the raw transaction hex to this file")
+	noSidecar := fs.Bool("no-sidecar", false, "encode the canonical transaction without blobs")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc tx-encode [flags] <json>")
+		fmt.Fprintln(fs.Output(), "Encodes a signed transaction from its JSON form, in the network encoding if it has blobs.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read transaction JSON: %w", err)
+	}
+	t := new(types.Transaction)
+	if err := json.Unmarshal(data, t); err != nil {
+		return fmt.Errorf("invalid transaction JSON: %w", err)
+	}
+	// go-ethereum writes the sidecar of a blob transaction to JSON but does
+	// not read it back. The hash in the JSON is that of the transaction it
+	// was decoded from: edited fields no longer match its signature, which
+	// then recovers to another sender.
+	var extra struct {
+		Hash        *hexutil.Bytes       `json:"hash"`
+		Blobs       []kzg4844.Blob       `json:"blobs"`
+		Commitments []kzg4844.Commitment `json:"commitments"`
+		Proofs      []kzg4844.Proof      `json:"proofs"`
+	}
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return fmt.Errorf("invalid transaction JSON: %w", err)
+	}
+	if extra.Hash != nil && !bytes.Equal(*extra.Hash, t.Hash().Bytes()) {
+		slog.Warn("Transaction fields differ from those signed; the signature recovers to another sender", "was", hexutil.Encode(*extra.Hash), "hash", t.Hash())
+	}
+	if t.Type() == types.BlobTxType && len(extra.Blobs) > 0 {
+		t = t.WithBlobTxSidecar(&types.BlobTxSidecar{Blobs: extra.Blobs, Commitments: extra.Commitments, Proofs: extra.Proofs})
+	}
+	if *noSidecar {
+		t = t.WithoutBlobTxSidecar()
+	}
+	if t.BlobTxSidecar() != nil {
+		if err := tx.CheckSidecar(t); err != nil {
+			slog.Warn("Encoding a transaction whose sidecar does not verify", "err", err)
+		}
+	}
+
+	raw, err := t.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	summary := newTxSummary(t)
+	summary.print(o)
+	if *out != "" {
+		if err := writeOutput(*out, []byte(hexutil.Encode(raw)+"\n")); err != nil {
+			return err
+		}
+	} else {
+		o.Printf("Raw Transaction: %s\n", hexutil.Encode(raw))
+		summary.Raw = raw
+	}
+	return o.emit(summary)
+}
+
+// printTx writes every field of t as text. size is the length of its
+// encoding.
+func printTx(o *output, t *types.Transaction, size int) {
+	name := txTypeNames[t.Type()]
+	if name == "" {
+		name = "unknown"
+	}
+	o.Printf("Type: %d (%s)\n", t.Type(), name)
+	encoding := "canonical"
+	if t.BlobTxSidecar() != nil {
+		encoding = "network, with sidecar"
+	}
+	o.Printf("Encoding: %s, %d bytes\n", encoding, size)
+	o.Printf("Hash: %s\n", t.Hash())
+	if from, err := types.Sender(types.LatestSignerForChainID(t.ChainId()), t); err == nil {
+		o.Printf("Sender: %s\n", from)
+	} else {
+		o.Printf("Sender: unknown (%v)\n", err)
+	}
+	o.Printf("Chain ID: %s\n", t.ChainId())
+	o.Printf("Nonce: %d\n", t.Nonce())
+	if to := t.To(); to != nil {
+		o.Printf("To: %s\n", to)
+	} else {
+		o.Printf("To: none (contract creation)\n")
+	}
+	o.Printf("Value: %s wei\n", t.Value())
+	o.Printf("Gas: %d\n", t.Gas())
+	if t.Type() == types.LegacyTxType || t.Type() == types.AccessListTxType {
+		o.Printf("Gas Price: %s gwei\n", formatGwei(t.GasPrice()))
+	} else {
+		o.Printf("Max Priority Fee per Gas: %s gwei\n", formatGwei(t.GasTipCap()))
+		o.Printf("Max Fee per Gas: %s gwei\n", formatGwei(t.GasFeeCap()))
+	}
+	if t.Type() == types.BlobTxType {
+		o.Printf("Max Fee per Blob Gas: %s gwei\n", formatGwei(t.BlobGasFeeCap()))
+	}
+	o.Printf("Data: %d bytes", len(t.Data()))
+	if len(t.Data()) > 0 {
+		o.Printf(" %s", hexutil.Encode(t.Data()))
+	}
+	o.Printf("\n")
+	if al := t.AccessList(); len(al) > 0 {
+		o.Printf("Access List: %d addresses, %d storage keys\n", len(al), al.StorageKeys())
+		for _, e := range al {
+			o.Printf("  %s\n", e.Address)
+			for _, k := range e.StorageKeys {
+				o.Printf("    %s\n", k)
+			}
+		}
+	}
+	if hashes := t.BlobHashes(); t.Type() == types.BlobTxType {
+		o.Printf("Blob Versioned Hashes: %d (blob gas %d)\n", len(hashes), t.BlobGas())
+		for i, h := range hashes {
+			o.Printf("  %d: %s\n", i, h)
+		}
+	}
+	v, r, s := t.RawSignatureValues()
+	o.Printf("Signature: v %s, r %#x, s %#x\n", v, r, s)
+	if sc := t.BlobTxSidecar(); sc != nil {
+		o.Printf("Sidecar: %d blobs\n", len(sc.Blobs))
+		for i := range sc.Blobs {
+			if i < len(sc.Commitments) {
+				o.Printf("  Commitment %d: %s\n", i, hexutil.Encode(sc.Commitments[i][:]))
+			}
+			if i < len(sc.Proofs) {
+				o.Printf("  Proof %d:      %s\n", i, hexutil.Encode(sc.Proofs[i][:]))
+			}
+		}
+	}
+}
+
+// txDocument returns the --json output of tx-decode: the transaction in
+// go-ethereum's JSON form, which tx-encode reads back, with its sender,
+// encoding and sidecar check added.
+func txDocument(t *types.Transaction, size int, sidecarErr error) (map[string]any, error) {
+	data, err := t.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if from, err := types.Sender(types.LatestSignerForChainID(t.ChainId()), t); err == nil {
+		doc["from"] = from
+	}
+	doc["size"] = size
+	if t.Type() == types.BlobTxType {
+		doc["blob_gas"] = hexutil.Uint64(t.BlobGas())
+	}
+	if t.BlobTxSidecar() != nil {
+		doc["sidecar_valid"] = sidecarErr == nil
+		if sidecarErr != nil {
+			doc["sidecar_error"] = sidecarErr.Error()
+		}
+	}
+	return doc, nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/batch"
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/manifest"
+)
+
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	blobPath := fs.String("blob", "", "blob file (hex text or raw binary)")
+	commitmentHex := fs.String("commitment", "", "hex-encoded 48-byte KZG commitment")
+	proofHex := fs.String("proof", "", "hex-encoded 48-byte KZG proof")
+	versionedHashHex := fs.String("versioned-hash", "", "optional hex-encoded versioned hash to check against the commitment")
+	hf := addHashFlags(fs)
+	diagnose := fs.Bool("diagnose", false, "on failure, recompute the commitment, proof and versioned hash from the blob and report which supplied artifact is inconsistent, and at which bytes")
+	manifestPath := fs.String("manifest", "", "take the artifacts from this artifact manifest instead of --commitment and --proof")
+	index := fs.Int("index", -1, "with --manifest, the index of the blob in the manifest (default: the entry whose file is --blob)")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc verify --blob <file> (--commitment <hex> --proof <hex> [--versioned-hash <hex>] | --manifest <file> [--index n])")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *manifestPath != "" {
+		if *commitmentHex != "" || *proofHex != "" || *versionedHashHex != "" {
+			return errors.New("--manifest cannot be combined with --commitment, --proof or --versioned-hash")
+		}
+		if *blobPath == "" {
+			return errors.New("--blob is required")
+		}
+		return verifyManifestBlob(o, *manifestPath, *blobPath, *index)
+	}
+
+	if *blobPath == "" || *commitmentHex == "" || *proofHex == "" {
+		return errors.New("--blob, --commitment and --proof are required")
+	}
+
+	b, err := readBlobFile(*blobPath)
+	if err != nil {
+		return err
+	}
+	var commitment kzg4844.Commitment
+	data, err := parseHexFixed("commitment", *commitmentHex, len(commitment))
+	if err != nil {
+		return err
+	}
+	copy(commitment[:], data)
+
+	var proof kzg4844.Proof
+	data, err = parseHexFixed("proof", *proofHex, len(proof))
+	if err != nil {
+		return err
+	}
+	copy(proof[:], data)
+
+	var versionedHash []byte
+	if *versionedHashHex != "" {
+		if versionedHash, err = parseHexFixed("versioned hash", *versionedHashHex, common.HashLength); err != nil {
+			return err
+		}
+	}
+	expectedHash, err := hf.versionedHash(commitment)
+	if err != nil {
+		return err
+	}
+
+	err = blob.Verify(&b, commitment, proof)
+	if err == nil && versionedHash != nil && common.BytesToHash(versionedHash) != expectedHash {
+		err = fmt.Errorf("%w: commitment hashes to %x, got %x", blob.ErrVersionedHashMismatch, expectedHash, versionedHash)
+	}
+	res := newVerifyResult(err)
+	if err != nil && *diagnose {
+		d, derr := blob.Diagnose(&b, commitment, proof)
+		if derr != nil {
+			return derr
+		}
+		if versionedHash != nil {
+			d.CheckVersionedHash(common.BytesToHash(versionedHash), func(c kzg4844.Commitment) common.Hash {
+				h, _ := hf.versionedHash(c) // The scheme was checked above.
+				return h
+			})
+		}
+		printDiagnosis(o, d)
+		if derr := d.Err(); derr != nil {
+			err = derr
+		}
+		res = newVerifyResult(err)
+		res.Diagnosis = d
+	}
+	if emitErr := o.emit(res); emitErr != nil {
+		return emitErr
+	}
+	if err != nil {
+		return err
+	}
+	o.Println("✅ Proof verification successful!")
+	if versionedHash != nil {
+		o.Println("✅ Versioned hash matches commitment")
+	}
+	return nil
+}
+
+// verifyManifestBlob checks the blob at path against entry index of the
+// manifest, or the entry naming that file when index is negative.
+func verifyManifestBlob(o *output, manifestPath, path string, index int) error {
+	m, err := manifest.Read(manifestPath)
+	if err != nil {
+		return err
+	}
+	if index < 0 {
+		if index = manifestIndex(manifestPath, m, path); index < 0 {
+			return fmt.Errorf("%s is not listed in %s; pass --index", path, manifestPath)
+		}
+	}
+	b, err := readBlobFile(path)
+	if err != nil {
+		return err
+	}
+	err = m.VerifyBlob(index, &b)
+	if emitErr := o.emit(newVerifyResult(err)); emitErr != nil {
+		return emitErr
+	}
+	if err != nil {
+		return err
+	}
+	if m.Blobs[index].Proof == (kzg4844.Proof{}) {
+		o.Printf("✅ Blob %d commits to the manifest's commitment (the manifest has no proof)\n", index)
+	} else {
+		o.Printf("✅ Blob %d matches the manifest's commitment and proof\n", index)
+	}
+	return nil
+}
+
+// manifestIndex returns the index of the manifest entry whose file, relative
+// to the manifest, is the file at path, or -1.
+func manifestIndex(manifestPath string, m *manifest.Manifest, path string) int {
+	info, err := os.Stat(path)
+	if err != nil {
+		return -1
+	}
+	for i, entry := range m.Blobs {
+		if entry.File == "" {
+			continue
+		}
+		other, err := os.Stat(manifestBlobPath(filepath.Dir(manifestPath), entry))
+		if err == nil && os.SameFile(info, other) {
+			return i
+		}
+	}
+	return -1
+}
+
+// manifestBlobPath returns the path of the file of entry, which unless
+// absolute is relative to dir, normally the manifest's directory.
+func manifestBlobPath(dir string, entry manifest.Blob) string {
+	file := filepath.FromSlash(entry.File)
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(dir, file)
+}
+
+// verifyResult is the JSON output of the verification commands.
+type verifyResult struct {
+	Valid     bool            `json:"valid"`
+	Error     string          `json:"error,omitempty"`
+	Failures  []batch.Failure `json:"failures,omitempty"`
+	Diagnosis *blob.Diagnosis `json:"diagnosis,omitempty"`
+}
+
+func newVerifyResult(err error) verifyResult {
+	var failed *batch.FailedError
+	switch {
+	case errors.As(err, &failed):
+		return verifyResult{Error: err.Error(), Failures: failed.Failures}
+	case err != nil:
+		return verifyResult{Error: err.Error()}
+	}
+	return verifyResult{Valid: true}
+}
+
+// printDiagnosis reports each artifact checked by verify --diagnose.
+func printDiagnosis(o *output, d *blob.Diagnosis) {
+	o.Println("Diagnosis:")
+	printArtifactDiff(o, "Commitment", d.Commitment)
+	printArtifactDiff(o, "Proof", d.Proof)
+	if d.VersionedHash != nil {
+		printArtifactDiff(o, "Versioned Hash", *d.VersionedHash)
+	}
+	if d.ProofVerifies {
+		o.Println("  The proof verifies against the supplied commitment")
+	}
+	if err := d.Err(); err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			o.Printf("  ❌ %s\n", line)
+		}
+	} else {
+		o.Println("  The supplied artifacts are those of the blob")
+	}
+}
+
+func printArtifactDiff(o *output, name string, d blob.ArtifactDiff) {
+	if d.Match() {
+		o.Printf("  %s: ✅ matches the blob\n", name)
+		return
+	}
+	o.Printf("  %s: ❌ %d of %d bytes differ (%s)\n", name, len(d.Bytes), len(d.Expected), d.Ranges())
+	o.Printf("    Supplied: %s\n", d.Supplied)
+	o.Printf("    Expected: %s\n", d.Expected)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/batch"
+	"kzg-blob-poc/pkg/blob"
+)
+
+// verifyBatchEntry is one element of a verify-batch manifest. The report
+// written by the batch command is accepted as well, in which case the blob
+// path is taken from its name field.
+type verifyBatchEntry struct {
+	Blob       string             `json:"blob"`
+	Name       string             `json:"name"`
+	Commitment kzg4844.Commitment `json:"commitment"`
+	Proof      kzg4844.Proof      `json:"proof"`
+}
+
+func runVerifyBatch(args []string) error {
+	fs := flag.NewFlagSet("verify-batch", flag.ExitOnError)
+	in := fs.String("in", "", "JSON manifest of {blob, commitment, proof} entries")
+	failFast := fs.Bool("fail-fast", false, "stop at the first entry that cannot be read or fails, instead of reporting every failure at the end")
+	failuresPath := fs.String("failures", "", "write the index, name and reason of each failed entry as JSON to this file")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc verify-batch [flags] <manifest.json>")
+		fmt.Fprintln(fs.Output(), "Relative blob paths are resolved against the manifest's directory.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var entries []verifyBatchEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("manifest contains no entries")
+	}
+
+	// Entries that cannot be read are left out of the batch, and their
+	// indices kept, unless failFast stops at the first.
+	var (
+		blobs       = make([]kzg4844.Blob, 0, len(entries))
+		commitments = make([]kzg4844.Commitment, 0, len(entries))
+		proofs      = make([]kzg4844.Proof, 0, len(entries))
+		loaded      = make([]int, 0, len(entries)) // entry index of each blob
+		names       = make([]string, 0, len(entries))
+		failed      = &batch.FailedError{Total: len(entries)}
+	)
+	for i, e := range entries {
+		blobPath := e.Blob
+		if blobPath == "" {
+			blobPath = e.Name
+		}
+		if blobPath == "" {
+			err = errors.New("no blob path")
+		} else {
+			if !filepath.IsAbs(blobPath) {
+				blobPath = filepath.Join(filepath.Dir(path), blobPath)
+			}
+			var b kzg4844.Blob
+			if b, err = readBlobFile(blobPath); err == nil {
+				blobs = append(blobs, b)
+				commitments = append(commitments, e.Commitment)
+				proofs = append(proofs, e.Proof)
+				loaded = append(loaded, i)
+				names = append(names, blobPath)
+				continue
+			}
+		}
+		if *failFast {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+		failed.Failures = append(failed.Failures, batch.NewFailure(i, blobPath, err))
+	}
+
+	start := time.Now()
+	if len(blobs) > 0 {
+		err = blob.VerifyBlobProofBatch(blobs, commitments, proofs)
+	}
+	if err != nil && *failFast {
+		err = fmt.Errorf("batch %w", err)
+	} else if err != nil {
+		// The batch check cannot tell which proofs are at fault, so each is
+		// checked on its own.
+		for j, i := range loaded {
+			if verr := blob.Verify(&blobs[j], commitments[j], proofs[j]); verr != nil {
+				failed.Failures = append(failed.Failures, batch.NewFailure(i, names[j], verr))
+			}
+		}
+		slices.SortFunc(failed.Failures, func(a, b batch.Failure) int { return a.Index - b.Index })
+		err = nil
+	}
+	if err == nil && len(failed.Failures) > 0 {
+		err = failed
+	}
+	if emitErr := o.emit(newVerifyResult(err)); emitErr != nil {
+		return emitErr
+	}
+	if err == failed {
+		o.Printf("%d of %d blob proofs verified\n", len(entries)-len(failed.Failures), len(entries))
+		return reportFailures(failed, *failuresPath)
+	}
+	if err != nil {
+		return err
+	}
+	o.Printf("✅ %d blob proofs verified in %s\n", len(entries), time.Since(start).Round(time.Millisecond))
+	return nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/batch"
+	"kzg-blob-poc/pkg/codec"
+	"kzg-blob-poc/pkg/manifest"
+)
+
+// manifestAudit is the JSON output of the verify-manifest command.
+type manifestAudit struct {
+	Valid    bool               `json:"valid"`
+	Blobs    int                `json:"blobs"`
+	Failures []blobAuditFailure `json:"failures,omitempty"`
+	Payload  verifyResult       `json:"payload"`
+}
+
+// blobAuditFailure is a blob that does not match the manifest.
+type blobAuditFailure struct {
+	Index int    `json:"index"`
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+func runVerifyManifest(args []string) error {
+	fs := flag.NewFlagSet("verify-manifest", flag.ExitOnError)
+	in := fs.String("in", "", "artifact manifest")
+	blobDir := fs.String("blob-dir", "", "directory the manifest's blob files are relative to (default: the manifest's directory)")
+	workers := fs.Int("workers", runtime.NumCPU(), "number of blobs to commit to and prove concurrently")
+	cacheDir := addCacheFlag(fs)
+	pf := addProgressFlags(fs)
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc verify-manifest [--blob-dir <dir>] [flags] <manifest.json>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	m, err := manifest.Read(path)
+	if err != nil {
+		return err
+	}
+	if *blobDir == "" {
+		*blobDir = filepath.Dir(path)
+	}
+	for i, entry := range m.Blobs {
+		if entry.File == "" {
+			return fmt.Errorf("blob %d: the manifest names no file", i)
+		}
+	}
+	c, err := openCache(*cacheDir)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pipeline := batch.NewPipeline(*workers)
+	pipeline.Cache = c
+	pipeline.Progress = pf.start("verify-manifest", int64(len(m.Blobs)))
+	audit, err := auditManifest(ctx, m, *blobDir, pipeline)
+	pipeline.Progress.Finish()
+	if err != nil {
+		return err
+	}
+
+	for _, f := range audit.Failures {
+		o.Printf("❌ %s\n", f.Error)
+	}
+	if len(audit.Failures) == 0 {
+		o.Printf("✅ All %d blobs match their commitments and proofs\n", audit.Blobs)
+	}
+	if audit.Payload.Valid {
+		o.Printf("✅ Payload matches: %d bytes, SHA-256 %x\n", m.Payload.Size, m.Payload.SHA256[:])
+	} else {
+		o.Printf("❌ %s\n", audit.Payload.Error)
+	}
+	if err := o.emit(audit); err != nil {
+		return err
+	}
+	switch {
+	case len(audit.Failures) > 0:
+		return fmt.Errorf("%d of %d blobs do not match the manifest: %w", len(audit.Failures), audit.Blobs, audit.firstErr)
+	case !audit.Payload.Valid:
+		return audit.firstErr
+	}
+	return nil
+}
+
+// auditResult is the outcome of auditManifest. firstErr keeps the first
+// failure's error chain for the exit code.
+type auditResult struct {
+	manifestAudit
+	firstErr error
+}
+
+// auditManifest recomputes the artifacts of every blob of m, read from dir,
+// compares them with the manifest, and hashes the payload they hold. Blobs
+// that are missing or do not match are reported and the audit goes on; only
+// a cancelled ctx or a failed computation ends it early.
+func auditManifest(ctx context.Context, m *manifest.Manifest, dir string, pipeline *batch.Pipeline) (*auditResult, error) {
+	res := &auditResult{manifestAudit: manifestAudit{Blobs: len(m.Blobs)}}
+	fail := func(i int, err error) {
+		res.Failures = append(res.Failures, blobAuditFailure{Index: i, File: m.Blobs[i].File, Error: err.Error()})
+		if res.firstErr == nil {
+			res.firstErr = err
+		}
+	}
+
+	// Workers read each blob and unpack its part of the payload; the
+	// collector, which sees the blobs in order, hashes those parts. A blob
+	// that cannot be read is processed as zeros and reported instead. A
+	// codec's payload is only recovered from all its blobs, so they are kept
+	// and decoded at the end.
+	type loaded struct {
+		part      []byte
+		blob      *kzg4844.Blob // a copy, for a codec
+		readErr   error         // the blob file could not be read
+		unpackErr error         // the blob does not hold its part of the payload
+	}
+	var (
+		mu    sync.Mutex
+		blobs = make(map[int]loaded)
+		whole = m.Encoding.Layout == manifest.LayoutCodec
+		kept  []kzg4844.Blob
+	)
+	jobs := func(yield func(batch.Job) bool) {
+		for i, entry := range m.Blobs {
+			job := batch.Job{
+				Name: entry.File,
+				Load: func(dst *kzg4844.Blob) error {
+					var l loaded
+					if *dst, l.readErr = readBlobFile(manifestBlobPath(dir, entry)); l.readErr != nil {
+						*dst = kzg4844.Blob{}
+						l.readErr = fmt.Errorf("blob %d: %w", i, l.readErr)
+					} else if whole {
+						l.blob = new(kzg4844.Blob)
+						*l.blob = *dst
+					} else {
+						l.part, l.unpackErr = m.Unpack(i, dst)
+					}
+					mu.Lock()
+					blobs[i] = l
+					mu.Unlock()
+					return nil
+				},
+			}
+			if !yield(job) {
+				return
+			}
+		}
+	}
+
+	var (
+		hasher     = sha256.New()
+		size       int
+		payloadErr error
+		next       int
+	)
+	err := pipeline.Run(ctx, jobs, func(r batch.Result) error {
+		i := next
+		next++
+		mu.Lock()
+		l := blobs[i]
+		delete(blobs, i)
+		mu.Unlock()
+
+		if l.readErr != nil {
+			fail(i, l.readErr)
+		} else if err := m.CompareArtifacts(i, r.Commitment, r.Proof); err != nil {
+			fail(i, err)
+		}
+		switch {
+		case payloadErr != nil:
+		case l.readErr != nil:
+			payloadErr = fmt.Errorf("%w: %w", manifest.ErrPayloadMismatch, l.readErr)
+		case l.unpackErr != nil:
+			payloadErr = fmt.Errorf("%w: %w", manifest.ErrPayloadMismatch, l.unpackErr)
+		case whole:
+			kept = append(kept, *l.blob)
+		default:
+			hasher.Write(l.part)
+			size += len(l.part)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case payloadErr != nil:
+	case whole:
+		if _, err := codec.Decode(m, kept); err != nil {
+			payloadErr = err
+			if !errors.Is(err, manifest.ErrPayloadMismatch) {
+				payloadErr = fmt.Errorf("%w: %w", manifest.ErrPayloadMismatch, err)
+			}
+		}
+	default:
+		payloadErr = m.CheckPayload(manifest.Payload{Size: size, SHA256: common.BytesToHash(hasher.Sum(nil))})
+	}
+	res.Payload = newVerifyResult(payloadErr)
+	if payloadErr != nil && res.firstErr == nil {
+		res.firstErr = payloadErr
+	}
+	res.Valid = len(res.Failures) == 0 && payloadErr == nil
+	return res, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"kzg-blob-poc/pkg/manifest"
+)
+
+// signatureCheck is the JSON output of verify-manifest-signature.
+type signatureCheck struct {
+	Valid  bool           `json:"valid"`
+	Scheme string         `json:"scheme,omitempty"`
+	Signer common.Address `json:"signer,omitzero"`
+	Error  string         `json:"error,omitempty"`
+}
+
+func runVerifyManifestSignature(args []string) error {
+	fs := flag.NewFlagSet("verify-manifest-signature", flag.ExitOnError)
+	in := fs.String("in", "", "artifact manifest")
+	signer := fs.String("signer", "", "address the manifest must be signed by (default: any, reported)")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc verify-manifest-signature [--signer <address>] <manifest.json>")
+		fmt.Fprintln(fs.Output(), "Checks who signed an artifact manifest and that it is unchanged since; exits with 3 if not.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	var want common.Address
+	if *signer != "" {
+		if !common.IsHexAddress(*signer) {
+			return fmt.Errorf("invalid --signer address %q", *signer)
+		}
+		want = common.HexToAddress(*signer)
+	}
+	m, err := manifest.Read(path)
+	if err != nil {
+		return err
+	}
+
+	got, verr := m.VerifySignature(want)
+	r := signatureCheck{Valid: verr == nil, Signer: got}
+	if m.Signature != nil {
+		r.Scheme = m.Signature.Scheme
+	}
+	switch {
+	case verr != nil:
+		r.Error = verr.Error()
+		o.Printf("❌ %s: %v\n", path, verr)
+	case want == (common.Address{}):
+		o.Printf("✅ %s is signed by %s (%s); pass --signer to require that signer\n", path, got, r.Scheme)
+	default:
+		o.Printf("✅ %s is signed by %s (%s)\n", path, got, r.Scheme)
+	}
+	if err := o.emit(r); err != nil {
+		return err
+	}
+	return verr
+}
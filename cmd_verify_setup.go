@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/config"
+)
+
+// loadedSetup is the trusted setup file installed by loadTrustedSetup and
+// its hash, or empty when the embedded ceremony setup is in use.
+var loadedSetup struct {
+	path string
+	hash common.Hash
+}
+
+// loadTrustedSetup installs the trusted setup at path, once it is well
+// formed and has the setup hash hashHex, or that of the ceremony output
+// when hashHex is empty. An empty path keeps the embedded setup.
+func loadTrustedSetup(path, hashHex string) error {
+	if path == "" {
+		if hashHex != "" {
+			return errors.New("a trusted setup hash needs a trusted setup file")
+		}
+		return nil
+	}
+	if cfg.Backend == config.BackendCKZG {
+		return errors.New("a trusted setup file needs the gokzg backend")
+	}
+	want := blob.CeremonySetupHash
+	if hashHex != "" {
+		h, err := parseHexFixed("trusted setup hash", hashHex, common.HashLength)
+		if err != nil {
+			return err
+		}
+		want = common.Hash(h)
+	}
+	ts, err := blob.ReadTrustedSetup(path)
+	if err != nil {
+		return err
+	}
+	if err := blob.CheckTrustedSetup(ts, want); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := blob.UseTrustedSetup(ts); err != nil {
+		return err
+	}
+	loadedSetup.path, loadedSetup.hash = path, want
+	slog.Debug("Loaded trusted setup", "file", path, "hash", want)
+	return nil
+}
+
+// setupReport is the JSON output of verify-setup.
+type setupReport struct {
+	File      string      `json:"file,omitempty"` // empty for the embedded setup
+	SetupHash common.Hash `json:"setup_hash,omitzero"`
+	Ceremony  bool        `json:"ceremony"` // the setup is the ceremony output
+	Valid     bool        `json:"valid"`
+	Error     string      `json:"error,omitempty"`
+}
+
+func runVerifySetup(args []string) error {
+	fs := flag.NewFlagSet("verify-setup", flag.ExitOnError)
+	hashHex := fs.String("hash", "", "setup hash the file must have (default: the ceremony output's)")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc verify-setup [--hash h] [<setup file>]")
+		fmt.Fprintln(fs.Output(), "Without a file, checks the setup in use: --trusted-setup or the embedded one.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var (
+		r   setupReport
+		err error
+	)
+	switch {
+	case fs.NArg() > 0:
+		r.File = fs.Arg(0)
+		err = verifySetupFile(&r, *hashHex)
+	case *hashHex != "":
+		return errors.New("--hash needs a setup file")
+	case loadedSetup.path != "":
+		// Checked when it was loaded.
+		r.File, r.SetupHash = loadedSetup.path, loadedSetup.hash
+		r.Ceremony = r.SetupHash == blob.CeremonySetupHash
+	default:
+		// The KZG libraries do not expose their setup, so it is checked
+		// by its results.
+		err = blob.CheckLoadedSetup()
+		if err == nil {
+			r.SetupHash, r.Ceremony = blob.CeremonySetupHash, true
+		}
+	}
+	r.Valid = err == nil
+	if err != nil {
+		r.Error = err.Error()
+	}
+	if emitErr := o.emit(r); emitErr != nil {
+		return emitErr
+	}
+	if err != nil {
+		return err
+	}
+
+	name := r.File
+	if name == "" {
+		name = "The embedded trusted setup"
+	}
+	if r.Ceremony {
+		o.Printf("✅ %s is the Ethereum KZG ceremony output\n", name)
+	} else {
+		o.Printf("✅ %s has the expected hash; it is not the ceremony output\n", name)
+	}
+	o.Printf("   Setup hash: %s\n", r.SetupHash)
+	return nil
+}
+
+// verifySetupFile checks the setup file r.File against hashHex, or the hash
+// of the ceremony output, and fills in r.
+func verifySetupFile(r *setupReport, hashHex string) error {
+	want := blob.CeremonySetupHash
+	if hashHex != "" {
+		h, err := parseHexFixed("--hash", hashHex, common.HashLength)
+		if err != nil {
+			return err
+		}
+		want = common.Hash(h)
+	}
+	ts, err := blob.ReadTrustedSetup(r.File)
+	if err != nil {
+		return err
+	}
+	if r.SetupHash, err = blob.SetupHash(ts); err != nil {
+		return err
+	}
+	r.Ceremony = r.SetupHash == blob.CeremonySetupHash
+	return blob.CheckTrustedSetup(ts, want)
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/fsnotify/fsnotify"
+
+	"kzg-blob-poc/pkg/archive"
+	"kzg-blob-poc/pkg/batch"
+	"kzg-blob-poc/pkg/tx"
+)
+
+// Names watch writes into the artifact directory of each file.
+const (
+	watchDirSuffix = ".blobs"
+	sentFileName   = "sent.json"
+)
+
+// watchResult is the --json line watch emits for each processed file.
+type watchResult struct {
+	File         string        `json:"file"`
+	Dir          string        `json:"dir"`
+	PayloadSize  int           `json:"payload_size"`
+	Blobs        int           `json:"blobs"`
+	Archived     bool          `json:"archived,omitempty"`
+	Transactions []*txSummary  `json:"transactions,omitempty"`
+	Plan         []tx.PackedTx `json:"plan,omitempty"`
+	Error        string        `json:"error,omitempty"`
+}
+
+func runWatch(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	outDir := fs.String("out-dir", "", "write the artifacts of <file> to <out-dir>/<file>"+watchDirSuffix+" (default: next to the file)")
+	pattern := fs.String("pattern", "*", "only process files whose name matches this glob")
+	settle := fs.Duration("settle", 2*time.Second, "process a file once it has not changed for this long")
+	existing := fs.Bool("existing", false, "also process files already in the directory that have no artifacts yet")
+	workers := fs.Int("workers", runtime.NumCPU(), "number of blobs of a file to commit to and prove concurrently")
+	cacheDir := addCacheFlag(fs)
+	archiveURL := addArchiveFlag(fs, "store the blobs of each file and their artifacts in this archive")
+	submit := fs.Bool("submit", false, "send the blobs of each file in blob transactions and wait for the receipts")
+	rpcURL := fs.String("rpc-url", cfg.RPCURL, "execution client JSON-RPC endpoint (with --submit)")
+	kf := addKeyFlags(fs)
+	to := fs.String("to", "", "recipient address of the blob transactions (with --submit)")
+	callf := addCallFlags(fs)
+	maxBlobs := fs.Int("max-blobs-per-tx", maxBlobsPerTx(), "most blobs per transaction (with --submit)")
+	txTimeout := fs.Duration("tx-timeout", 5*time.Minute, "how long to wait for the receipts of one file (with --submit)")
+	cf := addFeeCapFlags(fs)
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc watch [flags] <dir>")
+		fmt.Fprintln(fs.Output(), "With --json, one JSON object per processed file is written to stdout.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("exactly one directory is required")
+	}
+	dir := fs.Arg(0)
+	if info, err := os.Stat(dir); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if _, err := filepath.Match(*pattern, ""); err != nil {
+		return fmt.Errorf("invalid --pattern: %w", err)
+	}
+	if *submit && *rpcURL == "" {
+		return errors.New("--rpc-url is required with --submit")
+	}
+	if *submit && !common.IsHexAddress(*to) {
+		return fmt.Errorf("invalid --to address %q", *to)
+	}
+	call, err := callf.parse("")
+	if err != nil {
+		return err
+	}
+	if *outDir != "" {
+		if err := os.MkdirAll(*outDir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c, err := openCache(*cacheDir)
+	if err != nil {
+		return err
+	}
+	a, err := openArchive(*archiveURL)
+	if err != nil {
+		return err
+	}
+	w := &watcher{
+		o:        o,
+		outDir:   *outDir,
+		pipeline: batch.NewPipeline(*workers),
+		archive:  a,
+		to:       common.HexToAddress(*to),
+		timeout:  *txTimeout,
+	}
+	w.pipeline.Cache = c
+	if *submit {
+		client, err := dialEth(ctx, *rpcURL)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		signer, err := kf.signer(ctx)
+		if err != nil {
+			return err
+		}
+		w.sender = &blobSender{client: client, signer: signer, nonces: tx.NewNonceTracker(client), maxBlobs: *maxBlobs, call: call}
+		if err := cf.apply(w.sender); err != nil {
+			return err
+		}
+	}
+
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fw.Close()
+	if err := fw.Add(dir); err != nil {
+		return err
+	}
+	slog.Info("Watching for new files", "dir", dir, "pattern", *pattern, "submit", *submit)
+
+	// Each file is processed once no event for it has arrived for --settle,
+	// so files still being written are not picked up half-way. Files are
+	// processed one at a time, which keeps transaction nonces in file order.
+	var (
+		timers = map[string]*time.Timer{}
+		ready  = make(chan string)
+	)
+	schedule := func(path string) {
+		if t, ok := timers[path]; ok {
+			t.Reset(*settle)
+			return
+		}
+		timers[path] = time.AfterFunc(*settle, func() {
+			select {
+			case ready <- path:
+			case <-ctx.Done():
+			}
+		})
+	}
+	watched := func(path string) bool {
+		name := filepath.Base(path)
+		ok, _ := filepath.Match(*pattern, name)
+		info, err := os.Stat(path)
+		return ok && !strings.HasPrefix(name, ".") && err == nil && info.Mode().IsRegular()
+	}
+
+	if *existing {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			path := filepath.Join(dir, e.Name())
+			if !watched(path) {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(w.artifactDir(path), chunksFileName)); err == nil {
+				continue
+			}
+			schedule(path)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Stopped watching", "dir", dir)
+			return nil
+		case err := <-fw.Errors:
+			return fmt.Errorf("watch %s: %w", dir, err)
+		case ev := <-fw.Events:
+			switch {
+			case ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename):
+				if t, ok := timers[ev.Name]; ok {
+					t.Stop()
+					delete(timers, ev.Name)
+				}
+			case ev.Has(fsnotify.Create) || ev.Has(fsnotify.Write):
+				if watched(ev.Name) {
+					schedule(ev.Name)
+				}
+			}
+		case path := <-ready:
+			delete(timers, path)
+			if err := w.process(ctx, path); err != nil {
+				slog.Error("Failed to process file", "file", path, "err", err)
+			}
+		}
+	}
+}
+
+// watcher encodes and optionally submits the files found by watch.
+type watcher struct {
+	o        *output
+	outDir   string
+	pipeline *batch.Pipeline
+	archive  *archive.Archive
+	sender   *blobSender // nil unless --submit
+	to       common.Address
+	timeout  time.Duration
+}
+
+// artifactDir is where the blobs and artifacts of the file at path go.
+func (w *watcher) artifactDir(path string) string {
+	dir := w.outDir
+	if dir == "" {
+		dir = filepath.Dir(path)
+	}
+	return filepath.Join(dir, filepath.Base(path)+watchDirSuffix)
+}
+
+// process splits the file at path into blobs next to their artifacts and,
+// with --submit, sends them. The outcome is also reported as a JSON line.
+func (w *watcher) process(ctx context.Context, path string) error {
+	res := watchResult{File: path, Dir: w.artifactDir(path)}
+	err := w.encode(ctx, path, &res)
+	if err != nil {
+		res.Error = err.Error()
+	}
+	if emitErr := w.o.emitLine(res); emitErr != nil {
+		return emitErr
+	}
+	return err
+}
+
+func (w *watcher) encode(ctx context.Context, path string, res *watchResult) error {
+	p, err := openPayload(path, false)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+	if err := os.MkdirAll(res.Dir, 0o755); err != nil {
+		return err
+	}
+	meta, err := splitPayload(ctx, p, res.Dir, w.pipeline)
+	if err != nil {
+		return err
+	}
+	res.PayloadSize, res.Blobs = meta.PayloadSize, len(meta.Chunks)
+	recordBlobs(ctx, chunkRecords("watch", res.Dir, meta)...)
+	slog.Info("Encoded file", "file", path, "bytes", meta.PayloadSize, "blobs", len(meta.Chunks), "dir", res.Dir)
+	if (w.sender == nil && w.archive == nil) || len(meta.Chunks) == 0 {
+		return nil
+	}
+
+	blobs := make([]kzg4844.Blob, len(meta.Chunks))
+	commitments := make([]kzg4844.Commitment, len(meta.Chunks))
+	proofs := make([]kzg4844.Proof, len(meta.Chunks))
+	for i, entry := range meta.Chunks {
+		if blobs[i], err = readBlobFile(filepath.Join(res.Dir, entry.File)); err != nil {
+			return err
+		}
+		commitments[i], proofs[i] = entry.Commitment, entry.Proof
+	}
+	if w.archive != nil {
+		if err := archiveBlobs(ctx, w.archive, blobs, commitments, proofs); err != nil {
+			return err
+		}
+		res.Archived = true
+	}
+	if w.sender == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+	plan, summaries, err := w.sender.send(ctx, w.o, tx.Params{To: w.to}, blobs)
+	if err != nil {
+		return err
+	}
+	res.Plan, res.Transactions = plan, summaries
+	if err := writeJSON(filepath.Join(res.Dir, sentFileName), packedSend{Plan: plan, Transactions: summaries}); err != nil {
+		return err
+	}
+	reverted := 0
+	for _, s := range summaries {
+		if *s.Status != types.ReceiptStatusSuccessful {
+			reverted++
+		}
+	}
+	slog.Info("Submitted file", "file", path, "transactions", len(summaries), "reverted", reverted)
+	if reverted > 0 {
+		return fmt.Errorf("%d of %d transactions reverted", reverted, len(summaries))
+	}
+	return nil
+}
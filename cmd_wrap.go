@@ -0,0 +1,240 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/tx"
+)
+
+// unwrappedTx is the JSON output of unwrap for each transaction.
+type unwrappedTx struct {
+	*txSummary
+	Nonce       uint64               `json:"nonce"`
+	Commitments []kzg4844.Commitment `json:"commitments,omitempty"`
+	Proofs      []kzg4844.Proof      `json:"proofs,omitempty"`
+	Dir         string               `json:"dir,omitempty"`
+}
+
+// unwrapReport is the JSON output of unwrap.
+type unwrapReport struct {
+	Pooled       bool          `json:"pooled"`
+	RequestID    uint64        `json:"request_id,omitempty"`
+	Transactions []unwrappedTx `json:"transactions"`
+}
+
+func runWrap(args []string) error {
+	fs := flag.NewFlagSet("wrap", flag.ExitOnError)
+	var txs listFlag
+	fs.Var(&txs, "tx", "signed transaction, as a file or 0x-prefixed hex (repeat for a --pooled message)")
+	pooled := fs.Bool("pooled", false, "write an eth/68 PooledTransactions message instead of a single transaction")
+	requestID := fs.Uint64("request-id", 0, "request ID of the --pooled message")
+	out := fs.String("out", "", "write the hex encoding to this file")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc wrap --tx <file|hex> [--pooled [--request-id n]] [flags] [<blob>...]")
+		fmt.Fprintln(fs.Output(), "Attaches the blobs, with their commitments and proofs, to a signed blob transaction in the network encoding.")
+		fmt.Fprintln(fs.Output(), "Without blobs, each transaction must already carry its sidecar, which is checked.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if len(txs) == 0 {
+		return errors.New("--tx is required")
+	}
+	if len(txs) > 1 && !*pooled {
+		return errors.New("several --tx need --pooled")
+	}
+	if len(txs) > 1 && fs.NArg() > 0 {
+		return errors.New("blob files can only be given with a single --tx")
+	}
+
+	wrapped := make([]*types.Transaction, len(txs))
+	for i, arg := range txs {
+		raw, err := readRawTx(arg)
+		if err != nil {
+			return err
+		}
+		signed := new(types.Transaction)
+		if err := signed.UnmarshalBinary(raw); err != nil {
+			return fmt.Errorf("%s: invalid transaction: %w", arg, err)
+		}
+		sidecar := signed.BlobTxSidecar()
+		if fs.NArg() > 0 {
+			blobs := make([]kzg4844.Blob, fs.NArg())
+			for j, path := range fs.Args() {
+				if blobs[j], err = readBlobFile(path); err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+			}
+			if sidecar, err = tx.NewSidecar(blobs); err != nil {
+				return err
+			}
+		}
+		if signed.Type() == types.BlobTxType && sidecar == nil {
+			return fmt.Errorf("%s: %w; give its blob files", arg, tx.ErrNoSidecar)
+		}
+		if signed.Type() != types.BlobTxType && *pooled {
+			wrapped[i] = signed
+			continue
+		}
+		if wrapped[i], err = tx.Wrap(signed, sidecar); err != nil {
+			return err
+		}
+	}
+
+	var (
+		enc []byte
+		err error
+	)
+	if *pooled {
+		enc, err = tx.EncodePooledTransactions(*requestID, wrapped)
+	} else {
+		enc, err = tx.MarshalNetwork(wrapped[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode: %w", err)
+	}
+
+	summaries := make([]*txSummary, len(wrapped))
+	for i, w := range wrapped {
+		summaries[i] = newTxSummary(w)
+		summaries[i].print(o)
+	}
+	if *pooled {
+		o.Printf("PooledTransactions (request %d, message code %#x): %d transactions, %d bytes\n", *requestID, tx.PooledTransactionsMsg, len(wrapped), len(enc))
+	} else {
+		o.Printf("Network Encoding: %d bytes\n", len(enc))
+	}
+	report := struct {
+		Transactions []*txSummary  `json:"transactions"`
+		Raw          hexutil.Bytes `json:"raw,omitempty"`
+	}{Transactions: summaries}
+	if *out != "" {
+		if err := writeOutput(*out, []byte(hexutil.Encode(enc)+"\n")); err != nil {
+			return err
+		}
+	} else {
+		o.Printf("Raw: %s\n", hexutil.Encode(enc))
+		report.Raw = enc
+	}
+	return o.emit(report)
+}
+
+func runUnwrap(args []string) error {
+	fs := flag.NewFlagSet("unwrap", flag.ExitOnError)
+	in := fs.String("in", "", "network-encoded blob transaction or PooledTransactions message, as a file or 0x-prefixed hex")
+	outDir := fs.String("out-dir", "", "write each transaction's canonical encoding and blob files to this directory")
+	o := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: blob-poc unwrap [--out-dir <dir>] [flags] <file|hex>")
+		fmt.Fprintln(fs.Output(), "Parses a network-encoded blob transaction or an eth/68 PooledTransactions message and verifies every sidecar.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	arg, err := inputPath(*in, fs.Args())
+	if err != nil {
+		return err
+	}
+	raw, err := readRawTx(arg)
+	if err != nil {
+		return err
+	}
+
+	// A typed transaction starts with its type byte, a message with the
+	// RLP list header of at least 0xc0.
+	var r unwrapReport
+	var txs []*types.Transaction
+	if len(raw) > 0 && raw[0] >= 0xc0 {
+		r.Pooled = true
+		if r.RequestID, txs, err = tx.DecodePooledTransactions(raw); err != nil {
+			return err
+		}
+		o.Printf("PooledTransactions (request %d): %d transactions\n", r.RequestID, len(txs))
+	} else {
+		t, err := tx.UnmarshalNetwork(raw)
+		if err != nil {
+			return err
+		}
+		txs = []*types.Transaction{t}
+	}
+
+	for i, t := range txs {
+		u := unwrappedTx{txSummary: newTxSummary(t), Nonce: t.Nonce()}
+		if sc := t.BlobTxSidecar(); sc != nil {
+			u.Commitments, u.Proofs = sc.Commitments, sc.Proofs
+		}
+		u.print(o)
+		if sc := t.BlobTxSidecar(); sc != nil {
+			o.Printf("✅ %d blobs match their versioned hashes and proofs\n", len(sc.Blobs))
+		}
+		if *outDir != "" {
+			u.Dir = *outDir
+			if len(txs) > 1 {
+				u.Dir = filepath.Join(*outDir, fmt.Sprintf("tx-%d", i))
+			}
+			if err := writeUnwrapped(u.Dir, t); err != nil {
+				return err
+			}
+			o.Printf("Wrote %s\n", u.Dir)
+		}
+		r.Transactions = append(r.Transactions, u)
+	}
+	return o.emit(r)
+}
+
+// writeUnwrapped writes the canonical encoding of t to dir/tx.hex, as tx
+// --no-sidecar would, and its blobs to dir/blob-NNNN.bin.
+func writeUnwrapped(dir string, t *types.Transaction) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	sc := t.BlobTxSidecar()
+	canonical, err := t.WithoutBlobTxSidecar().MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	if err := writeOutput(filepath.Join(dir, "tx.hex"), []byte(hexutil.Encode(canonical)+"\n")); err != nil {
+		return err
+	}
+	if sc == nil {
+		return nil
+	}
+	for i := range sc.Blobs {
+		if err := writeBinary(filepath.Join(dir, fmt.Sprintf("blob-%04d.bin", i)), sc.Blobs[i][:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readRawTx reads an encoded transaction or message: arg is 0x-prefixed hex,
+// or a file of hex text or raw bytes.
+func readRawTx(arg string) ([]byte, error) {
+	if strings.HasPrefix(arg, "0x") {
+		return hexutil.Decode(strings.TrimSpace(arg))
+	}
+	data, err := readInputFile(arg)
+	if err != nil {
+		return nil, err
+	}
+	if inputFormat == "" && blob.IsHexText(data) {
+		if data, err = decodeInput(formatHex, data); err != nil {
+			return nil, fmt.Errorf("%s: %w", arg, err)
+		}
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%s is empty", arg)
+	}
+	return data, nil
+}
@@ -0,0 +1,511 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+
+	"kzg-blob-poc/pkg/archive"
+	"kzg-blob-poc/pkg/batch"
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/cache"
+	"kzg-blob-poc/pkg/codec"
+	"kzg-blob-poc/pkg/encrypt"
+	"kzg-blob-poc/pkg/keys"
+	"kzg-blob-poc/pkg/manifest"
+	"kzg-blob-poc/pkg/progress"
+	"kzg-blob-poc/pkg/tx"
+)
+
+// inputPath resolves the input file from the --in flag or the first
+// positional argument.
+func inputPath(flagValue string, args []string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	return "", errors.New("no input file given")
+}
+
+// blobValidation is the result of checking one blob with --validate-only.
+type blobValidation struct {
+	File            string `json:"file"`
+	Valid           bool   `json:"valid"`
+	InvalidElements []int  `json:"invalid_elements,omitempty"`
+	Error           string `json:"error,omitempty"` // the blob could not be read
+}
+
+// validateBlobFile checks the field elements of the blob at path without
+// any KZG work. Only a failure to read the blob is returned as an error.
+func validateBlobFile(path string) (blobValidation, error) {
+	b, err := readBlobFile(path)
+	if err != nil {
+		return blobValidation{}, err
+	}
+	v := blobValidation{File: path, Valid: true}
+	var ferr *blob.FieldElementError
+	if errors.As(blob.ValidateBlob(&b), &ferr) {
+		v.Valid, v.InvalidElements = false, ferr.Indices
+	}
+	return v, nil
+}
+
+// runValidateOnly implements --validate-only for commands taking blob files:
+// it reports whether every field element of each blob is canonical, to path
+// or stdout, and fails with ErrInvalidFieldElement if any blob is not. A
+// single blob is reported as an object, several as a list. A blob that
+// cannot be read stops the run with failFast; otherwise it is reported
+// with the rest and the run fails with a *batch.FailedError.
+func runValidateOnly(o *output, path string, files []string, failFast bool) error {
+	var (
+		text    strings.Builder
+		results = make([]blobValidation, len(files))
+		failed  = &batch.FailedError{Total: len(files)}
+		invalid int
+	)
+	for i, file := range files {
+		v, err := validateBlobFile(file)
+		if err != nil {
+			if failFast {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+			results[i] = blobValidation{File: file, Error: err.Error()}
+			failed.Failures = append(failed.Failures, batch.NewFailure(i, file, err))
+			fmt.Fprintf(&text, "%s: %v\n", file, err)
+			continue
+		}
+		results[i] = v
+		if v.Valid {
+			fmt.Fprintf(&text, "%s: valid (%d canonical field elements)\n", file, blob.FieldElementsPerBlob)
+			continue
+		}
+		invalid++
+		ferr := &blob.FieldElementError{Indices: v.InvalidElements}
+		failed.Failures = append(failed.Failures, batch.NewFailure(i, file, ferr))
+		fmt.Fprintf(&text, "%s: %v\n", file, ferr)
+	}
+
+	var report any = results
+	if len(files) == 1 {
+		report = results[0]
+	}
+	if err := o.report(path, text.String(), report); err != nil {
+		return err
+	}
+	if len(failed.Failures) > invalid {
+		return failed
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%w: %d of %d blobs are invalid", blob.ErrInvalidFieldElement, invalid, len(files))
+	}
+	return nil
+}
+
+// parseHexFixed decodes an optionally 0x-prefixed hex string that must be
+// exactly size bytes long.
+func parseHexFixed(name, s string, size int) ([]byte, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if len(data) != size {
+		return nil, fmt.Errorf("invalid %s: got %d bytes, want %d", name, len(data), size)
+	}
+	return data, nil
+}
+
+// parseHex32 decodes an optionally 0x-prefixed hex string of at most 32
+// bytes, left-padding it with zeros to a big-endian 32-byte value.
+func parseHex32(name, s string) ([32]byte, error) {
+	var out [32]byte
+	s = strings.TrimPrefix(s, "0x")
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return out, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if len(data) > len(out) {
+		return out, fmt.Errorf("invalid %s: got %d bytes, max %d", name, len(data), len(out))
+	}
+	copy(out[len(out)-len(data):], data)
+	return out, nil
+}
+
+// writeOutput writes data to path, or to stdout when path is empty.
+func writeOutput(path string, data []byte) error {
+	if path == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// bigFlag is a flag.Value holding an arbitrary-precision integer, accepting
+// decimal or 0x-prefixed hex input.
+type bigFlag struct{ *big.Int }
+
+func newBigFlag(v int64) *bigFlag { return &bigFlag{big.NewInt(v)} }
+
+func (f *bigFlag) String() string {
+	if f == nil || f.Int == nil {
+		return "0"
+	}
+	return f.Int.String()
+}
+
+func (f *bigFlag) Set(s string) error {
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return fmt.Errorf("invalid integer %q", s)
+	}
+	f.Int = v
+	return nil
+}
+
+// listFlag is a flag.Value collecting the values of a flag given several
+// times.
+type listFlag []string
+
+func (f *listFlag) String() string { return strings.Join(*f, " ") }
+
+func (f *listFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+// ensureHexPrefix adds a 0x prefix so hexutil accepts bare hex input.
+func ensureHexPrefix(s string) string {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return s
+	}
+	return "0x" + s
+}
+
+// formatGwei renders a wei amount in gwei without losing precision.
+func formatGwei(wei *big.Int) string {
+	gwei := new(big.Rat).SetFrac(wei, big.NewInt(params.GWei))
+	s := gwei.FloatString(9)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// hashFlags selects the versioned hash scheme, for research forks that
+// experiment with other versions or hash functions.
+type hashFlags struct {
+	version uint
+	scheme  string
+}
+
+// addHashFlags registers --hash-version and --hash on fs.
+func addHashFlags(fs *flag.FlagSet) *hashFlags {
+	h := new(hashFlags)
+	fs.UintVar(&h.version, "hash-version", blob.VersionKZG, "version byte of the versioned hash")
+	fs.StringVar(&h.scheme, "hash", "sha256", "hash function of the versioned hash: sha256, keccak256 or sha3-256")
+	return h
+}
+
+// versionedHash computes the versioned hash of commitment with the selected
+// scheme.
+func (h *hashFlags) versionedHash(commitment kzg4844.Commitment) (common.Hash, error) {
+	if h.version > 0xff {
+		return common.Hash{}, fmt.Errorf("invalid --hash-version %d: must fit in a byte", h.version)
+	}
+	hasher, err := blob.NewHasher(h.scheme)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return blob.CalcBlobHash(byte(h.version), hasher, commitment)
+}
+
+// keyFlags selects where the signing key is loaded from. Keys are only read
+// from files, or kept in an external signer, so they never end up in shell
+// history.
+type keyFlags struct {
+	fs            *flag.FlagSet
+	keyFile       string
+	keystore      string
+	passwordFile  string
+	mnemonicFile  string
+	hdPath        string
+	signerURL     string
+	signerType    string
+	signerAccount string
+}
+
+// addKeyFlags registers the signing key flags on fs.
+func addKeyFlags(fs *flag.FlagSet) *keyFlags {
+	k := &keyFlags{fs: fs}
+	fs.StringVar(&k.keyFile, "key-file", cfg.KeyFile, "file containing the hex-encoded signing key")
+	fs.StringVar(&k.keystore, "keystore", cfg.Keystore, "geth encrypted keystore file holding the signing key")
+	fs.StringVar(&k.passwordFile, "password-file", cfg.PasswordFile, "file containing the keystore password or mnemonic passphrase")
+	fs.StringVar(&k.mnemonicFile, "mnemonic-file", cfg.MnemonicFile, "file containing a BIP-39 mnemonic to derive the signing key from")
+	hdPath := cmp.Or(cfg.HDPath, keys.DefaultHDPath)
+	fs.StringVar(&k.hdPath, "hd-path", hdPath, "BIP-32 derivation path used with --mnemonic-file")
+	fs.StringVar(&k.signerURL, "signer-url", cfg.SignerURL, "sign with a remote signer at this URL instead of a local key")
+	signerType := cmp.Or(cfg.SignerType, tx.RemoteClef)
+	fs.StringVar(&k.signerType, "signer-type", signerType, "remote signer API: clef or web3signer")
+	fs.StringVar(&k.signerAccount, "signer-account", cfg.SignerAccount, "account to sign with at the remote signer (default: its only account)")
+	return k
+}
+
+// source returns the name of the one selected key source flag. Sources
+// given on the command line take precedence over those from the config
+// file.
+func (k *keyFlags) source() (string, error) {
+	sources := map[string]string{
+		"key-file":      k.keyFile,
+		"keystore":      k.keystore,
+		"mnemonic-file": k.mnemonicFile,
+		"signer-url":    k.signerURL,
+	}
+	explicit := map[string]bool{}
+	k.fs.Visit(func(f *flag.Flag) {
+		if _, ok := sources[f.Name]; ok {
+			explicit[f.Name] = true
+		}
+	})
+	var selected []string
+	for name, value := range sources {
+		if value != "" && (len(explicit) == 0 || explicit[name]) {
+			selected = append(selected, name)
+		}
+	}
+	switch len(selected) {
+	case 0:
+		return "", errors.New("one of --key-file, --keystore, --mnemonic-file or --signer-url is required")
+	case 1:
+		return selected[0], nil
+	default:
+		slices.Sort(selected)
+		return "", fmt.Errorf("only one key source may be given, have --%s", strings.Join(selected, " and --"))
+	}
+}
+
+// signer returns a signer for the selected key source.
+func (k *keyFlags) signer(ctx context.Context) (tx.Signer, error) {
+	source, err := k.source()
+	if err != nil {
+		return nil, err
+	}
+	if source == "signer-url" {
+		var account common.Address
+		if k.signerAccount != "" {
+			if !common.IsHexAddress(k.signerAccount) {
+				return nil, fmt.Errorf("invalid --signer-account %q", k.signerAccount)
+			}
+			account = common.HexToAddress(k.signerAccount)
+		}
+		return tx.DialRemoteSigner(ctx, k.signerType, k.signerURL, account)
+	}
+	key, err := k.localKey(source)
+	if err != nil {
+		return nil, err
+	}
+	return tx.NewKeySigner(key), nil
+}
+
+// privateKey returns the key of the selected key source, which must hold
+// the key locally rather than in a remote signer. what says what the key
+// is for, such as "signing a manifest".
+func (k *keyFlags) privateKey(what string) (*ecdsa.PrivateKey, error) {
+	source, err := k.source()
+	if err != nil {
+		return nil, err
+	}
+	if source == "signer-url" {
+		return nil, fmt.Errorf("%s needs a local key: --signer-url only signs transactions", what)
+	}
+	return k.localKey(source)
+}
+
+// localKey loads the key of source, a key source flag other than
+// signer-url.
+func (k *keyFlags) localKey(source string) (*ecdsa.PrivateKey, error) {
+	var password string
+	if k.passwordFile != "" {
+		var err error
+		if password, err = keys.ReadSecret(k.passwordFile); err != nil {
+			return nil, fmt.Errorf("failed to read password file: %w", err)
+		}
+	}
+	var (
+		key *ecdsa.PrivateKey
+		err error
+	)
+	switch source {
+	case "keystore":
+		if k.passwordFile == "" {
+			return nil, errors.New("--password-file is required with --keystore")
+		}
+		key, err = keys.FromKeystore(k.keystore, password)
+	case "mnemonic-file":
+		key, err = keys.FromMnemonicFile(k.mnemonicFile, password, k.hdPath)
+	default:
+		key, err = keys.FromHexFile(k.keyFile)
+	}
+	return key, err
+}
+
+// encryptionFlags are the flags selecting the key or passphrase of an
+// encrypted payload.
+type encryptionFlags struct {
+	keyFile        string
+	passphraseFile string
+}
+
+// addEncryptionFlags registers the encryption flags on fs. verb says what
+// the secret is used for, such as "encrypt the payload".
+func addEncryptionFlags(fs *flag.FlagSet, verb string) *encryptionFlags {
+	e := new(encryptionFlags)
+	fs.StringVar(&e.keyFile, "encryption-key-file", "", verb+" with the hex-encoded 32-byte AES-256-GCM key in this file")
+	fs.StringVar(&e.passphraseFile, "passphrase-file", "", verb+" with a key derived from the passphrase in this file")
+	return e
+}
+
+// secret returns the selected secret, or nil if no flag was given.
+func (e *encryptionFlags) secret() (*encrypt.Secret, error) {
+	switch {
+	case e.keyFile != "" && e.passphraseFile != "":
+		return nil, errors.New("only one of --encryption-key-file and --passphrase-file may be given")
+	case e.keyFile != "":
+		return encrypt.ReadKeyFile(e.keyFile)
+	case e.passphraseFile != "":
+		passphrase, err := keys.ReadSecret(e.passphraseFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase file: %w", err)
+		}
+		return encrypt.NewPassphrase(passphrase)
+	}
+	return nil, nil
+}
+
+// addCacheFlag registers the --cache-dir flag on fs.
+func addCacheFlag(fs *flag.FlagSet) *string {
+	return fs.String("cache-dir", cfg.CacheDir, "reuse and store KZG artifacts in this directory, keyed by blob SHA-256 (empty disables)")
+}
+
+// openCache opens the artifact cache in dir, or returns nil when dir is
+// empty, which caches nothing. Entries only hold for the trusted setup they
+// were computed with, so those of a setup other than the ceremony's are
+// kept apart, in <dir>/setup-<setup hash>.
+func openCache(dir string) (*cache.Cache, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if loadedSetup.path != "" && loadedSetup.hash != blob.CeremonySetupHash {
+		dir = filepath.Join(dir, "setup-"+hex.EncodeToString(loadedSetup.hash[:]))
+	}
+	return cache.Open(dir)
+}
+
+// addNoProofFlag registers the --no-proof flag on fs.
+func addNoProofFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("no-proof", false, "compute only commitments and versioned hashes, skipping the proofs (about half the work)")
+}
+
+// addBlobTimeoutFlag registers the --blob-timeout flag on fs, defaulting to
+// blob_timeout from the config, which Load has already validated.
+func addBlobTimeoutFlag(fs *flag.FlagSet) *time.Duration {
+	def, _ := time.ParseDuration(cfg.BlobTimeout)
+	return fs.Duration("blob-timeout", def, "give up on a blob whose KZG commitment and proof take longer than this (0 for no limit)")
+}
+
+// addArchiveFlag registers the --archive flag on fs.
+func addArchiveFlag(fs *flag.FlagSet, usage string) *string {
+	return fs.String("archive", cfg.ArchiveURL, usage+" (s3://bucket[/prefix][?endpoint=url&region=r], ipfs://host:port[?index=file], or a directory)")
+}
+
+// openArchive opens the blob archive at url, or returns nil when url is
+// empty. S3 requests use netPolicy.
+func openArchive(url string) (*archive.Archive, error) {
+	if url == "" {
+		return nil, nil
+	}
+	store, err := archive.Open(url, netPolicy.Client())
+	if err != nil {
+		return nil, err
+	}
+	return archive.New(store), nil
+}
+
+// addCodecFlag registers --codec on a command that encodes a payload into
+// blobs.
+func addCodecFlag(fs *flag.FlagSet) *string {
+	return fs.String("codec", codec.Raw, "encode the payload with this codec: "+strings.Join(codec.Names(), ", "))
+}
+
+// openCodec returns the codec named name, or nil for the raw codec, which
+// commands stream through splitPayload instead of encoding in memory.
+func openCodec(name string) (codec.Codec, error) {
+	c, err := codec.Lookup(name)
+	if err != nil || name == codec.Raw {
+		return nil, err
+	}
+	return c, nil
+}
+
+// progressFlags are the --quiet and --progress-json flags of commands that
+// process many blobs.
+type progressFlags struct {
+	quiet bool
+	json  bool
+}
+
+// addProgressFlags registers the --quiet and --progress-json flags on fs.
+func addProgressFlags(fs *flag.FlagSet) *progressFlags {
+	p := new(progressFlags)
+	fs.BoolVar(&p.quiet, "quiet", false, "do not report progress")
+	fs.BoolVar(&p.json, "progress-json", false, "report progress on stderr as one JSON object per line, even when stderr is not a terminal")
+	return p
+}
+
+// start returns a tracker reporting the progress of operation on stderr,
+// or nil when progress is off: with --quiet, or when stderr is not a
+// terminal and --progress-json is not set.
+func (p *progressFlags) start(operation string, total int64) *progress.Tracker {
+	switch {
+	case p.quiet:
+		return nil
+	case p.json:
+		return progress.New(os.Stderr, progress.JSON, operation, total)
+	case isTerminal(os.Stderr):
+		return progress.New(os.Stderr, progress.Text, operation, total)
+	}
+	return nil
+}
+
+// isTerminal reports whether f is a character device, such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// toolInfo identifies this build in the manifests it writes. The version is
+// the module version stamped by the go command, such as a tag or a
+// pseudo-version, or "(devel)".
+func toolInfo() manifest.Tool {
+	t := manifest.Tool{Name: "blob-poc", Version: "(devel)"}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		t.Version = info.Main.Version
+	}
+	return t
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/manifest"
+	"kzg-blob-poc/pkg/tx"
+)
+
+// Exit codes. Usage errors exit with 2, as the flag package does.
+const (
+	exitFailure             = 1
+	exitUsage               = 2
+	exitVerificationFailed  = 3
+	exitTooLarge            = 4
+	exitInvalidFieldElement = 5
+	exitInvalidEncoding     = 6
+	exitFeeCap              = 7
+)
+
+// exitCode maps the error returned by a command to the process exit code,
+// so scripts can tell a failed verification from a malformed input.
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, blob.ErrProofMismatch),
+		errors.Is(err, blob.ErrCommitmentMismatch),
+		errors.Is(err, blob.ErrVersionedHashMismatch),
+		errors.Is(err, blob.ErrEquivalenceMismatch),
+		errors.Is(err, blob.ErrEvaluationMismatch),
+		errors.Is(err, blob.ErrSetupMismatch),
+		errors.Is(err, blob.ErrBlobMismatch),
+		errors.Is(err, manifest.ErrPayloadMismatch),
+		errors.Is(err, manifest.ErrChunkMismatch),
+		errors.Is(err, manifest.ErrSignatureMismatch),
+		errors.Is(err, manifest.ErrUnsigned):
+		return exitVerificationFailed
+	case errors.Is(err, blob.ErrBlobTooLarge):
+		return exitTooLarge
+	case errors.Is(err, blob.ErrInvalidFieldElement):
+		return exitInvalidFieldElement
+	case errors.Is(err, blob.ErrNotFramed), errors.Is(err, blob.ErrInvalidFrame):
+		return exitInvalidEncoding
+	case errors.Is(err, tx.ErrFeeCap):
+		return exitFeeCap
+	default:
+		return exitFailure
+	}
+}
@@ -0,0 +1,38 @@
+// Package atomicfile writes files so that readers, and a crash or
+// interruption, see either the old contents or the new ones, never a
+// partial file.
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Write writes data to path through a temporary file in the same
+// directory, synced before it is renamed over path, so that an
+// interruption leaves the previous file intact and a crash after Write
+// returns does not leave an empty one. The file is readable by everyone,
+// mode 0644 like the other outputs, rather than the temporary file's 0600.
+func Write(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// Formats of binary input and output: blobs, payloads and sidecars.
+const (
+	formatRaw    = "raw"    // input as is
+	formatBinary = "binary" // output as is
+	formatHex    = "hex"    // 0x-prefixed hex text, whitespace ignored on input
+	formatBase64 = "base64" // standard base64, whitespace ignored on input
+)
+
+// inputFormat and outputFormat are set with --input-format and
+// --output-format. An empty inputFormat reads payloads and sidecars as raw
+// bytes and detects the format of blob files; an empty outputFormat writes
+// binary.
+var inputFormat, outputFormat string
+
+// setupFormats checks and sets the input and output formats.
+func setupFormats(in, out string) error {
+	switch in = strings.ToLower(in); in {
+	case "", formatRaw, formatHex, formatBase64:
+	default:
+		return fmt.Errorf("invalid input format %q (want %s, %s or %s)", in, formatRaw, formatHex, formatBase64)
+	}
+	switch out = strings.ToLower(out); out {
+	case "", formatBinary, formatHex, formatBase64:
+	default:
+		return fmt.Errorf("invalid output format %q (want %s, %s or %s)", out, formatBinary, formatHex, formatBase64)
+	}
+	inputFormat, outputFormat = in, out
+	return nil
+}
+
+// decodeInput decodes the contents of an input file in format.
+func decodeInput(format string, data []byte) ([]byte, error) {
+	switch format {
+	case formatHex:
+		text := strings.TrimPrefix(strings.Join(strings.Fields(string(data)), ""), "0x")
+		out, err := hex.DecodeString(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex input: %w", err)
+		}
+		return out, nil
+	case formatBase64:
+		out, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(data)), ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 input: %w", err)
+		}
+		return out, nil
+	default:
+		return data, nil
+	}
+}
+
+// readInputFile reads a payload or sidecar file in the input format.
+func readInputFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeInput(inputFormat, data)
+}
+
+// inputReader decodes a payload stream in the input format.
+func inputReader(r io.Reader) io.Reader {
+	switch inputFormat {
+	case formatHex:
+		br := bufio.NewReader(spaceFilter{r})
+		if prefix, _ := br.Peek(2); string(prefix) == "0x" {
+			br.Discard(2)
+		}
+		return hex.NewDecoder(br)
+	case formatBase64:
+		return base64.NewDecoder(base64.StdEncoding, spaceFilter{r})
+	default:
+		return r
+	}
+}
+
+// spaceFilter drops ASCII whitespace from a stream.
+type spaceFilter struct{ r io.Reader }
+
+func (f spaceFilter) Read(p []byte) (int, error) {
+	for {
+		n, err := f.r.Read(p)
+		kept := p[:0]
+		for _, c := range p[:n] {
+			switch c {
+			case ' ', '\n', '\r', '\t':
+			default:
+				kept = append(kept, c)
+			}
+		}
+		if len(kept) > 0 || err != nil {
+			return len(kept), err
+		}
+	}
+}
+
+// encodeOutput encodes binary data in the output format. Text formats end
+// with a newline.
+func encodeOutput(data []byte) []byte {
+	switch outputFormat {
+	case formatHex:
+		out := make([]byte, 2+hex.EncodedLen(len(data))+1)
+		copy(out, "0x")
+		hex.Encode(out[2:], data)
+		out[len(out)-1] = '\n'
+		return out
+	case formatBase64:
+		out := make([]byte, base64.StdEncoding.EncodedLen(len(data))+1)
+		base64.StdEncoding.Encode(out, data)
+		out[len(out)-1] = '\n'
+		return out
+	default:
+		return data
+	}
+}
+
+// writeBinary writes a blob, payload or sidecar to path, or stdout when path
+// is empty, in the output format.
+func writeBinary(path string, data []byte) error {
+	return writeOutput(path, encodeOutput(data))
+}
+
+// readBlobFile loads a blob file in the input format. Without one, the file
+// is read as raw binary if it has exactly blob.Size bytes and is not hex
+// text, else as hex, or as base64 if it decodes to exactly blob.Size bytes.
+// Hex blobs shorter than blob.Size are zero-padded.
+func readBlobFile(path string) (kzg4844.Blob, error) {
+	var b kzg4844.Blob
+	err := readBlobFileInto(path, &b)
+	return b, err
+}
+
+// readBlobFileInto is readBlobFile decoding straight into dst, such as a
+// pooled blob, so the only allocation is the file's contents.
+func readBlobFileInto(path string, dst *kzg4844.Blob) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	format := inputFormat
+	if format == "" {
+		format = blobFormat(data)
+	}
+	if format == formatHex {
+		// The file's buffer is ours, so whitespace is dropped in place.
+		return blob.SetBlobHex(dst, slices.DeleteFunc(data, isSpace))
+	}
+	if data, err = decodeInput(format, data); err != nil {
+		return err
+	}
+	return blob.SetBlobBytes(dst, data)
+}
+
+// isSpace reports whether c is ASCII whitespace, as strings.Fields splits
+// hex text on.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// blobFormat detects the format of a blob file. Binary data longer than a
+// blob is taken as raw, so it is reported as too large rather than as
+// invalid hex.
+func blobFormat(data []byte) string {
+	switch {
+	case len(data) == blob.Size && !blob.IsHexText(data):
+		return formatRaw
+	case blob.IsHexText(data):
+		return formatHex
+	}
+	if b, err := decodeInput(formatBase64, data); err == nil && len(b) == blob.Size {
+		return formatBase64
+	}
+	if len(data) > blob.Size {
+		return formatRaw
+	}
+	return formatHex
+}
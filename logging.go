@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Log formats accepted by --log-format.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// globalFlags are the flags given before the command name. They apply to
+// every command and override the config file and environment.
+type globalFlags struct {
+	logLevel       string
+	logFormat      string
+	requestTimeout string
+	maxRetries     string
+	retryBackoff   string
+	dbPath         string
+	network        string
+	inputFormat    string
+	outputFormat   string
+	setup          string
+	setupHash      string
+}
+
+// parseGlobalFlags parses the flags before the command name and returns the
+// remaining arguments, starting with the command.
+func parseGlobalFlags(args []string) (*globalFlags, []string, error) {
+	g := new(globalFlags)
+	fs := flag.NewFlagSet("blob-poc", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.StringVar(&g.logLevel, "log-level", "", "log level: debug, info, warn or error (default info)")
+	fs.StringVar(&g.logFormat, "log-format", "", "log format: text or json (default text)")
+	fs.StringVar(&g.requestTimeout, "request-timeout", "", "timeout of each attempt of an RPC or beacon API request (default 30s)")
+	fs.StringVar(&g.maxRetries, "max-retries", "", "retries of a failed RPC or beacon API request (default 3)")
+	fs.StringVar(&g.retryBackoff, "retry-backoff", "", "delay before the first retry, doubling up to 10s, with jitter (default 250ms)")
+	fs.StringVar(&g.dbPath, "db", "", "record every processed blob in this SQLite database")
+	fs.StringVar(&g.network, "network", "", "target network: mainnet, sepolia, holesky, hoodi or devnet")
+	fs.StringVar(&g.inputFormat, "input-format", "", "format of blob, payload and sidecar files read: raw, hex or base64 (default: raw, detected for blobs)")
+	fs.StringVar(&g.outputFormat, "output-format", "", "format of blob, payload and sidecar files written: binary, hex or base64 (default binary)")
+	fs.StringVar(&g.setup, "trusted-setup", "", "KZG trusted setup file to use instead of the embedded ceremony output")
+	fs.StringVar(&g.setupHash, "trusted-setup-hash", "", "setup hash --trusted-setup must have (default: the ceremony output's)")
+	fs.Usage = usage
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	return g, fs.Args(), nil
+}
+
+// setupLogging installs the default slog logger, writing to stderr at level
+// in format. Empty values select info and text.
+func setupLogging(level, format string) error {
+	var lvl slog.Level
+	if level != "" {
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			return fmt.Errorf("invalid log level %q (want debug, info, warn or error)", level)
+		}
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "", logFormatText:
+		h = slog.NewTextHandler(os.Stderr, opts)
+	case logFormatJSON:
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("invalid log format %q (want %s or %s)", format, logFormatText, logFormatJSON)
+	}
+	slog.SetDefault(slog.New(h))
+	return nil
+}
@@ -1,149 +1,198 @@
 package main
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"cmp"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"os"
-	"strings"
 
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
-	"github.com/ethereum/go-ethereum/common"
-)
 
-func main() {
-	// Example blob data (you can replace this with your own)
-	// Simple example: "Hello World" padded with zeros
-	blobDataHex := "48656c6c6f20576f726c64" // "Hello World" in hex
+	"kzg-blob-poc/pkg/config"
+)
 
-	fmt.Println("KZG Blob Commitment and Proof Generation PoC")
-	fmt.Println(strings.Repeat("=", 50))
+// command is a single blob-poc subcommand.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
 
-	// Option 1: Parse blob data from hex string (current approach)
-	// blob, err := createBlobFromHex(blobDataHex)
-	
-	// Option 2: Load blob data from file
-	blob, err := createBlobFromFile("blob_data.txt")
-	if err != nil {
-		log.Printf("Failed to load from file, falling back to hex string: %v", err)
-		// Fall back to hex string if file doesn't exist
-		blob, err = createBlobFromHex(blobDataHex)
-		if err != nil {
-			log.Fatalf("Failed to create blob: %v", err)
-		}
-	}
+// commands lists every subcommand in the order shown by usage.
+var commands = []command{
+	{"commit", "Compute the KZG commitment and versioned hash of a blob", runCommit},
+	{"prove", "Compute the KZG commitment, proof and versioned hash of a blob", runProve},
+	{"verify", "Verify a KZG proof against a blob and commitment", runVerify},
+	{"batch", "Compute artifacts for a directory or manifest of blobs in parallel", runBatch},
+	{"verify-batch", "Verify many blob proofs at once from a JSON manifest", runVerifyBatch},
+	{"verify-manifest", "Audit a set of blob files against an artifact manifest and its payload hash", runVerifyManifest},
+	{"sign-manifest", "Sign an artifact manifest with the publisher's key", runSignManifest},
+	{"verify-manifest-signature", "Check who signed an artifact manifest and that it is unchanged", runVerifyManifestSignature},
+	{"prove-chunk", "Prove that one blob's chunk belongs to a payload's chunk Merkle root", runProveChunk},
+	{"verify-chunk", "Check a blob against a chunk proof and a trusted chunk root", runVerifyChunk},
+	{"spec-test", "Run the consensus-spec / c-kzg-4844 KZG reference test vectors", runSpecTest},
+	{"verify-setup", "Check that a KZG trusted setup is the Ethereum ceremony output, or has a pinned hash", runVerifySetup},
+	{"bench", "Measure KZG commitments, proofs and verifications per second on this machine", runBench},
+	{"prove-point", "Compute a KZG proof of the blob polynomial at an evaluation point", runProvePoint},
+	{"verify-point", "Verify a claimed evaluation y at point z against a commitment", runVerifyPoint},
+	{"evaluate", "Evaluate the blob polynomial at a point, without a proof, to check a claimed y", runEvaluate},
+	{"locate", "Find the field element and evaluation point holding a payload byte, and prove it", runLocate},
+	{"prove-inclusion", "Prove a blob transaction is in the chain up to a trusted block, with its blob artifacts", runProveInclusion},
+	{"verify-inclusion", "Check a blob transaction inclusion proof against a trusted block hash", runVerifyInclusion},
+	{"prove-equivalence", "Prove a payload's keccak256 hash and a blob commitment hold the same data", runProveEquivalence},
+	{"verify-equivalence", "Check an equivalence proof between a payload hash and a blob commitment", runVerifyEquivalence},
+	{"precompile-input", "Build the 192-byte point evaluation precompile input for a blob", runPrecompileInput},
+	{"precompile-verify", "Check a precompile input locally, mimicking the 0x0A precompile", runPrecompileVerify},
+	{"sol-fixture", "Emit a Solidity test fixture of blobs' versioned hashes, evaluations, proofs and precompile inputs", runSolFixture},
+	{"export-fixtures", "Write a deterministic JSON corpus of edge-case blobs with their commitments, proofs and openings", runExportFixtures},
+	{"cells", "Compute the EIP-7594 (PeerDAS) cells and cell proofs of a blob", runCells},
+	{"verify-cells", "Verify cell proofs against the blob commitment", runVerifyCells},
+	{"recover", "Reconstruct a blob from at least half of its cells", runRecover},
+	{"encode", "Pack a raw payload into a blob file", runEncode},
+	{"decode", "Recover the payload stored in a blob file", runDecode},
+	{"split", "Split a payload of any size across multiple blob files", runSplit},
+	{"join", "Reassemble a payload from the blob files written by split", runJoin},
+	{"gen", "Generate seeded random or patterned test blobs with their artifacts", runGen},
+	{"watch", "Encode, and optionally send, each new file dropped into a directory", runWatch},
+	{"sidecar", "Write a blob and its KZG artifacts as an SSZ BlobSidecar", runSidecar},
+	{"sidecar-read", "Load an SSZ BlobSidecar and verify its proof", runSidecarRead},
+	{"encode-call", "ABI-encode a contract call, such as registering blob versioned hashes with an inbox", runEncodeCall},
+	{"tx", "Build and sign a blob transaction carrying blob files", runTx},
+	{"wrap", "Attach blobs, commitments and proofs to a signed blob transaction in the network encoding", runWrap},
+	{"unwrap", "Parse and verify a network-encoded blob transaction or PooledTransactions message", runUnwrap},
+	{"tx-decode", "Print every field of a raw transaction, with or without its sidecar, and check the sidecar", runTxDecode},
+	{"tx-encode", "Encode a signed transaction from the JSON form tx-decode writes", runTxEncode},
+	{"send", "Sign, broadcast and confirm a blob transaction over JSON-RPC", runSend},
+	{"publish", "Encode a payload into blobs, send, confirm, verify and archive them in one go", runPublish},
+	{"receipt", "Report the blob and execution cost a mined blob transaction paid, per payload byte", runReceipt},
+	{"bump", "Replace a stuck pending blob transaction with higher fees", runBump},
+	{"fetch", "Download blobs from a beacon node and verify them against on-chain versioned hashes", runFetch},
+	{"resolve", "Find the blob transaction, block and sender that carried a versioned hash", runResolve},
+	{"follow", "Follow new beacon blocks and verify the blob sidecars of each as it arrives", runFollow},
+	{"mempool", "Watch pending blob transactions and report their blobs and fee bids as they arrive", runMempool},
+	{"fee", "Recommend a maxFeePerBlobGas from the current and recent blob base fees", runFee},
+	{"calc-blob-fee", "Compute the excess blob gas and blob base fees implied by raw header fields", runCalcBlobFee},
+	{"inspect", "List a blob's field elements, flagging non-canonical ones, its padding and framing header", runInspect},
+	{"diff", "Report the field elements at which two blobs differ", runDiff},
+	{"analyze", "Report blob utilization, blob gas and cost for a payload", runAnalyze},
+	{"networks", "List the known networks with their chain IDs, blob limits and endpoints", runNetworks},
+	{"simulate-cost", "Replay a payload's blob cost over recent blocks and report min, median and p95", runSimulateCost},
+	{"stats", "Report the blob usage, blob gas against the target, fees and top senders of a block range", runStats},
+	{"archive-put", "Store blobs and their KZG artifacts in an S3 or directory archive", runArchivePut},
+	{"archive-get", "Retrieve and verify an archived blob by its versioned hash", runArchiveGet},
+	{"archive-list", "List the versioned hashes of the archived blobs", runArchiveList},
+	{"db-list", "List the blobs recorded in the blob database", runDBList},
+	{"db-show", "Show a recorded blob and the transactions that carried it", runDBShow},
+	{"db-search", "Find recorded blobs by payload, transaction, block or commitment", runDBSearch},
+	{"serve", "Serve commit, prove, verify and encode over an HTTP JSON API", runServe},
+	{"shell", "Work on named in-memory blobs at an interactive prompt or from a script", runShell},
+}
 
-	fmt.Printf("Blob data size: %d bytes\n", len(blob[:]))
-	fmt.Printf("First 32 bytes: %x\n", blob[:32])
+// cfg holds the defaults loaded from the config file and environment. The
+// commands use its values as flag defaults, so flags always win.
+var cfg = new(config.Config)
 
-	// Generate KZG commitment
-	commitment, err := kzg4844.BlobToCommitment(&blob)
-	if err != nil {
-		log.Fatalf("Failed to generate KZG commitment: %v", err)
+func main() {
+	global, args, err := parseGlobalFlags(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
 	}
-
-	fmt.Printf("KZG Commitment (48 bytes): %x\n", commitment[:])
-
-	// Generate KZG proof
-	proof, err := kzg4844.ComputeBlobProof(&blob, commitment)
 	if err != nil {
-		log.Fatalf("Failed to generate KZG proof: %v", err)
+		os.Exit(exitUsage)
 	}
-
-	fmt.Printf("KZG Proof (48 bytes): %x\n", proof[:])
-
-	// Compute versioned hash (blob hash)
-	versionedHash := computeVersionedHash(commitment)
-	fmt.Printf("Versioned Hash (blob hash): %x\n", versionedHash[:])
-
-	// Verify the proof
-	err = kzg4844.VerifyBlobProof(&blob, commitment, proof)
-	if err != nil {
-		log.Fatalf("Proof verification failed: %v", err)
+	if len(args) == 0 {
+		usage()
+		os.Exit(exitUsage)
 	}
-
-	fmt.Println("✅ Proof verification successful!")
-
-	// Display summary
-	fmt.Println("\n" + strings.Repeat("=", 50))
-	fmt.Println("SUMMARY:")
-	fmt.Printf("• Blob Data: %d bytes\n", len(blob[:]))
-	fmt.Printf("• KZG Commitment: %x\n", commitment[:])
-	fmt.Printf("• KZG Proof: %x\n", proof[:])
-	fmt.Printf("• Versioned Hash: %x\n", versionedHash[:])
-	fmt.Println("• Verification: PASSED ✅")
-}
-
-// createBlobFromHex creates a KZG blob from hex string, padding to 131072 bytes if needed
-func createBlobFromHex(hexStr string) (kzg4844.Blob, error) {
-	var blob kzg4844.Blob
-
-	// Remove 0x prefix if present
-	if len(hexStr) >= 2 && hexStr[:2] == "0x" {
-		hexStr = hexStr[2:]
+	// Help needs no config or trusted setup, so a broken one does not hide it.
+	name := args[0]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		usage()
+		return
 	}
 
-	// Decode hex string
-	data, err := hex.DecodeString(hexStr)
-	if err != nil {
-		return blob, fmt.Errorf("failed to decode hex string: %w", err)
+	// Log with the flags alone until the config is loaded, so config errors
+	// already use the requested format.
+	if err := setupLogging(global.logLevel, global.logFormat); err != nil {
+		fail("blob-poc", err)
 	}
-
-	// Check if data is too large
-	if len(data) > len(blob) {
-		return blob, fmt.Errorf("blob data too large: %d bytes, max %d bytes", len(data), len(blob))
+	if cfg, err = config.Load(); err != nil {
+		fail("config", err)
+	}
+	if err := setupLogging(cmp.Or(global.logLevel, cfg.LogLevel), cmp.Or(global.logFormat, cfg.LogFormat)); err != nil {
+		fail("config", err)
+	}
+	if err := setupNetwork(cmp.Or(global.requestTimeout, cfg.RequestTimeout), cmp.Or(global.maxRetries, cfg.MaxRetries), cmp.Or(global.retryBackoff, cfg.RetryBackoff)); err != nil {
+		fail("config", err)
+	}
+	blobDBPath = cmp.Or(global.dbPath, cfg.DBPath)
+	if err := selectNetwork(cmp.Or(global.network, cfg.Network)); err != nil {
+		fail("config", err)
+	}
+	if err := setupFormats(cmp.Or(global.inputFormat, cfg.InputFormat), cmp.Or(global.outputFormat, cfg.OutputFormat)); err != nil {
+		fail("config", err)
+	}
+	if cfg.Backend == config.BackendCKZG {
+		if err := kzg4844.UseCKZG(true); err != nil {
+			fail("config", err)
+		}
+	}
+	if err := loadTrustedSetup(cmp.Or(global.setup, cfg.TrustedSetup), cmp.Or(global.setupHash, cfg.TrustedSetupHash)); err != nil {
+		fail("config", err)
 	}
 
-	// Copy data to blob (rest will be zero-padded automatically)
-	copy(blob[:], data)
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		slog.Debug("running command", "command", name, "args", args[1:])
+		if err := cmd.run(args[1:]); err != nil {
+			fail(name, err)
+		}
+		return
+	}
 
-	return blob, nil
+	slog.Error("unknown command", "command", name)
+	usage()
+	os.Exit(exitUsage)
 }
 
-// computeVersionedHash computes the versioned hash from KZG commitment
-// createBlobFromFile creates a KZG blob from a file containing hex data
-func createBlobFromFile(filename string) (kzg4844.Blob, error) {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return kzg4844.Blob{}, fmt.Errorf("failed to read file: %w", err)
-	}
-	
-	// Remove whitespace and newlines
-	hexStr := strings.ReplaceAll(string(data), "\n", "")
-	hexStr = strings.ReplaceAll(hexStr, " ", "")
-	hexStr = strings.ReplaceAll(hexStr, "\t", "")
-	
-	return createBlobFromHex(hexStr)
+// fail logs err and exits with the code exitCode assigns to it.
+func fail(name string, err error) {
+	code := exitCode(err)
+	slog.Error("command failed", "command", name, "err", err, "exit_code", code)
+	os.Exit(code)
 }
 
-// createBlobFromBytes creates a KZG blob from raw bytes
-func createBlobFromBytes(data []byte) (kzg4844.Blob, error) {
-	var blob kzg4844.Blob
-	
-	if len(data) > len(blob) {
-		return blob, fmt.Errorf("data too large: %d bytes, max %d bytes", len(data), len(blob))
+func usage() {
+	fmt.Fprintln(os.Stderr, "KZG Blob Commitment and Proof Generation PoC")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Usage: blob-poc [global flags] <command> [flags] [args]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Global flags:")
+	fmt.Fprintln(os.Stderr, "  --log-level level       debug, info, warn or error (default info)")
+	fmt.Fprintln(os.Stderr, "  --log-format format     text or json (default text)")
+	fmt.Fprintln(os.Stderr, "  --request-timeout d     timeout of each RPC or beacon API request attempt (default 30s)")
+	fmt.Fprintln(os.Stderr, "  --max-retries n         retries of a failed RPC or beacon API request (default 3)")
+	fmt.Fprintln(os.Stderr, "  --retry-backoff d       delay before the first retry, doubling up to 10s (default 250ms)")
+	fmt.Fprintln(os.Stderr, "  --db file               record every processed blob in this SQLite database")
+	fmt.Fprintln(os.Stderr, "  --network name          target network: mainnet, sepolia, holesky, hoodi or devnet")
+	fmt.Fprintln(os.Stderr, "  --input-format f        blob, payload and sidecar files read: raw, hex or base64 (default: raw, detected for blobs)")
+	fmt.Fprintln(os.Stderr, "  --output-format f       blob, payload and sidecar files written: binary, hex or base64 (default binary)")
+	fmt.Fprintln(os.Stderr, "  --trusted-setup file    KZG trusted setup to use instead of the embedded ceremony output")
+	fmt.Fprintln(os.Stderr, "  --trusted-setup-hash h  setup hash --trusted-setup must have (default: the ceremony output's)")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	width := 0
+	for _, cmd := range commands {
+		width = max(width, len(cmd.name))
 	}
-	
-	copy(blob[:], data)
-	return blob, nil
-}
-
-// createBlobFromReader creates a KZG blob from an io.Reader
-func createBlobFromReader(r io.Reader) (kzg4844.Blob, error) {
-	var blob kzg4844.Blob
-	n, err := io.ReadFull(r, blob[:])
-	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
-		return blob, fmt.Errorf("failed to read data: %w", err)
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-*s  %s\n", width, cmd.name, cmd.summary)
 	}
-	
-	fmt.Printf("Read %d bytes from reader\n", n)
-	return blob, nil
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run 'blob-poc <command> -h' for the flags of a command.")
 }
-
-// computeVersionedHash computes the versioned hash from KZG commitment
-func computeVersionedHash(commitment kzg4844.Commitment) common.Hash {
-	hasher := sha256.New()
-	return kzg4844.CalcBlobHashV1(hasher, &commitment)
-}
\ No newline at end of file
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"kzg-blob-poc/pkg/beacon"
+	"kzg-blob-poc/pkg/chain"
+	"kzg-blob-poc/pkg/failover"
+	"kzg-blob-poc/pkg/retry"
+	"kzg-blob-poc/pkg/tx"
+)
+
+// netPolicy is the timeout and retry policy of every HTTP request to an
+// execution, beacon or archive endpoint.
+var netPolicy = retry.DefaultPolicy
+
+// setupNetwork sets netPolicy from the global flags or config. Empty values
+// keep the defaults.
+func setupNetwork(timeout, retries, backoff string) error {
+	p := retry.DefaultPolicy
+	var err error
+	if timeout != "" {
+		if p.Timeout, err = time.ParseDuration(timeout); err != nil {
+			return fmt.Errorf("invalid request timeout %q: %w", timeout, err)
+		}
+	}
+	if retries != "" {
+		if p.MaxRetries, err = strconv.Atoi(retries); err != nil {
+			return fmt.Errorf("invalid max retries %q: %w", retries, err)
+		}
+	}
+	if backoff != "" {
+		if p.BaseDelay, err = time.ParseDuration(backoff); err != nil {
+			return fmt.Errorf("invalid retry backoff %q: %w", backoff, err)
+		}
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	netPolicy = p
+	return nil
+}
+
+// network is the chain profile selected with --network or network in the
+// config, or nil when none is.
+var network *chain.Profile
+
+// selectNetwork sets network to the profile named name and makes its
+// endpoints the defaults where the config sets none. Empty selects none.
+func selectNetwork(name string) error {
+	if name == "" {
+		return nil
+	}
+	p, err := chain.Lookup(name)
+	if err != nil {
+		return err
+	}
+	network = p
+	if cfg.RPCURL == "" {
+		cfg.RPCURL = p.RPCURL
+	}
+	if cfg.BeaconURL == "" {
+		cfg.BeaconURL = p.BeaconURL
+	}
+	return nil
+}
+
+// maxBlobsPerTx is the default of --max-blobs-per-tx: the block limit of
+// the latest fork of the selected network, or of Cancun.
+func maxBlobsPerTx() int {
+	if network != nil {
+		return network.Latest().Blobs.Max
+	}
+	return tx.DefaultMaxBlobsPerTx
+}
+
+// slotTime is the time between blocks, used to find the fork of the next
+// block.
+const slotTime = 12
+
+// headerFork returns the fork of the block with header h: by the fork list
+// of the selected network if it has one, and otherwise detected from h.
+func headerFork(h *types.Header) (chain.Fork, bool) {
+	if network != nil && len(network.Forks) > 0 {
+		return network.ForkAt(h.Time)
+	}
+	return chain.DetectFork(h)
+}
+
+// nextFork returns the fork of the block after head, like headerFork. Without
+// a fork list, it is taken to be that of head.
+func nextFork(head *types.Header) (chain.Fork, bool) {
+	if network != nil && len(network.Forks) > 0 {
+		return network.ForkAt(head.Time + slotTime)
+	}
+	return chain.DetectFork(head)
+}
+
+// pools holds the failover pool of each endpoint list, so that every
+// client of one list shares what is known of its endpoints' health.
+var (
+	poolsMu sync.Mutex
+	pools   = make(map[string]*failover.Pool)
+)
+
+// endpointPool returns the failover pool of list, a comma-separated list
+// of endpoints probed with probe, or nil if list has a single endpoint.
+func endpointPool(list string, probe failover.Probe) (*failover.Pool, error) {
+	if !strings.Contains(list, ",") {
+		return nil, nil
+	}
+	poolsMu.Lock()
+	defer poolsMu.Unlock()
+	if p, ok := pools[list]; ok {
+		return p, nil
+	}
+	var urls []string
+	for _, u := range strings.Split(list, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	p, err := failover.New(urls, probe)
+	if err != nil {
+		return nil, err
+	}
+	pools[list] = p
+	return p, nil
+}
+
+// endpointClient returns the URL to address requests to and the HTTP
+// client for list, an endpoint or a comma-separated list of endpoints to
+// fail over between, probed with probe. Each attempt of policy, if set,
+// may fail over.
+func endpointClient(list string, probe failover.Probe, policy *retry.Policy) (string, *http.Client, error) {
+	p, err := endpointPool(list, probe)
+	if err != nil {
+		return "", nil, err
+	}
+	var base http.RoundTripper = http.DefaultTransport
+	url := list
+	if p != nil {
+		base, url = p.Transport(base), p.URL()
+	}
+	if policy != nil {
+		base = policy.Transport(base)
+	}
+	return url, &http.Client{Transport: base}, nil
+}
+
+// dialRPC connects to a JSON-RPC endpoint, or to the first healthy one of
+// a comma-separated list. HTTP endpoints use netPolicy; WebSocket and IPC
+// connections are not retried.
+func dialRPC(ctx context.Context, list string) (*rpc.Client, error) {
+	url, hc, err := endpointClient(list, failover.ExecutionProbe, &netPolicy)
+	if err != nil {
+		return nil, err
+	}
+	client, err := rpc.DialOptions(ctx, url, rpc.WithHTTPClient(hc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", list, err)
+	}
+	return client, nil
+}
+
+// dialEth connects to an execution client, like dialRPC. With a network
+// selected, it also checks that the node is on that chain, so transactions
+// are never signed for the wrong one.
+func dialEth(ctx context.Context, url string) (*ethclient.Client, error) {
+	rpcClient, err := dialRPC(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	client := ethclient.NewClient(rpcClient)
+	if network != nil {
+		id, err := client.ChainID(ctx)
+		if err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to get chain ID of %s: %w", url, err)
+		}
+		if err := network.CheckChainID(id.Uint64()); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("%s: %w", url, err)
+		}
+	}
+	return client, nil
+}
+
+// newBeaconClient returns a beacon API client that uses netPolicy, for an
+// endpoint or a comma-separated list of endpoints to fail over between.
+func newBeaconClient(list string) (*beacon.Client, error) {
+	url, hc, err := endpointClient(list, failover.BeaconProbe, &netPolicy)
+	if err != nil {
+		return nil, err
+	}
+	return beacon.NewClient(url, hc), nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// output routes the results of a command. By default human-readable text
+// goes to stdout. With --json, a single JSON document is written to stdout
+// and the human-readable text moves to stderr so scripts can parse stdout.
+type output struct {
+	json bool
+}
+
+// addOutputFlags registers the --json flag on fs.
+func addOutputFlags(fs *flag.FlagSet) *output {
+	o := new(output)
+	fs.BoolVar(&o.json, "json", false, "emit machine-readable JSON on stdout (text goes to stderr)")
+	return o
+}
+
+// text returns the writer for human-readable text.
+func (o *output) text() io.Writer {
+	if o.json {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// Printf writes human-readable text.
+func (o *output) Printf(format string, args ...any) {
+	fmt.Fprintf(o.text(), format, args...)
+}
+
+// Println writes a line of human-readable text.
+func (o *output) Println(args ...any) {
+	fmt.Fprintln(o.text(), args...)
+}
+
+// emit writes v as JSON to stdout when --json is set.
+func (o *output) emit(v any) error {
+	if !o.json {
+		return nil
+	}
+	return writeJSON("", v)
+}
+
+// emitLine writes v as one line of JSON to stdout when --json is set, for
+// commands that report results as they go.
+func (o *output) emitLine(v any) error {
+	if !o.json {
+		return nil
+	}
+	return json.NewEncoder(os.Stdout).Encode(v)
+}
+
+// report writes the result of a command to path, or stdout when path is
+// empty: the text normally, or v as JSON with --json, in which case the text
+// still goes to stderr.
+func (o *output) report(path, text string, v any) error {
+	if !o.json {
+		return writeOutput(path, []byte(text))
+	}
+	fmt.Fprint(os.Stderr, text)
+	return writeJSON(path, v)
+}
+
+// writeJSON writes v as indented JSON to path, or stdout when path is empty.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutput(path, append(data, '\n'))
+}
@@ -0,0 +1,84 @@
+// Package arbitrum decodes the blobs the Arbitrum Nitro batch poster posts
+// to L1: the RLP string spread over a transaction's blobs, the sequencer
+// message header byte, its brotli compression and the segments inside.
+package arbitrum
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// maxBatchSize bounds the data of one batch read from blobs. A blob
+// transaction carries far less.
+const maxBatchSize = 1 << 24
+
+// DecodeBlobs recovers the sequencer message data carried by the blobs of
+// one batch. The batch poster RLP-encodes the data as a byte string and
+// packs it 31 bytes per field element, like blob.Pack, continuing from one
+// blob into the next.
+func DecodeBlobs(blobs []*kzg4844.Blob) ([]byte, error) {
+	var packed []byte
+	for i, b := range blobs {
+		p, err := blob.Unpack(b, blob.MaxPackedSize)
+		if err != nil {
+			return nil, fmt.Errorf("blob %d: %w", i, err)
+		}
+		packed = append(packed, p...)
+	}
+	var data []byte
+	if err := rlp.Decode(bytes.NewReader(packed), &data); err != nil {
+		return nil, fmt.Errorf("invalid Arbitrum blob data: %w", err)
+	}
+	return data, nil
+}
+
+// EncodeBlobs encodes data the way the Arbitrum batch poster does, the
+// inverse of DecodeBlobs.
+func EncodeBlobs(data []byte) ([]kzg4844.Blob, error) {
+	enc, err := rlp.EncodeToBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	var blobs []kzg4844.Blob
+	for len(enc) > 0 {
+		n := min(len(enc), blob.MaxPackedSize)
+		b, err := blob.Pack(enc[:n])
+		if err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, b)
+		enc = enc[n:]
+	}
+	return blobs, nil
+}
+
+// blobsNeeded reads the RLP string header at the start of a batch's first
+// blob and returns how many blobs the batch spans.
+func blobsNeeded(b *kzg4844.Blob) (int, error) {
+	head, err := blob.Unpack(b, 9)
+	if err != nil {
+		return 0, err
+	}
+	var size, header int
+	switch h := head[0]; {
+	case h >= 0x80 && h < 0xb8:
+		size, header = int(h-0x80), 1
+	case h >= 0xb8 && h < 0xc0:
+		n := int(h - 0xb7)
+		for _, c := range head[1 : 1+n] {
+			size = size<<8 | int(c)
+		}
+		header = 1 + n
+	default:
+		return 0, fmt.Errorf("invalid Arbitrum blob data: starts with 0x%02x, want an RLP string", h)
+	}
+	if size > maxBatchSize {
+		return 0, fmt.Errorf("invalid Arbitrum blob data: %d-byte batch exceeds %d bytes", size, maxBatchSize)
+	}
+	return (header + size + blob.MaxPackedSize - 1) / blob.MaxPackedSize, nil
+}
@@ -0,0 +1,66 @@
+package arbitrum
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// Result is the decoded content of a list of blobs.
+type Result struct {
+	Batches []Batch `json:"batches"`
+	// Skipped lists the blobs that do not start an Arbitrum batch, such as
+	// those of other rollups in the same block, with the reason.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// Batch is one sequencer batch found in the blobs.
+type Batch struct {
+	FirstBlob int `json:"first_blob"`
+	Blobs     int `json:"blobs"`
+	Size      int `json:"size"`
+	SequencerMessage
+}
+
+// Decode finds the batches in blobs, given in transaction order. A batch
+// spans the consecutive blobs its RLP length covers, so the blobs of
+// several batch transactions, or a whole block, can be decoded at once.
+// Blobs that do not start a batch are skipped, and Decode only fails if no
+// batch is found.
+func Decode(blobs []*kzg4844.Blob) (*Result, error) {
+	res := new(Result)
+	for i := 0; i < len(blobs); {
+		b, err := decodeBatch(blobs, i)
+		if err != nil {
+			res.Skipped = append(res.Skipped, fmt.Sprintf("blob %d: %v", i, err))
+			i++
+			continue
+		}
+		res.Batches = append(res.Batches, *b)
+		i += b.Blobs
+	}
+	if len(res.Batches) == 0 {
+		return nil, fmt.Errorf("no Arbitrum batches in %d blobs: %s", len(blobs), strings.Join(res.Skipped, "; "))
+	}
+	return res, nil
+}
+
+func decodeBatch(blobs []*kzg4844.Blob, first int) (*Batch, error) {
+	n, err := blobsNeeded(blobs[first])
+	if err != nil {
+		return nil, err
+	}
+	if first+n > len(blobs) {
+		return nil, fmt.Errorf("batch spans %d blobs, only %d left", n, len(blobs)-first)
+	}
+	data, err := DecodeBlobs(blobs[first : first+n])
+	if err != nil {
+		return nil, err
+	}
+	m, err := ParseSequencerData(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Batch{FirstBlob: first, Blobs: n, Size: len(data), SequencerMessage: *m}, nil
+}
@@ -0,0 +1,197 @@
+package arbitrum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/andybalholm/brotli"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Sequencer message header bytes and flags, the first byte of the batch
+// data.
+const (
+	BrotliMessageHeaderByte          = 0x00
+	DASMessageHeaderFlag             = 0x80
+	TreeDASMessageHeaderFlag         = 0x08
+	L1AuthenticatedMessageHeaderFlag = 0x40
+	ZeroheavyMessageHeaderFlag       = 0x20
+	BlobHashesHeaderFlag             = L1AuthenticatedMessageHeaderFlag | 0x10
+)
+
+const (
+	// MaxDecompressedLen caps the decompressed size of a sequencer message.
+	MaxDecompressedLen = 16 << 20
+
+	// MaxSegmentsPerSequencerMessage caps the segments of a message.
+	MaxSegmentsPerSequencerMessage = 100 * 1024
+
+	// maxL2MessageSize caps each message nested in an L2 batch message.
+	maxL2MessageSize = 256 * 1024
+
+	// maxNestingDepth caps how deep L2 batch messages nest.
+	maxNestingDepth = 16
+)
+
+// Segment kinds, the first byte of each segment.
+const (
+	SegmentKindL2Message            = 0
+	SegmentKindL2MessageBrotli      = 1
+	SegmentKindDelayedMessages      = 2
+	SegmentKindAdvanceTimestamp     = 3
+	SegmentKindAdvanceL1BlockNumber = 4
+)
+
+// L2 message kinds, the first byte of an L2 message.
+const (
+	l2MessageKindUnsignedUserTx = 0
+	l2MessageKindContractTx     = 1
+	l2MessageKindBatch          = 3
+	l2MessageKindSignedTx       = 4
+)
+
+// SequencerMessage is the metadata of a decoded sequencer message. The
+// 40-byte header with the batch's time and block bounds is not part of the
+// blob data; the sequencer inbox contract emits it on L1.
+type SequencerMessage struct {
+	HeaderByte       byte   `json:"header_byte"`
+	Compression      string `json:"compression"`
+	DecompressedSize int    `json:"decompressed_size"`
+	Segments         int    `json:"segments"`
+	L2Messages       int    `json:"l2_messages"`
+	Transactions     int    `json:"transactions"`
+	DelayedMessages  int    `json:"delayed_messages"`
+	// TimestampAdvance and L1BlockAdvance are the sums of the
+	// advance-timestamp and advance-L1-block-number segments.
+	TimestampAdvance uint64 `json:"timestamp_advance"`
+	L1BlockAdvance   uint64 `json:"l1_block_advance"`
+	// UnknownSegments counts segments of unknown kinds, which derivation
+	// skips.
+	UnknownSegments int `json:"unknown_segments,omitempty"`
+}
+
+// ParseSequencerData decodes the data of a batch: a header byte, then for
+// the brotli header byte a compressed RLP stream of segments. Data held in
+// an AnyTrust data availability committee is only referenced by a
+// certificate and cannot be decoded.
+func ParseSequencerData(data []byte) (*SequencerMessage, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty sequencer message")
+	}
+	m := &SequencerMessage{HeaderByte: data[0]}
+	switch h := data[0]; {
+	case h&(DASMessageHeaderFlag|TreeDASMessageHeaderFlag) != 0:
+		return nil, fmt.Errorf("header byte 0x%02x: batch data is held by an AnyTrust DAS", h)
+	case h != BrotliMessageHeaderByte:
+		return nil, fmt.Errorf("unsupported sequencer message header byte 0x%02x", h)
+	}
+	m.Compression = "brotli"
+
+	decompressed, err := io.ReadAll(io.LimitReader(brotli.NewReader(bytes.NewReader(data[1:])), MaxDecompressedLen+1))
+	if err != nil {
+		return nil, fmt.Errorf("brotli decompression failed: %w", err)
+	}
+	if len(decompressed) > MaxDecompressedLen {
+		return nil, fmt.Errorf("brotli decompression failed: output exceeds %d bytes", MaxDecompressedLen)
+	}
+	m.DecompressedSize = len(decompressed)
+
+	s := rlp.NewStream(bytes.NewReader(decompressed), 0)
+	for {
+		segment, err := s.Bytes()
+		if errors.Is(err, io.EOF) {
+			return m, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("segment %d: %w", m.Segments, err)
+		}
+		if m.Segments == MaxSegmentsPerSequencerMessage {
+			return nil, fmt.Errorf("more than %d segments", MaxSegmentsPerSequencerMessage)
+		}
+		if err := m.addSegment(segment); err != nil {
+			return nil, fmt.Errorf("segment %d: %w", m.Segments, err)
+		}
+		m.Segments++
+	}
+}
+
+func (m *SequencerMessage) addSegment(segment []byte) error {
+	if len(segment) == 0 {
+		return nil
+	}
+	kind, body := segment[0], segment[1:]
+	switch kind {
+	case SegmentKindL2Message, SegmentKindL2MessageBrotli:
+		if kind == SegmentKindL2MessageBrotli {
+			var err error
+			body, err = io.ReadAll(io.LimitReader(brotli.NewReader(bytes.NewReader(body)), maxL2MessageSize+1))
+			if err != nil {
+				return fmt.Errorf("brotli decompression failed: %w", err)
+			}
+			if len(body) > maxL2MessageSize {
+				return fmt.Errorf("L2 message exceeds %d bytes", maxL2MessageSize)
+			}
+		}
+		n, err := countTransactions(body, 0)
+		if err != nil {
+			return err
+		}
+		m.L2Messages++
+		m.Transactions += n
+	case SegmentKindDelayedMessages:
+		m.DelayedMessages++
+	case SegmentKindAdvanceTimestamp, SegmentKindAdvanceL1BlockNumber:
+		var advance uint64
+		if err := rlp.DecodeBytes(body, &advance); err != nil {
+			return fmt.Errorf("invalid advance: %w", err)
+		}
+		if kind == SegmentKindAdvanceTimestamp {
+			m.TimestampAdvance += advance
+		} else {
+			m.L1BlockAdvance += advance
+		}
+	default:
+		m.UnknownSegments++
+	}
+	return nil
+}
+
+// countTransactions counts the transactions in an L2 message, descending
+// into batch messages, which hold a sequence of 8-byte length-prefixed
+// messages.
+func countTransactions(msg []byte, depth int) (int, error) {
+	if len(msg) == 0 {
+		return 0, errors.New("empty L2 message")
+	}
+	switch msg[0] {
+	case l2MessageKindUnsignedUserTx, l2MessageKindContractTx, l2MessageKindSignedTx:
+		return 1, nil
+	case l2MessageKindBatch:
+		if depth >= maxNestingDepth {
+			return 0, fmt.Errorf("L2 batch messages nested more than %d deep", maxNestingDepth)
+		}
+		var count int
+		for rest := msg[1:]; len(rest) > 0; {
+			if len(rest) < 8 {
+				return 0, errors.New("truncated L2 batch message length")
+			}
+			size := binary.BigEndian.Uint64(rest)
+			if size > maxL2MessageSize || size > uint64(len(rest)-8) {
+				return 0, fmt.Errorf("invalid L2 batch message length %d", size)
+			}
+			n, err := countTransactions(rest[8:8+size], depth+1)
+			if err != nil {
+				return 0, err
+			}
+			count += n
+			rest = rest[8+size:]
+		}
+		return count, nil
+	default:
+		// Heartbeats and other kinds carry no transaction.
+		return 0, nil
+	}
+}
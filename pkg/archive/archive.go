@@ -0,0 +1,165 @@
+// Package archive persists blobs and their KZG artifacts beyond the roughly
+// 18 days beacon nodes keep them, under their versioned hash, in an object
+// store such as S3, MinIO or a local directory.
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// ErrNotFound means the store holds no object under the key.
+var ErrNotFound = errors.New("not found in archive")
+
+// Store is an object store keyed by slash-separated names.
+type Store interface {
+	// Put stores data under key, replacing any existing object.
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns the object under key, or an error wrapping ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// List returns the keys starting with prefix, in lexical order.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Open returns the store at rawURL: s3://bucket/prefix for S3 or MinIO (see
+// NewS3Store for the query parameters), ipfs://host:port for an IPFS node
+// (see NewIPFSStore), or file:///path or a plain path for a local
+// directory. httpClient is used for S3 and IPFS; nil uses
+// http.DefaultClient.
+func Open(rawURL string, httpClient *http.Client) (Store, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive URL: %w", err)
+	}
+	switch u.Scheme {
+	case "s3":
+		return NewS3Store(u, httpClient)
+	case "ipfs":
+		return NewIPFSStore(u, httpClient)
+	case "file":
+		return NewDirStore(u.Path)
+	case "":
+		return NewDirStore(rawURL)
+	default:
+		return nil, fmt.Errorf("unsupported archive URL scheme %q (want s3, ipfs or file)", u.Scheme)
+	}
+}
+
+// A ContentAddressedStore also reports the content identifier it stores
+// each object under, such as its IPFS CID.
+type ContentAddressedStore interface {
+	Store
+	CID(key string) (string, bool)
+}
+
+// Archive stores each blob as blobs/<versioned hash>.bin and its artifacts
+// as blobs/<versioned hash>.json.
+type Archive struct {
+	store Store
+}
+
+// New returns an archive in store.
+func New(store Store) *Archive {
+	return &Archive{store: store}
+}
+
+const blobsPrefix = "blobs/"
+
+func blobKey(h common.Hash) string     { return blobsPrefix + h.Hex() + ".bin" }
+func artifactKey(h common.Hash) string { return blobsPrefix + h.Hex() + ".json" }
+
+// Put stores b and its artifacts a, which must belong to b, and returns the
+// versioned hash they are stored under. A nil a is computed.
+func (a *Archive) Put(ctx context.Context, b *kzg4844.Blob, art *blob.BlobArtifacts) (common.Hash, error) {
+	if art == nil {
+		var err error
+		if art, err = blob.NewArtifacts(b, false); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	entry := *art
+	entry.BlobHex = ""
+	meta, err := json.MarshalIndent(&entry, "", "  ")
+	if err != nil {
+		return common.Hash{}, err
+	}
+	// The blob goes first, so an artifact entry always has its blob.
+	h := entry.VersionedHash
+	if err := a.store.Put(ctx, blobKey(h), b[:]); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to archive blob %s: %w", h, err)
+	}
+	if err := a.store.Put(ctx, artifactKey(h), meta); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to archive artifacts of %s: %w", h, err)
+	}
+	return h, nil
+}
+
+// Get returns the blob archived under h and its artifacts, after checking
+// that the blob commits to the archived commitment and that it hashes to h.
+func (a *Archive) Get(ctx context.Context, h common.Hash) (*kzg4844.Blob, *blob.BlobArtifacts, error) {
+	data, err := a.store.Get(ctx, blobKey(h))
+	if err != nil {
+		return nil, nil, fmt.Errorf("blob %s: %w", h, err)
+	}
+	b, err := blob.NewBlobFromBytes(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("blob %s: %w", h, err)
+	}
+	meta, err := a.store.Get(ctx, artifactKey(h))
+	if err != nil {
+		return nil, nil, fmt.Errorf("artifacts of %s: %w", h, err)
+	}
+	var art blob.BlobArtifacts
+	if err := json.Unmarshal(meta, &art); err != nil {
+		return nil, nil, fmt.Errorf("artifacts of %s: %w", h, err)
+	}
+	if err := blob.CheckVersionedHash(art.Commitment, h); err != nil {
+		return nil, nil, err
+	}
+	commitment, err := blob.Commit(&b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(commitment[:], art.Commitment[:]) {
+		return nil, nil, fmt.Errorf("%w: archived blob %s commits to %x", blob.ErrCommitmentMismatch, h, commitment)
+	}
+	return &b, &art, nil
+}
+
+// CID returns the content identifier of the blob archived under h, if the
+// store is content-addressed and holds it.
+func (a *Archive) CID(h common.Hash) (string, bool) {
+	if s, ok := a.store.(ContentAddressedStore); ok {
+		return s.CID(blobKey(h))
+	}
+	return "", false
+}
+
+// List returns the versioned hashes of the archived blobs.
+func (a *Archive) List(ctx context.Context) ([]common.Hash, error) {
+	keys, err := a.store.List(ctx, blobsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var hashes []common.Hash
+	for _, key := range keys {
+		name, ok := strings.CutSuffix(strings.TrimPrefix(key, blobsPrefix), ".bin")
+		if ok && len(name) == 2+2*common.HashLength {
+			hashes = append(hashes, common.HexToHash(name))
+		}
+	}
+	slices.SortFunc(hashes, func(x, y common.Hash) int { return bytes.Compare(x[:], y[:]) })
+	return hashes, nil
+}
@@ -0,0 +1,186 @@
+package archive
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"kzg-blob-poc/internal/atomicfile"
+)
+
+// IPFSStore is a Store that adds and pins every object to an IPFS node
+// through the Kubo RPC API. IPFS addresses content by CID, so the CID of
+// each key is recorded in a local JSON index file.
+type IPFSStore struct {
+	client *http.Client
+	api    *url.URL // Kubo RPC endpoint, e.g. http://127.0.0.1:5001
+
+	mu        sync.Mutex
+	indexPath string
+	index     map[string]string // key -> CID
+}
+
+// NewIPFSStore returns the store at u, an ipfs://host:port URL of the
+// node's RPC API (default 127.0.0.1:5001). Its query may set:
+//
+//   - index: the file recording the CID of each key (default:
+//     <user config dir>/blob-poc/ipfs-index.json).
+//   - tls: true to reach the API over HTTPS.
+func NewIPFSStore(u *url.URL, httpClient *http.Client) (*IPFSStore, error) {
+	s := &IPFSStore{
+		client:    cmp.Or(httpClient, http.DefaultClient),
+		api:       &url.URL{Scheme: "http", Host: cmp.Or(u.Host, "127.0.0.1:5001")},
+		indexPath: u.Query().Get("index"),
+		index:     map[string]string{},
+	}
+	if u.Query().Get("tls") == "true" {
+		s.api.Scheme = "https"
+	}
+	if s.indexPath == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("no IPFS index file given and %w", err)
+		}
+		s.indexPath = filepath.Join(dir, "blob-poc", "ipfs-index.json")
+	}
+	data, err := os.ReadFile(s.indexPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read IPFS index: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &s.index); err != nil {
+			return nil, fmt.Errorf("failed to parse IPFS index %s: %w", s.indexPath, err)
+		}
+	}
+	return s, nil
+}
+
+// addResponse is the part of the /api/v0/add response Put reads.
+type addResponse struct {
+	Hash string
+}
+
+// Put adds data to the node, pinned, and records its CID under key.
+func (s *IPFSStore) Put(ctx context.Context, key string, data []byte) error {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", filepath.Base(key))
+	if err != nil {
+		return err
+	}
+	part.Write(data)
+	if err := mw.Close(); err != nil {
+		return err
+	}
+	query := url.Values{"pin": {"true"}, "cid-version": {"1"}, "quiet": {"true"}}
+	resp, err := s.call(ctx, "add", query, &body, mw.FormDataContentType())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var added addResponse
+	if err := json.NewDecoder(resp.Body).Decode(&added); err != nil || added.Hash == "" {
+		return fmt.Errorf("invalid IPFS add response for %s: %v", key, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.index[key] = added.Hash
+	return s.saveIndex()
+}
+
+// Get looks up the CID of key in the index and reads it from the node.
+func (s *IPFSStore) Get(ctx context.Context, key string) ([]byte, error) {
+	cid, ok := s.CID(key)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s has no CID in %s", ErrNotFound, key, s.indexPath)
+	}
+	resp, err := s.call(ctx, "cat", url.Values{"arg": {cid}}, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// List returns the indexed keys starting with prefix.
+func (s *IPFSStore) List(_ context.Context, prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key := range s.index {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// CID returns the CID the object under key was added as.
+func (s *IPFSStore) CID(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cid, ok := s.index[key]
+	return cid, ok
+}
+
+// saveIndex writes the index atomically. s.mu must be held.
+func (s *IPFSStore) saveIndex() error {
+	data, err := json.MarshalIndent(s.index, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(s.indexPath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return atomicfile.Write(s.indexPath, append(data, '\n'))
+}
+
+// kuboError is the JSON error body of a failed RPC call.
+type kuboError struct {
+	Message string
+}
+
+// call invokes an RPC API command, which Kubo only accepts over POST, and
+// returns the response if it succeeded.
+func (s *IPFSStore) call(ctx context.Context, command string, query url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	u := *s.api
+	u.Path = "/api/v0/" + command
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ipfs %s: %w", command, err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	var e kuboError
+	json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&e)
+	if e.Message != "" {
+		return nil, fmt.Errorf("ipfs %s: %s: %s", command, resp.Status, e.Message)
+	}
+	return nil, fmt.Errorf("ipfs %s: %s", command, resp.Status)
+}
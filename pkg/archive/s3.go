@@ -0,0 +1,294 @@
+package archive
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// S3Store is a Store in an S3 bucket, or a bucket of an S3-compatible
+// server such as MinIO, accessed with AWS Signature Version 4.
+type S3Store struct {
+	client   *http.Client
+	endpoint *url.URL // scheme and host requests are sent to
+	bucket   string
+	prefix   string // prepended to every key; empty or ending in '/'
+	region   string
+	// pathStyle addresses the bucket as the first path segment instead of
+	// as a subdomain, as MinIO and most other S3-compatible servers need.
+	pathStyle bool
+	creds     credentials
+}
+
+type credentials struct {
+	accessKey, secretKey, sessionToken string
+}
+
+// NewS3Store returns the store at u, an s3://bucket/prefix URL. Its query
+// may set:
+//
+//   - endpoint: the server URL, e.g. http://localhost:9000 for MinIO
+//     (default: AWS for the region). A custom endpoint uses path-style
+//     addressing unless path_style=false.
+//   - region: the signing region (default: $AWS_REGION, else us-east-1).
+//
+// Credentials are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and, for
+// temporary credentials, AWS_SESSION_TOKEN.
+func NewS3Store(u *url.URL, httpClient *http.Client) (*S3Store, error) {
+	if u.Host == "" {
+		return nil, errors.New("archive URL has no bucket: want s3://bucket[/prefix]")
+	}
+	s := &S3Store{
+		client: httpClient,
+		bucket: u.Host,
+		prefix: strings.Trim(u.Path, "/"),
+		region: cmp.Or(u.Query().Get("region"), os.Getenv("AWS_REGION"), "us-east-1"),
+		creds: credentials{
+			accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+			secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		},
+	}
+	if s.client == nil {
+		s.client = http.DefaultClient
+	}
+	if s.prefix != "" {
+		s.prefix += "/"
+	}
+	if s.creds.accessKey == "" || s.creds.secretKey == "" {
+		return nil, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set for an s3:// archive")
+	}
+
+	if endpoint := u.Query().Get("endpoint"); endpoint != "" {
+		e, err := url.Parse(endpoint)
+		if err != nil || (e.Scheme != "http" && e.Scheme != "https") || e.Host == "" {
+			return nil, fmt.Errorf("invalid archive endpoint %q", endpoint)
+		}
+		s.endpoint = &url.URL{Scheme: e.Scheme, Host: e.Host}
+		s.pathStyle = u.Query().Get("path_style") != "false"
+	} else {
+		s.endpoint = &url.URL{Scheme: "https", Host: "s3." + s.region + ".amazonaws.com"}
+	}
+	if !s.pathStyle {
+		s.endpoint.Host = s.bucket + "." + s.endpoint.Host
+	}
+	return s, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, s.prefix+key, nil, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.prefix+key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// listResult is the part of a ListObjectsV2 response List reads.
+type listResult struct {
+	Contents []struct {
+		Key string
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+func (s *S3Store) List(ctx context.Context, prefix string) ([]string, error) {
+	var (
+		keys  []string
+		token string
+	)
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.prefix + prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page listResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse bucket listing: %w", err)
+		}
+		for _, c := range page.Contents {
+			keys = append(keys, strings.TrimPrefix(c.Key, s.prefix))
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			break
+		}
+		token = page.NextContinuationToken
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// s3Error is the XML error body of a failed S3 request.
+type s3Error struct {
+	Code    string
+	Message string
+}
+
+// do sends a signed request for key, or for the bucket when key is empty,
+// and returns the response if it succeeded.
+func (s *S3Store) do(ctx context.Context, method, key string, query url.Values, body []byte) (*http.Response, error) {
+	u := *s.endpoint
+	u.Path = "/"
+	if s.pathStyle {
+		u.Path += s.bucket + "/"
+	}
+	u.Path += key
+	u.RawPath = escapePath(u.Path)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		req.Body, req.GetBody = nil, nil
+	}
+	req.ContentLength = int64(len(body))
+	s.sign(req, body, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("archive %s %s: %w", method, u.Path, err)
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	var e s3Error
+	xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&e)
+	if resp.StatusCode == http.StatusNotFound && (e.Code == "" || e.Code == "NoSuchKey") {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if e.Code != "" {
+		return nil, fmt.Errorf("archive %s %s: %s: %s: %s", method, u.Path, resp.Status, e.Code, e.Message)
+	}
+	return nil, fmt.Errorf("archive %s %s: %s", method, u.Path, resp.Status)
+}
+
+// sign adds the AWS Signature Version 4 authorization for req, whose body is
+// body, to its headers. Every header set on req at this point is signed.
+func (s *S3Store) sign(req *http.Request, body []byte, now time.Time) {
+	const algorithm = "AWS4-HMAC-SHA256"
+	now = now.UTC()
+	stamp, day := now.Format("20060102T150405Z"), now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.creds.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + s.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{algorithm, stamp, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.creds.secretKey), day)
+	for _, part := range []string{s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, s.creds.accessKey, scope, signedHeaders, signature))
+}
+
+// escapePath percent-encodes every byte of path except unreserved
+// characters and '/', as SigV4 requires.
+func escapePath(path string) string {
+	var b strings.Builder
+	for _, c := range []byte(path) {
+		if c == '/' || isUnreserved(c) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// canonicalQuery encodes query with sorted keys and SigV4 escaping.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, escapeQuery(k)+"="+escapeQuery(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func escapeQuery(s string) string {
+	return strings.ReplaceAll(escapePath(s), "/", "%2F")
+}
+
+func isUnreserved(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '_' || c == '.' || c == '~'
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
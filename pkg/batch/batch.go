@@ -0,0 +1,85 @@
+// Package batch computes KZG commitments, proofs and versioned hashes for
+// many blobs concurrently.
+package batch
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// Job is a single blob to process. Load is called from a worker goroutine,
+// so expensive I/O such as reading the blob file runs in parallel too. It
+// fills dst, a zeroed blob from blob.GetBlob that is recycled once the
+// artifacts are computed, so Load must not keep it.
+type Job struct {
+	Name string
+	Load func(dst *kzg4844.Blob) error
+}
+
+// Result holds the KZG artifacts computed for one job.
+type Result struct {
+	Name          string             `json:"name"`
+	Commitment    kzg4844.Commitment `json:"commitment"`
+	Proof         kzg4844.Proof      `json:"proof,omitzero"` // zero with NoProof
+	VersionedHash common.Hash        `json:"versioned_hash"`
+}
+
+// Run processes jobs on a pool of workers and returns the results in job
+// order. A workers value of zero or less uses one worker per CPU. Run stops
+// at the first failure, or when ctx is done, and returns that error.
+func Run(ctx context.Context, jobs []Job, workers int) ([]Result, error) {
+	results := make([]Result, 0, len(jobs))
+	err := NewPipeline(workers).Run(ctx, slices.Values(jobs), func(r Result) error {
+		results = append(results, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// process loads a single blob and computes its artifacts, or takes them
+// from the cache. With NoProof the proof is left zero. A positive Timeout
+// bounds the computation, which then also ends when ctx is done.
+func (p *Pipeline) process(ctx context.Context, job Job) (Result, error) {
+	var b *kzg4844.Blob
+	if p.unpooled {
+		b = new(kzg4844.Blob)
+	} else {
+		b = blob.GetBlob()
+		defer blob.PutBlob(b)
+	}
+	if err := job.Load(b); err != nil {
+		return Result{}, err
+	}
+	compute := p.Cache.Artifacts
+	if p.NoProof {
+		compute = p.Cache.CommitOnly
+	}
+	var (
+		a   *blob.BlobArtifacts
+		err error
+	)
+	if p.Timeout > 0 {
+		a, err = blob.WithTimeout(ctx, p.Timeout, b, func(b *kzg4844.Blob) (*blob.BlobArtifacts, error) {
+			return compute(b, false)
+		})
+	} else {
+		a, err = compute(b, false)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Name:          job.Name,
+		Commitment:    a.Commitment,
+		Proof:         a.Proof,
+		VersionedHash: a.VersionedHash,
+	}, nil
+}
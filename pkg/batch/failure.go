@@ -0,0 +1,106 @@
+package batch
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// Reasons a job failed, as reported in Failure.Reason.
+const (
+	ReasonTooLarge            = "too_large"
+	ReasonInvalidHex          = "invalid_hex"
+	ReasonInvalidBase64       = "invalid_base64"
+	ReasonInvalidFieldElement = "invalid_field_element"
+	ReasonProofMismatch       = "proof_mismatch"
+	ReasonTimeout             = "timeout"
+	ReasonOther               = "error"
+)
+
+// Failure records a job that failed in a run that went on with the rest.
+type Failure struct {
+	Index  int    `json:"index"` // position of the job in the run
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+	Error  string `json:"error"`
+
+	err error
+}
+
+// NewFailure records that the job at index failed with err.
+func NewFailure(index int, name string, err error) Failure {
+	return Failure{Index: index, Name: name, Reason: Reason(err), Error: err.Error(), err: err}
+}
+
+// Unwrap returns the error the job failed with.
+func (f Failure) Unwrap() error { return f.err }
+
+// Reason classifies the error a job failed with.
+func Reason(err error) string {
+	var (
+		hexErr    hex.InvalidByteError
+		base64Err base64.CorruptInputError
+	)
+	switch {
+	case errors.Is(err, blob.ErrBlobTooLarge):
+		return ReasonTooLarge
+	case errors.Is(err, blob.ErrInvalidFieldElement):
+		return ReasonInvalidFieldElement
+	case errors.Is(err, blob.ErrProofMismatch):
+		return ReasonProofMismatch
+	case errors.Is(err, blob.ErrTimeout):
+		return ReasonTimeout
+	case errors.As(err, &hexErr), errors.Is(err, hex.ErrLength):
+		return ReasonInvalidHex
+	case errors.As(err, &base64Err):
+		return ReasonInvalidBase64
+	default:
+		return ReasonOther
+	}
+}
+
+// FailedError is returned by a run that went on past failed jobs. It
+// unwraps to the error of every failure, so errors.Is finds their causes.
+type FailedError struct {
+	Failures []Failure
+	Total    int // number of jobs in the run
+}
+
+func (e *FailedError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d blobs failed", len(e.Failures), e.Total)
+	for i, f := range e.Failures {
+		if i == 3 {
+			fmt.Fprintf(&b, " and %d more", len(e.Failures)-i)
+			break
+		}
+		sep := ", "
+		if i == 0 {
+			sep = ": "
+		}
+		fmt.Fprintf(&b, "%s#%d (%s)", sep, f.Index, f.Reason)
+	}
+	return b.String()
+}
+
+func (e *FailedError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.err
+	}
+	return errs
+}
+
+// Summary returns a line per failure, giving its index, name and error.
+func (e *FailedError) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d blobs failed:\n", len(e.Failures), e.Total)
+	for _, f := range e.Failures {
+		fmt.Fprintf(&b, "  #%d %s: %s: %s\n", f.Index, f.Name, f.Reason, f.Error)
+	}
+	return b.String()
+}
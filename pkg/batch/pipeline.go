@@ -0,0 +1,159 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"runtime"
+	"sync"
+	"time"
+
+	"kzg-blob-poc/pkg/cache"
+	"kzg-blob-poc/pkg/progress"
+)
+
+// Pipeline computes KZG artifacts for a stream of jobs on a pool of workers
+// and delivers the results in job order. At most a few jobs per worker are
+// in flight at once, so long streams are processed in bounded memory.
+type Pipeline struct {
+	// Cache, when set, supplies the artifacts of blobs seen before and
+	// stores the ones computed.
+	Cache *cache.Cache
+	// Progress, when set, counts each result emitted.
+	Progress *progress.Tracker
+	// NoProof skips the proofs, which roughly halves the work per blob, and
+	// leaves Result.Proof zero.
+	NoProof bool
+	// KeepGoing records the jobs that fail and goes on with the rest,
+	// instead of stopping at the first. Run then returns a *FailedError
+	// listing them, after emitting the results of the others.
+	KeepGoing bool
+	// Timeout, when positive, bounds the KZG work on each blob. A blob that
+	// takes longer fails with blob.ErrTimeout and its worker moves on to
+	// the next job; see blob.WithTimeout.
+	Timeout time.Duration
+
+	workers int
+	// unpooled gives each job a newly allocated blob instead of one from
+	// the blob pool, for BenchmarkPipeline to compare against.
+	unpooled bool
+}
+
+// NewPipeline returns a pipeline running the given number of workers. A
+// workers value of zero or less uses one worker per CPU.
+func NewPipeline(workers int) *Pipeline {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &Pipeline{workers: workers}
+}
+
+// outcome is the result of one job, handed from a worker to the collector.
+// job is the error of the job itself, which err wraps with its name.
+type outcome struct {
+	res Result
+	err error
+	job error
+}
+
+// Run processes jobs and calls emit with each result, in the order the jobs
+// were produced. emit runs on the caller's goroutine. Run stops at the
+// first job that fails, unless KeepGoing is set, or when emit returns an
+// error, and returns that error. When ctx is done, it stops taking jobs and returns ctx.Err() once
+// the jobs already being processed have finished, which is at most one blob
+// per worker.
+func (p *Pipeline) Run(ctx context.Context, jobs iter.Seq[Job], emit func(Result) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		queue   = make(chan func())
+		pending = make(chan chan outcome, 2*p.workers)
+		wg      sync.WaitGroup
+	)
+	for range p.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range queue {
+				task()
+			}
+		}()
+	}
+
+	// The producer reserves a result slot for every job before queueing it,
+	// so the collector can wait on the slots in order.
+	go func() {
+		defer close(pending)
+		defer close(queue)
+		for job := range jobs {
+			slot := make(chan outcome, 1)
+			select {
+			case pending <- slot:
+			case <-ctx.Done():
+				return
+			}
+			task := func() {
+				if err := ctx.Err(); err != nil {
+					slot <- outcome{err: err}
+					return
+				}
+				res, err := p.process(ctx, job)
+				if err != nil {
+					res.Name = job.Name
+					slot <- outcome{res, fmt.Errorf("%s: %w", job.Name, err), err}
+					return
+				}
+				slot <- outcome{res: res}
+			}
+			select {
+			case queue <- task:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		err    error
+		failed = new(FailedError)
+	)
+	for slot := range pending {
+		var out outcome
+		select {
+		case out = <-slot:
+		case <-ctx.Done():
+			out.err = ctx.Err()
+		}
+		index := failed.Total
+		failed.Total++
+		if out.job != nil && p.KeepGoing {
+			failed.Failures = append(failed.Failures, NewFailure(index, out.res.Name, out.job))
+			p.Progress.Add(1)
+			continue
+		}
+		if err = out.err; err == nil {
+			err = emit(out.res)
+		}
+		if err == nil {
+			p.Progress.Add(1)
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err != nil {
+		cancel()
+		for range pending {
+		}
+	}
+	wg.Wait()
+	if err == nil {
+		// The producer also stops when ctx is done, which ends pending
+		// without an error.
+		err = ctx.Err()
+	}
+	if err == nil && len(failed.Failures) > 0 {
+		err = failed
+	}
+	return err
+}
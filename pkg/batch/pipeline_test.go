@@ -0,0 +1,60 @@
+package batch
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"iter"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// BenchmarkPipeline runs a batch of blobs decoded from hex, as batch reads
+// blob files, with and without the blob pool. Compare allocs/op and B/op.
+func BenchmarkPipeline(b *testing.B) {
+	const jobs = 8
+	texts := make([][]byte, jobs)
+	for i := range texts {
+		bl, err := blob.GenerateBlob(blob.PatternRandom, 1, i)
+		if err != nil {
+			b.Fatal(err)
+		}
+		texts[i] = []byte(hex.EncodeToString(bl[:]))
+	}
+	// Load the trusted setup outside the measured runs.
+	if _, err := blob.CommitOnly(new(kzg4844.Blob), false); err != nil {
+		b.Fatal(err)
+	}
+	seq := func(yield func(Job) bool) {
+		for i, text := range texts {
+			job := Job{
+				Name: fmt.Sprint(i),
+				Load: func(dst *kzg4844.Blob) error { return blob.SetBlobHex(dst, text) },
+			}
+			if !yield(job) {
+				return
+			}
+		}
+	}
+
+	for _, unpooled := range []bool{false, true} {
+		name := "pooled"
+		if unpooled {
+			name = "unpooled"
+		}
+		b.Run(name, func(b *testing.B) {
+			p := NewPipeline(1)
+			p.NoProof = true
+			p.unpooled = unpooled
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := p.Run(context.Background(), iter.Seq[Job](seq), func(Result) error { return nil }); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// WriteJSON writes results as an indented JSON array.
+func WriteJSON(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+// WriteCSV writes results as CSV with a header row.
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"name", "commitment", "proof", "versioned_hash"}); err != nil {
+		return err
+	}
+	for _, r := range results {
+		record := []string{
+			r.Name,
+			hexutil.Encode(r.Commitment[:]),
+			proofHex(r.Proof),
+			r.VersionedHash.Hex(),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// proofHex encodes proof, or returns "" for the zero proof of a run with
+// NoProof.
+func proofHex(proof kzg4844.Proof) string {
+	if proof == (kzg4844.Proof{}) {
+		return ""
+	}
+	return hexutil.Encode(proof[:])
+}
@@ -0,0 +1,652 @@
+package beacon
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/holiman/uint256"
+)
+
+// The types below model the Deneb, Electra and Fulu BeaconBlockBody as
+// served by the beacon API, with just enough SSZ support to compute hash
+// tree roots. Integers are decimal strings and byte fields 0x-prefixed hex
+// in JSON.
+
+// Forks of the beacon block body, as the version of a /eth/v2/beacon/blocks
+// response names them. Fulu keeps the Electra body.
+const (
+	ForkDeneb   = "deneb"
+	ForkElectra = "electra"
+	ForkFulu    = "fulu"
+)
+
+// Deneb limits of the beacon block body.
+const (
+	MaxProposerSlashings       = 16
+	MaxAttesterSlashings       = 2
+	MaxAttestations            = 128
+	MaxDeposits                = 16
+	MaxVoluntaryExits          = 16
+	MaxBLSToExecutionChanges   = 16
+	MaxBlobCommitmentsPerBlock = 4096
+	MaxValidatorsPerCommittee  = 2048
+	MaxTransactionsPerPayload  = 1 << 20
+	MaxBytesPerTransaction     = 1 << 30
+	MaxWithdrawalsPerPayload   = 16
+	MaxExtraDataBytes          = 32
+	SyncCommitteeSize          = 512
+	DepositProofLength         = 33
+)
+
+// Electra limits of the beacon block body, where they differ from Deneb's.
+const (
+	MaxAttesterSlashingsElectra        = 1
+	MaxAttestationsElectra             = 8
+	MaxCommitteesPerSlot               = 64
+	MaxDepositRequestsPerPayload       = 8192
+	MaxWithdrawalRequestsPerPayload    = 16
+	MaxConsolidationRequestsPerPayload = 2
+)
+
+// bodyLayout is what differs between the bodies of the supported forks.
+type bodyLayout struct {
+	attesterSlashings int
+	attestations      int
+	// attestingValidators limits the aggregation_bits of an attestation
+	// and the attesting_indices of an indexed attestation.
+	attestingValidators int
+	// electra adds committee_bits to attestations and execution_requests
+	// to the body.
+	electra bool
+}
+
+var bodyLayouts = map[string]*bodyLayout{
+	ForkDeneb:   {MaxAttesterSlashings, MaxAttestations, MaxValidatorsPerCommittee, false},
+	ForkElectra: {MaxAttesterSlashingsElectra, MaxAttestationsElectra, MaxValidatorsPerCommittee * MaxCommitteesPerSlot, true},
+	ForkFulu:    {MaxAttesterSlashingsElectra, MaxAttestationsElectra, MaxValidatorsPerCommittee * MaxCommitteesPerSlot, true},
+}
+
+// BLSSignature is a compressed BLS12-381 G2 signature.
+type BLSSignature [96]byte
+
+// UnmarshalText parses a signature in hex syntax.
+func (s *BLSSignature) UnmarshalText(input []byte) error {
+	return hexutil.UnmarshalFixedText("BLSSignature", input, s[:])
+}
+
+// MarshalText returns the hex representation of s.
+func (s BLSSignature) MarshalText() ([]byte, error) {
+	return hexutil.Bytes(s[:]).MarshalText()
+}
+
+// BLSPubkey is a compressed BLS12-381 G1 public key.
+type BLSPubkey [48]byte
+
+// UnmarshalText parses a public key in hex syntax.
+func (k *BLSPubkey) UnmarshalText(input []byte) error {
+	return hexutil.UnmarshalFixedText("BLSPubkey", input, k[:])
+}
+
+// MarshalText returns the hex representation of k.
+func (k BLSPubkey) MarshalText() ([]byte, error) {
+	return hexutil.Bytes(k[:]).MarshalText()
+}
+
+// BeaconBlockBody is the beacon block body of Deneb or a later fork.
+type BeaconBlockBody struct {
+	// Version is the fork of the body, ForkDeneb, ForkElectra or ForkFulu,
+	// which selects its layout. It is not part of the body's JSON: the
+	// beacon API gives it as the version of the block response.
+	Version string `json:"-"`
+
+	RandaoReveal          BLSSignature                 `json:"randao_reveal"`
+	Eth1Data              Eth1Data                     `json:"eth1_data"`
+	Graffiti              common.Hash                  `json:"graffiti"`
+	ProposerSlashings     []ProposerSlashing           `json:"proposer_slashings"`
+	AttesterSlashings     []AttesterSlashing           `json:"attester_slashings"`
+	Attestations          []Attestation                `json:"attestations"`
+	Deposits              []Deposit                    `json:"deposits"`
+	VoluntaryExits        []SignedVoluntaryExit        `json:"voluntary_exits"`
+	SyncAggregate         SyncAggregate                `json:"sync_aggregate"`
+	ExecutionPayload      ExecutionPayload             `json:"execution_payload"`
+	BLSToExecutionChanges []SignedBLSToExecutionChange `json:"bls_to_execution_changes"`
+	BlobKZGCommitments    []kzg4844.Commitment         `json:"blob_kzg_commitments"`
+	// ExecutionRequests is only in Electra and later bodies.
+	ExecutionRequests *ExecutionRequests `json:"execution_requests,omitempty"`
+}
+
+// BeaconBlock is a beacon block of Deneb or a later fork.
+type BeaconBlock struct {
+	Slot          uint64          `json:"slot,string"`
+	ProposerIndex uint64          `json:"proposer_index,string"`
+	ParentRoot    common.Hash     `json:"parent_root"`
+	StateRoot     common.Hash     `json:"state_root"`
+	Body          BeaconBlockBody `json:"body"`
+}
+
+// SignedBeaconBlock is a beacon block with its proposer signature, as served
+// by /eth/v2/beacon/blocks.
+type SignedBeaconBlock struct {
+	Message   BeaconBlock  `json:"message"`
+	Signature BLSSignature `json:"signature"`
+}
+
+// DecodeSignedBeaconBlock parses the JSON of a signed beacon block of the
+// fork version. It fails for a fork whose body it does not model, rather
+// than computing a root that would not match the block's.
+func DecodeSignedBeaconBlock(version string, data []byte) (*SignedBeaconBlock, error) {
+	if _, ok := bodyLayouts[version]; !ok {
+		return nil, unsupportedFork(version)
+	}
+	block := new(SignedBeaconBlock)
+	if err := json.Unmarshal(data, block); err != nil {
+		return nil, err
+	}
+	block.Message.Body.Version = version
+	return block, nil
+}
+
+// unsupportedFork returns the error for a block of a fork without a body
+// model.
+func unsupportedFork(version string) error {
+	if version == "" {
+		return errors.New("beacon block has no fork version")
+	}
+	return fmt.Errorf("unsupported beacon block fork %q (want deneb, electra or fulu)", version)
+}
+
+// Header returns the block's header, committing to the body by its root.
+func (b *BeaconBlock) Header() (BeaconBlockHeader, error) {
+	bodyRoot, err := b.Body.HashTreeRoot()
+	if err != nil {
+		return BeaconBlockHeader{}, err
+	}
+	return BeaconBlockHeader{
+		Slot:          b.Slot,
+		ProposerIndex: b.ProposerIndex,
+		ParentRoot:    b.ParentRoot,
+		StateRoot:     b.StateRoot,
+		BodyRoot:      bodyRoot,
+	}, nil
+}
+
+// SignedHeader returns the block's header with the block signature, which
+// also signs the header since both share a root.
+func (b *SignedBeaconBlock) SignedHeader() (SignedBeaconBlockHeader, error) {
+	header, err := b.Message.Header()
+	if err != nil {
+		return SignedBeaconBlockHeader{}, err
+	}
+	return SignedBeaconBlockHeader{Message: header, Signature: b.Signature}, nil
+}
+
+// Eth1Data is the proposer's vote on the deposit contract state.
+type Eth1Data struct {
+	DepositRoot  common.Hash `json:"deposit_root"`
+	DepositCount uint64      `json:"deposit_count,string"`
+	BlockHash    common.Hash `json:"block_hash"`
+}
+
+// ProposerSlashing proves a proposer signed two headers for one slot.
+type ProposerSlashing struct {
+	SignedHeader1 SignedBeaconBlockHeader `json:"signed_header_1"`
+	SignedHeader2 SignedBeaconBlockHeader `json:"signed_header_2"`
+}
+
+// Checkpoint is an epoch boundary block.
+type Checkpoint struct {
+	Epoch uint64      `json:"epoch,string"`
+	Root  common.Hash `json:"root"`
+}
+
+// AttestationData is the vote carried by an attestation.
+type AttestationData struct {
+	Slot            uint64      `json:"slot,string"`
+	Index           uint64      `json:"index,string"`
+	BeaconBlockRoot common.Hash `json:"beacon_block_root"`
+	Source          Checkpoint  `json:"source"`
+	Target          Checkpoint  `json:"target"`
+}
+
+// IndexedAttestation is an attestation with explicit validator indices.
+type IndexedAttestation struct {
+	AttestingIndices []uint64        `json:"attesting_indices"`
+	Data             AttestationData `json:"data"`
+	Signature        BLSSignature    `json:"signature"`
+}
+
+// AttesterSlashing proves two conflicting attestations.
+type AttesterSlashing struct {
+	Attestation1 IndexedAttestation `json:"attestation_1"`
+	Attestation2 IndexedAttestation `json:"attestation_2"`
+}
+
+// Attestation is an aggregated committee vote.
+type Attestation struct {
+	// AggregationBits is an SSZ bitlist, including its delimiter bit.
+	AggregationBits hexutil.Bytes   `json:"aggregation_bits"`
+	Data            AttestationData `json:"data"`
+	Signature       BLSSignature    `json:"signature"`
+	// CommitteeBits is an SSZ bitvector of MaxCommitteesPerSlot bits,
+	// only in Electra and later attestations, which aggregate committees.
+	CommitteeBits hexutil.Bytes `json:"committee_bits,omitempty"`
+}
+
+// DepositData is the content of a deposit contract log.
+type DepositData struct {
+	Pubkey                BLSPubkey    `json:"pubkey"`
+	WithdrawalCredentials common.Hash  `json:"withdrawal_credentials"`
+	Amount                uint64       `json:"amount,string"`
+	Signature             BLSSignature `json:"signature"`
+}
+
+// Deposit is a deposit with its proof against the deposit root.
+type Deposit struct {
+	Proof []common.Hash `json:"proof"`
+	Data  DepositData   `json:"data"`
+}
+
+// VoluntaryExit is a validator's request to exit.
+type VoluntaryExit struct {
+	Epoch          uint64 `json:"epoch,string"`
+	ValidatorIndex uint64 `json:"validator_index,string"`
+}
+
+// SignedVoluntaryExit is a voluntary exit with its signature.
+type SignedVoluntaryExit struct {
+	Message   VoluntaryExit `json:"message"`
+	Signature BLSSignature  `json:"signature"`
+}
+
+// SyncAggregate is the sync committee's aggregate signature.
+type SyncAggregate struct {
+	// SyncCommitteeBits is an SSZ bitvector of SyncCommitteeSize bits.
+	SyncCommitteeBits      hexutil.Bytes `json:"sync_committee_bits"`
+	SyncCommitteeSignature BLSSignature  `json:"sync_committee_signature"`
+}
+
+// Withdrawal is a withdrawal processed by the execution payload.
+type Withdrawal struct {
+	Index          uint64         `json:"index,string"`
+	ValidatorIndex uint64         `json:"validator_index,string"`
+	Address        common.Address `json:"address"`
+	Amount         uint64         `json:"amount,string"`
+}
+
+// ExecutionPayload is the Deneb execution payload.
+type ExecutionPayload struct {
+	ParentHash    common.Hash     `json:"parent_hash"`
+	FeeRecipient  common.Address  `json:"fee_recipient"`
+	StateRoot     common.Hash     `json:"state_root"`
+	ReceiptsRoot  common.Hash     `json:"receipts_root"`
+	LogsBloom     types.Bloom     `json:"logs_bloom"`
+	PrevRandao    common.Hash     `json:"prev_randao"`
+	BlockNumber   uint64          `json:"block_number,string"`
+	GasLimit      uint64          `json:"gas_limit,string"`
+	GasUsed       uint64          `json:"gas_used,string"`
+	Timestamp     uint64          `json:"timestamp,string"`
+	ExtraData     hexutil.Bytes   `json:"extra_data"`
+	BaseFeePerGas uint256.Int     `json:"base_fee_per_gas"`
+	BlockHash     common.Hash     `json:"block_hash"`
+	Transactions  []hexutil.Bytes `json:"transactions"`
+	Withdrawals   []Withdrawal    `json:"withdrawals"`
+	BlobGasUsed   uint64          `json:"blob_gas_used,string"`
+	ExcessBlobGas uint64          `json:"excess_blob_gas,string"`
+}
+
+// DepositRequest is a deposit the execution layer passed to the beacon
+// chain.
+type DepositRequest struct {
+	Pubkey                BLSPubkey    `json:"pubkey"`
+	WithdrawalCredentials common.Hash  `json:"withdrawal_credentials"`
+	Amount                uint64       `json:"amount,string"`
+	Signature             BLSSignature `json:"signature"`
+	Index                 uint64       `json:"index,string"`
+}
+
+// WithdrawalRequest is a withdrawal triggered from a validator's
+// withdrawal address.
+type WithdrawalRequest struct {
+	SourceAddress   common.Address `json:"source_address"`
+	ValidatorPubkey BLSPubkey      `json:"validator_pubkey"`
+	Amount          uint64         `json:"amount,string"`
+}
+
+// ConsolidationRequest moves the balance of one validator to another.
+type ConsolidationRequest struct {
+	SourceAddress common.Address `json:"source_address"`
+	SourcePubkey  BLSPubkey      `json:"source_pubkey"`
+	TargetPubkey  BLSPubkey      `json:"target_pubkey"`
+}
+
+// ExecutionRequests are the requests of the execution payload, in Electra
+// and later bodies.
+type ExecutionRequests struct {
+	Deposits       []DepositRequest       `json:"deposits"`
+	Withdrawals    []WithdrawalRequest    `json:"withdrawals"`
+	Consolidations []ConsolidationRequest `json:"consolidations"`
+}
+
+// BLSToExecutionChange switches a validator to an execution address.
+type BLSToExecutionChange struct {
+	ValidatorIndex     uint64         `json:"validator_index,string"`
+	FromBLSPubkey      BLSPubkey      `json:"from_bls_pubkey"`
+	ToExecutionAddress common.Address `json:"to_execution_address"`
+}
+
+// SignedBLSToExecutionChange is a BLSToExecutionChange with its signature.
+type SignedBLSToExecutionChange struct {
+	Message   BLSToExecutionChange `json:"message"`
+	Signature BLSSignature         `json:"signature"`
+}
+
+// Validate checks the lengths that JSON decoding cannot enforce, with the
+// limits of the body's fork, and that the body has the fields of its fork.
+func (b *BeaconBlockBody) Validate() error {
+	l, ok := bodyLayouts[b.Version]
+	if !ok {
+		return unsupportedFork(b.Version)
+	}
+	if l.electra != (b.ExecutionRequests != nil) {
+		if l.electra {
+			return fmt.Errorf("%s body has no execution_requests", b.Version)
+		}
+		return fmt.Errorf("%s body has execution_requests", b.Version)
+	}
+	lists := []struct {
+		name     string
+		n, limit int
+	}{
+		{"proposer_slashings", len(b.ProposerSlashings), MaxProposerSlashings},
+		{"attester_slashings", len(b.AttesterSlashings), l.attesterSlashings},
+		{"attestations", len(b.Attestations), l.attestations},
+		{"deposits", len(b.Deposits), MaxDeposits},
+		{"voluntary_exits", len(b.VoluntaryExits), MaxVoluntaryExits},
+		{"bls_to_execution_changes", len(b.BLSToExecutionChanges), MaxBLSToExecutionChanges},
+		{"blob_kzg_commitments", len(b.BlobKZGCommitments), MaxBlobCommitmentsPerBlock},
+		{"transactions", len(b.ExecutionPayload.Transactions), MaxTransactionsPerPayload},
+		{"withdrawals", len(b.ExecutionPayload.Withdrawals), MaxWithdrawalsPerPayload},
+		{"extra_data", len(b.ExecutionPayload.ExtraData), MaxExtraDataBytes},
+	}
+	if r := b.ExecutionRequests; r != nil {
+		lists = append(lists, []struct {
+			name     string
+			n, limit int
+		}{
+			{"deposit requests", len(r.Deposits), MaxDepositRequestsPerPayload},
+			{"withdrawal requests", len(r.Withdrawals), MaxWithdrawalRequestsPerPayload},
+			{"consolidation requests", len(r.Consolidations), MaxConsolidationRequestsPerPayload},
+		}...)
+	}
+	for _, l := range lists {
+		if l.n > l.limit {
+			return fmt.Errorf("too many %s: %d, max %d", l.name, l.n, l.limit)
+		}
+	}
+	for i, s := range b.AttesterSlashings {
+		for _, a := range []IndexedAttestation{s.Attestation1, s.Attestation2} {
+			if len(a.AttestingIndices) > l.attestingValidators {
+				return fmt.Errorf("attester slashing %d: too many attesting indices: %d", i, len(a.AttestingIndices))
+			}
+		}
+	}
+	for i, a := range b.Attestations {
+		if _, _, err := parseBitlist(a.AggregationBits, l.attestingValidators); err != nil {
+			return fmt.Errorf("attestation %d: %w", i, err)
+		}
+		switch n := len(a.CommitteeBits); {
+		case l.electra && n != MaxCommitteesPerSlot/8:
+			return fmt.Errorf("attestation %d: invalid committee_bits length %d, want %d", i, n, MaxCommitteesPerSlot/8)
+		case !l.electra && n != 0:
+			return fmt.Errorf("attestation %d: %s attestation has committee_bits", i, b.Version)
+		}
+	}
+	for i, d := range b.Deposits {
+		if len(d.Proof) != DepositProofLength {
+			return fmt.Errorf("deposit %d: proof has %d hashes, want %d", i, len(d.Proof), DepositProofLength)
+		}
+	}
+	for i, tx := range b.ExecutionPayload.Transactions {
+		if len(tx) > MaxBytesPerTransaction {
+			return fmt.Errorf("transaction %d too large: %d bytes", i, len(tx))
+		}
+	}
+	if n := len(b.SyncAggregate.SyncCommitteeBits); n != SyncCommitteeSize/8 {
+		return fmt.Errorf("invalid sync_committee_bits length %d, want %d", n, SyncCommitteeSize/8)
+	}
+	return nil
+}
+
+// parseBitlist strips the delimiter bit from an SSZ bitlist, returning the
+// packed bits and the number of bits.
+func parseBitlist(b []byte, limit int) ([]byte, int, error) {
+	if len(b) == 0 || b[len(b)-1] == 0 {
+		return nil, 0, errors.New("invalid bitlist: missing delimiter bit")
+	}
+	last := b[len(b)-1]
+	msb := 7
+	for last>>msb == 0 {
+		msb--
+	}
+	n := (len(b)-1)*8 + msb
+	if n > limit {
+		return nil, 0, fmt.Errorf("bitlist too long: %d bits, max %d", n, limit)
+	}
+	bits := append([]byte(nil), b...)
+	bits[len(bits)-1] &^= 1 << msb
+	// Drop the byte that only held the delimiter.
+	bits = bits[:(n+7)/8]
+	return bits, n, nil
+}
+
+// HashTreeRoot returns the SSZ hash tree root of the body, which is the
+// body_root of its block header.
+func (b *BeaconBlockBody) HashTreeRoot() (common.Hash, error) {
+	fields, err := b.fieldRoots()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return containerRoot(fields...), nil
+}
+
+// fieldRoots returns the hash tree roots of the body's fields in order.
+func (b *BeaconBlockBody) fieldRoots() ([]common.Hash, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	l := bodyLayouts[b.Version]
+	fields := []common.Hash{
+		bytesRoot(b.RandaoReveal[:]),
+		b.Eth1Data.hashTreeRoot(),
+		b.Graffiti,
+		listRoot(mapRoots(b.ProposerSlashings, ProposerSlashing.hashTreeRoot), MaxProposerSlashings),
+		listRoot(mapRoots(b.AttesterSlashings, func(s AttesterSlashing) common.Hash { return s.hashTreeRoot(l) }), uint64(l.attesterSlashings)),
+		listRoot(mapRoots(b.Attestations, func(a Attestation) common.Hash { return a.hashTreeRoot(l) }), uint64(l.attestations)),
+		listRoot(mapRoots(b.Deposits, Deposit.hashTreeRoot), MaxDeposits),
+		listRoot(mapRoots(b.VoluntaryExits, SignedVoluntaryExit.hashTreeRoot), MaxVoluntaryExits),
+		b.SyncAggregate.hashTreeRoot(),
+		b.ExecutionPayload.hashTreeRoot(),
+		listRoot(mapRoots(b.BLSToExecutionChanges, SignedBLSToExecutionChange.hashTreeRoot), MaxBLSToExecutionChanges),
+		listRoot(mapRoots(b.BlobKZGCommitments, commitmentRoot), MaxBlobCommitmentsPerBlock),
+	}
+	if l.electra {
+		fields = append(fields, b.ExecutionRequests.hashTreeRoot())
+	}
+	return fields, nil
+}
+
+func mapRoots[T any](items []T, root func(T) common.Hash) []common.Hash {
+	roots := make([]common.Hash, len(items))
+	for i, item := range items {
+		roots[i] = root(item)
+	}
+	return roots
+}
+
+// commitmentRoot returns the hash tree root of a KZG commitment.
+func commitmentRoot(c kzg4844.Commitment) common.Hash {
+	return bytesRoot(c[:])
+}
+
+// HashTreeRoot returns the SSZ hash tree root of the header, which is the
+// block root.
+func (h *BeaconBlockHeader) HashTreeRoot() common.Hash {
+	return containerRoot(
+		uint64Root(h.Slot),
+		uint64Root(h.ProposerIndex),
+		h.ParentRoot,
+		h.StateRoot,
+		h.BodyRoot,
+	)
+}
+
+func (h SignedBeaconBlockHeader) hashTreeRoot() common.Hash {
+	return containerRoot(h.Message.HashTreeRoot(), bytesRoot(h.Signature[:]))
+}
+
+func (d Eth1Data) hashTreeRoot() common.Hash {
+	return containerRoot(d.DepositRoot, uint64Root(d.DepositCount), d.BlockHash)
+}
+
+func (s ProposerSlashing) hashTreeRoot() common.Hash {
+	return containerRoot(s.SignedHeader1.hashTreeRoot(), s.SignedHeader2.hashTreeRoot())
+}
+
+func (c Checkpoint) hashTreeRoot() common.Hash {
+	return containerRoot(uint64Root(c.Epoch), c.Root)
+}
+
+func (d AttestationData) hashTreeRoot() common.Hash {
+	return containerRoot(
+		uint64Root(d.Slot),
+		uint64Root(d.Index),
+		d.BeaconBlockRoot,
+		d.Source.hashTreeRoot(),
+		d.Target.hashTreeRoot(),
+	)
+}
+
+func (a IndexedAttestation) hashTreeRoot(l *bodyLayout) common.Hash {
+	packed := make([]byte, 0, len(a.AttestingIndices)*8)
+	for _, i := range a.AttestingIndices {
+		packed = binary.LittleEndian.AppendUint64(packed, i)
+	}
+	indices := mixInLength(merkleize(packBytes(packed), uint64(l.attestingValidators)*8/32), uint64(len(a.AttestingIndices)))
+	return containerRoot(indices, a.Data.hashTreeRoot(), bytesRoot(a.Signature[:]))
+}
+
+func (s AttesterSlashing) hashTreeRoot(l *bodyLayout) common.Hash {
+	return containerRoot(s.Attestation1.hashTreeRoot(l), s.Attestation2.hashTreeRoot(l))
+}
+
+func (a Attestation) hashTreeRoot(l *bodyLayout) common.Hash {
+	bits, n, _ := parseBitlist(a.AggregationBits, l.attestingValidators)
+	bitsRoot := mixInLength(merkleize(packBytes(bits), uint64(l.attestingValidators+255)/256), uint64(n))
+	if l.electra {
+		return containerRoot(bitsRoot, a.Data.hashTreeRoot(), bytesRoot(a.Signature[:]), bytesRoot(a.CommitteeBits))
+	}
+	return containerRoot(bitsRoot, a.Data.hashTreeRoot(), bytesRoot(a.Signature[:]))
+}
+
+func (d DepositData) hashTreeRoot() common.Hash {
+	return containerRoot(
+		bytesRoot(d.Pubkey[:]),
+		d.WithdrawalCredentials,
+		uint64Root(d.Amount),
+		bytesRoot(d.Signature[:]),
+	)
+}
+
+func (d Deposit) hashTreeRoot() common.Hash {
+	return containerRoot(merkleize(d.Proof, DepositProofLength), d.Data.hashTreeRoot())
+}
+
+func (e VoluntaryExit) hashTreeRoot() common.Hash {
+	return containerRoot(uint64Root(e.Epoch), uint64Root(e.ValidatorIndex))
+}
+
+func (e SignedVoluntaryExit) hashTreeRoot() common.Hash {
+	return containerRoot(e.Message.hashTreeRoot(), bytesRoot(e.Signature[:]))
+}
+
+func (s SyncAggregate) hashTreeRoot() common.Hash {
+	return containerRoot(bytesRoot(s.SyncCommitteeBits), bytesRoot(s.SyncCommitteeSignature[:]))
+}
+
+func (w Withdrawal) hashTreeRoot() common.Hash {
+	return containerRoot(
+		uint64Root(w.Index),
+		uint64Root(w.ValidatorIndex),
+		bytesRoot(w.Address[:]),
+		uint64Root(w.Amount),
+	)
+}
+
+func (p ExecutionPayload) hashTreeRoot() common.Hash {
+	baseFee := p.BaseFeePerGas.Bytes32()
+	slices.Reverse(baseFee[:]) // SSZ integers are little-endian
+	txs := make([]common.Hash, len(p.Transactions))
+	for i, tx := range p.Transactions {
+		txs[i] = byteListRoot(tx, MaxBytesPerTransaction)
+	}
+	return containerRoot(
+		p.ParentHash,
+		bytesRoot(p.FeeRecipient[:]),
+		p.StateRoot,
+		p.ReceiptsRoot,
+		bytesRoot(p.LogsBloom[:]),
+		p.PrevRandao,
+		uint64Root(p.BlockNumber),
+		uint64Root(p.GasLimit),
+		uint64Root(p.GasUsed),
+		uint64Root(p.Timestamp),
+		byteListRoot(p.ExtraData, MaxExtraDataBytes),
+		baseFee,
+		p.BlockHash,
+		listRoot(txs, MaxTransactionsPerPayload),
+		listRoot(mapRoots(p.Withdrawals, Withdrawal.hashTreeRoot), MaxWithdrawalsPerPayload),
+		uint64Root(p.BlobGasUsed),
+		uint64Root(p.ExcessBlobGas),
+	)
+}
+
+func (c BLSToExecutionChange) hashTreeRoot() common.Hash {
+	return containerRoot(uint64Root(c.ValidatorIndex), bytesRoot(c.FromBLSPubkey[:]), bytesRoot(c.ToExecutionAddress[:]))
+}
+
+func (c SignedBLSToExecutionChange) hashTreeRoot() common.Hash {
+	return containerRoot(c.Message.hashTreeRoot(), bytesRoot(c.Signature[:]))
+}
+
+func (r DepositRequest) hashTreeRoot() common.Hash {
+	return containerRoot(
+		bytesRoot(r.Pubkey[:]),
+		r.WithdrawalCredentials,
+		uint64Root(r.Amount),
+		bytesRoot(r.Signature[:]),
+		uint64Root(r.Index),
+	)
+}
+
+func (r WithdrawalRequest) hashTreeRoot() common.Hash {
+	return containerRoot(bytesRoot(r.SourceAddress[:]), bytesRoot(r.ValidatorPubkey[:]), uint64Root(r.Amount))
+}
+
+func (r ConsolidationRequest) hashTreeRoot() common.Hash {
+	return containerRoot(bytesRoot(r.SourceAddress[:]), bytesRoot(r.SourcePubkey[:]), bytesRoot(r.TargetPubkey[:]))
+}
+
+func (r *ExecutionRequests) hashTreeRoot() common.Hash {
+	return containerRoot(
+		listRoot(mapRoots(r.Deposits, DepositRequest.hashTreeRoot), MaxDepositRequestsPerPayload),
+		listRoot(mapRoots(r.Withdrawals, WithdrawalRequest.hashTreeRoot), MaxWithdrawalRequestsPerPayload),
+		listRoot(mapRoots(r.Consolidations, ConsolidationRequest.hashTreeRoot), MaxConsolidationRequestsPerPayload),
+	)
+}
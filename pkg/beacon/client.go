@@ -0,0 +1,172 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"kzg-blob-poc/pkg/metrics"
+)
+
+// ErrNotFound is returned when the node has no such object, such as the
+// block of a slot that was missed.
+var ErrNotFound = errors.New("not found")
+
+// Client is a minimal client for the standard beacon node HTTP API.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient returns a client for the beacon node at baseURL. A nil
+// httpClient uses http.DefaultClient.
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), http: httpClient}
+}
+
+// BlobSidecars fetches the blob sidecars of the block identified by blockID
+// (a slot, block root, "head", "genesis" or "finalized"). When indices is
+// non-empty only those sidecars are requested.
+func (c *Client) BlobSidecars(ctx context.Context, blockID string, indices ...uint64) ([]*BlobSidecar, error) {
+	path := "/eth/v1/beacon/blob_sidecars/" + url.PathEscape(blockID)
+	if len(indices) > 0 {
+		query := url.Values{}
+		for _, i := range indices {
+			query.Add("indices", strconv.FormatUint(i, 10))
+		}
+		path += "?" + query.Encode()
+	}
+
+	var sidecars []*BlobSidecar
+	if err := c.get(ctx, "/eth/v1/beacon/blob_sidecars/{block_id}", path, &sidecars); err != nil {
+		return nil, err
+	}
+	return sidecars, nil
+}
+
+// Block fetches the signed beacon block identified by blockID, decoded for
+// the fork the response's version names. A block of a fork without a body
+// model is an error.
+func (c *Client) Block(ctx context.Context, blockID string) (*SignedBeaconBlock, error) {
+	path := "/eth/v2/beacon/blocks/" + url.PathEscape(blockID)
+	var data json.RawMessage
+	version, err := c.getVersioned(ctx, "/eth/v2/beacon/blocks/{block_id}", path, &data)
+	if err != nil {
+		return nil, err
+	}
+	block, err := DecodeSignedBeaconBlock(version, data)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", path, err)
+	}
+	return block, nil
+}
+
+// BlockHeader fetches the root and signed header of the block identified
+// by blockID. The root is the node's, so it names the block without
+// depending on this package modelling its body.
+func (c *Client) BlockHeader(ctx context.Context, blockID string) (common.Hash, *SignedBeaconBlockHeader, error) {
+	var header struct {
+		Root   common.Hash             `json:"root"`
+		Header SignedBeaconBlockHeader `json:"header"`
+	}
+	if err := c.get(ctx, "/eth/v1/beacon/headers/{block_id}", "/eth/v1/beacon/headers/"+url.PathEscape(blockID), &header); err != nil {
+		return common.Hash{}, nil, err
+	}
+	return header.Root, &header.Header, nil
+}
+
+// GenesisTime returns the chain's genesis time in Unix seconds.
+func (c *Client) GenesisTime(ctx context.Context) (uint64, error) {
+	var genesis struct {
+		GenesisTime uint64 `json:"genesis_time,string"`
+	}
+	if err := c.get(ctx, "/eth/v1/beacon/genesis", "/eth/v1/beacon/genesis", &genesis); err != nil {
+		return 0, err
+	}
+	return genesis.GenesisTime, nil
+}
+
+// SecondsPerSlot returns the slot duration from the node's chain spec.
+func (c *Client) SecondsPerSlot(ctx context.Context) (uint64, error) {
+	var spec struct {
+		SecondsPerSlot uint64 `json:"SECONDS_PER_SLOT,string"`
+	}
+	if err := c.get(ctx, "/eth/v1/config/spec", "/eth/v1/config/spec", &spec); err != nil {
+		return 0, err
+	}
+	if spec.SecondsPerSlot == 0 {
+		return 0, fmt.Errorf("spec has no SECONDS_PER_SLOT")
+	}
+	return spec.SecondsPerSlot, nil
+}
+
+// SlotAt returns the slot of the block produced at timestamp, using the
+// node's genesis time and slot duration.
+func (c *Client) SlotAt(ctx context.Context, timestamp uint64) (uint64, error) {
+	genesis, err := c.GenesisTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+	secondsPerSlot, err := c.SecondsPerSlot(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if timestamp < genesis {
+		return 0, fmt.Errorf("timestamp %d is before genesis %d", timestamp, genesis)
+	}
+	return (timestamp - genesis) / secondsPerSlot, nil
+}
+
+// get performs a GET request and decodes the "data" field of the response
+// into v. Failures are counted in the metrics under route, the path with
+// its parameters left as placeholders.
+func (c *Client) get(ctx context.Context, route, path string, v any) error {
+	_, err := c.getVersioned(ctx, route, path, v)
+	return err
+}
+
+// getVersioned is get for responses that also name the fork of their data
+// in a "version" field, which it returns.
+func (c *Client) getVersioned(ctx context.Context, route, path string, v any) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		metrics.RPCError(route)
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("GET %s: %w", path, ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		metrics.RPCError(route)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("GET %s: %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	envelope := struct {
+		Version string `json:"version"`
+		Data    any    `json:"data"`
+	}{Data: v}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return "", fmt.Errorf("GET %s: failed to decode response: %w", path, err)
+	}
+	return envelope.Version, nil
+}
@@ -0,0 +1,108 @@
+package beacon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"kzg-blob-poc/pkg/metrics"
+)
+
+// Event is one server-sent event of the beacon node's event stream.
+type Event struct {
+	Topic string
+	Data  json.RawMessage
+}
+
+// HeadEvent is the data of a "head" event, sent when the node's head block
+// changes.
+type HeadEvent struct {
+	Slot  uint64      `json:"slot,string"`
+	Block common.Hash `json:"block"`
+	// EpochTransition is set when the block is the first of an epoch.
+	EpochTransition bool `json:"epoch_transition"`
+}
+
+// Events subscribes to the node's event stream for topics, such as "head"
+// or "block", and calls fn with each event until ctx is done, the stream
+// ends or fn returns an error. It returns that error, or nil when ctx is
+// done. The stream stays open indefinitely, so the client's HTTP client
+// must not time out requests.
+func (c *Client) Events(ctx context.Context, topics []string, fn func(Event) error) error {
+	const route = "/eth/v1/events"
+	path := route + "?" + url.Values{"topics": {strings.Join(topics, ",")}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		metrics.RPCError(route)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		metrics.RPCError(route)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GET %s: %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	err = readEvents(resp.Body, fn)
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err == nil {
+		err = fmt.Errorf("GET %s: event stream closed", path)
+	}
+	return err
+}
+
+// readEvents parses a text/event-stream, calling fn with each event that
+// has data. Comments, used by nodes as keep-alives, and fields other than
+// event and data are ignored.
+func readEvents(r io.Reader, fn func(Event) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	var (
+		ev   Event
+		data []string
+	)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			if len(data) > 0 {
+				ev.Data = json.RawMessage(strings.Join(data, "\n"))
+				if err := fn(ev); err != nil {
+					return err
+				}
+			}
+			ev, data = Event{}, nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			ev.Topic = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	return sc.Err()
+}
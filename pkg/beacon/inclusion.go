@@ -0,0 +1,96 @@
+package beacon
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// ErrInclusionProofMismatch is returned when a KZG commitment inclusion
+// proof does not lead to the expected body root. It wraps
+// blob.ErrProofMismatch.
+var ErrInclusionProofMismatch = fmt.Errorf("inclusion %w", blob.ErrProofMismatch)
+
+// blobKZGCommitmentsField is the position of blob_kzg_commitments in the
+// body. Deneb bodies have 12 fields and Electra ones 13, so both are a tree
+// of 16 leaves and the commitment has the same generalized index.
+const blobKZGCommitmentsField = 11
+
+// KZGCommitmentInclusionProof returns the Merkle branch proving that the
+// commitment at index is part of the body. The branch runs from the
+// commitment through the commitments list, its length mix-in and the body
+// fields, as in the kzg_commitment_inclusion_proof of a BlobSidecar.
+func (b *BeaconBlockBody) KZGCommitmentInclusionProof(index int) ([KZGCommitmentInclusionProofDepth]common.Hash, error) {
+	var proof [KZGCommitmentInclusionProofDepth]common.Hash
+	if index < 0 || index >= len(b.BlobKZGCommitments) {
+		return proof, fmt.Errorf("commitment index %d out of range: body has %d commitments", index, len(b.BlobKZGCommitments))
+	}
+	fields, err := b.fieldRoots()
+	if err != nil {
+		return proof, err
+	}
+
+	commitments := mapRoots(b.BlobKZGCommitments, commitmentRoot)
+	branch := merkleBranch(commitments, MaxBlobCommitmentsPerBlock, index)
+	branch = append(branch, uint64Root(uint64(len(commitments))))
+	branch = append(branch, merkleBranch(fields, uint64(len(fields)), blobKZGCommitmentsField)...)
+	copy(proof[:], branch)
+	return proof, nil
+}
+
+// VerifyKZGCommitmentInclusionProof checks that proof places commitment at
+// index of the blob_kzg_commitments of the body with the given root.
+func VerifyKZGCommitmentInclusionProof(commitment kzg4844.Commitment, index uint64, proof [KZGCommitmentInclusionProofDepth]common.Hash, bodyRoot common.Hash) error {
+	if index >= MaxBlobCommitmentsPerBlock {
+		return fmt.Errorf("commitment index %d out of range, max %d", index, MaxBlobCommitmentsPerBlock-1)
+	}
+	// The generalized index of the commitment, relative to the body root:
+	// the body field, then the data half of the list's length mix-in, then
+	// the position within the list.
+	subtreeIndex := uint64(blobKZGCommitmentsField)
+	subtreeIndex = subtreeIndex << 1
+	subtreeIndex = subtreeIndex<<treeDepth(MaxBlobCommitmentsPerBlock) | index
+
+	if !isValidMerkleBranch(commitmentRoot(commitment), proof[:], subtreeIndex, bodyRoot) {
+		return fmt.Errorf("%w: commitment %d does not lead to body root %x", ErrInclusionProofMismatch, index, bodyRoot)
+	}
+	return nil
+}
+
+// VerifyInclusionProof checks the sidecar's kzg_commitment_inclusion_proof
+// against the body root in its signed block header.
+func (s *BlobSidecar) VerifyInclusionProof() error {
+	return VerifyKZGCommitmentInclusionProof(s.KZGCommitment, s.Index, s.KZGCommitmentInclusionProof, s.SignedBlockHeader.Message.BodyRoot)
+}
+
+// NewBlobSidecar builds the sidecar for the blob at index of block, filling
+// in the signed block header and the commitment inclusion proof. The
+// commitment must match the one the block body lists at index.
+func NewBlobSidecar(block *SignedBeaconBlock, index int, b *kzg4844.Blob, commitment kzg4844.Commitment, proof kzg4844.Proof) (*BlobSidecar, error) {
+	body := &block.Message.Body
+	if index < 0 || index >= len(body.BlobKZGCommitments) {
+		return nil, fmt.Errorf("blob index %d out of range: block has %d commitments", index, len(body.BlobKZGCommitments))
+	}
+	if want := body.BlobKZGCommitments[index]; commitment != want {
+		return nil, fmt.Errorf("%w: blob commits to %x, block lists %x at index %d", blob.ErrCommitmentMismatch, commitment[:], want[:], index)
+	}
+	header, err := block.SignedHeader()
+	if err != nil {
+		return nil, err
+	}
+	inclusionProof, err := body.KZGCommitmentInclusionProof(index)
+	if err != nil {
+		return nil, err
+	}
+	return &BlobSidecar{
+		Index:                       uint64(index),
+		Blob:                        *b,
+		KZGCommitment:               commitment,
+		KZGProof:                    proof,
+		SignedBlockHeader:           header,
+		KZGCommitmentInclusionProof: inclusionProof,
+	}, nil
+}
@@ -0,0 +1,126 @@
+package beacon
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// The beacon API encodes integers as decimal strings and byte fields as
+// 0x-prefixed hex.
+
+type beaconBlockHeaderJSON struct {
+	Slot          uint64      `json:"slot,string"`
+	ProposerIndex uint64      `json:"proposer_index,string"`
+	ParentRoot    common.Hash `json:"parent_root"`
+	StateRoot     common.Hash `json:"state_root"`
+	BodyRoot      common.Hash `json:"body_root"`
+}
+
+type signedBeaconBlockHeaderJSON struct {
+	Message   beaconBlockHeaderJSON `json:"message"`
+	Signature hexutil.Bytes         `json:"signature"`
+}
+
+type indexedAttestationJSON struct {
+	AttestingIndices []string        `json:"attesting_indices"`
+	Data             AttestationData `json:"data"`
+	Signature        BLSSignature    `json:"signature"`
+}
+
+// MarshalJSON encodes the header in the beacon API format.
+func (h SignedBeaconBlockHeader) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&signedBeaconBlockHeaderJSON{
+		Message:   beaconBlockHeaderJSON(h.Message),
+		Signature: h.Signature[:],
+	})
+}
+
+// UnmarshalJSON decodes a header in the beacon API format.
+func (h *SignedBeaconBlockHeader) UnmarshalJSON(input []byte) error {
+	var dec signedBeaconBlockHeaderJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if n := len(dec.Signature); n != len(h.Signature) {
+		return fmt.Errorf("invalid signature length %d", n)
+	}
+	h.Message = BeaconBlockHeader(dec.Message)
+	copy(h.Signature[:], dec.Signature)
+	return nil
+}
+
+// MarshalJSON encodes the attestation in the beacon API format.
+func (a IndexedAttestation) MarshalJSON() ([]byte, error) {
+	enc := indexedAttestationJSON{
+		AttestingIndices: make([]string, len(a.AttestingIndices)),
+		Data:             a.Data,
+		Signature:        a.Signature,
+	}
+	for i, v := range a.AttestingIndices {
+		enc.AttestingIndices[i] = strconv.FormatUint(v, 10)
+	}
+	return json.Marshal(&enc)
+}
+
+// UnmarshalJSON decodes an attestation in the beacon API format.
+func (a *IndexedAttestation) UnmarshalJSON(input []byte) error {
+	var dec indexedAttestationJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	indices := make([]uint64, len(dec.AttestingIndices))
+	for i, v := range dec.AttestingIndices {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid attesting index %q", v)
+		}
+		indices[i] = n
+	}
+	a.AttestingIndices, a.Data, a.Signature = indices, dec.Data, dec.Signature
+	return nil
+}
+
+type blobSidecarJSON struct {
+	Index                       uint64                  `json:"index,string"`
+	Blob                        kzg4844.Blob            `json:"blob"`
+	KZGCommitment               kzg4844.Commitment      `json:"kzg_commitment"`
+	KZGProof                    kzg4844.Proof           `json:"kzg_proof"`
+	SignedBlockHeader           SignedBeaconBlockHeader `json:"signed_block_header"`
+	KZGCommitmentInclusionProof []common.Hash           `json:"kzg_commitment_inclusion_proof"`
+}
+
+// MarshalJSON encodes the sidecar in the beacon API format.
+func (s *BlobSidecar) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&blobSidecarJSON{
+		Index:                       s.Index,
+		Blob:                        s.Blob,
+		KZGCommitment:               s.KZGCommitment,
+		KZGProof:                    s.KZGProof,
+		SignedBlockHeader:           s.SignedBlockHeader,
+		KZGCommitmentInclusionProof: s.KZGCommitmentInclusionProof[:],
+	})
+}
+
+// UnmarshalJSON decodes a sidecar in the beacon API format.
+func (s *BlobSidecar) UnmarshalJSON(input []byte) error {
+	var dec blobSidecarJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if n := len(dec.KZGCommitmentInclusionProof); n != KZGCommitmentInclusionProofDepth {
+		return fmt.Errorf("invalid inclusion proof length %d, want %d", n, KZGCommitmentInclusionProofDepth)
+	}
+
+	s.Index = dec.Index
+	s.Blob = dec.Blob
+	s.KZGCommitment = dec.KZGCommitment
+	s.KZGProof = dec.KZGProof
+	s.SignedBlockHeader = dec.SignedBlockHeader
+	copy(s.KZGCommitmentInclusionProof[:], dec.KZGCommitmentInclusionProof)
+	return nil
+}
@@ -0,0 +1,140 @@
+package beacon
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// maxMerkleDepth covers the deepest tree hashed here: a transaction of up to
+// 2^30 bytes is 2^25 chunks, inside a list of up to 2^20 transactions.
+const maxMerkleDepth = 48
+
+// zeroHashes[i] is the root of a tree of depth i whose leaves are all zero.
+var zeroHashes = func() (z [maxMerkleDepth + 1]common.Hash) {
+	for i := 1; i < len(z); i++ {
+		z[i] = hashPair(z[i-1], z[i-1])
+	}
+	return z
+}()
+
+func hashPair(a, b common.Hash) common.Hash {
+	h := sha256.New()
+	h.Write(a[:])
+	h.Write(b[:])
+	var out common.Hash
+	h.Sum(out[:0])
+	return out
+}
+
+// treeDepth returns the depth of a tree with room for limit leaves.
+func treeDepth(limit uint64) int {
+	if limit <= 1 {
+		return 0
+	}
+	return bits.Len64(limit - 1)
+}
+
+// merkleize computes the SSZ Merkle root of chunks, padded with zero chunks
+// to the next power of two of limit.
+func merkleize(chunks []common.Hash, limit uint64) common.Hash {
+	depth := treeDepth(limit)
+	if len(chunks) == 0 {
+		return zeroHashes[depth]
+	}
+	layer := append([]common.Hash(nil), chunks...)
+	for d := 0; d < depth; d++ {
+		if len(layer)%2 == 1 {
+			layer = append(layer, zeroHashes[d])
+		}
+		for i := 0; i < len(layer)/2; i++ {
+			layer[i] = hashPair(layer[2*i], layer[2*i+1])
+		}
+		layer = layer[:len(layer)/2]
+	}
+	return layer[0]
+}
+
+// merkleBranch returns the sibling hashes on the path from chunks[index] to
+// the root of merkleize(chunks, limit), ordered from the leaf upwards.
+func merkleBranch(chunks []common.Hash, limit uint64, index int) []common.Hash {
+	depth := treeDepth(limit)
+	branch := make([]common.Hash, depth)
+	layer := append([]common.Hash(nil), chunks...)
+	for d := 0; d < depth; d++ {
+		if len(layer)%2 == 1 {
+			layer = append(layer, zeroHashes[d])
+		}
+		if sibling := index ^ 1; sibling < len(layer) {
+			branch[d] = layer[sibling]
+		} else {
+			branch[d] = zeroHashes[d]
+		}
+		for i := 0; i < len(layer)/2; i++ {
+			layer[i] = hashPair(layer[2*i], layer[2*i+1])
+		}
+		layer = layer[:len(layer)/2]
+		index /= 2
+	}
+	return branch
+}
+
+// isValidMerkleBranch implements is_valid_merkle_branch from the consensus
+// specs.
+func isValidMerkleBranch(leaf common.Hash, branch []common.Hash, index uint64, root common.Hash) bool {
+	value := leaf
+	for i, sibling := range branch {
+		if index>>i&1 == 1 {
+			value = hashPair(sibling, value)
+		} else {
+			value = hashPair(value, sibling)
+		}
+	}
+	return value == root
+}
+
+// mixInLength mixes the length of a list into its Merkle root.
+func mixInLength(root common.Hash, length uint64) common.Hash {
+	return hashPair(root, uint64Root(length))
+}
+
+// uint64Root returns the hash tree root of a uint64.
+func uint64Root(v uint64) common.Hash {
+	var h common.Hash
+	binary.LittleEndian.PutUint64(h[:], v)
+	return h
+}
+
+// packBytes splits b into zero-padded 32-byte chunks.
+func packBytes(b []byte) []common.Hash {
+	chunks := make([]common.Hash, (len(b)+31)/32)
+	for i := range chunks {
+		copy(chunks[i][:], b[i*32:])
+	}
+	return chunks
+}
+
+// bytesRoot returns the hash tree root of a fixed-size byte vector.
+func bytesRoot(b []byte) common.Hash {
+	return merkleize(packBytes(b), uint64(len(b)+31)/32)
+}
+
+// byteListRoot returns the hash tree root of a byte list of at most limit
+// bytes.
+func byteListRoot(b []byte, limit uint64) common.Hash {
+	return mixInLength(merkleize(packBytes(b), (limit+31)/32), uint64(len(b)))
+}
+
+// listRoot returns the hash tree root of a list of composite elements,
+// given their roots.
+func listRoot(roots []common.Hash, limit uint64) common.Hash {
+	return mixInLength(merkleize(roots, limit), uint64(len(roots)))
+}
+
+// containerRoot returns the hash tree root of a container, given the roots
+// of its fields.
+func containerRoot(fields ...common.Hash) common.Hash {
+	return merkleize(fields, uint64(len(fields)))
+}
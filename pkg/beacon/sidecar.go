@@ -0,0 +1,150 @@
+// Package beacon models the consensus-layer blob sidecar types and their SSZ
+// encoding.
+package beacon
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// KZGCommitmentInclusionProofDepth is the length of the Merkle branch proving
+// a KZG commitment is part of a beacon block body, the same for Deneb,
+// Electra and Fulu.
+const KZGCommitmentInclusionProofDepth = 17
+
+const (
+	// BeaconBlockHeaderSSZSize is the SSZ size of a BeaconBlockHeader.
+	BeaconBlockHeaderSSZSize = 8 + 8 + 32 + 32 + 32
+
+	// SignedBeaconBlockHeaderSSZSize is the SSZ size of a SignedBeaconBlockHeader.
+	SignedBeaconBlockHeaderSSZSize = BeaconBlockHeaderSSZSize + 96
+
+	// BlobSidecarSSZSize is the SSZ size of a BlobSidecar. Every field is
+	// fixed-size, so the encoding is a plain concatenation.
+	BlobSidecarSSZSize = 8 + len(kzg4844.Blob{}) + 48 + 48 + SignedBeaconBlockHeaderSSZSize + KZGCommitmentInclusionProofDepth*32
+)
+
+// BeaconBlockHeader is the consensus-layer block header.
+type BeaconBlockHeader struct {
+	Slot          uint64
+	ProposerIndex uint64
+	ParentRoot    common.Hash
+	StateRoot     common.Hash
+	BodyRoot      common.Hash
+}
+
+// SignedBeaconBlockHeader is a block header with its BLS signature.
+type SignedBeaconBlockHeader struct {
+	Message   BeaconBlockHeader
+	Signature [96]byte
+}
+
+// BlobSidecar is the Deneb BlobSidecar container gossiped and served by
+// beacon nodes.
+type BlobSidecar struct {
+	Index                       uint64
+	Blob                        kzg4844.Blob
+	KZGCommitment               kzg4844.Commitment
+	KZGProof                    kzg4844.Proof
+	SignedBlockHeader           SignedBeaconBlockHeader
+	KZGCommitmentInclusionProof [KZGCommitmentInclusionProofDepth]common.Hash
+}
+
+// MarshalSSZ returns the SSZ encoding of the header.
+func (h *BeaconBlockHeader) MarshalSSZ() []byte {
+	buf := make([]byte, 0, BeaconBlockHeaderSSZSize)
+	buf = binary.LittleEndian.AppendUint64(buf, h.Slot)
+	buf = binary.LittleEndian.AppendUint64(buf, h.ProposerIndex)
+	buf = append(buf, h.ParentRoot[:]...)
+	buf = append(buf, h.StateRoot[:]...)
+	buf = append(buf, h.BodyRoot[:]...)
+	return buf
+}
+
+// UnmarshalSSZ decodes an SSZ-encoded header.
+func (h *BeaconBlockHeader) UnmarshalSSZ(data []byte) error {
+	if len(data) != BeaconBlockHeaderSSZSize {
+		return fmt.Errorf("invalid BeaconBlockHeader size: got %d bytes, want %d", len(data), BeaconBlockHeaderSSZSize)
+	}
+	h.Slot = binary.LittleEndian.Uint64(data[0:8])
+	h.ProposerIndex = binary.LittleEndian.Uint64(data[8:16])
+	copy(h.ParentRoot[:], data[16:48])
+	copy(h.StateRoot[:], data[48:80])
+	copy(h.BodyRoot[:], data[80:112])
+	return nil
+}
+
+// MarshalSSZ returns the SSZ encoding of the signed header.
+func (h *SignedBeaconBlockHeader) MarshalSSZ() []byte {
+	return append(h.Message.MarshalSSZ(), h.Signature[:]...)
+}
+
+// UnmarshalSSZ decodes an SSZ-encoded signed header.
+func (h *SignedBeaconBlockHeader) UnmarshalSSZ(data []byte) error {
+	if len(data) != SignedBeaconBlockHeaderSSZSize {
+		return fmt.Errorf("invalid SignedBeaconBlockHeader size: got %d bytes, want %d", len(data), SignedBeaconBlockHeaderSSZSize)
+	}
+	if err := h.Message.UnmarshalSSZ(data[:BeaconBlockHeaderSSZSize]); err != nil {
+		return err
+	}
+	copy(h.Signature[:], data[BeaconBlockHeaderSSZSize:])
+	return nil
+}
+
+// MarshalSSZ returns the SSZ encoding of the sidecar.
+func (s *BlobSidecar) MarshalSSZ() []byte {
+	buf := make([]byte, 0, BlobSidecarSSZSize)
+	buf = binary.LittleEndian.AppendUint64(buf, s.Index)
+	buf = append(buf, s.Blob[:]...)
+	buf = append(buf, s.KZGCommitment[:]...)
+	buf = append(buf, s.KZGProof[:]...)
+	buf = append(buf, s.SignedBlockHeader.MarshalSSZ()...)
+	for _, h := range s.KZGCommitmentInclusionProof {
+		buf = append(buf, h[:]...)
+	}
+	return buf
+}
+
+// UnmarshalSSZ decodes an SSZ-encoded sidecar.
+func (s *BlobSidecar) UnmarshalSSZ(data []byte) error {
+	if len(data) != BlobSidecarSSZSize {
+		return fmt.Errorf("invalid BlobSidecar size: got %d bytes, want %d", len(data), BlobSidecarSSZSize)
+	}
+
+	s.Index = binary.LittleEndian.Uint64(data[:8])
+	data = data[8:]
+	data = data[copy(s.Blob[:], data):]
+	data = data[copy(s.KZGCommitment[:], data):]
+	data = data[copy(s.KZGProof[:], data):]
+	if err := s.SignedBlockHeader.UnmarshalSSZ(data[:SignedBeaconBlockHeaderSSZSize]); err != nil {
+		return err
+	}
+	data = data[SignedBeaconBlockHeaderSSZSize:]
+	for i := range s.KZGCommitmentInclusionProof {
+		data = data[copy(s.KZGCommitmentInclusionProof[i][:], data):]
+	}
+	return nil
+}
+
+// Verify recomputes the KZG commitment of the sidecar's blob, checks it
+// matches the commitment the sidecar claims, and verifies the KZG proof.
+func (s *BlobSidecar) Verify() error {
+	commitment, err := blob.Commit(&s.Blob)
+	if err != nil {
+		return fmt.Errorf("failed to compute KZG commitment: %w", err)
+	}
+	if commitment != s.KZGCommitment {
+		return fmt.Errorf("%w: blob commits to %x, sidecar claims %x", blob.ErrCommitmentMismatch, commitment[:], s.KZGCommitment[:])
+	}
+	return blob.Verify(&s.Blob, s.KZGCommitment, s.KZGProof)
+}
+
+// VersionedHash returns the versioned hash of the sidecar's commitment.
+func (s *BlobSidecar) VersionedHash() common.Hash {
+	return blob.VersionedHash(s.KZGCommitment)
+}
@@ -0,0 +1,148 @@
+// Package bench measures the KZG throughput of this machine: how many blob
+// commitments, proofs and proof verifications per second it sustains on a
+// given number of goroutines and with a given KZG library.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// Operations measured by Measure.
+const (
+	OpCommit = "commit"
+	OpProve  = "prove"
+	OpVerify = "verify"
+)
+
+// Ops lists the operations in the order they are measured.
+var Ops = []string{OpCommit, OpProve, OpVerify}
+
+// Result is the throughput of one operation on one backend and number of
+// goroutines.
+type Result struct {
+	Backend    string  `json:"backend"`
+	Goroutines int     `json:"goroutines"`
+	Op         string  `json:"op"`
+	Count      int64   `json:"count"` // operations completed
+	Seconds    float64 `json:"seconds"`
+	Rate       float64 `json:"per_second"`
+}
+
+// fixture is a blob with its commitment and proof, so each operation can be
+// measured on its own.
+type fixture struct {
+	blob       kzg4844.Blob
+	commitment kzg4844.Commitment
+	proof      kzg4844.Proof
+}
+
+// Bench holds one random blob per goroutine, shared by every measurement.
+type Bench struct {
+	fixtures []fixture
+}
+
+// New prepares blobs for up to goroutines concurrent measurements. Their
+// commitments and proofs are the same with either KZG library.
+func New(goroutines int) (*Bench, error) {
+	b := &Bench{fixtures: make([]fixture, goroutines)}
+	for i := range b.fixtures {
+		f := &b.fixtures[i]
+		var err error
+		if f.blob, err = blob.GenerateBlob(blob.PatternRandom, 1, i); err != nil {
+			return nil, err
+		}
+		if f.commitment, err = blob.Commit(&f.blob); err != nil {
+			return nil, err
+		}
+		if f.proof, err = blob.Prove(&f.blob, f.commitment); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// Measure runs op on goroutines goroutines for about d and returns the
+// throughput, labelled with backend. Each goroutine repeats the operation
+// on its own blob, and the operations in flight when d is up are counted.
+// It stops early, with the count so far, when ctx is done.
+func (b *Bench) Measure(ctx context.Context, backend, op string, goroutines int, d time.Duration) (Result, error) {
+	if goroutines < 1 || goroutines > len(b.fixtures) {
+		return Result{}, fmt.Errorf("goroutines must be between 1 and %d", len(b.fixtures))
+	}
+	run, err := operation(op)
+	if err != nil {
+		return Result{}, err
+	}
+	// Run once up front, so a failure is reported instead of counted and a
+	// backend's trusted setup is loaded before the clock starts.
+	if err := run(&b.fixtures[0]); err != nil {
+		return Result{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	var (
+		count atomic.Int64
+		wg    sync.WaitGroup
+	)
+	start := time.Now()
+	for i := range goroutines {
+		wg.Add(1)
+		go func(f *fixture) {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				run(f)
+				count.Add(1)
+			}
+		}(&b.fixtures[i])
+	}
+	wg.Wait()
+	elapsed := time.Since(start).Seconds()
+	return Result{
+		Backend:    backend,
+		Goroutines: goroutines,
+		Op:         op,
+		Count:      count.Load(),
+		Seconds:    elapsed,
+		Rate:       float64(count.Load()) / elapsed,
+	}, nil
+}
+
+// operation returns the function performing op on a fixture.
+func operation(op string) (func(*fixture) error, error) {
+	switch op {
+	case OpCommit:
+		return func(f *fixture) error {
+			_, err := blob.Commit(&f.blob)
+			return err
+		}, nil
+	case OpProve:
+		return func(f *fixture) error {
+			_, err := blob.Prove(&f.blob, f.commitment)
+			return err
+		}, nil
+	case OpVerify:
+		return func(f *fixture) error {
+			return blob.Verify(&f.blob, f.commitment, f.proof)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q (want %s, %s or %s)", op, OpCommit, OpProve, OpVerify)
+	}
+}
+
+// GoroutineSteps returns the powers of two below max, followed by max.
+func GoroutineSteps(max int) []int {
+	var steps []int
+	for n := 1; n < max; n *= 2 {
+		steps = append(steps, n)
+	}
+	return append(steps, max)
+}
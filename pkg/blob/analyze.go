@@ -0,0 +1,32 @@
+package blob
+
+// Utilization describes how a payload fills blobs when it is split with
+// SplitIntoBlobs.
+type Utilization struct {
+	// PayloadSize is the number of payload bytes.
+	PayloadSize int `json:"payload_size"`
+	// Blobs is the number of blobs needed.
+	Blobs int `json:"blobs"`
+	// BlobBytes is the raw size of those blobs, Blobs * Size.
+	BlobBytes int `json:"blob_bytes"`
+	// FieldElements is the number of field elements holding payload.
+	FieldElements int `json:"field_elements"`
+	// EncodedBytes is the space those field elements occupy, including the
+	// zero high byte of each.
+	EncodedBytes int `json:"encoded_bytes"`
+	// Efficiency is PayloadSize / BlobBytes.
+	Efficiency float64 `json:"efficiency"`
+}
+
+// Analyze reports the blob utilization of a payload of the given size.
+func Analyze(size int) Utilization {
+	u := Utilization{PayloadSize: size}
+	for _, c := range ChunkLayout(size) {
+		u.Blobs++
+		u.FieldElements += (c.Size + UsableBytesPerFieldElement - 1) / UsableBytesPerFieldElement
+	}
+	u.BlobBytes = u.Blobs * Size
+	u.EncodedBytes = u.FieldElements * BytesPerFieldElement
+	u.Efficiency = float64(size) / float64(u.BlobBytes)
+	return u
+}
@@ -0,0 +1,62 @@
+package blob
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// BlobArtifacts is the machine-readable result of processing a blob. BlobHex
+// holds the 0x-prefixed blob contents and is only set on request, since it
+// is 256 KiB of text. Proof is zero, and left out of the JSON, when the
+// artifacts come from CommitOnly.
+type BlobArtifacts struct {
+	Commitment    kzg4844.Commitment `json:"commitment"`
+	Proof         kzg4844.Proof      `json:"proof,omitzero"`
+	VersionedHash common.Hash        `json:"versioned_hash"`
+	BlobHex       string             `json:"blob_hex,omitempty"`
+}
+
+// NewArtifacts computes the commitment, proof and versioned hash of blob.
+// When includeBlob is set the blob itself is also included as hex.
+func NewArtifacts(blob *kzg4844.Blob, includeBlob bool) (*BlobArtifacts, error) {
+	commitment, err := Commit(blob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate KZG commitment: %w", err)
+	}
+	proof, err := Prove(blob, commitment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate KZG proof: %w", err)
+	}
+
+	a := &BlobArtifacts{
+		Commitment:    commitment,
+		Proof:         proof,
+		VersionedHash: VersionedHash(commitment),
+	}
+	if includeBlob {
+		a.BlobHex = hexutil.Encode(blob[:])
+	}
+	return a, nil
+}
+
+// CommitOnly computes the commitment and versioned hash of blob like
+// NewArtifacts, but leaves Proof zero. The proof costs about as much as the
+// commitment, so this halves the work when only the versioned hash is
+// needed, such as to estimate costs or to precompute hashes.
+func CommitOnly(blob *kzg4844.Blob, includeBlob bool) (*BlobArtifacts, error) {
+	commitment, err := Commit(blob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate KZG commitment: %w", err)
+	}
+	a := &BlobArtifacts{
+		Commitment:    commitment,
+		VersionedHash: VersionedHash(commitment),
+	}
+	if includeBlob {
+		a.BlobHex = hexutil.Encode(blob[:])
+	}
+	return a, nil
+}
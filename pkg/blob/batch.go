@@ -0,0 +1,48 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	goethkzg "github.com/crate-crypto/go-eth-kzg"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/metrics"
+)
+
+// VerifyBlobProofBatch verifies many blob proofs at once. The proofs are
+// combined with a random linear combination and checked with a single
+// pairing, which is much cheaper than calling Verify for each blob. On
+// failure it does not report which blob was invalid.
+func VerifyBlobProofBatch(blobs []kzg4844.Blob, commitments []kzg4844.Commitment, proofs []kzg4844.Proof) error {
+	if len(blobs) != len(commitments) || len(blobs) != len(proofs) {
+		return fmt.Errorf("mismatched lengths: %d blobs, %d commitments, %d proofs",
+			len(blobs), len(commitments), len(proofs))
+	}
+	ctx, err := goKZGContext()
+	if err != nil {
+		return err
+	}
+
+	kzgBlobs := make([]goethkzg.Blob, len(blobs))
+	kzgCommitments := make([]goethkzg.KZGCommitment, len(commitments))
+	kzgProofs := make([]goethkzg.KZGProof, len(proofs))
+	for i := range blobs {
+		kzgBlobs[i] = goethkzg.Blob(blobs[i])
+		kzgCommitments[i] = goethkzg.KZGCommitment(commitments[i])
+		kzgProofs[i] = goethkzg.KZGProof(proofs[i])
+	}
+	start := time.Now()
+	err = ctx.VerifyBlobKZGProofBatch(kzgBlobs, kzgCommitments, kzgProofs)
+	metrics.ObserveVerify(start, err)
+	err = kzgError(err, ErrProofMismatch)
+	if errors.Is(err, ErrInvalidFieldElement) {
+		for i := range blobs {
+			if verr := ValidateBlob(&blobs[i]); verr != nil {
+				return fmt.Errorf("blob %d: %w", i, verr)
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+// Package blob builds EIP-4844 blobs from arbitrary input and computes their
+// KZG commitments, proofs and versioned hashes.
+package blob
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// Size is the number of bytes in a single blob (FieldElementsPerBlob * BytesPerFieldElement).
+const Size = len(kzg4844.Blob{})
+
+// NewBlobFromBytes creates a KZG blob from raw bytes, zero-padding to Size
+func NewBlobFromBytes(data []byte) (kzg4844.Blob, error) {
+	var blob kzg4844.Blob
+	err := SetBlobBytes(&blob, data)
+	return blob, err
+}
+
+// SetBlobBytes fills dst with data like NewBlobFromBytes, without
+// allocating a blob, so dst can come from GetBlob.
+func SetBlobBytes(dst *kzg4844.Blob, data []byte) error {
+	if len(data) > len(dst) {
+		return fmt.Errorf("%w: %d bytes, max %d bytes", ErrBlobTooLarge, len(data), len(dst))
+	}
+	n := copy(dst[:], data)
+	clear(dst[n:])
+	return nil
+}
+
+// NewBlobFromHex creates a KZG blob from hex string, padding to Size bytes if needed
+func NewBlobFromHex(hexStr string) (kzg4844.Blob, error) {
+	var blob kzg4844.Blob
+	err := SetBlobHex(&blob, []byte(hexStr))
+	return blob, err
+}
+
+// SetBlobHex fills dst with the hex text like NewBlobFromHex, decoding in
+// place instead of allocating the bytes and the blob.
+func SetBlobHex(dst *kzg4844.Blob, text []byte) error {
+	// Remove 0x prefix if present
+	text = bytes.TrimPrefix(text, []byte("0x"))
+
+	if n := hex.DecodedLen(len(text)); n > len(dst) {
+		return fmt.Errorf("%w: %d bytes, max %d bytes", ErrBlobTooLarge, n, len(dst))
+	}
+	n, err := hex.Decode(dst[:], text)
+	if err != nil {
+		return fmt.Errorf("failed to decode hex string: %w", err)
+	}
+	clear(dst[n:])
+	return nil
+}
+
+// NewBlobFromFile creates a KZG blob from a file containing hex data
+func NewBlobFromFile(filename string) (kzg4844.Blob, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return kzg4844.Blob{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	// Remove whitespace and newlines
+	hexStr := strings.ReplaceAll(string(data), "\n", "")
+	hexStr = strings.ReplaceAll(hexStr, " ", "")
+	hexStr = strings.ReplaceAll(hexStr, "\t", "")
+
+	return NewBlobFromHex(hexStr)
+}
+
+// NewBlobFromReader creates a KZG blob from an io.Reader, reading at most Size
+// bytes. Use a BlobStream to pack a longer payload across several blobs.
+func NewBlobFromReader(r io.Reader) (kzg4844.Blob, error) {
+	var blob kzg4844.Blob
+	_, err := io.ReadFull(r, blob[:])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return blob, fmt.Errorf("failed to read data: %w", err)
+	}
+	return blob, nil
+}
@@ -0,0 +1,66 @@
+package blob
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// ElementDiff is a field element at which two blobs differ.
+type ElementDiff struct {
+	Index  int           `json:"index"`
+	Offset int           `json:"offset"` // of the element in the blob
+	A      hexutil.Bytes `json:"a"`
+	B      hexutil.Bytes `json:"b"`
+	// Bytes are the positions within the element at which they differ.
+	Bytes []int `json:"differing_bytes"`
+}
+
+// Ranges formats the differing byte positions as ranges, such as "0, 30-31".
+func (d ElementDiff) Ranges() string {
+	return formatRanges(d.Bytes)
+}
+
+// BlobDiff lists the field elements at which two blobs differ, in order.
+type BlobDiff struct {
+	Elements []ElementDiff `json:"elements"`
+	// DifferingBytes counts the differing bytes across all elements.
+	DifferingBytes int `json:"differing_bytes"`
+}
+
+// DiffBlobs compares a and b field element by field element.
+func DiffBlobs(a, b *kzg4844.Blob) *BlobDiff {
+	d := &BlobDiff{Elements: []ElementDiff{}}
+	for i := range FieldElementsPerBlob {
+		off := i * BytesPerFieldElement
+		ea, eb := a[off:off+BytesPerFieldElement], b[off:off+BytesPerFieldElement]
+		if bytes.Equal(ea, eb) {
+			continue
+		}
+		e := ElementDiff{Index: i, Offset: off, A: ea, B: eb}
+		for j := range ea {
+			if ea[j] != eb[j] {
+				e.Bytes = append(e.Bytes, j)
+			}
+		}
+		d.DifferingBytes += len(e.Bytes)
+		d.Elements = append(d.Elements, e)
+	}
+	return d
+}
+
+// Equal reports whether the blobs are identical.
+func (d *BlobDiff) Equal() bool {
+	return len(d.Elements) == 0
+}
+
+// Ranges formats the indices of the differing elements as ranges, such as
+// "0, 3-5".
+func (d *BlobDiff) Ranges() string {
+	indices := make([]int, len(d.Elements))
+	for i, e := range d.Elements {
+		indices[i] = e.Index
+	}
+	return formatRanges(indices)
+}
@@ -0,0 +1,140 @@
+package blob
+
+import (
+	"fmt"
+	"reflect"
+
+	goethkzg "github.com/crate-crypto/go-eth-kzg"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+const (
+	// CellsPerExtBlob is the number of cells an EIP-7594 (PeerDAS) extended
+	// blob is split into.
+	CellsPerExtBlob = goethkzg.CellsPerExtBlob
+
+	// BytesPerCell is the size of a single cell.
+	BytesPerCell = goethkzg.BytesPerCell
+)
+
+// Cell is one of the CellsPerExtBlob pieces of an erasure-coded blob.
+type Cell [BytesPerCell]byte
+
+// UnmarshalJSON parses a cell in hex syntax.
+func (c *Cell) UnmarshalJSON(input []byte) error {
+	return hexutil.UnmarshalFixedJSON(reflect.TypeOf(Cell{}), input, c[:])
+}
+
+// MarshalText returns the hex representation of c.
+func (c Cell) MarshalText() ([]byte, error) {
+	return hexutil.Bytes(c[:]).MarshalText()
+}
+
+// ComputeCells erasure-codes blob into its CellsPerExtBlob cells. The first
+// half of the cells hold the blob itself, the second half the extension.
+func ComputeCells(blob *kzg4844.Blob) ([]Cell, error) {
+	ctx, err := goKZGContext()
+	if err != nil {
+		return nil, err
+	}
+	cells, err := ctx.ComputeCells((*goethkzg.Blob)(blob), 0)
+	if err != nil {
+		return nil, err
+	}
+	return copyCells(cells), nil
+}
+
+// ComputeCellsAndKZGProofs erasure-codes blob into its cells and computes
+// the KZG proof of every cell.
+func ComputeCellsAndKZGProofs(blob *kzg4844.Blob) ([]Cell, []kzg4844.Proof, error) {
+	ctx, err := goKZGContext()
+	if err != nil {
+		return nil, nil, err
+	}
+	cells, proofs, err := ctx.ComputeCellsAndKZGProofs((*goethkzg.Blob)(blob), 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return copyCells(cells), copyProofs(proofs), nil
+}
+
+// VerifyCellKZGProofBatch verifies that every cells[i], at position
+// cellIndices[i] of its extended blob, belongs to the blob committed to by
+// commitments[i].
+func VerifyCellKZGProofBatch(commitments []kzg4844.Commitment, cellIndices []uint64, cells []Cell, proofs []kzg4844.Proof) error {
+	if len(commitments) != len(cells) || len(cellIndices) != len(cells) || len(proofs) != len(cells) {
+		return fmt.Errorf("mismatched lengths: %d commitments, %d indices, %d cells, %d proofs",
+			len(commitments), len(cellIndices), len(cells), len(proofs))
+	}
+	ctx, err := goKZGContext()
+	if err != nil {
+		return err
+	}
+
+	kzgCommitments := make([]goethkzg.KZGCommitment, len(commitments))
+	kzgCells := make([]*goethkzg.Cell, len(cells))
+	kzgProofs := make([]goethkzg.KZGProof, len(proofs))
+	for i := range cells {
+		kzgCommitments[i] = goethkzg.KZGCommitment(commitments[i])
+		kzgCells[i] = (*goethkzg.Cell)(&cells[i])
+		kzgProofs[i] = goethkzg.KZGProof(proofs[i])
+	}
+	return kzgError(ctx.VerifyCellKZGProofBatch(kzgCommitments, cellIndices, kzgCells, kzgProofs), ErrProofMismatch)
+}
+
+func copyCells(cells [CellsPerExtBlob]*goethkzg.Cell) []Cell {
+	out := make([]Cell, len(cells))
+	for i, c := range cells {
+		out[i] = Cell(*c)
+	}
+	return out
+}
+
+func copyProofs(proofs [CellsPerExtBlob]goethkzg.KZGProof) []kzg4844.Proof {
+	out := make([]kzg4844.Proof, len(proofs))
+	for i, p := range proofs {
+		out[i] = kzg4844.Proof(p)
+	}
+	return out
+}
+
+// RecoverCellsAndKZGProofs reconstructs every cell of an extended blob, and
+// the proofs of all cells, from any half of its cells. cellIndices[i] is the
+// position of cells[i] in the extended blob.
+func RecoverCellsAndKZGProofs(cellIndices []uint64, cells []Cell) ([]Cell, []kzg4844.Proof, error) {
+	if len(cellIndices) != len(cells) {
+		return nil, nil, fmt.Errorf("mismatched lengths: %d indices, %d cells", len(cellIndices), len(cells))
+	}
+	if len(cells) < CellsPerExtBlob/2 {
+		return nil, nil, fmt.Errorf("not enough cells to recover: have %d, need %d", len(cells), CellsPerExtBlob/2)
+	}
+	ctx, err := goKZGContext()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	kzgCells := make([]*goethkzg.Cell, len(cells))
+	for i := range cells {
+		kzgCells[i] = (*goethkzg.Cell)(&cells[i])
+	}
+	recovered, proofs, err := ctx.RecoverCellsAndComputeKZGProofs(cellIndices, kzgCells, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return copyCells(recovered), copyProofs(proofs), nil
+}
+
+// CellsToBlob reassembles the original blob from the cells of its extended
+// blob. Only the first CellsPerExtBlob/2 cells are needed, as they hold the
+// blob itself; the rest are the erasure-coded extension.
+func CellsToBlob(cells []Cell) (kzg4844.Blob, error) {
+	var blob kzg4844.Blob
+	if len(cells) < CellsPerExtBlob/2 {
+		return blob, fmt.Errorf("need the first %d cells, have %d", CellsPerExtBlob/2, len(cells))
+	}
+	for i := range CellsPerExtBlob / 2 {
+		copy(blob[i*BytesPerCell:], cells[i][:])
+	}
+	return blob, nil
+}
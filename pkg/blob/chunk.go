@@ -0,0 +1,65 @@
+package blob
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// Chunk records where the contents of one blob sit in the original payload.
+type Chunk struct {
+	Index  int `json:"index"`
+	Offset int `json:"offset"`
+	Size   int `json:"size"`
+}
+
+// ChunkLayout returns the ordered chunks a payload of the given size is
+// split into, each holding at most MaxPackedSize bytes. An empty payload
+// still occupies a single (empty) chunk.
+func ChunkLayout(size int) []Chunk {
+	chunks := []Chunk{}
+	for offset := 0; offset < size || len(chunks) == 0; offset += MaxPackedSize {
+		chunks = append(chunks, Chunk{
+			Index:  len(chunks),
+			Offset: offset,
+			Size:   min(MaxPackedSize, size-offset),
+		})
+	}
+	return chunks
+}
+
+// SplitIntoBlobs packs an arbitrary-size payload across as many blobs as
+// needed, in the order given by ChunkLayout.
+func SplitIntoBlobs(data []byte) ([]kzg4844.Blob, error) {
+	chunks := ChunkLayout(len(data))
+	blobs := make([]kzg4844.Blob, len(chunks))
+	for i, c := range chunks {
+		b, err := Pack(data[c.Offset : c.Offset+c.Size])
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", i, err)
+		}
+		blobs[i] = b
+	}
+	return blobs, nil
+}
+
+// JoinBlobs reassembles the payload split by SplitIntoBlobs. The chunks must
+// describe the blobs in the same order and be contiguous from offset zero.
+func JoinBlobs(blobs []kzg4844.Blob, chunks []Chunk) ([]byte, error) {
+	if len(blobs) != len(chunks) {
+		return nil, fmt.Errorf("have %d blobs but %d chunks", len(blobs), len(chunks))
+	}
+
+	var data []byte
+	for i, c := range chunks {
+		if c.Index != i || c.Offset != len(data) {
+			return nil, fmt.Errorf("chunk %d out of order: index %d, offset %d", i, c.Index, c.Offset)
+		}
+		part, err := Unpack(&blobs[i], c.Size)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", i, err)
+		}
+		data = append(data, part...)
+	}
+	return data, nil
+}
@@ -0,0 +1,122 @@
+package blob
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression identifies the algorithm applied to a payload before it is
+// framed. Its value is stored in the frame header.
+type Compression byte
+
+const (
+	CompressionNone Compression = iota
+	CompressionZlib
+	CompressionBrotli
+	CompressionZstd
+)
+
+// MaxDecompressedSize bounds the output of Decompress so that a small
+// crafted blob cannot expand without limit.
+const MaxDecompressedSize = 64 << 20
+
+var compressionNames = map[Compression]string{
+	CompressionNone:   "none",
+	CompressionZlib:   "zlib",
+	CompressionBrotli: "brotli",
+	CompressionZstd:   "zstd",
+}
+
+func (c Compression) String() string {
+	if name, ok := compressionNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("compression(%d)", byte(c))
+}
+
+// ParseCompression returns the algorithm with the given name: none, zlib,
+// brotli or zstd. The empty string means none.
+func ParseCompression(name string) (Compression, error) {
+	if name == "" {
+		return CompressionNone, nil
+	}
+	for c, n := range compressionNames {
+		if strings.EqualFold(name, n) {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown compression %q (want none, zlib, brotli or zstd)", name)
+}
+
+// Compress compresses data with c at the algorithm's best ratio, since blob
+// space is far more expensive than CPU time.
+func Compress(c Compression, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch c {
+	case CompressionNone:
+		return data, nil
+	case CompressionZlib:
+		w, _ = zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	case CompressionBrotli:
+		w = brotli.NewWriterLevel(&buf, brotli.BestCompression)
+	case CompressionZstd:
+		zw, err := zstd.NewWriter(&buf, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+		if err != nil {
+			return nil, err
+		}
+		w = zw
+	default:
+		return nil, fmt.Errorf("unsupported compression %s", c)
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("%s compression failed: %w", c, err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("%s compression failed: %w", c, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress reverses Compress. It fails if the output would exceed
+// MaxDecompressedSize.
+func Decompress(c Compression, data []byte) ([]byte, error) {
+	var r io.Reader
+	switch c {
+	case CompressionNone:
+		return data, nil
+	case CompressionZlib:
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("zlib decompression failed: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	case CompressionBrotli:
+		r = brotli.NewReader(bytes.NewReader(data))
+	case CompressionZstd:
+		zr, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("zstd decompression failed: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return nil, fmt.Errorf("unsupported compression %s", c)
+	}
+
+	out, err := io.ReadAll(io.LimitReader(r, MaxDecompressedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s decompression failed: %w", c, err)
+	}
+	if len(out) > MaxDecompressedSize {
+		return nil, fmt.Errorf("%s decompression failed: output exceeds %d bytes", c, MaxDecompressedSize)
+	}
+	return out, nil
+}
@@ -0,0 +1,140 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// ArtifactDiff compares a supplied artifact with the one recomputed from the
+// blob.
+type ArtifactDiff struct {
+	Supplied hexutil.Bytes `json:"supplied"`
+	Expected hexutil.Bytes `json:"expected"`
+	// Bytes are the positions at which they differ.
+	Bytes []int `json:"differing_bytes,omitempty"`
+}
+
+func newArtifactDiff(supplied, expected []byte) ArtifactDiff {
+	d := ArtifactDiff{Supplied: supplied, Expected: expected}
+	for i := range supplied {
+		if supplied[i] != expected[i] {
+			d.Bytes = append(d.Bytes, i)
+		}
+	}
+	return d
+}
+
+// Match reports whether the supplied artifact is the expected one.
+func (d ArtifactDiff) Match() bool {
+	return len(d.Bytes) == 0
+}
+
+// Ranges formats the differing byte positions as ranges, such as
+// "0-3, 17, 20-47".
+func (d ArtifactDiff) Ranges() string {
+	return formatRanges(d.Bytes)
+}
+
+// formatRanges formats ascending positions as ranges, such as
+// "0-3, 17, 20-47".
+func formatRanges(positions []int) string {
+	var parts []string
+	for i := 0; i < len(positions); {
+		j := i
+		for j+1 < len(positions) && positions[j+1] == positions[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, fmt.Sprint(positions[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", positions[i], positions[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Diagnosis tells which of the artifacts supplied for a blob are
+// inconsistent with it, by recomputing them.
+type Diagnosis struct {
+	Commitment ArtifactDiff `json:"commitment"`
+	Proof      ArtifactDiff `json:"proof"`
+	// VersionedHash is set by CheckVersionedHash.
+	VersionedHash *ArtifactDiff `json:"versioned_hash,omitempty"`
+	// ProofVerifies reports whether the supplied proof verifies against the
+	// supplied commitment.
+	ProofVerifies bool `json:"proof_verifies"`
+	// Swapped means the supplied commitment and proof are each other's
+	// expected value.
+	Swapped bool `json:"swapped,omitempty"`
+	// HashOfSupplied means the supplied versioned hash is that of the
+	// supplied commitment, so both belong to another blob.
+	HashOfSupplied bool `json:"versioned_hash_of_supplied_commitment,omitempty"`
+}
+
+// Diagnose recomputes the commitment and proof of b and compares them with
+// the supplied ones. Blob proofs are deterministic, so a proof for the right
+// commitment matches byte for byte. It fails only if b itself is invalid.
+func Diagnose(b *kzg4844.Blob, commitment kzg4844.Commitment, proof kzg4844.Proof) (*Diagnosis, error) {
+	expected, err := Commit(b)
+	if err != nil {
+		return nil, err
+	}
+	expectedProof, err := Prove(b, expected)
+	if err != nil {
+		return nil, err
+	}
+	return &Diagnosis{
+		Commitment:    newArtifactDiff(commitment[:], expected[:]),
+		Proof:         newArtifactDiff(proof[:], expectedProof[:]),
+		ProofVerifies: Verify(b, commitment, proof) == nil,
+		Swapped:       commitment == kzg4844.Commitment(expectedProof) && proof == kzg4844.Proof(expected) && commitment != kzg4844.Commitment(proof),
+	}, nil
+}
+
+// CheckVersionedHash compares the supplied versioned hash with the hash of
+// the recomputed commitment, computed by hash. A nil hash uses
+// VersionedHash.
+func (d *Diagnosis) CheckVersionedHash(supplied common.Hash, hash func(kzg4844.Commitment) common.Hash) {
+	if hash == nil {
+		hash = VersionedHash
+	}
+	expected := hash(kzg4844.Commitment(d.Commitment.Expected))
+	diff := newArtifactDiff(supplied[:], expected[:])
+	d.VersionedHash = &diff
+	d.HashOfSupplied = !diff.Match() && !d.Commitment.Match() && supplied == hash(kzg4844.Commitment(d.Commitment.Supplied))
+}
+
+// Err explains every inconsistency found, wrapping ErrCommitmentMismatch,
+// ErrProofMismatch or ErrVersionedHashMismatch, or returns nil if the
+// supplied artifacts are those of the blob.
+func (d *Diagnosis) Err() error {
+	var errs []error
+	switch {
+	case d.Swapped:
+		errs = append(errs, fmt.Errorf("%w: the commitment and proof are swapped", ErrCommitmentMismatch))
+	case !d.Commitment.Match():
+		errs = append(errs, fmt.Errorf("%w: the commitment is not that of the blob (differs at bytes %s)", ErrCommitmentMismatch, d.Commitment.Ranges()))
+	}
+	if !d.Swapped && !d.Proof.Match() {
+		if d.ProofVerifies {
+			// Proofs are binding, so the commitment is another blob's.
+			errs = append(errs, fmt.Errorf("%w: the proof is valid for the supplied commitment, not the blob's", ErrProofMismatch))
+		} else {
+			errs = append(errs, fmt.Errorf("%w: the proof is not that of the blob (differs at bytes %s)", ErrProofMismatch, d.Proof.Ranges()))
+		}
+	}
+	if d.VersionedHash != nil && !d.VersionedHash.Match() {
+		if d.HashOfSupplied {
+			errs = append(errs, fmt.Errorf("%w: the versioned hash is that of the supplied commitment, not the blob's", ErrVersionedHashMismatch))
+		} else {
+			errs = append(errs, fmt.Errorf("%w: the versioned hash is not that of the blob's commitment (differs at bytes %s)", ErrVersionedHashMismatch, d.VersionedHash.Ranges()))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,139 @@
+package blob
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// primitiveRoot generates the multiplicative group of the BLS12-381 scalar
+// field; the spec derives the blob domain from it.
+const primitiveRoot = 7
+
+var (
+	domainOnce sync.Once
+	domain     []fr.Element
+)
+
+// blobDomain returns the evaluation domain of a blob: the 4096th roots of
+// unity in bit-reversed order, so that field element i of a blob is the
+// polynomial's value at domain[i].
+func blobDomain() []fr.Element {
+	domainOnce.Do(func() {
+		var root fr.Element
+		root.SetUint64(primitiveRoot)
+		exp := new(big.Int).Sub(fr.Modulus(), big.NewInt(1))
+		exp.Div(exp, big.NewInt(FieldElementsPerBlob))
+		root.Exp(root, exp)
+
+		domain = make([]fr.Element, FieldElementsPerBlob)
+		var w fr.Element
+		w.SetOne()
+		bits := uint(0)
+		for 1<<bits < FieldElementsPerBlob {
+			bits++
+		}
+		for i := range FieldElementsPerBlob {
+			domain[reverseBits(uint64(i), bits)] = w
+			w.Mul(&w, &root)
+		}
+	})
+	return domain
+}
+
+// reverseBits reverses the lowest bits bits of n.
+func reverseBits(n uint64, bits uint) uint64 {
+	var r uint64
+	for range bits {
+		r = r<<1 | n&1
+		n >>= 1
+	}
+	return r
+}
+
+// EvaluationPoint returns the root of unity at which a blob's polynomial
+// takes the value of its field element i. A KZG proof at this point opens
+// the element itself, so y is the element's 32 bytes.
+func EvaluationPoint(i int) (kzg4844.Point, error) {
+	if i < 0 || i >= FieldElementsPerBlob {
+		return kzg4844.Point{}, fmt.Errorf("field element index %d out of range [0, %d)", i, FieldElementsPerBlob)
+	}
+	return kzg4844.Point(blobDomain()[i].Bytes()), nil
+}
+
+// ElementIndex returns the index of the field element whose evaluation
+// point is z, and false if z is not a root of unity of the blob domain.
+func ElementIndex(z kzg4844.Point) (int, bool) {
+	var x fr.Element
+	if err := x.SetBytesCanonical(z[:]); err != nil {
+		return 0, false
+	}
+	for i, w := range blobDomain() {
+		if w.Equal(&x) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// PayloadByte is where one byte of a payload sits in the blobs holding it.
+type PayloadByte struct {
+	// Offset is the byte's offset in the payload.
+	Offset int `json:"offset"`
+	// Blob is the index of the blob holding the byte, in the order of
+	// ChunkLayout. It is 0 for the layouts that hold one blob.
+	Blob int `json:"blob"`
+	// Element is the index of the field element holding the byte.
+	Element int `json:"element"`
+	// Byte is the byte's position within the element's 32 big-endian
+	// bytes, so 1 to 31 for the layouts built on Pack.
+	Byte int `json:"byte"`
+	// Z is the evaluation point of the element, as EvaluationPoint gives.
+	Z common.Hash `json:"z"`
+}
+
+// LocatePayloadByte returns where byte offset of a payload is stored in
+// the given layout, one of those Sniff reports: LayoutPacked for Pack and
+// SplitIntoBlobs, LayoutLengthPrefixed for EncodeBlob, LayoutFramed for an
+// uncompressed, unencrypted EncodeFramed and LayoutRaw for a payload copied
+// into blobs as is. A compressed frame stores other bytes than the payload,
+// so its bytes cannot be located.
+func LocatePayloadByte(offset int, layout string) (PayloadByte, error) {
+	if offset < 0 {
+		return PayloadByte{}, fmt.Errorf("invalid payload offset %d", offset)
+	}
+	var header, capacity, perElement, skip int
+	switch layout {
+	case LayoutPacked:
+		perElement, skip = UsableBytesPerFieldElement, 1
+	case LayoutLengthPrefixed:
+		header, capacity, perElement, skip = LengthPrefixSize, MaxPayloadSize, UsableBytesPerFieldElement, 1
+	case LayoutFramed:
+		header, capacity, perElement, skip = FrameHeaderSize, MaxFramedPayloadSize, UsableBytesPerFieldElement, 1
+	case LayoutRaw:
+		perElement = BytesPerFieldElement
+	default:
+		return PayloadByte{}, fmt.Errorf("unknown layout %q", layout)
+	}
+	if capacity > 0 && offset >= capacity {
+		return PayloadByte{}, fmt.Errorf("%w: offset %d is past the %d bytes a %s blob holds", ErrBlobTooLarge, offset, capacity, layout)
+	}
+	stored := header + offset
+	perBlob := FieldElementsPerBlob * perElement
+	p := PayloadByte{
+		Offset:  offset,
+		Blob:    stored / perBlob,
+		Element: stored % perBlob / perElement,
+		Byte:    stored%perElement + skip,
+	}
+	z, err := EvaluationPoint(p.Element)
+	if err != nil {
+		return PayloadByte{}, err
+	}
+	p.Z = common.Hash(z)
+	return p, nil
+}
@@ -0,0 +1,103 @@
+package blob
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/metrics"
+)
+
+const (
+	// FieldElementsPerBlob is the number of field elements in a blob.
+	FieldElementsPerBlob = 4096
+
+	// BytesPerFieldElement is the serialized size of a single field element.
+	BytesPerFieldElement = 32
+
+	// UsableBytesPerFieldElement is the payload capacity of a field element
+	// when its most significant byte is kept zero, which guarantees the
+	// element is below the BLS12-381 scalar field modulus.
+	UsableBytesPerFieldElement = 31
+
+	// MaxPackedSize is the largest payload Pack can store in one blob.
+	MaxPackedSize = FieldElementsPerBlob * UsableBytesPerFieldElement
+
+	// LengthPrefixSize is the size of the big-endian payload length that
+	// EncodeBlob writes ahead of the payload.
+	LengthPrefixSize = 4
+
+	// MaxPayloadSize is the largest payload EncodeBlob can store in one blob.
+	MaxPayloadSize = MaxPackedSize - LengthPrefixSize
+)
+
+// Pack stores data in the low 31 bytes of each big-endian field element,
+// leaving the high byte zero so every element is canonical regardless of the
+// payload contents. Unused elements are zero.
+func Pack(data []byte) (kzg4844.Blob, error) {
+	var blob kzg4844.Blob
+
+	if len(data) > MaxPackedSize {
+		return blob, fmt.Errorf("%w: %d bytes, max %d bytes", ErrBlobTooLarge, len(data), MaxPackedSize)
+	}
+
+	for i := 0; len(data) > 0; i++ {
+		offset := i*BytesPerFieldElement + 1
+		n := copy(blob[offset:offset+UsableBytesPerFieldElement], data)
+		data = data[n:]
+	}
+	metrics.BlobsEncoded.Inc()
+	return blob, nil
+}
+
+// Unpack reverses Pack, returning the first size payload bytes stored in the
+// blob. It fails if any element holding payload has a non-zero high byte.
+func Unpack(blob *kzg4844.Blob, size int) ([]byte, error) {
+	if size < 0 || size > MaxPackedSize {
+		return nil, fmt.Errorf("invalid payload size: %d bytes, max %d bytes", size, MaxPackedSize)
+	}
+
+	data := make([]byte, 0, size)
+	for i := 0; len(data) < size; i++ {
+		offset := i * BytesPerFieldElement
+		if blob[offset] != 0 {
+			return nil, fmt.Errorf("%w: element %d has non-zero high byte 0x%02x", ErrInvalidFieldElement, i, blob[offset])
+		}
+		n := min(UsableBytesPerFieldElement, size-len(data))
+		data = append(data, blob[offset+1:offset+1+n]...)
+	}
+	return data, nil
+}
+
+// EncodeBlob packs data into a blob behind a 4-byte big-endian length prefix
+// so DecodeBlob can later recover it exactly, including trailing zero bytes.
+func EncodeBlob(data []byte) (kzg4844.Blob, error) {
+	if len(data) > MaxPayloadSize {
+		return kzg4844.Blob{}, fmt.Errorf("%w: %d bytes, max %d bytes", ErrBlobTooLarge, len(data), MaxPayloadSize)
+	}
+
+	buf := make([]byte, LengthPrefixSize+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	copy(buf[LengthPrefixSize:], data)
+	return Pack(buf)
+}
+
+// DecodeBlob recovers the payload written by EncodeBlob, stripping the field
+// element encoding, the length prefix and the zero padding.
+func DecodeBlob(blob kzg4844.Blob) ([]byte, error) {
+	prefix, err := Unpack(&blob, LengthPrefixSize)
+	if err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(prefix)
+	if size > MaxPayloadSize {
+		return nil, fmt.Errorf("%w: length prefix %d bytes, max %d bytes", ErrInvalidFrame, size, MaxPayloadSize)
+	}
+
+	data, err := Unpack(&blob, LengthPrefixSize+int(size))
+	if err != nil {
+		return nil, err
+	}
+	return data[LengthPrefixSize:], nil
+}
@@ -0,0 +1,109 @@
+package blob
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// EquivalenceProof ties the keccak256 hash of a payload to the commitment of
+// the blob holding it, the way rollups that prove their batches in a circuit
+// do: the evaluation point z is derived from both hashes, so the blob
+// polynomial and the polynomial of the hashed payload can only agree at z if
+// they are equal. Evaluating the payload at z needs the payload, but checking
+// the KZG proof needs only the commitment, as the point evaluation precompile
+// does on chain.
+type EquivalenceProof struct {
+	// PayloadHash is the keccak256 of the payload.
+	PayloadHash   common.Hash        `json:"payload_hash"`
+	Commitment    kzg4844.Commitment `json:"commitment"`
+	VersionedHash common.Hash        `json:"versioned_hash"`
+	// Z is EquivalenceChallenge(PayloadHash, VersionedHash). Z and Y are
+	// hashes only so they encode as hex.
+	Z common.Hash `json:"z"`
+	// Y is the evaluation of the blob polynomial at Z.
+	Y     common.Hash   `json:"y"`
+	Proof kzg4844.Proof `json:"proof"`
+}
+
+// EquivalenceChallenge returns the Fiat-Shamir evaluation point of an
+// equivalence proof: keccak256(payloadHash || versionedHash) reduced modulo
+// the BLS12-381 scalar field.
+func EquivalenceChallenge(payloadHash, versionedHash common.Hash) kzg4844.Point {
+	h := crypto.Keccak256(payloadHash[:], versionedHash[:])
+	v := new(big.Int).SetBytes(h)
+	v.Mod(v, BLSModulus.Big())
+	var z kzg4844.Point
+	v.FillBytes(z[:])
+	return z
+}
+
+// ProveEquivalence packs payload into a blob with Pack, commits to it and
+// opens the commitment at the challenge derived from the payload's keccak256
+// and the blob's versioned hash.
+func ProveEquivalence(payload []byte) (*EquivalenceProof, error) {
+	b, err := Pack(payload)
+	if err != nil {
+		return nil, err
+	}
+	commitment, err := Commit(&b)
+	if err != nil {
+		return nil, err
+	}
+	p := &EquivalenceProof{
+		PayloadHash:   crypto.Keccak256Hash(payload),
+		Commitment:    commitment,
+		VersionedHash: VersionedHash(commitment),
+	}
+	p.Z = common.Hash(EquivalenceChallenge(p.PayloadHash, p.VersionedHash))
+	proof, y, err := ProveAt(&b, kzg4844.Point(p.Z))
+	if err != nil {
+		return nil, err
+	}
+	p.Proof, p.Y = proof, common.Hash(y)
+	return p, nil
+}
+
+// VerifyEquivalenceOpening checks the part of an equivalence proof that
+// needs no payload: the versioned hash is that of the commitment, z is the
+// challenge of the two hashes, and the commitment opens to y at z. A
+// verifier that trusts y to be the payload's evaluation at z, such as a
+// contract fed by a circuit, needs nothing more. The error wraps
+// ErrVersionedHashMismatch, ErrEquivalenceMismatch or ErrProofMismatch.
+func VerifyEquivalenceOpening(p *EquivalenceProof) error {
+	if err := CheckVersionedHash(p.Commitment, p.VersionedHash); err != nil {
+		return err
+	}
+	if z := EquivalenceChallenge(p.PayloadHash, p.VersionedHash); z != kzg4844.Point(p.Z) {
+		return fmt.Errorf("%w: challenge is %x, proof has %x", ErrEquivalenceMismatch, z[:], p.Z[:])
+	}
+	return VerifyAt(p.Commitment, kzg4844.Point(p.Z), kzg4844.Claim(p.Y), p.Proof)
+}
+
+// VerifyEquivalence checks that payload hashes to the proof's payload hash
+// and that its polynomial, packed as by Pack, evaluates to y at z, then
+// verifies the opening with VerifyEquivalenceOpening. It shows the payload
+// is the one committed to without recomputing the commitment.
+func VerifyEquivalence(payload []byte, p *EquivalenceProof) error {
+	if h := crypto.Keccak256Hash(payload); h != p.PayloadHash {
+		return fmt.Errorf("%w: payload hashes to %s, proof has %s", ErrEquivalenceMismatch, h, p.PayloadHash)
+	}
+	if err := VerifyEquivalenceOpening(p); err != nil {
+		return err
+	}
+	b, err := Pack(payload)
+	if err != nil {
+		return err
+	}
+	y, err := EvaluateBlobAt(&b, kzg4844.Point(p.Z))
+	if err != nil {
+		return err
+	}
+	if y != kzg4844.Claim(p.Y) {
+		return fmt.Errorf("%w: payload evaluates to %x at z, proof has %x", ErrEquivalenceMismatch, y[:], p.Y[:])
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+
+	goethkzg "github.com/crate-crypto/go-eth-kzg"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// Errors returned by this package, wrapped with details. Test for them with
+// errors.Is.
+var (
+	// ErrBlobTooLarge means a payload does not fit into a blob.
+	ErrBlobTooLarge = errors.New("data too large")
+
+	// ErrInvalidFieldElement means a blob, cell or evaluation point holds a
+	// value that is not a canonical BLS12-381 scalar, or is not encoded the
+	// way Pack writes it.
+	ErrInvalidFieldElement = errors.New("invalid field element")
+
+	// ErrProofMismatch means a KZG proof did not verify.
+	ErrProofMismatch = errors.New("proof verification failed")
+
+	// ErrCommitmentMismatch means a blob does not commit to the expected
+	// commitment.
+	ErrCommitmentMismatch = errors.New("commitment mismatch")
+
+	// ErrVersionedHashMismatch means a commitment does not hash to the
+	// expected versioned hash.
+	ErrVersionedHashMismatch = errors.New("versioned hash mismatch")
+
+	// ErrInvalidFrame means a blob starts with FrameMagic but its frame is
+	// malformed.
+	ErrInvalidFrame = errors.New("invalid frame")
+
+	// ErrEquivalenceMismatch means an equivalence proof does not tie its
+	// payload hash to its commitment.
+	ErrEquivalenceMismatch = errors.New("equivalence proof mismatch")
+
+	// ErrEvaluationMismatch means a blob's polynomial does not evaluate to
+	// a claimed y.
+	ErrEvaluationMismatch = errors.New("evaluation mismatch")
+
+	// ErrBlobMismatch means two blobs that should be identical differ.
+	ErrBlobMismatch = errors.New("blobs differ")
+
+	// ErrTimeout means a KZG computation did not finish within its
+	// per-blob timeout.
+	ErrTimeout = errors.New("KZG computation timed out")
+
+	// ErrSetupMismatch means a KZG trusted setup is not the one expected,
+	// such as the output of the Ethereum KZG ceremony.
+	ErrSetupMismatch = errors.New("trusted setup mismatch")
+)
+
+// CheckVersionedHash returns an error wrapping ErrVersionedHashMismatch
+// unless commitment hashes to want.
+func CheckVersionedHash(commitment kzg4844.Commitment, want common.Hash) error {
+	if computed := VersionedHash(commitment); computed != want {
+		return fmt.Errorf("%w: commitment hashes to %x, got %x", ErrVersionedHashMismatch, computed, want)
+	}
+	return nil
+}
+
+// kzgError classifies an error from the KZG library. Non-canonical scalars
+// are reported as ErrInvalidFieldElement and other errors are wrapped with
+// fallback, if set.
+func kzgError(err, fallback error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, goethkzg.ErrNonCanonicalScalar):
+		return fmt.Errorf("%w: %w", ErrInvalidFieldElement, err)
+	case fallback != nil:
+		return fmt.Errorf("%w: %w", fallback, err)
+	default:
+		return err
+	}
+}
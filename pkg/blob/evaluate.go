@@ -0,0 +1,46 @@
+package blob
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// EvaluateBlobAt returns the value y at z of the polynomial whose
+// evaluations on the blob domain are blob's field elements: the claim
+// ProveAt would prove, found without the multi-scalar multiplication of a
+// proof. It lets a claimed y be checked against the blob itself. The point
+// must be a canonical big-endian field element.
+func EvaluateBlobAt(blob *kzg4844.Blob, z kzg4844.Point) (kzg4844.Claim, error) {
+	f, err := blobElements(blob)
+	if err != nil {
+		return kzg4844.Claim{}, err
+	}
+	var x fr.Element
+	if err := x.SetBytesCanonical(z[:]); err != nil {
+		return kzg4844.Claim{}, fmt.Errorf("%w: z: %w", ErrInvalidFieldElement, err)
+	}
+	omega := blobDomain()
+	denominators := make([]fr.Element, len(f))
+	for i := range denominators {
+		denominators[i].Sub(&omega[i], &x)
+		if denominators[i].IsZero() {
+			return kzg4844.Claim(f[i].Bytes()), nil
+		}
+	}
+	y := evaluateAt(f, omega, &x, fr.BatchInvert(denominators), -1)
+	return kzg4844.Claim(y.Bytes()), nil
+}
+
+// blobElements validates blob and returns its field elements.
+func blobElements(blob *kzg4844.Blob) ([]fr.Element, error) {
+	if err := ValidateBlob(blob); err != nil {
+		return nil, err
+	}
+	f := make([]fr.Element, FieldElementsPerBlob)
+	for i := range f {
+		f[i].SetBytes(blob[i*BytesPerFieldElement : (i+1)*BytesPerFieldElement])
+	}
+	return f, nil
+}
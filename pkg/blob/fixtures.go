@@ -0,0 +1,213 @@
+package blob
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// FixtureSchemaVersion is the version of the Fixtures JSON schema. It
+// changes whenever a field is renamed or its meaning changes, not when
+// cases are added.
+const FixtureSchemaVersion = 1
+
+// Fixtures is a corpus of blobs with their KZG commitments, proofs,
+// versioned hashes and openings, for differential tests between KZG
+// implementations. Every value is computed by this package, which checks
+// each before returning it, and depends only on the seed and the trusted
+// setup.
+type Fixtures struct {
+	SchemaVersion int `json:"schema_version"`
+	// SetupHash is the SetupHash of the trusted setup the values were
+	// computed with, CeremonySetupHash for the Ethereum KZG ceremony.
+	SetupHash            common.Hash   `json:"trusted_setup_hash"`
+	Seed                 uint64        `json:"seed"`
+	FieldElementsPerBlob int           `json:"field_elements_per_blob"`
+	Cases                []FixtureCase `json:"cases"`
+}
+
+// FixtureCase is one blob of a Fixtures corpus: the blob, its commitment,
+// blob proof (compute_blob_kzg_proof) and versioned hash, and its openings
+// at the fixture points.
+type FixtureCase struct {
+	Name          string             `json:"name"`
+	Description   string             `json:"description"`
+	Blob          kzg4844.Blob       `json:"blob"`
+	Commitment    kzg4844.Commitment `json:"commitment"`
+	Proof         kzg4844.Proof      `json:"proof"`
+	VersionedHash common.Hash        `json:"versioned_hash"`
+	Openings      []FixtureOpening   `json:"openings"`
+}
+
+// FixtureOpening is the evaluation y of a fixture blob's polynomial at z
+// and its KZG proof (compute_kzg_proof), as the point evaluation
+// precompile takes them.
+type FixtureOpening struct {
+	// Point names how z was chosen.
+	Point string `json:"point"`
+	Opening
+}
+
+// fixtureBlob is a blob of the corpus before its artifacts are computed.
+type fixtureBlob struct {
+	name, description string
+	blob              func(seed uint64) (kzg4844.Blob, error)
+}
+
+var fixtureBlobs = []fixtureBlob{
+	{"zeros", "all-zero blob; its commitment and proofs are the point at infinity", func(uint64) (kzg4844.Blob, error) {
+		return GenerateBlob(PatternZeros, 0, 0)
+	}},
+	{"ones", "every field element is 1, the constant polynomial 1; its commitment is the G1 generator", func(uint64) (kzg4844.Blob, error) {
+		return fillBlob(big.NewInt(1)), nil
+	}},
+	{"max_field_element", "every field element is the modulus minus one, the largest canonical value", func(uint64) (kzg4844.Blob, error) {
+		return GenerateBlob(PatternMaxFieldElement, 0, 0)
+	}},
+	{"single_first", "field element 0 is 1, all others zero", func(uint64) (kzg4844.Blob, error) {
+		return singleElementBlob(0, big.NewInt(1)), nil
+	}},
+	{"single_last_max", "the last field element is the modulus minus one, all others zero", func(uint64) (kzg4844.Blob, error) {
+		return singleElementBlob(FieldElementsPerBlob-1, maxFieldElement()), nil
+	}},
+	{"incrementing", "field element i is i", func(uint64) (kzg4844.Blob, error) {
+		return GenerateBlob(PatternIncrementing, 0, 0)
+	}},
+	{"random_full", "every field element is a random canonical scalar from the seed, as gen writes blob 0", func(seed uint64) (kzg4844.Blob, error) {
+		return GenerateBlob(PatternRandom, seed, 0)
+	}},
+}
+
+// fixturePoints are the evaluation points each fixture blob is opened at.
+// A point of the blob domain opens the blob's own field element there.
+var fixturePoints = []struct {
+	name  string
+	point func(vh common.Hash, seed uint64) kzg4844.Point
+}{
+	{"domain_first", func(common.Hash, uint64) kzg4844.Point { return mustEvaluationPoint(0) }},
+	{"domain_last", func(common.Hash, uint64) kzg4844.Point { return mustEvaluationPoint(FieldElementsPerBlob - 1) }},
+	{"zero", func(common.Hash, uint64) kzg4844.Point { return kzg4844.Point{} }},
+	{"modulus_minus_one", func(common.Hash, uint64) kzg4844.Point {
+		var z kzg4844.Point
+		maxFieldElement().FillBytes(z[:])
+		return z
+	}},
+	{"versioned_hash_challenge", func(vh common.Hash, _ uint64) kzg4844.Point { return VersionedHashChallenge(vh) }},
+	// The random point is drawn from the seed and the blob, so each blob
+	// has its own.
+	{"random", func(vh common.Hash, seed uint64) kzg4844.Point {
+		var z kzg4844.Point
+		randomScalar(rand.New(rand.NewPCG(seed, binary.BigEndian.Uint64(vh[24:]))), z[:])
+		return z
+	}},
+}
+
+// NewFixtures computes the fixture corpus for seed, which only changes the
+// random blob and points. The corpus covers the all-zero blob, constant and
+// maximal field elements, a single nonzero element at either end, the
+// incrementing pattern and a full random blob, each opened at the first
+// and last points of the blob domain, zero, the modulus minus one, the
+// keccak256 challenge of its versioned hash and a random point. SetupHash
+// is left for the caller, which knows the setup in use.
+func NewFixtures(seed uint64) (*Fixtures, error) {
+	f := &Fixtures{
+		SchemaVersion:        FixtureSchemaVersion,
+		Seed:                 seed,
+		FieldElementsPerBlob: FieldElementsPerBlob,
+	}
+	for _, fb := range fixtureBlobs {
+		b, err := fb.blob(seed)
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", fb.name, err)
+		}
+		c, err := newFixtureCase(fb.name, fb.description, &b, seed)
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", fb.name, err)
+		}
+		f.Cases = append(f.Cases, *c)
+	}
+	return f, nil
+}
+
+// newFixtureCase computes the artifacts and openings of b and verifies
+// each.
+func newFixtureCase(name, description string, b *kzg4844.Blob, seed uint64) (*FixtureCase, error) {
+	a, err := NewArtifacts(b, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := Verify(b, a.Commitment, a.Proof); err != nil {
+		return nil, err
+	}
+	c := &FixtureCase{
+		Name:          name,
+		Description:   description,
+		Blob:          *b,
+		Commitment:    a.Commitment,
+		Proof:         a.Proof,
+		VersionedHash: a.VersionedHash,
+	}
+	for _, p := range fixturePoints {
+		z := p.point(a.VersionedHash, seed)
+		proof, y, err := ProveAt(b, z)
+		if err != nil {
+			return nil, fmt.Errorf("point %s: %w", p.name, err)
+		}
+		if err := VerifyAt(a.Commitment, z, y, proof); err != nil {
+			return nil, fmt.Errorf("point %s: %w", p.name, err)
+		}
+		c.Openings = append(c.Openings, FixtureOpening{
+			Point:   p.name,
+			Opening: Opening{Z: common.Hash(z), Y: common.Hash(y), Proof: proof},
+		})
+	}
+	return c, nil
+}
+
+// VersionedHashChallenge returns an evaluation point fixed by a blob but
+// not chosen by hand: keccak256 of its versioned hash reduced modulo the
+// BLS12-381 scalar field.
+func VersionedHashChallenge(versionedHash common.Hash) kzg4844.Point {
+	v := new(big.Int).SetBytes(crypto.Keccak256(versionedHash[:]))
+	v.Mod(v, BLSModulus.Big())
+	var z kzg4844.Point
+	v.FillBytes(z[:])
+	return z
+}
+
+// maxFieldElement returns the modulus minus one.
+func maxFieldElement() *big.Int {
+	return new(big.Int).Sub(BLSModulus.Big(), big.NewInt(1))
+}
+
+// fillBlob returns the blob whose every field element is v.
+func fillBlob(v *big.Int) kzg4844.Blob {
+	var b kzg4844.Blob
+	for i := range FieldElementsPerBlob {
+		v.FillBytes(b[i*BytesPerFieldElement : (i+1)*BytesPerFieldElement])
+	}
+	return b
+}
+
+// singleElementBlob returns the blob whose field element i is v and all
+// others zero.
+func singleElementBlob(i int, v *big.Int) kzg4844.Blob {
+	var b kzg4844.Blob
+	v.FillBytes(b[i*BytesPerFieldElement : (i+1)*BytesPerFieldElement])
+	return b
+}
+
+// mustEvaluationPoint returns EvaluationPoint(i) for an i known to be in
+// range.
+func mustEvaluationPoint(i int) kzg4844.Point {
+	z, err := EvaluationPoint(i)
+	if err != nil {
+		panic(err)
+	}
+	return z
+}
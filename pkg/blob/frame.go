@@ -0,0 +1,201 @@
+package blob
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/encrypt"
+)
+
+// FrameMagic identifies a blob written by EncodeFramed.
+var FrameMagic = [4]byte{'B', 'L', 'O', 'B'}
+
+const (
+	// FrameVersion is the framing format version written by EncodeFramed.
+	// Version 2 added the compression byte; version 1 frames are still
+	// decoded.
+	FrameVersion = 2
+
+	// FrameHeaderSize is the size of the frame header: the magic bytes, a
+	// version byte, a compression byte and a 4-byte big-endian length of
+	// the (possibly compressed) payload.
+	FrameHeaderSize = len(FrameMagic) + 1 + 1 + 4
+
+	// MaxFramedPayloadSize is the largest payload EncodeFramed can store in
+	// one blob, after compression.
+	MaxFramedPayloadSize = MaxPackedSize - FrameHeaderSize
+
+	// frameV1HeaderSize is the header size of version 1 frames, which have
+	// no compression byte.
+	frameV1HeaderSize = FrameHeaderSize - 1
+
+	// frameEncrypted is set in the compression byte when the compressed
+	// payload is encrypted.
+	frameEncrypted = 0x80
+)
+
+// ErrNotFramed is returned by DecodeFramed when the blob does not start with
+// FrameMagic.
+var ErrNotFramed = errors.New("blob is not framed: magic bytes missing")
+
+// ErrEncrypted is returned by DecodeFramed when the payload is encrypted;
+// use DecodeFramedEncrypted.
+var ErrEncrypted = errors.New("payload is encrypted")
+
+// EncodeFramed packs data into a blob as magic bytes, version, compression,
+// payload length, payload and zero padding. Every payload has exactly one
+// framed encoding, so DecodeFramed can tell the payload from the padding and
+// reject anything else.
+func EncodeFramed(data []byte) (kzg4844.Blob, error) {
+	return EncodeFramedCompressed(data, CompressionNone)
+}
+
+// EncodeFramedCompressed is like EncodeFramed but compresses data with c
+// first and records c in the frame header, so DecodeFramed decompresses it
+// transparently. The size limit applies to the compressed payload.
+func EncodeFramedCompressed(data []byte, c Compression) (kzg4844.Blob, error) {
+	return EncodeFramedEncrypted(data, c, nil)
+}
+
+// EncodeFramedEncrypted is like EncodeFramedCompressed but also encrypts the
+// compressed payload with secret, and marks the frame as encrypted, so only
+// DecodeFramedEncrypted with the same secret recovers it. A nil secret
+// leaves the payload in the clear. The size limit applies to the encrypted
+// payload.
+func EncodeFramedEncrypted(data []byte, c Compression, secret *encrypt.Secret) (kzg4844.Blob, error) {
+	stored, err := Compress(c, data)
+	if err != nil {
+		return kzg4844.Blob{}, err
+	}
+	flags := byte(c)
+	if secret != nil {
+		if stored, err = secret.Seal(stored); err != nil {
+			return kzg4844.Blob{}, err
+		}
+		flags |= frameEncrypted
+	}
+	if len(stored) > MaxFramedPayloadSize {
+		switch {
+		case secret != nil:
+			return kzg4844.Blob{}, fmt.Errorf("%w: %d bytes take %d bytes compressed with %s and encrypted, max %d bytes",
+				ErrBlobTooLarge, len(data), len(stored), c, MaxFramedPayloadSize)
+		case c == CompressionNone:
+			return kzg4844.Blob{}, fmt.Errorf("%w: %d bytes, max %d bytes", ErrBlobTooLarge, len(data), MaxFramedPayloadSize)
+		}
+		return kzg4844.Blob{}, fmt.Errorf("%w: %d bytes compress to %d bytes with %s, max %d bytes",
+			ErrBlobTooLarge, len(data), len(stored), c, MaxFramedPayloadSize)
+	}
+
+	buf := make([]byte, FrameHeaderSize+len(stored))
+	copy(buf, FrameMagic[:])
+	buf[len(FrameMagic)] = FrameVersion
+	buf[len(FrameMagic)+1] = flags
+	binary.BigEndian.PutUint32(buf[len(FrameMagic)+2:], uint32(len(stored)))
+	copy(buf[FrameHeaderSize:], stored)
+	return Pack(buf)
+}
+
+// DecodeFramed recovers the payload written by EncodeFramed, decompressing
+// it if needed. It fails if the magic bytes or version do not match, or if
+// anything other than zeros follows the payload, and with ErrEncrypted if
+// the payload is encrypted.
+func DecodeFramed(blob kzg4844.Blob) ([]byte, error) {
+	return DecodeFramedEncrypted(blob, nil)
+}
+
+// DecodeFramedEncrypted is like DecodeFramed but first decrypts an
+// encrypted payload with secret. A payload in the clear is decoded as is.
+// The error wraps encrypt.ErrDecrypt if secret is not the one the payload
+// was encrypted with.
+func DecodeFramedEncrypted(blob kzg4844.Blob, secret *encrypt.Secret) ([]byte, error) {
+	data, c, encrypted, err := decodeFrame(blob)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted {
+		if secret == nil {
+			return nil, ErrEncrypted
+		}
+		if data, err = secret.Open(data); err != nil {
+			return nil, err
+		}
+	}
+	return Decompress(c, data)
+}
+
+// DecodeFramedRaw is like DecodeFramed but returns the payload as stored,
+// without decompressing it, along with its compression. It fails with
+// ErrEncrypted if the payload is encrypted.
+func DecodeFramedRaw(blob kzg4844.Blob) ([]byte, Compression, error) {
+	data, c, encrypted, err := decodeFrame(blob)
+	if err == nil && encrypted {
+		err = ErrEncrypted
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, c, nil
+}
+
+// Frame describes the header of a framed blob.
+type Frame struct {
+	Compression Compression
+	Encrypted   bool
+	// StoredSize is the size of the payload as stored, after compression
+	// and encryption.
+	StoredSize int
+}
+
+// ReadFrame returns the header of a framed blob, which is readable without
+// the key of an encrypted payload.
+func ReadFrame(blob *kzg4844.Blob) (Frame, error) {
+	data, c, encrypted, err := decodeFrame(*blob)
+	if err != nil {
+		return Frame{}, err
+	}
+	return Frame{Compression: c, Encrypted: encrypted, StoredSize: len(data)}, nil
+}
+
+// decodeFrame returns the payload of a frame as stored, its compression and
+// whether it is encrypted.
+func decodeFrame(blob kzg4844.Blob) ([]byte, Compression, bool, error) {
+	packed, err := Unpack(&blob, MaxPackedSize)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if !bytes.Equal(packed[:len(FrameMagic)], FrameMagic[:]) {
+		return nil, 0, false, ErrNotFramed
+	}
+
+	var (
+		c          = CompressionNone
+		encrypted  bool
+		headerSize int
+	)
+	switch v := packed[len(FrameMagic)]; v {
+	case 1:
+		headerSize = frameV1HeaderSize
+	case 2:
+		headerSize = FrameHeaderSize
+		flags := packed[len(FrameMagic)+1]
+		c, encrypted = Compression(flags&^frameEncrypted), flags&frameEncrypted != 0
+	default:
+		return nil, 0, false, fmt.Errorf("%w: unsupported version %d", ErrInvalidFrame, v)
+	}
+	size := binary.BigEndian.Uint32(packed[headerSize-4:])
+	if int(size) > MaxPackedSize-headerSize {
+		return nil, 0, false, fmt.Errorf("%w: length %d bytes, max %d bytes", ErrInvalidFrame, size, MaxPackedSize-headerSize)
+	}
+
+	end := headerSize + int(size)
+	for i := end; i < len(packed); i++ {
+		if packed[i] != 0 {
+			return nil, 0, false, fmt.Errorf("%w: non-zero padding at offset %d after %d-byte payload", ErrInvalidFrame, i, size)
+		}
+	}
+	return packed[headerSize:end], c, encrypted, nil
+}
@@ -0,0 +1,97 @@
+package blob
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// Pattern selects the contents of a generated test blob. Every pattern
+// yields canonical field elements, so the blobs commit and prove without
+// error.
+type Pattern byte
+
+const (
+	// PatternRandom fills each field element with a uniformly random scalar.
+	PatternRandom Pattern = iota
+	// PatternZeros is the all-zero blob.
+	PatternZeros
+	// PatternIncrementing sets element i of blob n to n*FieldElementsPerBlob+i,
+	// so every element of a generated set is distinct.
+	PatternIncrementing
+	// PatternMaxFieldElement sets every element to the modulus minus one,
+	// the largest canonical value.
+	PatternMaxFieldElement
+)
+
+var patternNames = map[Pattern]string{
+	PatternRandom:          "random",
+	PatternZeros:           "zeros",
+	PatternIncrementing:    "incrementing",
+	PatternMaxFieldElement: "max-field-element",
+}
+
+func (p Pattern) String() string {
+	if name, ok := patternNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("pattern(%d)", byte(p))
+}
+
+// ParsePattern returns the pattern with the given name: random, zeros,
+// incrementing or max-field-element.
+func ParsePattern(name string) (Pattern, error) {
+	for p, n := range patternNames {
+		if strings.EqualFold(name, n) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown pattern %q (want random, zeros, incrementing or max-field-element)", name)
+}
+
+// GenerateBlob returns test blob number index of pattern p. Random blobs
+// depend only on seed and index, so a fixture set can be regenerated, or
+// extended, blob by blob.
+func GenerateBlob(p Pattern, seed uint64, index int) (kzg4844.Blob, error) {
+	var blob kzg4844.Blob
+	switch p {
+	case PatternZeros:
+	case PatternIncrementing:
+		base := uint64(index) * FieldElementsPerBlob
+		for i := range FieldElementsPerBlob {
+			binary.BigEndian.PutUint64(blob[(i+1)*BytesPerFieldElement-8:], base+uint64(i))
+		}
+	case PatternMaxFieldElement:
+		top := new(big.Int).Sub(new(big.Int).SetBytes(BLSModulus[:]), big.NewInt(1)).Bytes()
+		for i := range FieldElementsPerBlob {
+			copy(blob[i*BytesPerFieldElement:], top)
+		}
+	case PatternRandom:
+		rng := rand.New(rand.NewPCG(seed, uint64(index)))
+		for i := range FieldElementsPerBlob {
+			randomScalar(rng, blob[i*BytesPerFieldElement:(i+1)*BytesPerFieldElement])
+		}
+	default:
+		return blob, fmt.Errorf("unknown pattern %v", p)
+	}
+	return blob, nil
+}
+
+// randomScalar fills elem with a uniformly random canonical scalar by
+// rejection sampling. The modulus is about 0.9 * 2^255, so few 255-bit
+// candidates are rejected.
+func randomScalar(rng *rand.Rand, elem []byte) {
+	for {
+		for j := 0; j < BytesPerFieldElement; j += 8 {
+			binary.BigEndian.PutUint64(elem[j:], rng.Uint64())
+		}
+		elem[0] &= 0x7f
+		if string(elem) < string(BLSModulus[:]) {
+			return
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package blob
+
+import (
+	"sync"
+
+	goethkzg "github.com/crate-crypto/go-eth-kzg"
+)
+
+var (
+	goKZGCtx     *goethkzg.Context
+	goKZGCtxErr  error
+	goKZGCtxOnce sync.Once
+)
+
+// goKZGContext lazily initializes a go-eth-kzg context for the operations
+// go-ethereum's kzg4844 package does not expose, such as cells and batch
+// verification. It returns the context of UseTrustedSetup if one is set.
+func goKZGContext() (*goethkzg.Context, error) {
+	if ctx := customCtx.Load(); ctx != nil {
+		return ctx, nil
+	}
+	goKZGCtxOnce.Do(func() {
+		goKZGCtx, goKZGCtxErr = goethkzg.NewContext4096Secure()
+	})
+	return goKZGCtx, goKZGCtxErr
+}
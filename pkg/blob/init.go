@@ -0,0 +1,47 @@
+package blob
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// Init initializes the KZG contexts now rather than on first use: the one
+// of go-ethereum's kzg4844 package behind Commit and the proofs, unless a
+// setup was installed with UseTrustedSetup, and the go-eth-kzg context
+// behind cells and batch verification. Loading the setup into either takes
+// noticeable time, which otherwise falls on the first operation. Later
+// calls return at once.
+func Init() error {
+	if customCtx.Load() == nil {
+		// kzg4844 has no initializer of its own. Committing to the zero
+		// blob runs its lazy one and costs next to nothing after it.
+		if _, err := kzg4844.BlobToCommitment(new(kzg4844.Blob)); err != nil {
+			return fmt.Errorf("failed to initialize KZG context: %w", err)
+		}
+	}
+	if _, err := goKZGContext(); err != nil {
+		return fmt.Errorf("failed to initialize KZG context: %w", err)
+	}
+	return nil
+}
+
+// Prewarm calls Init and then commits to, proves and verifies
+// ReferenceBlob, so a service's first request runs as fast as later ones.
+// It returns how long that took.
+func Prewarm() (time.Duration, error) {
+	start := time.Now()
+	if err := Init(); err != nil {
+		return 0, err
+	}
+	b := ReferenceBlob()
+	a, err := NewArtifacts(b, false)
+	if err != nil {
+		return 0, err
+	}
+	if err := Verify(b, a.Commitment, a.Proof); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
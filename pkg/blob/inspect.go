@@ -0,0 +1,77 @@
+package blob
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// Inspection describes how a blob's field elements are used, as reported
+// by Inspect.
+type Inspection struct {
+	// NonCanonical lists the field elements that are not below the
+	// BLS12-381 modulus, which the KZG functions reject.
+	NonCanonical []int `json:"non_canonical,omitempty"`
+	// DataEnd is the offset after the last non-zero byte, 0 for the zero
+	// blob.
+	DataEnd int `json:"data_end"`
+	// PaddingStart is the first field element of the trailing zero
+	// padding, or FieldElementsPerBlob if the last element is not zero.
+	PaddingStart int `json:"padding_start"`
+	// Packed reports that the high byte of every element is zero, as Pack
+	// writes them.
+	Packed bool `json:"packed"`
+	// Scheme is the decode chain Sniff found, such as
+	// "blob-poc-frame+zstd", or empty if no layout matched.
+	Scheme string `json:"scheme,omitempty"`
+	// PayloadSize is the size of the payload Sniff decoded.
+	PayloadSize int `json:"payload_size,omitempty"`
+	// Frame is the header of a blob-poc frame.
+	Frame *FrameHeader `json:"frame,omitempty"`
+}
+
+// FrameHeader is the header of a blob-poc frame, as reported by Inspect.
+type FrameHeader struct {
+	Version     int    `json:"version"`
+	Compression string `json:"compression"`
+	Encrypted   bool   `json:"encrypted,omitempty"`
+	StoredSize  int    `json:"stored_size"`
+}
+
+// Inspect reports the non-canonical field elements of b, where its zero
+// padding begins and the framing it was encoded with, if any is known.
+func Inspect(b *kzg4844.Blob) *Inspection {
+	in := &Inspection{Packed: true}
+	var ferr *FieldElementError
+	if errors.As(ValidateBlob(b), &ferr) {
+		in.NonCanonical = ferr.Indices
+	}
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] != 0 {
+			in.DataEnd = i + 1
+			break
+		}
+	}
+	in.PaddingStart = (in.DataEnd + BytesPerFieldElement - 1) / BytesPerFieldElement
+	for i := 0; i < len(b); i += BytesPerFieldElement {
+		if b[i] != 0 {
+			in.Packed = false
+			break
+		}
+	}
+
+	if f, err := ReadFrame(b); err == nil {
+		header, _ := Unpack(b, FrameHeaderSize)
+		in.Frame = &FrameHeader{
+			Version:     int(header[len(FrameMagic)]),
+			Compression: f.Compression.String(),
+			Encrypted:   f.Encrypted,
+			StoredSize:  f.StoredSize,
+		}
+		in.Scheme = LayoutFramed
+	}
+	if r, err := Sniff(b); err == nil {
+		in.Scheme, in.PayloadSize = r.Scheme(), len(r.Payload)
+	}
+	return in
+}
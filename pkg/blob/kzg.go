@@ -0,0 +1,102 @@
+package blob
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+
+	goethkzg "github.com/crate-crypto/go-eth-kzg"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"golang.org/x/crypto/sha3"
+
+	"kzg-blob-poc/pkg/metrics"
+)
+
+// VersionKZG is the EIP-4844 version byte of versioned hashes of KZG
+// commitments.
+const VersionKZG = 0x01
+
+// Commit generates the 48-byte KZG commitment for a blob
+func Commit(blob *kzg4844.Blob) (kzg4844.Commitment, error) {
+	var (
+		commitment kzg4844.Commitment
+		err        error
+	)
+	if ctx := customCtx.Load(); ctx != nil {
+		var c goethkzg.KZGCommitment
+		c, err = ctx.BlobToKZGCommitment((*goethkzg.Blob)(blob), 0)
+		commitment = kzg4844.Commitment(c)
+	} else {
+		commitment, err = kzg4844.BlobToCommitment(blob)
+	}
+	if err == nil {
+		metrics.Commitments.Inc()
+	}
+	return commitment, blobError(blob, err, nil)
+}
+
+// Prove generates the 48-byte KZG proof binding a blob to its commitment
+func Prove(blob *kzg4844.Blob, commitment kzg4844.Commitment) (kzg4844.Proof, error) {
+	if ctx := customCtx.Load(); ctx != nil {
+		proof, err := ctx.ComputeBlobKZGProof((*goethkzg.Blob)(blob), goethkzg.KZGCommitment(commitment), 0)
+		return kzg4844.Proof(proof), blobError(blob, err, nil)
+	}
+	proof, err := kzg4844.ComputeBlobProof(blob, commitment)
+	return proof, blobError(blob, err, nil)
+}
+
+// Verify checks that proof attests commitment is the KZG commitment of blob
+func Verify(blob *kzg4844.Blob, commitment kzg4844.Commitment, proof kzg4844.Proof) error {
+	start := time.Now()
+	var err error
+	if ctx := customCtx.Load(); ctx != nil {
+		err = ctx.VerifyBlobKZGProof((*goethkzg.Blob)(blob), goethkzg.KZGCommitment(commitment), goethkzg.KZGProof(proof))
+	} else {
+		err = kzg4844.VerifyBlobProof(blob, commitment, proof)
+	}
+	metrics.ObserveVerify(start, err)
+	return blobError(blob, err, ErrProofMismatch)
+}
+
+// VersionedHash computes the versioned hash (blob hash) from a KZG commitment
+func VersionedHash(commitment kzg4844.Commitment) common.Hash {
+	// A SHA-256 digest is always 32 bytes, so this cannot fail.
+	vh, _ := CalcBlobHash(VersionKZG, sha256.New(), commitment)
+	return vh
+}
+
+// CalcBlobHash computes a versioned hash with an arbitrary version byte and
+// hash function: the version followed by bytes 1 to 31 of the digest of the
+// commitment, the first 32 bytes with the first replaced. hasher is reset
+// first, and must give digests of at least 32 bytes. VersionedHash is the
+// EIP-4844 case of version 0x01 with SHA-256.
+func CalcBlobHash(version byte, hasher hash.Hash, commitment kzg4844.Commitment) (common.Hash, error) {
+	hasher.Reset()
+	hasher.Write(commitment[:])
+	d := hasher.Sum(nil)
+	if len(d) < common.HashLength {
+		return common.Hash{}, fmt.Errorf("%d-byte digest is too short for a versioned hash, need %d", len(d), common.HashLength)
+	}
+	var vh common.Hash
+	copy(vh[:], d[:common.HashLength])
+	vh[0] = version
+	return vh, nil
+}
+
+// NewHasher returns the hash function named by scheme: sha256, keccak256 or
+// sha3-256. Digests must be at least 32 bytes long.
+func NewHasher(scheme string) (hash.Hash, error) {
+	switch strings.ToLower(scheme) {
+	case "sha256":
+		return sha256.New(), nil
+	case "keccak256":
+		return sha3.NewLegacyKeccak256(), nil
+	case "sha3-256":
+		return sha3.New256(), nil
+	default:
+		return nil, fmt.Errorf("unknown hash scheme %q (want sha256, keccak256 or sha3-256)", scheme)
+	}
+}
@@ -0,0 +1,123 @@
+package blob
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// Opening is the evaluation of a blob polynomial at one point and the KZG
+// proof of it. Z and Y are hashes only so they encode as hex.
+type Opening struct {
+	Z     common.Hash   `json:"z"`
+	Y     common.Hash   `json:"y"`
+	Proof kzg4844.Proof `json:"proof"`
+}
+
+// ProveAtPoints computes the KZG proofs that blob's polynomial evaluates to
+// y at each of points, as ProveAt does for one, in the order given. The
+// blob is converted to field elements once and the divisions of all points
+// share one batch inversion, so a further point costs little more than the
+// commitment to its quotient, which is its proof. Points must be canonical
+// big-endian field elements; a point of the blob's domain is opened to the
+// blob's own field element there.
+func ProveAtPoints(blob *kzg4844.Blob, points []kzg4844.Point) ([]Opening, error) {
+	f, err := blobElements(blob)
+	if err != nil {
+		return nil, err
+	}
+	const n = FieldElementsPerBlob
+	omega := blobDomain()
+
+	// denominators[k*n+i] is omega[i]-z_k, or 1 at the point itself when
+	// z_k is in the domain, where the quotient has no pole to divide by.
+	zs := make([]fr.Element, len(points))
+	inDomain := make([]int, len(points))
+	denominators := make([]fr.Element, len(points)*n)
+	for k, p := range points {
+		if err := zs[k].SetBytesCanonical(p[:]); err != nil {
+			return nil, fmt.Errorf("%w: point %d: %w", ErrInvalidFieldElement, k, err)
+		}
+		inDomain[k] = -1
+		d := denominators[k*n : (k+1)*n]
+		for i := range d {
+			d[i].Sub(&omega[i], &zs[k])
+			if d[i].IsZero() {
+				inDomain[k] = i
+				d[i].SetOne()
+			}
+		}
+	}
+	inverses := fr.BatchInvert(denominators)
+
+	openings := make([]Opening, len(points))
+	var quotient kzg4844.Blob
+	for k := range points {
+		inv := inverses[k*n : (k+1)*n]
+		y := evaluateAt(f, omega, &zs[k], inv, inDomain[k])
+
+		var diff, sum fr.Element
+		for i := range f {
+			if i == inDomain[k] {
+				continue
+			}
+			diff.Sub(&f[i], &y)
+			// (f_i - y) / (omega_i - z)
+			var q fr.Element
+			q.Mul(&diff, &inv[i])
+			putElement(&quotient, i, &q)
+			if inDomain[k] >= 0 {
+				// The quotient at z = omega_m is the sum over the other
+				// points of (f_i - y) * omega_i / (z * (z - omega_i)).
+				q.Mul(&q, &omega[i])
+				sum.Sub(&sum, &q)
+			}
+		}
+		if m := inDomain[k]; m >= 0 {
+			var zInv fr.Element
+			zInv.Inverse(&zs[k])
+			sum.Mul(&sum, &zInv)
+			putElement(&quotient, m, &sum)
+		}
+
+		proof, err := Commit(&quotient)
+		if err != nil {
+			return nil, fmt.Errorf("point %d: %w", k, err)
+		}
+		openings[k] = Opening{Z: common.Hash(points[k]), Y: y.Bytes(), Proof: kzg4844.Proof(proof)}
+	}
+	return openings, nil
+}
+
+// evaluateAt returns the value at z of the polynomial whose values on the
+// domain omega are f, given the inverses of omega_i - z. If z is domain
+// point m, the value is f[m]; otherwise it is found with the barycentric
+// formula (z^n - 1) / n * sum(f_i * omega_i / (z - omega_i)).
+func evaluateAt(f, omega []fr.Element, z *fr.Element, inv []fr.Element, m int) fr.Element {
+	if m >= 0 {
+		return f[m]
+	}
+	var sum, t fr.Element
+	for i := range f {
+		t.Mul(&f[i], &omega[i])
+		t.Mul(&t, &inv[i])
+		sum.Sub(&sum, &t) // inv holds 1 / (omega_i - z)
+	}
+	var zn, n fr.Element
+	zn.Exp(*z, big.NewInt(int64(len(f))))
+	zn.Sub(&zn, new(fr.Element).SetOne())
+	n.SetUint64(uint64(len(f)))
+	n.Inverse(&n)
+	sum.Mul(&sum, &zn)
+	sum.Mul(&sum, &n)
+	return sum
+}
+
+// putElement writes e as field element i of b.
+func putElement(b *kzg4844.Blob, i int, e *fr.Element) {
+	v := e.Bytes()
+	copy(b[i*BytesPerFieldElement:], v[:])
+}
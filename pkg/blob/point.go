@@ -0,0 +1,28 @@
+package blob
+
+import (
+	goethkzg "github.com/crate-crypto/go-eth-kzg"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// ProveAt computes the KZG proof that the polynomial committed to by blob
+// evaluates to the returned claim y at the evaluation point z. The point must
+// be a canonical big-endian field element.
+func ProveAt(blob *kzg4844.Blob, z kzg4844.Point) (kzg4844.Proof, kzg4844.Claim, error) {
+	if ctx := customCtx.Load(); ctx != nil {
+		proof, claim, err := ctx.ComputeKZGProof((*goethkzg.Blob)(blob), goethkzg.Scalar(z), 0)
+		return kzg4844.Proof(proof), kzg4844.Claim(claim), blobError(blob, err, nil)
+	}
+	proof, claim, err := kzg4844.ComputeProof(blob, z)
+	return proof, claim, blobError(blob, err, nil)
+}
+
+// VerifyAt checks that proof attests the polynomial committed to by
+// commitment evaluates to y at z, as the point evaluation precompile does.
+func VerifyAt(commitment kzg4844.Commitment, z kzg4844.Point, y kzg4844.Claim, proof kzg4844.Proof) error {
+	if ctx := customCtx.Load(); ctx != nil {
+		err := ctx.VerifyKZGProof(goethkzg.KZGCommitment(commitment), goethkzg.Scalar(z), goethkzg.Scalar(y), goethkzg.KZGProof(proof))
+		return kzgError(err, ErrProofMismatch)
+	}
+	return kzgError(kzg4844.VerifyProof(commitment, z, y, proof), ErrProofMismatch)
+}
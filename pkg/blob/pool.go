@@ -0,0 +1,29 @@
+package blob
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// blobPool recycles blob buffers. A blob is 128 KiB, so allocating one per
+// job in a long batch keeps the garbage collector busy for no benefit.
+var blobPool = sync.Pool{
+	New: func() any { return new(kzg4844.Blob) },
+}
+
+// GetBlob returns a zeroed blob from a shared pool. Hand it back with
+// PutBlob once nothing refers to it any more.
+func GetBlob() *kzg4844.Blob {
+	return blobPool.Get().(*kzg4844.Blob)
+}
+
+// PutBlob zeroes b and returns it to the pool used by GetBlob. b must not be
+// used afterwards. A nil b is ignored.
+func PutBlob(b *kzg4844.Blob) {
+	if b == nil {
+		return
+	}
+	clear(b[:])
+	blobPool.Put(b)
+}
@@ -0,0 +1,79 @@
+package blob
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// PointEvaluationAddress is the address of the EIP-4844 point evaluation
+// precompile.
+var PointEvaluationAddress = common.BytesToAddress([]byte{0x0a})
+
+// PointEvaluationInputSize is the length of the precompile input:
+// versioned hash (32) | z (32) | y (32) | commitment (48) | proof (48).
+const PointEvaluationInputSize = 192
+
+// BLSModulus is the BLS12-381 scalar field modulus, big-endian.
+var BLSModulus = common.HexToHash("0x73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001")
+
+var (
+	errPrecompileInputLength   = errors.New("invalid input length")
+	errPrecompileVersionedHash = fmt.Errorf("%w in precompile input", ErrVersionedHashMismatch)
+)
+
+// PointEvaluationInput assembles the 192-byte input of the point evaluation
+// precompile for the given commitment, evaluation and proof.
+func PointEvaluationInput(commitment kzg4844.Commitment, z kzg4844.Point, y kzg4844.Claim, proof kzg4844.Proof) []byte {
+	versionedHash := VersionedHash(commitment)
+
+	input := make([]byte, 0, PointEvaluationInputSize)
+	input = append(input, versionedHash[:]...)
+	input = append(input, z[:]...)
+	input = append(input, y[:]...)
+	input = append(input, commitment[:]...)
+	input = append(input, proof[:]...)
+	return input
+}
+
+// PointEvaluationOutput is the precompile's return value on success:
+// FIELD_ELEMENTS_PER_BLOB and BLS_MODULUS as 32-byte big-endian integers.
+func PointEvaluationOutput() []byte {
+	output := make([]byte, 64)
+	binary.BigEndian.PutUint64(output[24:32], FieldElementsPerBlob)
+	copy(output[32:], BLSModulus[:])
+	return output
+}
+
+// RunPointEvaluation mimics the point evaluation precompile: it returns
+// PointEvaluationOutput when input is accepted and an error when the
+// precompile would revert.
+func RunPointEvaluation(input []byte) ([]byte, error) {
+	if len(input) != PointEvaluationInputSize {
+		return nil, fmt.Errorf("%w: got %d bytes, want %d", errPrecompileInputLength, len(input), PointEvaluationInputSize)
+	}
+
+	var (
+		z          kzg4844.Point
+		y          kzg4844.Claim
+		commitment kzg4844.Commitment
+		proof      kzg4844.Proof
+	)
+	versionedHash := input[:32]
+	copy(z[:], input[32:64])
+	copy(y[:], input[64:96])
+	copy(commitment[:], input[96:144])
+	copy(proof[:], input[144:192])
+
+	if computed := VersionedHash(commitment); !bytes.Equal(versionedHash, computed[:]) {
+		return nil, errPrecompileVersionedHash
+	}
+	if err := VerifyAt(commitment, z, y, proof); err != nil {
+		return nil, fmt.Errorf("error verifying kzg proof: %w", err)
+	}
+	return PointEvaluationOutput(), nil
+}
@@ -0,0 +1,167 @@
+package blob
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	goethkzg "github.com/crate-crypto/go-eth-kzg"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// TrustedSetup is a KZG trusted setup: the compressed G1 points in monomial
+// and Lagrange form and the G2 points, as 0x-prefixed hex.
+type TrustedSetup = goethkzg.JSONTrustedSetup
+
+// CeremonySetupHash is the SetupHash of the output of the Ethereum KZG
+// ceremony, the setup go-ethereum and go-eth-kzg embed.
+var CeremonySetupHash = common.HexToHash("0x753bd011b238fb9b63a35b9b526f7830057ced42b9a9c87368a67105bd8f0566")
+
+// Known answers of the ceremony setup for ReferenceBlob, which tie a
+// setup that is only reachable through the KZG functions to the ceremony.
+var (
+	ceremonyCommitment = kzg4844.Commitment(common.FromHex("0xb6b9804594a3ec4d0d6a7233d9daa1bf152b10c35eabe8925197e97bcfa406dc5a369748dfefa3eb3f0b54fc6a050861"))
+	ceremonyProof      = kzg4844.Proof(common.FromHex("0xb3704e48d87127bdceae1fd9fdd792754a5039fb103a7406b594077980a201b9caa3a2a13d4136cc22ff8e9dd9a560b5"))
+)
+
+// customCtx is the go-eth-kzg context of the setup installed with
+// UseTrustedSetup, or nil for the embedded one.
+var customCtx atomic.Pointer[goethkzg.Context]
+
+// ReadTrustedSetup reads a trusted setup file, either in the JSON format
+// of go-ethereum and go-eth-kzg or in the text format of c-kzg-4844.
+func ReadTrustedSetup(path string) (*TrustedSetup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trusted setup: %w", err)
+	}
+	ts, err := ParseTrustedSetup(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return ts, nil
+}
+
+// ParseTrustedSetup parses a trusted setup in JSON or c-kzg-4844 text
+// format. The c-kzg-4844 file lists the G1 and G2 point counts, the G1
+// Lagrange points, the G2 points and then the G1 monomial points, which
+// older files lack and which are required here.
+func ParseTrustedSetup(data []byte) (*TrustedSetup, error) {
+	ts := new(TrustedSetup)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		if err := json.Unmarshal(data, ts); err != nil {
+			return nil, fmt.Errorf("invalid trusted setup JSON: %w", err)
+		}
+		return ts, nil
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid trusted setup: missing point counts")
+	}
+	g1, err1 := strconv.Atoi(fields[0])
+	g2, err2 := strconv.Atoi(fields[1])
+	if err1 != nil || err2 != nil || g1 != FieldElementsPerBlob || g2 < 2 {
+		return nil, fmt.Errorf("invalid trusted setup: want %d G1 and at least 2 G2 points, got %q and %q", FieldElementsPerBlob, fields[0], fields[1])
+	}
+	points := fields[2:]
+	if len(points) != 2*g1+g2 {
+		return nil, fmt.Errorf("invalid trusted setup: got %d points, want %d Lagrange G1, %d G2 and %d monomial G1", len(points), g1, g2, g1)
+	}
+	for i := range g1 {
+		ts.SetupG1Lagrange[i] = "0x" + strings.TrimPrefix(points[i], "0x")
+		ts.SetupG1Monomial[i] = "0x" + strings.TrimPrefix(points[g1+g2+i], "0x")
+	}
+	for _, p := range points[g1 : g1+g2] {
+		ts.SetupG2 = append(ts.SetupG2, "0x"+strings.TrimPrefix(p, "0x"))
+	}
+	return ts, nil
+}
+
+// SetupHash returns the SHA-256 of the setup's points as bytes: the
+// monomial G1 points, the Lagrange G1 points and the G2 points, in order.
+// It does not depend on the file format or the case of the hex.
+func SetupHash(ts *TrustedSetup) (common.Hash, error) {
+	h := sha256.New()
+	groups := [][]string{ts.SetupG1Monomial[:], ts.SetupG1Lagrange[:], ts.SetupG2}
+	sizes := []int{48, 48, 96}
+	for g, points := range groups {
+		for i, p := range points {
+			b, err := hex.DecodeString(strings.TrimPrefix(p, "0x"))
+			if err != nil || len(b) != sizes[g] {
+				return common.Hash{}, fmt.Errorf("invalid trusted setup: point %d of group %d is not %d bytes of hex", i, g, sizes[g])
+			}
+			h.Write(b)
+		}
+	}
+	return common.Hash(h.Sum(nil)), nil
+}
+
+// CheckTrustedSetup checks that every point of ts is a valid point in its
+// subgroup and that the setup hashes to want, such as CeremonySetupHash.
+// A setup with a different hash yields an error wrapping ErrSetupMismatch.
+func CheckTrustedSetup(ts *TrustedSetup, want common.Hash) error {
+	if len(ts.SetupG2) < 2 {
+		return fmt.Errorf("invalid trusted setup: %d G2 points, need at least 2", len(ts.SetupG2))
+	}
+	got, err := SetupHash(ts)
+	if err != nil {
+		return err
+	}
+	if err := goethkzg.CheckTrustedSetupIsWellFormed(ts); err != nil {
+		return fmt.Errorf("invalid trusted setup: %w", err)
+	}
+	if got != want {
+		return fmt.Errorf("%w: setup hashes to %s, want %s", ErrSetupMismatch, got, want)
+	}
+	return nil
+}
+
+// UseTrustedSetup makes every KZG function of this package use ts instead
+// of the ceremony setup embedded in the KZG libraries. Check ts with
+// CheckTrustedSetup first; a malformed setup may panic. The functions then
+// always run on go-eth-kzg, whatever kzg4844 backend is selected.
+func UseTrustedSetup(ts *TrustedSetup) error {
+	ctx, err := goethkzg.NewContext4096(ts)
+	if err != nil {
+		return fmt.Errorf("failed to load trusted setup: %w", err)
+	}
+	customCtx.Store(ctx)
+	return nil
+}
+
+// ReferenceBlob returns the blob CheckLoadedSetup commits to: the
+// incrementing pattern, whose element i is i.
+func ReferenceBlob() *kzg4844.Blob {
+	b, _ := GenerateBlob(PatternIncrementing, 0, 0)
+	return &b
+}
+
+// CheckLoadedSetup checks that the setup in use, which the KZG libraries
+// do not expose, is the ceremony setup, by committing to ReferenceBlob and
+// proving it and comparing the results with the known answers. A
+// different setup yields an error wrapping ErrSetupMismatch.
+func CheckLoadedSetup() error {
+	b := ReferenceBlob()
+	a, err := NewArtifacts(b, false)
+	if err != nil {
+		return err
+	}
+	if a.Commitment != ceremonyCommitment {
+		return fmt.Errorf("%w: reference blob commits to %x, want %x", ErrSetupMismatch, a.Commitment, ceremonyCommitment)
+	}
+	if a.Proof != ceremonyProof {
+		return fmt.Errorf("%w: reference blob proof is %x, want %x", ErrSetupMismatch, a.Proof, ceremonyProof)
+	}
+	if err := Verify(b, a.Commitment, a.Proof); err != nil {
+		return fmt.Errorf("%w: %w", ErrSetupMismatch, err)
+	}
+	return nil
+}
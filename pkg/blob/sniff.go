@@ -0,0 +1,254 @@
+package blob
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// Layout names reported by Sniff.
+const (
+	LayoutFramed         = "blob-poc-frame"
+	LayoutLengthPrefixed = "length-prefixed"
+	LayoutPacked         = "packed"
+	LayoutRaw            = "raw"
+)
+
+// SniffResult is the outcome of Sniff: the decode chain that succeeded and
+// the payload it produced.
+type SniffResult struct {
+	// Layout is how the bytes were stored in the field elements.
+	Layout string `json:"layout"`
+	// Codecs are the decoding steps applied after the layout, outermost
+	// first, e.g. ["zlib"].
+	Codecs []string `json:"codecs,omitempty"`
+	// Payload is the decoded content.
+	Payload []byte `json:"-"`
+}
+
+// Scheme returns the decode chain as a single string, such as
+// "packed+zlib".
+func (r *SniffResult) Scheme() string {
+	return strings.Join(append([]string{r.Layout}, r.Codecs...), "+")
+}
+
+// layout extracts the stored bytes of a blob in one storage scheme. exact
+// reports that the scheme delimits its content, so an undetected codec is
+// still a confident match; schemes that only strip padding are tried with
+// every codec first.
+type layout struct {
+	name   string
+	exact  bool
+	unpack func(b *kzg4844.Blob) ([]byte, error)
+}
+
+// layouts are tried in order, most specific first.
+var layouts = []layout{
+	{LayoutFramed, true, unpackFramed},
+	{LayoutLengthPrefixed, true, unpackLengthPrefixed},
+	{LayoutPacked, false, func(b *kzg4844.Blob) ([]byte, error) { return Unpack(b, MaxPackedSize) }},
+	{LayoutRaw, false, func(b *kzg4844.Blob) ([]byte, error) { return b[:], nil }},
+}
+
+// codec is a compression format recognized by Sniff. match checks its magic
+// bytes; a nil match means the format has none and is only tried when no
+// other codec matches.
+type codec struct {
+	name   string
+	match  func(data []byte) bool
+	decode func(data []byte) ([]byte, error)
+}
+
+var codecs = []codec{
+	{"zstd", hasPrefix(0x28, 0xb5, 0x2f, 0xfd), decompressWith(CompressionZstd)},
+	{"gzip", hasPrefix(0x1f, 0x8b, 0x08), gunzip},
+	{"zlib", isZlibHeader, decompressWith(CompressionZlib)},
+	{"brotli", nil, decompressWith(CompressionBrotli)},
+}
+
+// Sniff decodes a blob of unknown origin. It tries each known storage
+// layout (this package's frame, the EncodeBlob length prefix, Pack's
+// 31-byte elements, and the raw bytes) and each known compression (zstd,
+// gzip and zlib by their magic bytes, brotli by trial), and returns the
+// first chain that decodes cleanly. Trailing zero padding is stripped from
+// layouts that do not record their length. Because most layouts accept
+// almost anything, the result is a best guess, and a raw blob with no
+// recognized compression is returned when nothing else fits.
+func Sniff(b *kzg4844.Blob) (*SniffResult, error) {
+	var fallback *SniffResult
+	for _, l := range layouts {
+		data, err := l.unpack(b)
+		if errors.Is(err, ErrEncrypted) {
+			// Any other layout would only find ciphertext.
+			return nil, err
+		}
+		if err != nil {
+			continue
+		}
+		if l.name == LayoutFramed {
+			// The frame header names its compression.
+			return sniffFramed(data)
+		}
+		trimmed := data
+		if !l.exact {
+			trimmed = bytes.TrimRight(data, "\x00")
+		}
+		if r := sniffCodecs(l.name, data, trimmed); r != nil {
+			return r, nil
+		}
+		if l.exact {
+			return &SniffResult{Layout: l.name, Payload: data}, nil
+		}
+		if fallback == nil {
+			fallback = &SniffResult{Layout: l.name, Payload: trimmed}
+		}
+	}
+	if fallback == nil {
+		return nil, errors.New("no known blob layout matched")
+	}
+	return fallback, nil
+}
+
+// sniffFramed decodes the payload of a blob-poc frame, stored as
+// "compression byte + payload" by unpackFramed.
+func sniffFramed(data []byte) (*SniffResult, error) {
+	c, payload := Compression(data[0]), data[1:]
+	r := &SniffResult{Layout: LayoutFramed, Payload: payload}
+	if c != CompressionNone {
+		out, err := Decompress(c, payload)
+		if err != nil {
+			return nil, err
+		}
+		r.Codecs, r.Payload = []string{c.String()}, out
+	}
+	return r, nil
+}
+
+// maxTrailingZeros is how many zero bytes stripped as padding sniffCodecs
+// puts back, since compressed streams often end in zero bytes of a length or
+// checksum.
+const maxTrailingZeros = 4
+
+// sniffCodecs returns the result of the first codec that decodes data, or
+// nil if none does. trimmed is data without its trailing zeros; it is tried
+// first, then with the zeros put back one at a time, then as all of data.
+func sniffCodecs(layout string, data, trimmed []byte) *SniffResult {
+	if len(trimmed) == 0 {
+		return nil
+	}
+	candidates := [][]byte{trimmed}
+	for n := len(trimmed) + 1; n <= min(len(trimmed)+maxTrailingZeros, len(data)); n++ {
+		candidates = append(candidates, data[:n])
+	}
+	if len(data) > len(trimmed)+maxTrailingZeros {
+		candidates = append(candidates, data)
+	}
+	for _, c := range codecs {
+		if c.match != nil && !c.match(trimmed) {
+			continue
+		}
+		if c.match == nil && matchesAny(trimmed) {
+			continue
+		}
+		for _, in := range candidates {
+			if out, err := c.decode(in); err == nil && len(out) > 0 {
+				return &SniffResult{Layout: layout, Codecs: []string{c.name}, Payload: out}
+			}
+		}
+	}
+	return nil
+}
+
+// matchesAny reports whether data starts with the magic bytes of a codec.
+func matchesAny(data []byte) bool {
+	for _, c := range codecs {
+		if c.match != nil && c.match(data) {
+			return true
+		}
+	}
+	return false
+}
+
+func unpackFramed(b *kzg4844.Blob) ([]byte, error) {
+	payload, c, err := DecodeFramedRaw(*b)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{byte(c)}, payload...), nil
+}
+
+// unpackLengthPrefixed reads the EncodeBlob layout, requiring zero padding
+// after the recorded length so that arbitrary packed data is not mistaken
+// for it.
+func unpackLengthPrefixed(b *kzg4844.Blob) ([]byte, error) {
+	packed, err := Unpack(b, MaxPackedSize)
+	if err != nil {
+		return nil, err
+	}
+	size := int(binary.BigEndian.Uint32(packed))
+	if size == 0 || size > MaxPayloadSize {
+		return nil, fmt.Errorf("%w: length prefix %d bytes", ErrInvalidFrame, size)
+	}
+	end := LengthPrefixSize + size
+	if bytes.ContainsFunc(packed[end:], func(r rune) bool { return r != 0 }) {
+		return nil, fmt.Errorf("%w: non-zero padding after %d-byte payload", ErrInvalidFrame, size)
+	}
+	return packed[LengthPrefixSize:end], nil
+}
+
+func hasPrefix(magic ...byte) func([]byte) bool {
+	return func(data []byte) bool { return bytes.HasPrefix(data, magic) }
+}
+
+// isZlibHeader checks the RFC 1950 header: deflate with a window of at most
+// 32 KiB and a valid check value.
+func isZlibHeader(data []byte) bool {
+	return len(data) >= 2 && data[0]&0x0f == 8 && data[0]>>4 <= 7 &&
+		(uint16(data[0])<<8|uint16(data[1]))%31 == 0
+}
+
+func decompressWith(c Compression) func([]byte) ([]byte, error) {
+	return func(data []byte) ([]byte, error) { return Decompress(c, data) }
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	// Stop at the end of the member rather than reading padding as another.
+	zr.Multistream(false)
+	out, err := io.ReadAll(io.LimitReader(zr, MaxDecompressedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > MaxDecompressedSize {
+		return nil, fmt.Errorf("gzip decompression failed: output exceeds %d bytes", MaxDecompressedSize)
+	}
+	return out, nil
+}
+
+// IsHexText reports whether data looks like whitespace-separated hex text,
+// optionally 0x-prefixed. Empty or all-whitespace data is not.
+func IsHexText(data []byte) bool {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("0x"))
+	if len(data) == 0 {
+		return false
+	}
+	for _, c := range data {
+		switch {
+		case c >= '0' && c <= '9', c >= 'a' && c <= 'f', c >= 'A' && c <= 'F':
+		case c == ' ', c == '\n', c == '\r', c == '\t':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// BlobStream packs a payload read from an io.Reader into successive blobs,
+// in the same layout as SplitIntoBlobs, holding only one blob in memory at
+// a time. It is used like a bufio.Scanner:
+//
+//	s := blob.NewBlobStream(r)
+//	for s.Next() {
+//		a, err := s.Artifacts()
+//		...
+//	}
+//	if err := s.Err(); err != nil {
+//		...
+//	}
+type BlobStream struct {
+	r         io.Reader
+	buf       []byte
+	data      []byte // the whole payload when r is nil
+	payload   []byte
+	chunk     Chunk
+	blob      kzg4844.Blob
+	artifacts *BlobArtifacts
+	read      int64
+	started   bool
+	done      bool
+	err       error
+}
+
+// NewBlobStream returns a stream reading its payload from r.
+func NewBlobStream(r io.Reader) *BlobStream {
+	return &BlobStream{r: r, buf: make([]byte, MaxPackedSize)}
+}
+
+// NewBlobStreamFromBytes returns a stream over a payload already in memory,
+// such as a memory-mapped file. Blobs are packed straight from data, which
+// must not change while the stream is in use.
+func NewBlobStreamFromBytes(data []byte) *BlobStream {
+	return &BlobStream{data: data}
+}
+
+// Next reads the next MaxPackedSize bytes of the payload and packs them into
+// a blob. It returns false at the end of the payload or on a read error. An
+// empty payload yields a single empty blob.
+func (s *BlobStream) Next() bool {
+	if s.done {
+		return false
+	}
+	if s.r == nil {
+		return s.nextFromBytes()
+	}
+	n, err := io.ReadFull(s.r, s.buf)
+	switch {
+	case errors.Is(err, io.EOF) && s.started:
+		s.done = true
+		return false
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		s.done = true
+	case err != nil:
+		s.done, s.err = true, fmt.Errorf("failed to read payload: %w", err)
+		return false
+	}
+
+	s.pack(s.buf[:n])
+	return true
+}
+
+// nextFromBytes is Next for a payload in memory.
+func (s *BlobStream) nextFromBytes() bool {
+	rest := s.data[s.read:]
+	if len(rest) == 0 && s.started {
+		s.done = true
+		return false
+	}
+	n := min(len(rest), MaxPackedSize)
+	s.done = n == len(rest)
+	s.pack(rest[:n])
+	return true
+}
+
+// pack makes payload, at most MaxPackedSize bytes, the current blob.
+func (s *BlobStream) pack(payload []byte) {
+	if s.started {
+		s.chunk = Chunk{Index: s.chunk.Index + 1, Offset: s.chunk.Offset + s.chunk.Size}
+	}
+	s.chunk.Size = len(payload)
+	s.started = true
+	s.read += int64(len(payload))
+	s.payload = payload
+	s.artifacts = nil
+	// Pack cannot fail as the payload is at most MaxPackedSize bytes.
+	s.blob, _ = Pack(payload)
+}
+
+// Blob returns the blob produced by the last call to Next. It is
+// overwritten by the following call.
+func (s *BlobStream) Blob() *kzg4844.Blob {
+	return &s.blob
+}
+
+// Payload returns the payload bytes packed into the current blob. Like the
+// blob, they are only valid until the following call to Next.
+func (s *BlobStream) Payload() []byte {
+	return s.payload
+}
+
+// Chunk returns where the current blob's contents sit in the payload.
+func (s *BlobStream) Chunk() Chunk {
+	return s.chunk
+}
+
+// Artifacts returns the commitment, proof and versioned hash of the current
+// blob, computing them on first use.
+func (s *BlobStream) Artifacts() (*BlobArtifacts, error) {
+	if s.artifacts == nil {
+		a, err := NewArtifacts(&s.blob, false)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", s.chunk.Index, err)
+		}
+		s.artifacts = a
+	}
+	return s.artifacts, nil
+}
+
+// BytesRead returns the number of payload bytes consumed so far.
+func (s *BlobStream) BytesRead() int64 {
+	return s.read
+}
+
+// Err returns the first read error encountered by the stream.
+func (s *BlobStream) Err() error {
+	return s.err
+}
@@ -0,0 +1,56 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/metrics"
+)
+
+// WithTimeout runs compute on a copy of b and returns its result. If compute
+// has not returned after timeout, it returns an error wrapping ErrTimeout
+// instead, and if ctx is done first, ctx.Err(). A timeout of zero or less
+// sets no deadline of its own.
+//
+// A goroutine cannot be stopped from outside, so an abandoned computation
+// runs on in the background and its result is dropped. What the deadline
+// buys is that the caller, such as a batch worker or a server request, is
+// freed at once and may reuse b, since compute only sees the copy.
+func WithTimeout[T any](ctx context.Context, timeout time.Duration, b *kzg4844.Blob, compute func(*kzg4844.Blob) (T, error)) (T, error) {
+	if timeout <= 0 && ctx.Done() == nil {
+		return compute(b)
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w after %s", ErrTimeout, timeout))
+		defer cancel()
+	}
+
+	type result struct {
+		v   T
+		err error
+	}
+	done := make(chan result, 1)
+	c := GetBlob()
+	*c = *b
+	go func() {
+		defer PutBlob(c)
+		v, err := compute(c)
+		done <- result{v, err}
+	}()
+	select {
+	case r := <-done:
+		return r.v, r.err
+	case <-ctx.Done():
+		var zero T
+		err := context.Cause(ctx)
+		if errors.Is(err, ErrTimeout) {
+			metrics.KZGTimeouts.Inc()
+		}
+		return zero, err
+	}
+}
@@ -0,0 +1,70 @@
+package blob
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// maxListedElements bounds how many indices FieldElementError.Error lists.
+const maxListedElements = 8
+
+// FieldElementError lists the field elements of a blob that are not
+// canonical BLS12-381 scalars. It wraps ErrInvalidFieldElement.
+type FieldElementError struct {
+	// Indices are the invalid field element indices in ascending order. The
+	// element at index i starts at byte offset i*BytesPerFieldElement.
+	Indices []int
+}
+
+func (e *FieldElementError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v: %d of %d field elements are not below the BLS12-381 modulus: ",
+		ErrInvalidFieldElement, len(e.Indices), FieldElementsPerBlob)
+	for i, idx := range e.Indices[:min(len(e.Indices), maxListedElements)] {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "element %d (offset %d)", idx, idx*BytesPerFieldElement)
+	}
+	if len(e.Indices) > maxListedElements {
+		fmt.Fprintf(&b, " and %d more", len(e.Indices)-maxListedElements)
+	}
+	return b.String()
+}
+
+func (e *FieldElementError) Unwrap() error { return ErrInvalidFieldElement }
+
+// ValidateBlob checks that every 32-byte big-endian field element of blob is
+// smaller than the BLS12-381 scalar field modulus, as the KZG functions
+// require. It returns nil or a *FieldElementError listing every invalid
+// element. ValidateBlob does no KZG work and allocates only on failure, so
+// it is cheap enough to call on every input, fuzzed ones included.
+func ValidateBlob(blob *kzg4844.Blob) error {
+	var invalid []int
+	for i := range FieldElementsPerBlob {
+		elem := blob[i*BytesPerFieldElement : (i+1)*BytesPerFieldElement]
+		if bytes.Compare(elem, BLSModulus[:]) >= 0 {
+			invalid = append(invalid, i)
+		}
+	}
+	if invalid != nil {
+		return &FieldElementError{Indices: invalid}
+	}
+	return nil
+}
+
+// blobError is kzgError for functions taking a blob: a non-canonical scalar
+// is reported with the offending elements of blob.
+func blobError(blob *kzg4844.Blob, err, fallback error) error {
+	err = kzgError(err, fallback)
+	if errors.Is(err, ErrInvalidFieldElement) {
+		if verr := ValidateBlob(blob); verr != nil {
+			return verr
+		}
+	}
+	return err
+}
@@ -0,0 +1,471 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: blob.proto
+
+package blobpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type BlobRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Blob contents, at most 131072 bytes. Shorter input is zero-padded.
+	Blob          []byte `protobuf:"bytes,1,opt,name=blob,proto3" json:"blob,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlobRequest) Reset() {
+	*x = BlobRequest{}
+	mi := &file_blob_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlobRequest) ProtoMessage() {}
+
+func (x *BlobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlobRequest.ProtoReflect.Descriptor instead.
+func (*BlobRequest) Descriptor() ([]byte, []int) {
+	return file_blob_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BlobRequest) GetBlob() []byte {
+	if x != nil {
+		return x.Blob
+	}
+	return nil
+}
+
+type Artifacts struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Commitment []byte                 `protobuf:"bytes,1,opt,name=commitment,proto3" json:"commitment,omitempty"`
+	// Not set by Commit.
+	Proof         []byte `protobuf:"bytes,2,opt,name=proof,proto3" json:"proof,omitempty"`
+	VersionedHash []byte `protobuf:"bytes,3,opt,name=versioned_hash,json=versionedHash,proto3" json:"versioned_hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Artifacts) Reset() {
+	*x = Artifacts{}
+	mi := &file_blob_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Artifacts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Artifacts) ProtoMessage() {}
+
+func (x *Artifacts) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Artifacts.ProtoReflect.Descriptor instead.
+func (*Artifacts) Descriptor() ([]byte, []int) {
+	return file_blob_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Artifacts) GetCommitment() []byte {
+	if x != nil {
+		return x.Commitment
+	}
+	return nil
+}
+
+func (x *Artifacts) GetProof() []byte {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+func (x *Artifacts) GetVersionedHash() []byte {
+	if x != nil {
+		return x.VersionedHash
+	}
+	return nil
+}
+
+type VerifyRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Blob       []byte                 `protobuf:"bytes,1,opt,name=blob,proto3" json:"blob,omitempty"`
+	Commitment []byte                 `protobuf:"bytes,2,opt,name=commitment,proto3" json:"commitment,omitempty"`
+	Proof      []byte                 `protobuf:"bytes,3,opt,name=proof,proto3" json:"proof,omitempty"`
+	// Optional; when set it must match the commitment.
+	VersionedHash []byte `protobuf:"bytes,4,opt,name=versioned_hash,json=versionedHash,proto3" json:"versioned_hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyRequest) Reset() {
+	*x = VerifyRequest{}
+	mi := &file_blob_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyRequest) ProtoMessage() {}
+
+func (x *VerifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyRequest.ProtoReflect.Descriptor instead.
+func (*VerifyRequest) Descriptor() ([]byte, []int) {
+	return file_blob_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *VerifyRequest) GetBlob() []byte {
+	if x != nil {
+		return x.Blob
+	}
+	return nil
+}
+
+func (x *VerifyRequest) GetCommitment() []byte {
+	if x != nil {
+		return x.Commitment
+	}
+	return nil
+}
+
+func (x *VerifyRequest) GetProof() []byte {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+func (x *VerifyRequest) GetVersionedHash() []byte {
+	if x != nil {
+		return x.VersionedHash
+	}
+	return nil
+}
+
+type VerifyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Valid         bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyResponse) Reset() {
+	*x = VerifyResponse{}
+	mi := &file_blob_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyResponse) ProtoMessage() {}
+
+func (x *VerifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyResponse.ProtoReflect.Descriptor instead.
+func (*VerifyResponse) Descriptor() ([]byte, []int) {
+	return file_blob_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *VerifyResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PayloadChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PayloadChunk) Reset() {
+	*x = PayloadChunk{}
+	mi := &file_blob_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PayloadChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PayloadChunk) ProtoMessage() {}
+
+func (x *PayloadChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PayloadChunk.ProtoReflect.Descriptor instead.
+func (*PayloadChunk) Descriptor() ([]byte, []int) {
+	return file_blob_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PayloadChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type SplitBlob struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Position of the blob's contents in the payload, as in blob.ChunkLayout.
+	Index         int64      `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Offset        int64      `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Size          int64      `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Blob          []byte     `protobuf:"bytes,4,opt,name=blob,proto3" json:"blob,omitempty"`
+	Artifacts     *Artifacts `protobuf:"bytes,5,opt,name=artifacts,proto3" json:"artifacts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SplitBlob) Reset() {
+	*x = SplitBlob{}
+	mi := &file_blob_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SplitBlob) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SplitBlob) ProtoMessage() {}
+
+func (x *SplitBlob) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SplitBlob.ProtoReflect.Descriptor instead.
+func (*SplitBlob) Descriptor() ([]byte, []int) {
+	return file_blob_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SplitBlob) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *SplitBlob) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *SplitBlob) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *SplitBlob) GetBlob() []byte {
+	if x != nil {
+		return x.Blob
+	}
+	return nil
+}
+
+func (x *SplitBlob) GetArtifacts() *Artifacts {
+	if x != nil {
+		return x.Artifacts
+	}
+	return nil
+}
+
+var File_blob_proto protoreflect.FileDescriptor
+
+const file_blob_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"blob.proto\x12\n" +
+	"blobpoc.v1\"!\n" +
+	"\vBlobRequest\x12\x12\n" +
+	"\x04blob\x18\x01 \x01(\fR\x04blob\"h\n" +
+	"\tArtifacts\x12\x1e\n" +
+	"\n" +
+	"commitment\x18\x01 \x01(\fR\n" +
+	"commitment\x12\x14\n" +
+	"\x05proof\x18\x02 \x01(\fR\x05proof\x12%\n" +
+	"\x0eversioned_hash\x18\x03 \x01(\fR\rversionedHash\"\x80\x01\n" +
+	"\rVerifyRequest\x12\x12\n" +
+	"\x04blob\x18\x01 \x01(\fR\x04blob\x12\x1e\n" +
+	"\n" +
+	"commitment\x18\x02 \x01(\fR\n" +
+	"commitment\x12\x14\n" +
+	"\x05proof\x18\x03 \x01(\fR\x05proof\x12%\n" +
+	"\x0eversioned_hash\x18\x04 \x01(\fR\rversionedHash\"<\n" +
+	"\x0eVerifyResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\"\n" +
+	"\fPayloadChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"\x96\x01\n" +
+	"\tSplitBlob\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x03R\x05index\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04blob\x18\x04 \x01(\fR\x04blob\x123\n" +
+	"\tartifacts\x18\x05 \x01(\v2\x15.blobpoc.v1.ArtifactsR\tartifacts2\xff\x01\n" +
+	"\vBlobService\x128\n" +
+	"\x06Commit\x12\x17.blobpoc.v1.BlobRequest\x1a\x15.blobpoc.v1.Artifacts\x127\n" +
+	"\x05Prove\x12\x17.blobpoc.v1.BlobRequest\x1a\x15.blobpoc.v1.Artifacts\x12?\n" +
+	"\x06Verify\x12\x19.blobpoc.v1.VerifyRequest\x1a\x1a.blobpoc.v1.VerifyResponse\x12<\n" +
+	"\x05Split\x12\x18.blobpoc.v1.PayloadChunk\x1a\x15.blobpoc.v1.SplitBlob(\x010\x01B\x19Z\x17kzg-blob-poc/pkg/blobpbb\x06proto3"
+
+var (
+	file_blob_proto_rawDescOnce sync.Once
+	file_blob_proto_rawDescData []byte
+)
+
+func file_blob_proto_rawDescGZIP() []byte {
+	file_blob_proto_rawDescOnce.Do(func() {
+		file_blob_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_blob_proto_rawDesc), len(file_blob_proto_rawDesc)))
+	})
+	return file_blob_proto_rawDescData
+}
+
+var file_blob_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_blob_proto_goTypes = []any{
+	(*BlobRequest)(nil),    // 0: blobpoc.v1.BlobRequest
+	(*Artifacts)(nil),      // 1: blobpoc.v1.Artifacts
+	(*VerifyRequest)(nil),  // 2: blobpoc.v1.VerifyRequest
+	(*VerifyResponse)(nil), // 3: blobpoc.v1.VerifyResponse
+	(*PayloadChunk)(nil),   // 4: blobpoc.v1.PayloadChunk
+	(*SplitBlob)(nil),      // 5: blobpoc.v1.SplitBlob
+}
+var file_blob_proto_depIdxs = []int32{
+	1, // 0: blobpoc.v1.SplitBlob.artifacts:type_name -> blobpoc.v1.Artifacts
+	0, // 1: blobpoc.v1.BlobService.Commit:input_type -> blobpoc.v1.BlobRequest
+	0, // 2: blobpoc.v1.BlobService.Prove:input_type -> blobpoc.v1.BlobRequest
+	2, // 3: blobpoc.v1.BlobService.Verify:input_type -> blobpoc.v1.VerifyRequest
+	4, // 4: blobpoc.v1.BlobService.Split:input_type -> blobpoc.v1.PayloadChunk
+	1, // 5: blobpoc.v1.BlobService.Commit:output_type -> blobpoc.v1.Artifacts
+	1, // 6: blobpoc.v1.BlobService.Prove:output_type -> blobpoc.v1.Artifacts
+	3, // 7: blobpoc.v1.BlobService.Verify:output_type -> blobpoc.v1.VerifyResponse
+	5, // 8: blobpoc.v1.BlobService.Split:output_type -> blobpoc.v1.SplitBlob
+	5, // [5:9] is the sub-list for method output_type
+	1, // [1:5] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_blob_proto_init() }
+func file_blob_proto_init() {
+	if File_blob_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_blob_proto_rawDesc), len(file_blob_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_blob_proto_goTypes,
+		DependencyIndexes: file_blob_proto_depIdxs,
+		MessageInfos:      file_blob_proto_msgTypes,
+	}.Build()
+	File_blob_proto = out.File
+	file_blob_proto_goTypes = nil
+	file_blob_proto_depIdxs = nil
+}
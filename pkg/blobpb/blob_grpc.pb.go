@@ -0,0 +1,250 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: blob.proto
+
+package blobpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BlobService_Commit_FullMethodName = "/blobpoc.v1.BlobService/Commit"
+	BlobService_Prove_FullMethodName  = "/blobpoc.v1.BlobService/Prove"
+	BlobService_Verify_FullMethodName = "/blobpoc.v1.BlobService/Verify"
+	BlobService_Split_FullMethodName  = "/blobpoc.v1.BlobService/Split"
+)
+
+// BlobServiceClient is the client API for BlobService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BlobService computes and checks EIP-4844 KZG artifacts.
+type BlobServiceClient interface {
+	// Commit returns the KZG commitment and versioned hash of a blob.
+	Commit(ctx context.Context, in *BlobRequest, opts ...grpc.CallOption) (*Artifacts, error)
+	// Prove returns the KZG commitment, proof and versioned hash of a blob.
+	Prove(ctx context.Context, in *BlobRequest, opts ...grpc.CallOption) (*Artifacts, error)
+	// Verify checks a KZG proof against a blob and commitment. A proof that
+	// does not verify is reported in the response, not as an RPC error.
+	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
+	// Split packs a payload of any size into blobs. The client streams the
+	// payload in chunks of any size; the server replies with each blob and
+	// its artifacts as soon as enough data has arrived to fill it, so the
+	// payload never has to be held in memory at once.
+	Split(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PayloadChunk, SplitBlob], error)
+}
+
+type blobServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBlobServiceClient(cc grpc.ClientConnInterface) BlobServiceClient {
+	return &blobServiceClient{cc}
+}
+
+func (c *blobServiceClient) Commit(ctx context.Context, in *BlobRequest, opts ...grpc.CallOption) (*Artifacts, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Artifacts)
+	err := c.cc.Invoke(ctx, BlobService_Commit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blobServiceClient) Prove(ctx context.Context, in *BlobRequest, opts ...grpc.CallOption) (*Artifacts, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Artifacts)
+	err := c.cc.Invoke(ctx, BlobService_Prove_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blobServiceClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyResponse)
+	err := c.cc.Invoke(ctx, BlobService_Verify_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blobServiceClient) Split(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PayloadChunk, SplitBlob], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BlobService_ServiceDesc.Streams[0], BlobService_Split_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PayloadChunk, SplitBlob]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BlobService_SplitClient = grpc.BidiStreamingClient[PayloadChunk, SplitBlob]
+
+// BlobServiceServer is the server API for BlobService service.
+// All implementations must embed UnimplementedBlobServiceServer
+// for forward compatibility.
+//
+// BlobService computes and checks EIP-4844 KZG artifacts.
+type BlobServiceServer interface {
+	// Commit returns the KZG commitment and versioned hash of a blob.
+	Commit(context.Context, *BlobRequest) (*Artifacts, error)
+	// Prove returns the KZG commitment, proof and versioned hash of a blob.
+	Prove(context.Context, *BlobRequest) (*Artifacts, error)
+	// Verify checks a KZG proof against a blob and commitment. A proof that
+	// does not verify is reported in the response, not as an RPC error.
+	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
+	// Split packs a payload of any size into blobs. The client streams the
+	// payload in chunks of any size; the server replies with each blob and
+	// its artifacts as soon as enough data has arrived to fill it, so the
+	// payload never has to be held in memory at once.
+	Split(grpc.BidiStreamingServer[PayloadChunk, SplitBlob]) error
+	mustEmbedUnimplementedBlobServiceServer()
+}
+
+// UnimplementedBlobServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBlobServiceServer struct{}
+
+func (UnimplementedBlobServiceServer) Commit(context.Context, *BlobRequest) (*Artifacts, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Commit not implemented")
+}
+func (UnimplementedBlobServiceServer) Prove(context.Context, *BlobRequest) (*Artifacts, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Prove not implemented")
+}
+func (UnimplementedBlobServiceServer) Verify(context.Context, *VerifyRequest) (*VerifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Verify not implemented")
+}
+func (UnimplementedBlobServiceServer) Split(grpc.BidiStreamingServer[PayloadChunk, SplitBlob]) error {
+	return status.Errorf(codes.Unimplemented, "method Split not implemented")
+}
+func (UnimplementedBlobServiceServer) mustEmbedUnimplementedBlobServiceServer() {}
+func (UnimplementedBlobServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeBlobServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BlobServiceServer will
+// result in compilation errors.
+type UnsafeBlobServiceServer interface {
+	mustEmbedUnimplementedBlobServiceServer()
+}
+
+func RegisterBlobServiceServer(s grpc.ServiceRegistrar, srv BlobServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBlobServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BlobService_ServiceDesc, srv)
+}
+
+func _BlobService_Commit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlobServiceServer).Commit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlobService_Commit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlobServiceServer).Commit(ctx, req.(*BlobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlobService_Prove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlobServiceServer).Prove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlobService_Prove_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlobServiceServer).Prove(ctx, req.(*BlobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlobService_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlobServiceServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlobService_Verify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlobServiceServer).Verify(ctx, req.(*VerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlobService_Split_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BlobServiceServer).Split(&grpc.GenericServerStream[PayloadChunk, SplitBlob]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BlobService_SplitServer = grpc.BidiStreamingServer[PayloadChunk, SplitBlob]
+
+// BlobService_ServiceDesc is the grpc.ServiceDesc for BlobService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BlobService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "blobpoc.v1.BlobService",
+	HandlerType: (*BlobServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Commit",
+			Handler:    _BlobService_Commit_Handler,
+		},
+		{
+			MethodName: "Prove",
+			Handler:    _BlobService_Prove_Handler,
+		},
+		{
+			MethodName: "Verify",
+			Handler:    _BlobService_Verify_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Split",
+			Handler:       _BlobService_Split_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "blob.proto",
+}
@@ -0,0 +1,5 @@
+// Package blobpb holds the generated protobuf and gRPC code for
+// proto/blob.proto.
+package blobpb
+
+//go:generate protoc -I ../../proto --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative blob.proto
@@ -0,0 +1,139 @@
+// Package cache stores the KZG artifacts of blobs on disk, addressed by the
+// SHA-256 of the blob contents, so blobs that were seen before are not
+// committed to and proven again.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/internal/atomicfile"
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/metrics"
+)
+
+// Cache is a directory of artifact entries. A nil *Cache is valid and
+// caches nothing, so callers can thread an optional cache through.
+//
+// Entries live in <dir>/<first two hex digits>/<sha256 hex>.json. They are
+// written atomically, so concurrent processes can share a directory. The
+// artifacts depend on the trusted setup, which the key does not cover, so
+// each setup needs a directory of its own.
+type Cache struct {
+	dir string
+}
+
+// DefaultDir returns <user cache dir>/blob-poc.
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "blob-poc"), nil
+}
+
+// Open returns the cache in dir, creating the directory if needed.
+func Open(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Key returns the hex SHA-256 of b, the address of its entry.
+func Key(b *kzg4844.Blob) string {
+	sum := sha256.Sum256(b[:])
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Get returns the cached artifacts of b. Missing, unreadable and corrupt
+// entries are all reported as a miss; a corrupt entry is overwritten by the
+// next Put.
+func (c *Cache) Get(b *kzg4844.Blob) (*blob.BlobArtifacts, bool) {
+	if c == nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.path(Key(b)))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			metrics.CacheErrors.Inc()
+		}
+		metrics.CacheMisses.Inc()
+		return nil, false
+	}
+	var a blob.BlobArtifacts
+	if err := json.Unmarshal(data, &a); err != nil || a.VersionedHash != blob.VersionedHash(a.Commitment) {
+		metrics.CacheErrors.Inc()
+		metrics.CacheMisses.Inc()
+		return nil, false
+	}
+	metrics.CacheHits.Inc()
+	return &a, true
+}
+
+// Put stores the artifacts of b. BlobHex is not stored.
+func (c *Cache) Put(b *kzg4844.Blob, a *blob.BlobArtifacts) error {
+	if c == nil {
+		return nil
+	}
+	entry := *a
+	entry.BlobHex = ""
+	data, err := json.Marshal(&entry)
+	if err != nil {
+		return err
+	}
+
+	path := c.path(Key(b))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return atomicfile.Write(path, data)
+}
+
+// Artifacts is blob.NewArtifacts backed by the cache: it returns the cached
+// artifacts of b, or computes and stores them. Failing to store an entry is
+// not an error, since the artifacts are still correct.
+func (c *Cache) Artifacts(b *kzg4844.Blob, includeBlob bool) (*blob.BlobArtifacts, error) {
+	a, ok := c.Get(b)
+	if !ok {
+		var err error
+		if a, err = blob.NewArtifacts(b, false); err != nil {
+			return nil, err
+		}
+		if err := c.Put(b, a); err != nil {
+			metrics.CacheErrors.Inc()
+		}
+	}
+	if includeBlob {
+		a.BlobHex = hexutil.Encode(b[:])
+	}
+	return a, nil
+}
+
+// CommitOnly is blob.CommitOnly backed by the cache: it returns the cached
+// artifacts of b without their proof, or computes the commitment. A miss is
+// not stored, since an entry also needs the proof.
+func (c *Cache) CommitOnly(b *kzg4844.Blob, includeBlob bool) (*blob.BlobArtifacts, error) {
+	a, ok := c.Get(b)
+	if !ok {
+		return blob.CommitOnly(b, includeBlob)
+	}
+	a.Proof = kzg4844.Proof{}
+	if includeBlob {
+		a.BlobHex = hexutil.Encode(b[:])
+	}
+	return a, nil
+}
@@ -0,0 +1,305 @@
+// Package calldata ABI-encodes contract calls for the data field of a blob
+// transaction, so that the transaction carrying blobs can also register
+// them with a contract, such as a rollup's batch inbox, atomically. The
+// arguments of a call may stand for the transaction's own versioned hashes,
+// which are only known once its blobs are packed.
+package calldata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Placeholders an argument can be given as, replaced when encoding for a
+// transaction.
+const (
+	// BlobHashes stands for the versioned hashes of the transaction's
+	// blobs, in order, as a bytes32[] argument.
+	BlobHashes = "@blobhashes"
+	// BlobHash followed by an index, such as @blobhash0, stands for one
+	// versioned hash of the transaction, as a bytes32 argument.
+	BlobHash = "@blobhash"
+	// BlobCount stands for the number of blobs of the transaction, as an
+	// integer argument.
+	BlobCount = "@blobcount"
+)
+
+// Call is a contract function call whose calldata depends on the blobs of
+// the transaction making it.
+type Call struct {
+	method abi.Method
+	args   []any
+	// blobs is the fewest blobs a transaction needs for the placeholders
+	// of args.
+	blobs int
+}
+
+// Parse returns the call of the function with the given signature, such as
+// "registerBlobs(bytes32[],uint64)", with args. Each argument is a string,
+// a json.Number, a bool or, for arrays and tuples, a []any of arguments;
+// ParseArg turns a command-line argument into one. Integers may be decimal
+// or 0x-prefixed hex, and bytes are hex.
+func Parse(signature string, args []any) (*Call, error) {
+	sel, err := abi.ParseSelector(strings.Join(strings.Fields(signature), ""))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal([]abi.SelectorMarshaling{sel})
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(strings.NewReader(string(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid function signature %q: %w", signature, err)
+	}
+	c := &Call{method: parsed.Methods[sel.Name], args: args}
+	if len(args) != len(c.method.Inputs) {
+		return nil, fmt.Errorf("%s takes %d arguments, got %d", c.method.Sig, len(c.method.Inputs), len(args))
+	}
+	c.blobs = minBlobs(args)
+	// Encode once with as few blobs as a transaction can have, so that a
+	// malformed argument is reported before any transaction is built.
+	if _, err := c.Encode(make([]common.Hash, max(1, c.blobs))); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Signature returns the canonical signature of the called function.
+func (c *Call) Signature() string { return c.method.Sig }
+
+// Selector returns the 4-byte selector of the called function.
+func (c *Call) Selector() []byte { return c.method.ID }
+
+// Encode returns the calldata of the call for a transaction carrying blobs
+// with the versioned hashes blobHashes: the function selector followed by
+// the ABI-encoded arguments, with the placeholders replaced.
+func (c *Call) Encode(blobHashes []common.Hash) ([]byte, error) {
+	if len(blobHashes) < c.blobs {
+		return nil, fmt.Errorf("the arguments refer to %d blobs, but the transaction has %d", c.blobs, len(blobHashes))
+	}
+	values := make([]any, len(c.args))
+	for i, arg := range c.args {
+		in := c.method.Inputs[i]
+		v, err := value(in.Type, arg, blobHashes)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d (%s): %w", i, in.Type, err)
+		}
+		values[i] = v.Interface()
+	}
+	packed, err := c.method.Inputs.Pack(values...)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, c.method.ID...), packed...), nil
+}
+
+// ParseArg returns the call argument written as s on a command line: a
+// JSON array, for array and tuple arguments, or s itself.
+func ParseArg(s string) (any, error) {
+	if !strings.HasPrefix(strings.TrimSpace(s), "[") {
+		return s, nil
+	}
+	d := json.NewDecoder(strings.NewReader(s))
+	d.UseNumber()
+	var v []any
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid array argument %s: %w", s, err)
+	}
+	return v, nil
+}
+
+// minBlobs returns how many blobs the placeholders among args refer to.
+func minBlobs(args []any) int {
+	n := 0
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case []any:
+			n = max(n, minBlobs(arg))
+		case string:
+			if i, ok := blobHashIndex(arg); ok {
+				n = max(n, i+1)
+			}
+		}
+	}
+	return n
+}
+
+// blobHashIndex returns the index of a BlobHash placeholder.
+func blobHashIndex(s string) (int, bool) {
+	rest, ok := strings.CutPrefix(s, BlobHash)
+	if !ok || rest == "" || rest[0] == 's' {
+		return 0, false
+	}
+	i, err := strconv.Atoi(rest)
+	return i, err == nil && i >= 0
+}
+
+// value converts arg to the Go type that abi packs as t.
+func value(t abi.Type, arg any, blobHashes []common.Hash) (reflect.Value, error) {
+	if s, ok := arg.(string); ok && strings.HasPrefix(s, "@") {
+		return placeholder(t, s, blobHashes)
+	}
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		return integer(t, arg)
+	case abi.BoolTy:
+		switch arg := arg.(type) {
+		case bool:
+			return reflect.ValueOf(arg), nil
+		case string:
+			b, err := strconv.ParseBool(arg)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("invalid bool %q", arg)
+			}
+			return reflect.ValueOf(b), nil
+		}
+	case abi.StringTy:
+		switch arg := arg.(type) {
+		case string:
+			return reflect.ValueOf(arg), nil
+		case json.Number:
+			return reflect.ValueOf(arg.String()), nil
+		}
+	case abi.AddressTy:
+		if s, ok := arg.(string); ok {
+			if !common.IsHexAddress(s) {
+				return reflect.Value{}, fmt.Errorf("invalid address %q", s)
+			}
+			return reflect.ValueOf(common.HexToAddress(s)), nil
+		}
+	case abi.BytesTy, abi.FixedBytesTy:
+		s, ok := arg.(string)
+		if !ok {
+			break
+		}
+		b, err := hexutil.Decode(s)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid bytes %q: %w", s, err)
+		}
+		if t.T == abi.BytesTy {
+			return reflect.ValueOf(b), nil
+		}
+		if len(b) != t.Size {
+			return reflect.Value{}, fmt.Errorf("have %d bytes, want %d", len(b), t.Size)
+		}
+		v := reflect.New(t.GetType()).Elem()
+		reflect.Copy(v, reflect.ValueOf(b))
+		return v, nil
+	case abi.SliceTy, abi.ArrayTy:
+		elems, ok := arg.([]any)
+		if !ok {
+			break
+		}
+		var v reflect.Value
+		if t.T == abi.SliceTy {
+			v = reflect.MakeSlice(t.GetType(), len(elems), len(elems))
+		} else if len(elems) != t.Size {
+			return reflect.Value{}, fmt.Errorf("have %d elements, want %d", len(elems), t.Size)
+		} else {
+			v = reflect.New(t.GetType()).Elem()
+		}
+		for i, elem := range elems {
+			e, err := value(*t.Elem, elem, blobHashes)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("element %d: %w", i, err)
+			}
+			v.Index(i).Set(e)
+		}
+		return v, nil
+	case abi.TupleTy:
+		fields, ok := arg.([]any)
+		if !ok {
+			break
+		}
+		if len(fields) != len(t.TupleElems) {
+			return reflect.Value{}, fmt.Errorf("have %d fields, want %d", len(fields), len(t.TupleElems))
+		}
+		v := reflect.New(t.GetType()).Elem()
+		for i, field := range fields {
+			f, err := value(*t.TupleElems[i], field, blobHashes)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("field %d: %w", i, err)
+			}
+			v.Field(i).Set(f)
+		}
+		return v, nil
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported type %s", t)
+	}
+	return reflect.Value{}, fmt.Errorf("invalid value %v", arg)
+}
+
+// placeholder returns the value a placeholder stands for, as type t.
+func placeholder(t abi.Type, s string, blobHashes []common.Hash) (reflect.Value, error) {
+	switch {
+	case s == BlobHashes:
+		hashes := make([]any, len(blobHashes))
+		for i, h := range blobHashes {
+			hashes[i] = h.Hex()
+		}
+		if t.T != abi.SliceTy && t.T != abi.ArrayTy {
+			return reflect.Value{}, fmt.Errorf("%s needs a bytes32[] argument", BlobHashes)
+		}
+		return value(t, hashes, blobHashes)
+	case s == BlobCount:
+		if t.T != abi.IntTy && t.T != abi.UintTy {
+			return reflect.Value{}, fmt.Errorf("%s needs an integer argument", BlobCount)
+		}
+		return integer(t, json.Number(strconv.Itoa(len(blobHashes))))
+	}
+	i, ok := blobHashIndex(s)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("unknown placeholder %q", s)
+	}
+	if t.T != abi.FixedBytesTy || t.Size != common.HashLength {
+		return reflect.Value{}, fmt.Errorf("%s needs a bytes32 argument", s)
+	}
+	if i >= len(blobHashes) {
+		return reflect.Value{}, fmt.Errorf("%s: the transaction has %d blobs", s, len(blobHashes))
+	}
+	return value(t, blobHashes[i].Hex(), blobHashes)
+}
+
+// integer converts arg, a decimal or 0x-prefixed hex string or number, to
+// the Go type abi packs as the integer type t, checking its range.
+func integer(t abi.Type, arg any) (reflect.Value, error) {
+	var s string
+	switch arg := arg.(type) {
+	case string:
+		s = arg
+	case json.Number:
+		s = arg.String()
+	default:
+		return reflect.Value{}, fmt.Errorf("invalid integer %v", arg)
+	}
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("invalid integer %q", s)
+	}
+	lo, hi := big.NewInt(0), new(big.Int).Lsh(big.NewInt(1), uint(t.Size))
+	if t.T == abi.IntTy {
+		hi.Rsh(hi, 1)
+		lo.Neg(hi)
+	}
+	if n.Cmp(lo) < 0 || n.Cmp(hi) >= 0 {
+		return reflect.Value{}, errors.New(s + " is out of range")
+	}
+	typ := t.GetType()
+	switch typ.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.ValueOf(n.Int64()).Convert(typ), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return reflect.ValueOf(n.Uint64()).Convert(typ), nil
+	}
+	return reflect.ValueOf(n), nil
+}
@@ -0,0 +1,212 @@
+// Package chain is the registry of the networks blob-poc knows: their chain
+// IDs, blob throughput limits and public endpoints, so fee math and blob
+// packing use the values of the chain being targeted.
+package chain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// BlobParams is the blob throughput schedule of a chain.
+type BlobParams struct {
+	// Target is the number of blobs per block the blob base fee steers
+	// towards.
+	Target int `json:"target_blobs_per_block"`
+	// Max is the most blobs a block can carry.
+	Max int `json:"max_blobs_per_block"`
+	// UpdateFraction controls how fast the blob base fee moves with excess
+	// blob gas: the larger it is, the slower.
+	UpdateFraction uint64 `json:"base_fee_update_fraction"`
+}
+
+// TargetBlobGas returns the blob gas of a block at the target.
+func (b BlobParams) TargetBlobGas() uint64 {
+	return uint64(b.Target) * params.BlobTxBlobGasPerBlob
+}
+
+// MaxBlobGas returns the blob gas of a full block.
+func (b BlobParams) MaxBlobGas() uint64 {
+	return uint64(b.Max) * params.BlobTxBlobGasPerBlob
+}
+
+// Blob schedules of the forks that changed them.
+var (
+	// CancunBlobs is the EIP-4844 schedule.
+	CancunBlobs = BlobParams{Target: 3, Max: 6, UpdateFraction: 3338477}
+	// PragueBlobs is the EIP-7691 schedule.
+	PragueBlobs = BlobParams{Target: 6, Max: 9, UpdateFraction: 5007716}
+)
+
+// Names of the forks with a blob schedule.
+const (
+	Cancun = "cancun"
+	Prague = "prague"
+)
+
+// Fork is a fork that set a new blob schedule.
+type Fork struct {
+	Name string `json:"name"`
+	// Time is the timestamp of the first block of the fork.
+	Time  uint64     `json:"time"`
+	Blobs BlobParams `json:"blobs"`
+}
+
+// LookupFork returns the fork named name, ignoring case, without an
+// activation time.
+func LookupFork(name string) (Fork, error) {
+	switch strings.ToLower(name) {
+	case Cancun:
+		return Fork{Name: Cancun, Blobs: CancunBlobs}, nil
+	case Prague:
+		return Fork{Name: Prague, Blobs: PragueBlobs}, nil
+	default:
+		return Fork{}, fmt.Errorf("unknown fork %q (want %s or %s)", name, Cancun, Prague)
+	}
+}
+
+// DetectFork tells the fork of a block from the fields its header gained:
+// the execution requests hash of Prague and the blob gas fields of Cancun.
+// It returns false for a block before Cancun. Devnets schedule forks
+// freely, so this is how their schedule is found without a fork list.
+func DetectFork(h *types.Header) (Fork, bool) {
+	switch {
+	case h.RequestsHash != nil:
+		return Fork{Name: Prague, Blobs: PragueBlobs}, true
+	case h.ExcessBlobGas != nil:
+		return Fork{Name: Cancun, Blobs: CancunBlobs}, true
+	default:
+		return Fork{}, false
+	}
+}
+
+// Profile describes a network.
+type Profile struct {
+	Name string `json:"name"`
+	// ChainID is the EIP-155 chain ID. Zero accepts whatever chain the
+	// node serves, for devnets that pick their own.
+	ChainID uint64 `json:"chain_id"`
+	// Forks are the forks that set a blob schedule, in activation order.
+	// None means the schedule is detected from block headers.
+	Forks []Fork `json:"forks,omitempty"`
+	// RPCURL and BeaconURL are public endpoints to use when none is
+	// configured.
+	RPCURL    string `json:"rpc_url,omitempty"`
+	BeaconURL string `json:"beacon_url,omitempty"`
+	// BlobscanURL is the network's Blobscan API, which serves blobs beacon
+	// nodes have pruned.
+	BlobscanURL string `json:"blobscan_url,omitempty"`
+}
+
+// Profiles lists the known networks.
+var Profiles = []*Profile{
+	{
+		Name:        "mainnet",
+		ChainID:     1,
+		Forks:       forks(1710338135, 1746612311),
+		RPCURL:      "https://ethereum-rpc.publicnode.com",
+		BeaconURL:   "https://ethereum-beacon-api.publicnode.com",
+		BlobscanURL: "https://api.blobscan.com",
+	},
+	{
+		Name:        "sepolia",
+		ChainID:     11155111,
+		Forks:       forks(1706655072, 1741159776),
+		RPCURL:      "https://ethereum-sepolia-rpc.publicnode.com",
+		BeaconURL:   "https://ethereum-sepolia-beacon-api.publicnode.com",
+		BlobscanURL: "https://api.sepolia.blobscan.com",
+	},
+	{
+		Name:        "holesky",
+		ChainID:     17000,
+		Forks:       forks(1707305664, 1740434112),
+		RPCURL:      "https://ethereum-holesky-rpc.publicnode.com",
+		BeaconURL:   "https://ethereum-holesky-beacon-api.publicnode.com",
+		BlobscanURL: "https://api.holesky.blobscan.com",
+	},
+	{
+		Name:        "hoodi",
+		ChainID:     560048,
+		Forks:       forks(0, 1742999832),
+		RPCURL:      "https://ethereum-hoodi-rpc.publicnode.com",
+		BeaconURL:   "https://ethereum-hoodi-beacon-api.publicnode.com",
+		BlobscanURL: "https://api.hoodi.blobscan.com",
+	},
+	{
+		// A local devnet, such as one started with Kurtosis or geth --dev,
+		// on the default ports of geth and Lighthouse.
+		Name:      "devnet",
+		RPCURL:    "http://127.0.0.1:8545",
+		BeaconURL: "http://127.0.0.1:5052",
+	},
+}
+
+// forks returns the Cancun and Prague forks activated at the given times.
+func forks(cancun, prague uint64) []Fork {
+	return []Fork{
+		{Name: Cancun, Time: cancun, Blobs: CancunBlobs},
+		{Name: Prague, Time: prague, Blobs: PragueBlobs},
+	}
+}
+
+// ForkAt returns the fork in force at time, or false before Cancun or when
+// p has no fork list.
+func (p *Profile) ForkAt(time uint64) (Fork, bool) {
+	for i := len(p.Forks) - 1; i >= 0; i-- {
+		if p.Forks[i].Time <= time {
+			return p.Forks[i], true
+		}
+	}
+	return Fork{}, false
+}
+
+// Latest returns the newest fork of p, which is the one in force on a live
+// network. Profiles without a fork list are assumed to run
+// Prague.
+func (p *Profile) Latest() Fork {
+	if len(p.Forks) == 0 {
+		return Fork{Name: Prague, Blobs: PragueBlobs}
+	}
+	return p.Forks[len(p.Forks)-1]
+}
+
+// Lookup returns the profile named name, ignoring case.
+func Lookup(name string) (*Profile, error) {
+	for _, p := range Profiles {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown network %q (want %s)", name, strings.Join(Names(), ", "))
+}
+
+// ByChainID returns the profile of chain id, or nil if none is known.
+func ByChainID(id uint64) *Profile {
+	for _, p := range Profiles {
+		if p.ChainID != 0 && p.ChainID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the known networks.
+func Names() []string {
+	names := make([]string, len(Profiles))
+	for i, p := range Profiles {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// CheckChainID returns an error unless a node reporting chain id serves the
+// network.
+func (p *Profile) CheckChainID(id uint64) error {
+	if p.ChainID != 0 && p.ChainID != id {
+		return fmt.Errorf("node is on chain %d, not %s (chain %d)", id, p.Name, p.ChainID)
+	}
+	return nil
+}
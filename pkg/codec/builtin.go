@@ -0,0 +1,123 @@
+package codec
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/manifest"
+	"kzg-blob-poc/pkg/opstack"
+)
+
+// Names of the built-in codecs. The framed codec is also registered with
+// each compression, as "framed+zstd" and so on.
+const (
+	Raw     = "raw"
+	Framed  = "framed"
+	OPStack = "op-stack"
+)
+
+func init() {
+	Register(raw{})
+	for _, c := range []blob.Compression{blob.CompressionNone, blob.CompressionZlib, blob.CompressionBrotli, blob.CompressionZstd} {
+		Register(framed{c})
+	}
+	Register(opStack{})
+}
+
+// raw packs the payload into as many blobs as it needs, 31 bytes per field
+// element, as split does: the chunked layout of a manifest.
+type raw struct{}
+
+func (raw) Name() string { return Raw }
+
+func (raw) Encode(payload []byte) ([]kzg4844.Blob, *manifest.Manifest, error) {
+	blobs, err := blob.SplitIntoBlobs(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := &manifest.Manifest{
+		Version:  manifest.Version,
+		Encoding: manifest.NewEncoding(manifest.LayoutChunked, ""),
+		Payload:  manifest.NewPayload(payload),
+	}
+	for _, c := range blob.ChunkLayout(len(payload)) {
+		m.Blobs = append(m.Blobs, manifest.Blob{Chunk: c, ChunkHash: sha256.Sum256(payload[c.Offset : c.Offset+c.Size])})
+	}
+	m.ChunkRoot = manifest.ChunkRoot(m.Blobs)
+	return blobs, m, nil
+}
+
+func (raw) Decode(blobs []kzg4844.Blob, m *manifest.Manifest) ([]byte, error) {
+	return blob.JoinBlobs(blobs, entries(m))
+}
+
+// framed stores the payload, compressed with c, in a single blob behind a
+// frame header: the framed layout of a manifest, as encode writes.
+type framed struct {
+	c blob.Compression
+}
+
+func (f framed) Name() string {
+	if f.c == blob.CompressionNone {
+		return Framed
+	}
+	return Framed + "+" + f.c.String()
+}
+
+func (f framed) Encode(payload []byte) ([]kzg4844.Blob, *manifest.Manifest, error) {
+	b, err := blob.EncodeFramedCompressed(payload, f.c)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := &manifest.Manifest{
+		Version:  manifest.Version,
+		Encoding: manifest.NewEncoding(manifest.LayoutFramed, f.c.String()),
+		Payload:  manifest.NewPayload(payload),
+		Blobs:    []manifest.Blob{{Chunk: blob.Chunk{Size: len(payload)}}},
+	}
+	return []kzg4844.Blob{b}, m, nil
+}
+
+func (framed) Decode(blobs []kzg4844.Blob, _ *manifest.Manifest) ([]byte, error) {
+	if len(blobs) != 1 {
+		return nil, fmt.Errorf("the framed codec stores one blob, have %d", len(blobs))
+	}
+	return blob.DecodeFramed(blobs[0])
+}
+
+// opStack cuts the payload into parts of at most opstack.MaxBlobDataSize
+// bytes and encodes each as an OP Stack batcher does.
+type opStack struct{}
+
+func (opStack) Name() string { return OPStack }
+
+func (opStack) Encode(payload []byte) ([]kzg4844.Blob, *manifest.Manifest, error) {
+	cs := layout(len(payload), opstack.MaxBlobDataSize)
+	blobs := make([]kzg4844.Blob, len(cs))
+	for i, c := range cs {
+		var err error
+		if blobs[i], err = opstack.EncodeBlob(payload[c.Offset : c.Offset+c.Size]); err != nil {
+			return nil, nil, fmt.Errorf("chunk %d: %w", i, err)
+		}
+	}
+	m, err := NewManifest(OPStack, payload, len(blobs), cs)
+	return blobs, m, err
+}
+
+func (opStack) Decode(blobs []kzg4844.Blob, m *manifest.Manifest) ([]byte, error) {
+	var payload []byte
+	for i := range blobs {
+		part, err := opstack.DecodeBlob(&blobs[i])
+		if err != nil {
+			return nil, fmt.Errorf("blob %d: %w", i, err)
+		}
+		if size := m.Blobs[i].Size; len(part) != size {
+			return nil, fmt.Errorf("blob %d holds %d bytes, manifest has %d", i, len(part), size)
+		}
+		payload = append(payload, part...)
+	}
+	return payload, nil
+}
@@ -0,0 +1,184 @@
+// Package codec turns payloads into blobs and back in a named format. The
+// blob-poc layouts and the OP Stack blob encoding are built in; a rollup
+// with its own format registers a Codec, and split, publish and decode
+// --manifest then commit to, send and recover its blobs like any other.
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/manifest"
+)
+
+// Codec encodes a payload into blobs and decodes it back.
+type Codec interface {
+	// Name is the name the codec is registered and selected by.
+	Name() string
+	// Encode encodes payload into blobs. The manifest describes the
+	// encoding, the payload and each blob's chunk of it; the blobs'
+	// artifacts are left for the caller to compute, such as with Commit.
+	Encode(payload []byte) ([]kzg4844.Blob, *manifest.Manifest, error)
+	// Decode recovers the payload from blobs, given in the order of m, the
+	// manifest Encode returned.
+	Decode(blobs []kzg4844.Blob, m *manifest.Manifest) ([]byte, error)
+}
+
+var (
+	mu       sync.RWMutex
+	registry = make(map[string]Codec)
+)
+
+// Register makes c available by its name. It panics if c has no name or a
+// codec is already registered under it, as registration happens at init.
+func Register(c Codec) {
+	mu.Lock()
+	defer mu.Unlock()
+	name := c.Name()
+	if name == "" {
+		panic("codec: Register of a codec without a name")
+	}
+	if _, ok := registry[name]; ok {
+		panic("codec: Register called twice for " + name)
+	}
+	registry[name] = c
+}
+
+// Lookup returns the codec registered as name.
+func Lookup(name string) (Codec, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	c, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown codec %q (want one of %s)", name, strings.Join(namesLocked(), ", "))
+	}
+	return c, nil
+}
+
+// Names returns the names of the registered codecs, sorted.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return namesLocked()
+}
+
+func namesLocked() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForManifest returns the codec that decodes the payload of m: Raw for the
+// chunked layout, the framed codec of its compression, or the codec a
+// codec-layout manifest names.
+func ForManifest(m *manifest.Manifest) (Codec, error) {
+	switch e := m.Encoding; e.Layout {
+	case manifest.LayoutChunked:
+		return Lookup(Raw)
+	case manifest.LayoutFramed:
+		if e.Compression == "" {
+			return Lookup(Framed)
+		}
+		return Lookup(Framed + "+" + e.Compression)
+	case manifest.LayoutCodec:
+		return Lookup(e.Codec)
+	default:
+		return nil, fmt.Errorf("unknown layout %q", e.Layout)
+	}
+}
+
+// Decode recovers the payload of m from blobs, given in manifest order,
+// with the codec ForManifest returns, and checks its size and SHA-256
+// against m. The error wraps manifest.ErrPayloadMismatch if they differ.
+func Decode(m *manifest.Manifest, blobs []kzg4844.Blob) ([]byte, error) {
+	if len(blobs) != len(m.Blobs) {
+		return nil, fmt.Errorf("have %d blobs, manifest lists %d", len(blobs), len(m.Blobs))
+	}
+	c, err := ForManifest(m)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := c.Decode(blobs, m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload with the %s codec: %w", c.Name(), err)
+	}
+	if err := m.CheckPayload(manifest.NewPayload(payload)); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// Commit computes the commitment, versioned hash and, with proofs, the
+// proof of every blob into the entries of m.
+func Commit(m *manifest.Manifest, blobs []kzg4844.Blob, proofs bool) error {
+	if len(blobs) != len(m.Blobs) {
+		return fmt.Errorf("have %d blobs, manifest lists %d", len(blobs), len(m.Blobs))
+	}
+	artifacts := blob.NewArtifacts
+	if !proofs {
+		artifacts = blob.CommitOnly
+	}
+	for i := range blobs {
+		a, err := artifacts(&blobs[i], false)
+		if err != nil {
+			return fmt.Errorf("blob %d: %w", i, err)
+		}
+		m.Blobs[i].Commitment, m.Blobs[i].Proof, m.Blobs[i].VersionedHash = a.Commitment, a.Proof, a.VersionedHash
+	}
+	return nil
+}
+
+// NewManifest returns the manifest of a payload stored in the given chunks
+// by the codec named name, as a Codec outside this package returns from
+// Encode. The chunks may be nil if the codec does not keep the payload in
+// contiguous parts; the manifest then has one entry per blob for the
+// artifacts.
+func NewManifest(name string, payload []byte, blobs int, chunks []blob.Chunk) (*manifest.Manifest, error) {
+	if chunks != nil && len(chunks) != blobs {
+		return nil, fmt.Errorf("have %d blobs but %d chunks", blobs, len(chunks))
+	}
+	if blobs == 0 {
+		return nil, errors.New("a payload needs at least one blob")
+	}
+	m := &manifest.Manifest{
+		Version:  manifest.Version,
+		Encoding: manifest.NewEncoding(manifest.LayoutCodec, name),
+		Payload:  manifest.NewPayload(payload),
+		Blobs:    make([]manifest.Blob, blobs),
+	}
+	for i := range m.Blobs {
+		m.Blobs[i].Chunk = blob.Chunk{Index: i}
+		if chunks != nil {
+			m.Blobs[i].Chunk = chunks[i]
+		}
+	}
+	return m, nil
+}
+
+// layout returns the chunks of a payload of the given size cut into parts
+// of at most limit bytes. An empty payload still has one empty chunk.
+func layout(size, limit int) []blob.Chunk {
+	cs := []blob.Chunk{}
+	for offset := 0; offset < size || len(cs) == 0; offset += limit {
+		cs = append(cs, blob.Chunk{Index: len(cs), Offset: offset, Size: min(limit, size-offset)})
+	}
+	return cs
+}
+
+// entries returns the chunks of the blob entries of m.
+func entries(m *manifest.Manifest) []blob.Chunk {
+	cs := make([]blob.Chunk, len(m.Blobs))
+	for i, b := range m.Blobs {
+		cs[i] = b.Chunk
+	}
+	return cs
+}
@@ -0,0 +1,249 @@
+// Package config loads blob-poc defaults from a YAML file and BLOBPOC_*
+// environment variables. Command-line flags take precedence over both.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the config file looked up in the working directory.
+const FileName = "blob-poc.yaml"
+
+// Backend names accepted in Config.Backend.
+const (
+	BackendGoKZG = "gokzg"
+	BackendCKZG  = "ckzg"
+)
+
+// Config holds the defaults shared by the commands. Empty fields leave the
+// built-in default in place.
+type Config struct {
+	// RPCURL is the execution client JSON-RPC endpoint.
+	RPCURL string `yaml:"rpc_url"`
+	// BeaconURL is the beacon node API endpoint.
+	BeaconURL string `yaml:"beacon_url"`
+	// KeyFile is the file holding the hex-encoded signing key.
+	KeyFile string `yaml:"key_file"`
+	// Keystore is a geth encrypted keystore file holding the signing key.
+	Keystore string `yaml:"keystore"`
+	// PasswordFile holds the keystore password or mnemonic passphrase.
+	PasswordFile string `yaml:"password_file"`
+	// MnemonicFile holds a BIP-39 mnemonic to derive the signing key from.
+	MnemonicFile string `yaml:"mnemonic_file"`
+	// HDPath is the derivation path used with MnemonicFile.
+	HDPath string `yaml:"hd_path"`
+	// SignerURL is a remote signer to sign with instead of a local key.
+	SignerURL string `yaml:"signer_url"`
+	// SignerType is the remote signer API: clef (default) or web3signer.
+	SignerType string `yaml:"signer_type"`
+	// SignerAccount is the account to sign with at the remote signer.
+	SignerAccount string `yaml:"signer_account"`
+	// OutputDir is the directory commands write multiple files to.
+	OutputDir string `yaml:"output_dir"`
+	// CacheDir is the artifact cache directory. Empty disables the cache.
+	CacheDir string `yaml:"cache_dir"`
+	// Backend selects the KZG library: gokzg (default) or ckzg.
+	Backend string `yaml:"backend"`
+	// LogLevel is the minimum level logged: debug, info (default), warn or
+	// error.
+	LogLevel string `yaml:"log_level"`
+	// LogFormat is the log format: text (default) or json.
+	LogFormat string `yaml:"log_format"`
+	// RequestTimeout bounds each attempt of an HTTP request to an RPC or
+	// beacon endpoint, as a Go duration.
+	RequestTimeout string `yaml:"request_timeout"`
+	// MaxRetries is how often a failed request is retried.
+	MaxRetries string `yaml:"max_retries"`
+	// RetryBackoff is the delay before the first retry, as a Go duration.
+	// It doubles with each further retry.
+	RetryBackoff string `yaml:"retry_backoff"`
+	// BlobTimeout bounds the KZG work on each blob in batch and serve, as
+	// a Go duration. Empty or zero sets no bound.
+	BlobTimeout string `yaml:"blob_timeout"`
+	// APIKeys are the keys serve accepts. With neither APIKeys nor
+	// JWTSecretFile set, serve accepts every request.
+	APIKeys []APIKey `yaml:"api_keys"`
+	// JWTSecretFile holds the hex HMAC-SHA256 secret of the JWTs serve
+	// accepts, in the format of an execution client's jwtsecret file.
+	JWTSecretFile string `yaml:"jwt_secret_file"`
+	// RateLimit is the requests per second serve allows each API key or
+	// JWT subject without a limit of its own. Empty or zero sets no limit.
+	RateLimit string `yaml:"rate_limit"`
+	// RateBurst is how many requests beyond RateLimit a key may make at
+	// once.
+	RateBurst string `yaml:"rate_burst"`
+	// ArchiveURL is the blob archive: an s3:// or ipfs:// URL or a
+	// directory.
+	ArchiveURL string `yaml:"archive_url"`
+	// BlobFallback lists, comma-separated, where fetch looks for blobs
+	// beacon nodes have pruned: blobscan, a Blobscan API URL or an archive
+	// URL.
+	BlobFallback string `yaml:"blob_fallback"`
+	// DBPath is the SQLite database every processed blob is recorded in.
+	// Empty records nothing.
+	DBPath string `yaml:"db_path"`
+	// Network is the target network, such as mainnet or sepolia. It sets
+	// the chain ID, blob limits and default endpoints.
+	Network string `yaml:"network"`
+	// InputFormat is the format of blob, payload and sidecar files read:
+	// raw, hex or base64. Empty reads raw bytes and detects blob files.
+	InputFormat string `yaml:"input_format"`
+	// OutputFormat is the format of blob, payload and sidecar files
+	// written: binary (default), hex or base64.
+	OutputFormat string `yaml:"output_format"`
+	// TrustedSetup is a KZG trusted setup file to use instead of the
+	// embedded ceremony output, in JSON or c-kzg-4844 text format.
+	TrustedSetup string `yaml:"trusted_setup"`
+	// TrustedSetupHash is the setup hash TrustedSetup must have. Empty
+	// requires the hash of the ceremony output.
+	TrustedSetupHash string `yaml:"trusted_setup_hash"`
+}
+
+// APIKey is a key serve accepts, sent as a bearer token or in an X-API-Key
+// header.
+type APIKey struct {
+	// Name identifies the key in logs and errors.
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+	// RateLimit is the requests per second allowed with the key, or zero
+	// for Config.RateLimit.
+	RateLimit float64 `yaml:"rate_limit"`
+	// Burst is how many requests beyond RateLimit the key may make at
+	// once, or zero for Config.RateBurst.
+	Burst int `yaml:"burst"`
+}
+
+// env maps each environment variable to the field it sets.
+func (c *Config) env() map[string]*string {
+	return map[string]*string{
+		"BLOBPOC_RPC_URL":            &c.RPCURL,
+		"BLOBPOC_BEACON_URL":         &c.BeaconURL,
+		"BLOBPOC_KEY_FILE":           &c.KeyFile,
+		"BLOBPOC_KEYSTORE":           &c.Keystore,
+		"BLOBPOC_PASSWORD_FILE":      &c.PasswordFile,
+		"BLOBPOC_MNEMONIC_FILE":      &c.MnemonicFile,
+		"BLOBPOC_HD_PATH":            &c.HDPath,
+		"BLOBPOC_SIGNER_URL":         &c.SignerURL,
+		"BLOBPOC_SIGNER_TYPE":        &c.SignerType,
+		"BLOBPOC_SIGNER_ACCOUNT":     &c.SignerAccount,
+		"BLOBPOC_OUTPUT_DIR":         &c.OutputDir,
+		"BLOBPOC_CACHE_DIR":          &c.CacheDir,
+		"BLOBPOC_BACKEND":            &c.Backend,
+		"BLOBPOC_LOG_LEVEL":          &c.LogLevel,
+		"BLOBPOC_LOG_FORMAT":         &c.LogFormat,
+		"BLOBPOC_REQUEST_TIMEOUT":    &c.RequestTimeout,
+		"BLOBPOC_MAX_RETRIES":        &c.MaxRetries,
+		"BLOBPOC_RETRY_BACKOFF":      &c.RetryBackoff,
+		"BLOBPOC_BLOB_TIMEOUT":       &c.BlobTimeout,
+		"BLOBPOC_JWT_SECRET_FILE":    &c.JWTSecretFile,
+		"BLOBPOC_RATE_LIMIT":         &c.RateLimit,
+		"BLOBPOC_RATE_BURST":         &c.RateBurst,
+		"BLOBPOC_ARCHIVE_URL":        &c.ArchiveURL,
+		"BLOBPOC_BLOB_FALLBACK":      &c.BlobFallback,
+		"BLOBPOC_DB_PATH":            &c.DBPath,
+		"BLOBPOC_NETWORK":            &c.Network,
+		"BLOBPOC_INPUT_FORMAT":       &c.InputFormat,
+		"BLOBPOC_OUTPUT_FORMAT":      &c.OutputFormat,
+		"BLOBPOC_TRUSTED_SETUP":      &c.TrustedSetup,
+		"BLOBPOC_TRUSTED_SETUP_HASH": &c.TrustedSetupHash,
+	}
+}
+
+// Load reads the config file, if any, and applies the environment on top.
+// The file is $BLOBPOC_CONFIG when set, which must then exist, or else the
+// first of ./blob-poc.yaml and <user config dir>/blob-poc/config.yaml that
+// exists.
+func Load() (*Config, error) {
+	c := new(Config)
+	path, err := findFile()
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		if err := c.readFile(path); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	for name, field := range c.env() {
+		if v, ok := os.LookupEnv(name); ok {
+			*field = v
+		}
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func findFile() (string, error) {
+	if path := os.Getenv("BLOBPOC_CONFIG"); path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("BLOBPOC_CONFIG: %w", err)
+		}
+		return path, nil
+	}
+	candidates := []string{FileName}
+	if dir, err := os.UserConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(dir, "blob-poc", "config.yaml"))
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+func (c *Config) validate() error {
+	switch c.Backend {
+	case "", BackendGoKZG, BackendCKZG:
+	default:
+		return fmt.Errorf("unknown backend %q (want %s or %s)", c.Backend, BackendGoKZG, BackendCKZG)
+	}
+	for i, k := range c.APIKeys {
+		if k.Key == "" {
+			return fmt.Errorf("api_keys[%d] (%s): empty key", i, k.Name)
+		}
+		if k.RateLimit < 0 || k.Burst < 0 {
+			return fmt.Errorf("api_keys[%d] (%s): negative rate limit", i, k.Name)
+		}
+	}
+	if c.RateLimit != "" {
+		if r, err := strconv.ParseFloat(c.RateLimit, 64); err != nil || r < 0 {
+			return fmt.Errorf("invalid rate limit %q: want requests per second", c.RateLimit)
+		}
+	}
+	if c.RateBurst != "" {
+		if n, err := strconv.Atoi(c.RateBurst); err != nil || n < 0 {
+			return fmt.Errorf("invalid rate burst %q: want a number of requests", c.RateBurst)
+		}
+	}
+	if c.BlobTimeout != "" {
+		if _, err := time.ParseDuration(c.BlobTimeout); err != nil {
+			return fmt.Errorf("invalid blob timeout %q: %w", c.BlobTimeout, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,341 @@
+// Package db keeps a local SQLite index of every blob blob-poc processes:
+// its KZG artifacts, the payload it carries and the transactions and blocks
+// it was included in, so a payload can be traced to its transaction long
+// after it was posted.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	_ "modernc.org/sqlite"
+)
+
+// ErrNotFound means no blob in the index matches.
+var ErrNotFound = errors.New("not found in database")
+
+// schema creates the tables. Hashes, commitments and proofs are 0x-prefixed
+// hex and times RFC 3339 in UTC, so the file reads well in the sqlite3
+// shell.
+const schema = `
+CREATE TABLE IF NOT EXISTS blobs (
+	versioned_hash TEXT PRIMARY KEY,
+	commitment     TEXT NOT NULL,
+	proof          TEXT NOT NULL,
+	payload_hash   TEXT,
+	source         TEXT NOT NULL,
+	file           TEXT,
+	created_at     TEXT NOT NULL,
+	updated_at     TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS blobs_payload_hash ON blobs (payload_hash);
+CREATE TABLE IF NOT EXISTS inclusions (
+	versioned_hash TEXT NOT NULL REFERENCES blobs (versioned_hash),
+	tx_hash        TEXT NOT NULL,
+	block_number   INTEGER,
+	recorded_at    TEXT NOT NULL,
+	PRIMARY KEY (versioned_hash, tx_hash)
+);
+CREATE INDEX IF NOT EXISTS inclusions_tx_hash ON inclusions (tx_hash);
+`
+
+// DB is the blob index. A nil *DB is valid and records nothing, so callers
+// can thread an optional index through.
+type DB struct {
+	db *sql.DB
+}
+
+// Open opens the index at path, creating the file and its tables if needed.
+func Open(path string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+	// WAL and a busy timeout let a watch keep recording while other
+	// commands read or write the same file.
+	dsn := "file:" + path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+	sqlDB, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := sqlDB.Exec(schema); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+	return &DB{db: sqlDB}, nil
+}
+
+// Close closes the database.
+func (d *DB) Close() error {
+	if d == nil {
+		return nil
+	}
+	return d.db.Close()
+}
+
+// Record is one observation of a blob. Zero Proof, PayloadHash, File, TxHash
+// and BlockNumber mean unknown and leave what was recorded before in place.
+type Record struct {
+	VersionedHash common.Hash
+	Commitment    kzg4844.Commitment
+	Proof         kzg4844.Proof
+	// PayloadHash is the SHA-256 of the whole payload the blob carries part
+	// of, such as the file split into it.
+	PayloadHash common.Hash
+	// Source is the command that processed the blob.
+	Source      string
+	File        string
+	TxHash      common.Hash
+	BlockNumber uint64
+}
+
+// Blob is an indexed blob with the transactions that carried it.
+type Blob struct {
+	VersionedHash common.Hash        `json:"versioned_hash"`
+	Commitment    kzg4844.Commitment `json:"commitment"`
+	Proof         kzg4844.Proof      `json:"proof,omitzero"`
+	PayloadHash   *common.Hash       `json:"payload_hash,omitempty"`
+	Source        string             `json:"source"`
+	File          string             `json:"file,omitempty"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	Inclusions    []Inclusion        `json:"inclusions"`
+}
+
+// Inclusion is a transaction that carried a blob.
+type Inclusion struct {
+	TxHash      common.Hash `json:"tx_hash"`
+	BlockNumber *uint64     `json:"block_number,omitempty"`
+	RecordedAt  time.Time   `json:"recorded_at"`
+}
+
+// zeroProof is how an unknown proof is stored.
+var zeroProof = hexutil.Encode(make([]byte, len(kzg4844.Proof{})))
+
+// Record stores recs in one transaction.
+func (d *DB) Record(ctx context.Context, recs ...Record) error {
+	if d == nil || len(recs) == 0 {
+		return nil
+	}
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	for _, r := range recs {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO blobs (versioned_hash, commitment, proof, payload_hash, source, file, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (versioned_hash) DO UPDATE SET
+				proof = CASE WHEN proof = ? THEN excluded.proof ELSE proof END,
+				payload_hash = COALESCE(excluded.payload_hash, payload_hash),
+				file = COALESCE(excluded.file, file),
+				updated_at = excluded.updated_at`,
+			r.VersionedHash.Hex(), hexutil.Encode(r.Commitment[:]), hexutil.Encode(r.Proof[:]),
+			nullHash(r.PayloadHash), r.Source, nullString(r.File), now, now, zeroProof)
+		if err != nil {
+			return fmt.Errorf("failed to record blob %s: %w", r.VersionedHash, err)
+		}
+		if r.TxHash == (common.Hash{}) {
+			continue
+		}
+		var block any
+		if r.BlockNumber != 0 {
+			block = int64(r.BlockNumber)
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO inclusions (versioned_hash, tx_hash, block_number, recorded_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (versioned_hash, tx_hash) DO UPDATE SET
+				block_number = COALESCE(excluded.block_number, block_number),
+				recorded_at = excluded.recorded_at`,
+			r.VersionedHash.Hex(), r.TxHash.Hex(), block, now)
+		if err != nil {
+			return fmt.Errorf("failed to record transaction of blob %s: %w", r.VersionedHash, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Query selects blobs. Zero fields match every blob.
+type Query struct {
+	VersionedHash common.Hash
+	PayloadHash   common.Hash
+	TxHash        common.Hash
+	Commitment    *kzg4844.Commitment
+	BlockNumber   uint64
+	Source        string
+	// Limit caps the number of blobs returned; zero returns all.
+	Limit int
+}
+
+// Find returns the blobs matching q, most recently updated first.
+func (d *DB) Find(ctx context.Context, q Query) ([]*Blob, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if q.VersionedHash != (common.Hash{}) {
+		where, args = append(where, "b.versioned_hash = ?"), append(args, q.VersionedHash.Hex())
+	}
+	if q.PayloadHash != (common.Hash{}) {
+		where, args = append(where, "b.payload_hash = ?"), append(args, q.PayloadHash.Hex())
+	}
+	if q.Commitment != nil {
+		where, args = append(where, "b.commitment = ?"), append(args, hexutil.Encode(q.Commitment[:]))
+	}
+	if q.Source != "" {
+		where, args = append(where, "b.source = ?"), append(args, q.Source)
+	}
+	if q.TxHash != (common.Hash{}) {
+		where, args = append(where, "b.versioned_hash IN (SELECT versioned_hash FROM inclusions WHERE tx_hash = ?)"), append(args, q.TxHash.Hex())
+	}
+	if q.BlockNumber != 0 {
+		where, args = append(where, "b.versioned_hash IN (SELECT versioned_hash FROM inclusions WHERE block_number = ?)"), append(args, int64(q.BlockNumber))
+	}
+	query := "SELECT versioned_hash, commitment, proof, payload_hash, source, file, created_at, updated_at FROM blobs b"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY updated_at DESC, versioned_hash"
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", q.Limit)
+	}
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var blobs []*Blob
+	for rows.Next() {
+		b, err := scanBlob(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		blobs = append(blobs, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, b := range blobs {
+		if b.Inclusions, err = d.inclusions(ctx, b.VersionedHash); err != nil {
+			return nil, err
+		}
+	}
+	return blobs, nil
+}
+
+// Get returns the blob with versioned hash h, or an error wrapping
+// ErrNotFound.
+func (d *DB) Get(ctx context.Context, h common.Hash) (*Blob, error) {
+	blobs, err := d.Find(ctx, Query{VersionedHash: h})
+	if err != nil {
+		return nil, err
+	}
+	if len(blobs) == 0 {
+		return nil, fmt.Errorf("%w: blob %s", ErrNotFound, h)
+	}
+	return blobs[0], nil
+}
+
+func (d *DB) inclusions(ctx context.Context, h common.Hash) ([]Inclusion, error) {
+	rows, err := d.db.QueryContext(ctx,
+		"SELECT tx_hash, block_number, recorded_at FROM inclusions WHERE versioned_hash = ? ORDER BY recorded_at", h.Hex())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	incs := []Inclusion{}
+	for rows.Next() {
+		var (
+			inc        Inclusion
+			txHash, at string
+			block      sql.NullInt64
+		)
+		if err := rows.Scan(&txHash, &block, &at); err != nil {
+			return nil, err
+		}
+		inc.TxHash = common.HexToHash(txHash)
+		if block.Valid {
+			n := uint64(block.Int64)
+			inc.BlockNumber = &n
+		}
+		if inc.RecordedAt, err = time.Parse(time.RFC3339Nano, at); err != nil {
+			return nil, err
+		}
+		incs = append(incs, inc)
+	}
+	return incs, rows.Err()
+}
+
+func scanBlob(rows *sql.Rows) (*Blob, error) {
+	var (
+		b                     Blob
+		vh, commitment, proof string
+		payloadHash, file     sql.NullString
+		createdAt, updatedAt  string
+	)
+	if err := rows.Scan(&vh, &commitment, &proof, &payloadHash, &b.Source, &file, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	b.VersionedHash = common.HexToHash(vh)
+	if err := decodeHex(b.Commitment[:], commitment); err != nil {
+		return nil, fmt.Errorf("blob %s: invalid commitment: %w", vh, err)
+	}
+	if err := decodeHex(b.Proof[:], proof); err != nil {
+		return nil, fmt.Errorf("blob %s: invalid proof: %w", vh, err)
+	}
+	if payloadHash.Valid {
+		h := common.HexToHash(payloadHash.String)
+		b.PayloadHash = &h
+	}
+	b.File = file.String
+	var err error
+	if b.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, err
+	}
+	if b.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// decodeHex decodes the 0x-prefixed hex s into dst, which it must fill.
+func decodeHex(dst []byte, s string) error {
+	data, err := hexutil.Decode(s)
+	if err != nil {
+		return err
+	}
+	if len(data) != len(dst) {
+		return fmt.Errorf("got %d bytes, want %d", len(data), len(dst))
+	}
+	copy(dst, data)
+	return nil
+}
+
+func nullHash(h common.Hash) any {
+	if h == (common.Hash{}) {
+		return nil
+	}
+	return h.Hex()
+}
+
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
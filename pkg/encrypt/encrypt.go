@@ -0,0 +1,185 @@
+// Package encrypt seals payloads with AES-256-GCM before they are packed
+// into blobs, so data posted for data availability experiments is not
+// readable by everyone who downloads the blobs. The key is either 32 random
+// bytes or derived from a passphrase with scrypt.
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// KeySize is the size of an AES-256 key.
+const KeySize = 32
+
+// Envelope layout: a version byte, a KDF byte, the KDF parameters, the
+// nonce and the ciphertext with its tag.
+const (
+	version = 1
+
+	kdfNone   = 0 // the secret is the key
+	kdfScrypt = 1 // the key is scrypt(passphrase, salt, 2^logN, 8, 1)
+
+	saltSize = 16
+	// scryptLogN makes a derivation take about 64 MiB and a fraction of a
+	// second, which is fine for a one-off encode or decode.
+	scryptLogN = 16
+	// maxScryptLogN bounds the work a crafted envelope can ask for.
+	maxScryptLogN = 22
+
+	nonceSize = 12
+	tagSize   = 16
+)
+
+// ErrDecrypt is returned when a payload cannot be decrypted: the key or
+// passphrase is wrong, or the ciphertext was modified.
+var ErrDecrypt = errors.New("decryption failed: wrong key or passphrase, or corrupted payload")
+
+// Secret is the key or passphrase a payload is sealed with.
+type Secret struct {
+	key        []byte
+	passphrase string
+}
+
+// NewKey returns a secret holding key, which must be KeySize bytes.
+func NewKey(key []byte) (*Secret, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return &Secret{key: key}, nil
+}
+
+// NewPassphrase returns a secret whose key is derived from passphrase, with
+// a fresh salt for every payload.
+func NewPassphrase(passphrase string) (*Secret, error) {
+	if passphrase == "" {
+		return nil, errors.New("empty passphrase")
+	}
+	return &Secret{passphrase: passphrase}, nil
+}
+
+// ReadKeyFile loads a hex-encoded key, with or without 0x, from a file.
+func ReadKeyFile(path string) (*Secret, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key: %w", err)
+	}
+	key, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key in %s: %w", path, err)
+	}
+	return NewKey(key)
+}
+
+// Overhead returns how many bytes Seal adds to a payload with s.
+func (s *Secret) Overhead() int {
+	n := 2 + nonceSize + tagSize
+	if s.key == nil {
+		n += 1 + saltSize
+	}
+	return n
+}
+
+// Seal encrypts plaintext with s. The result records how the key was
+// derived, so Open needs only the same secret.
+func (s *Secret) Seal(plaintext []byte) ([]byte, error) {
+	out := make([]byte, 0, len(plaintext)+s.Overhead())
+	key := s.key
+	if key == nil {
+		salt := make([]byte, saltSize)
+		rand.Read(salt)
+		var err error
+		if key, err = deriveKey(s.passphrase, salt, scryptLogN); err != nil {
+			return nil, err
+		}
+		out = append(out, version, kdfScrypt, scryptLogN)
+		out = append(out, salt...)
+	} else {
+		out = append(out, version, kdfNone)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, nonceSize)
+	rand.Read(nonce)
+	out = append(out, nonce...)
+	// The header is authenticated too, so its parameters cannot be swapped.
+	// It is copied since the additional data must not overlap the output.
+	return aead.Seal(out, nonce, plaintext, slices.Clone(out)), nil
+}
+
+// Open decrypts a payload sealed by Seal. It fails with ErrDecrypt if s is
+// not the secret it was sealed with or the payload was modified.
+func (s *Secret) Open(sealed []byte) ([]byte, error) {
+	if len(sealed) < 2 {
+		return nil, errors.New("encrypted payload too short")
+	}
+	if sealed[0] != version {
+		return nil, fmt.Errorf("unsupported encryption version %d", sealed[0])
+	}
+	header := 2
+	key := s.key
+	switch kdf := sealed[1]; kdf {
+	case kdfNone:
+		if key == nil {
+			return nil, errors.New("payload was encrypted with a key, not a passphrase")
+		}
+	case kdfScrypt:
+		if s.key != nil {
+			return nil, errors.New("payload was encrypted with a passphrase, not a key")
+		}
+		header += 1 + saltSize
+		if len(sealed) < header {
+			return nil, errors.New("encrypted payload too short")
+		}
+		logN := sealed[2]
+		if logN > maxScryptLogN {
+			return nil, fmt.Errorf("scrypt cost 2^%d exceeds 2^%d", logN, maxScryptLogN)
+		}
+		var err error
+		if key, err = deriveKey(s.passphrase, sealed[3:header], logN); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key derivation %d", kdf)
+	}
+	if len(sealed) < header+nonceSize+tagSize {
+		return nil, errors.New("encrypted payload too short")
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := sealed[header : header+nonceSize]
+	plaintext, err := aead.Open(nil, nonce, sealed[header+nonceSize:], sealed[:header+nonceSize])
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func deriveKey(passphrase string, salt []byte, logN byte) ([]byte, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<logN, 8, 1, KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
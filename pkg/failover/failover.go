@@ -0,0 +1,300 @@
+// Package failover spreads HTTP requests over several equivalent endpoints,
+// such as public execution or beacon nodes, so that one that errors or falls
+// behind the others does not stop an unattended publisher. Requests go to
+// the first healthy endpoint in the order given; one that fails on the
+// network or with a server error is taken out for a while and the request is
+// sent to the next. Periodic health checks also take out endpoints whose
+// head lags the best one.
+package failover
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of a Pool's settings.
+const (
+	DefaultMaxLag        = 3
+	DefaultCheckInterval = 30 * time.Second
+	DefaultCooldown      = 30 * time.Second
+	DefaultCheckTimeout  = 5 * time.Second
+)
+
+// Probe returns the head of the endpoint at url, such as its latest block
+// number or head slot, using client.
+type Probe func(ctx context.Context, client *http.Client, url string) (uint64, error)
+
+// Pool is a list of equivalent endpoints and what is known of their health.
+// It is safe for concurrent use.
+type Pool struct {
+	// MaxLag is how far an endpoint's head may be behind the best one
+	// before it is taken out until the next check.
+	MaxLag uint64
+	// CheckInterval is how often the endpoints are probed. A check runs
+	// before the first request and then, when due, before a request.
+	CheckInterval time.Duration
+	// Cooldown is how long an endpoint that failed a request or a probe is
+	// taken out.
+	Cooldown time.Duration
+	// CheckTimeout bounds each probe.
+	CheckTimeout time.Duration
+
+	probe     Probe
+	endpoints []*endpoint
+
+	mu       sync.Mutex
+	checked  time.Time
+	checking bool
+}
+
+type endpoint struct {
+	url       *url.URL
+	downUntil time.Time
+	reason    string
+	head      uint64
+	// lagging is set while the endpoint is out for lagging, which ends
+	// at the first check that finds it caught up.
+	lagging bool
+}
+
+// New returns a pool of the http(s) endpoints urls, checked with probe.
+func New(urls []string, probe Probe) (*Pool, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no endpoints given")
+	}
+	p := &Pool{
+		MaxLag:        DefaultMaxLag,
+		CheckInterval: DefaultCheckInterval,
+		Cooldown:      DefaultCooldown,
+		CheckTimeout:  DefaultCheckTimeout,
+		probe:         probe,
+	}
+	for _, s := range urls {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint %q: %w", s, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("endpoint %s: only http and https endpoints can fail over", u.Redacted())
+		}
+		p.endpoints = append(p.endpoints, &endpoint{url: u})
+	}
+	return p, nil
+}
+
+// URL returns the URL clients of the pool address their requests to: that
+// of the first endpoint, which the pool's transport rewrites to the
+// endpoint it chooses.
+func (p *Pool) URL() string { return p.endpoints[0].url.String() }
+
+// Status describes the health of one endpoint of a pool.
+type Status struct {
+	Host   string `json:"host"`
+	Up     bool   `json:"up"`
+	Head   uint64 `json:"head,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Status returns the health of every endpoint, in order.
+func (p *Pool) Status() []Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	s := make([]Status, len(p.endpoints))
+	for i, e := range p.endpoints {
+		s[i] = Status{Host: e.url.Host, Up: !now.Before(e.downUntil), Head: e.head}
+		if !s[i].Up {
+			s[i].Reason = e.reason
+		}
+	}
+	return s
+}
+
+// Check probes every endpoint concurrently through base. One that fails is
+// taken out for Cooldown, and one more than MaxLag behind the best head
+// until the next check.
+func (p *Pool) Check(ctx context.Context, base http.RoundTripper) {
+	client := &http.Client{Transport: base, Timeout: p.CheckTimeout}
+	heads := make([]uint64, len(p.endpoints))
+	errs := make([]error, len(p.endpoints))
+	var wg sync.WaitGroup
+	for i, e := range p.endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			heads[i], errs[i] = p.probe(ctx, client, e.url.String())
+		}()
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	p.checked = now
+	var best uint64
+	for i := range p.endpoints {
+		if errs[i] == nil {
+			best = max(best, heads[i])
+		}
+	}
+	for i, e := range p.endpoints {
+		switch {
+		case errs[i] != nil:
+			p.takeOut(e, now.Add(p.Cooldown), "health check failed: "+errs[i].Error())
+		case best-heads[i] > p.MaxLag:
+			e.head = heads[i]
+			p.takeOut(e, now.Add(p.CheckInterval), fmt.Sprintf("head %d is %d behind the best endpoint", heads[i], best-heads[i]))
+			e.lagging = true
+		default:
+			e.head = heads[i]
+			if e.lagging {
+				e.downUntil, e.reason, e.lagging = time.Time{}, "", false
+			}
+		}
+	}
+}
+
+// takeOut marks e down until the given time. p.mu must be held.
+func (p *Pool) takeOut(e *endpoint, until time.Time, reason string) {
+	if len(p.endpoints) > 1 && !time.Now().Before(e.downUntil) {
+		slog.Warn("Taking endpoint out of rotation", "host", e.url.Host, "reason", reason, "until", until.Format(time.TimeOnly))
+	}
+	e.downUntil, e.reason, e.lagging = until, reason, false
+}
+
+// checkIfDue runs Check if none ran for CheckInterval and none is running.
+func (p *Pool) checkIfDue(ctx context.Context, base http.RoundTripper) {
+	p.mu.Lock()
+	due := !p.checking && time.Since(p.checked) >= p.CheckInterval
+	if due {
+		p.checking = true
+	}
+	p.mu.Unlock()
+	if !due {
+		return
+	}
+	p.Check(ctx, base)
+	p.mu.Lock()
+	p.checking = false
+	p.mu.Unlock()
+}
+
+// order returns the endpoints to try: those up in the configured order,
+// then those down, soonest back first, as a last resort.
+func (p *Pool) order() []*endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	var up, down []*endpoint
+	for _, e := range p.endpoints {
+		if now.Before(e.downUntil) {
+			down = append(down, e)
+		} else {
+			up = append(up, e)
+		}
+	}
+	slices.SortStableFunc(down, func(a, b *endpoint) int { return a.downUntil.Compare(b.downUntil) })
+	return append(up, down...)
+}
+
+// Transport returns a transport that sends each request, addressed to
+// URL, to the endpoints in turn through base until one does not fail.
+// Request bodies are buffered so they can be sent again.
+func (p *Pool) Transport(base http.RoundTripper) http.RoundTripper {
+	return &transport{pool: p, base: base}
+}
+
+// Client returns an HTTP client using Transport over the default transport.
+func (p *Pool) Client() *http.Client {
+	return &http.Client{Transport: p.Transport(http.DefaultTransport)}
+}
+
+type transport struct {
+	pool *Pool
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.GetBody == nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	}
+	ctx := req.Context()
+	t.pool.checkIfDue(ctx, t.base)
+
+	order := t.pool.order()
+	for i, e := range order {
+		r := req.Clone(ctx)
+		r.URL, r.Host = t.pool.rewrite(req.URL, e.url), ""
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		resp, err := t.base.RoundTrip(r)
+		if i == len(order)-1 || ctx.Err() != nil || !failed(resp, err) {
+			return resp, err
+		}
+		failure := describe(resp, err)
+		t.pool.mu.Lock()
+		t.pool.takeOut(e, time.Now().Add(t.pool.Cooldown), failure)
+		t.pool.mu.Unlock()
+		slog.Debug("Failing over", "from", e.url.Host, "to", order[i+1].url.Host, "failure", failure)
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+	}
+	return nil, errors.New("failover: no endpoints")
+}
+
+// rewrite returns u, addressed to the pool's URL, addressed to the endpoint
+// at to instead: the path below the pool URL's path is kept, and the
+// query parameters of the pool URL are replaced by those of to.
+func (p *Pool) rewrite(u, to *url.URL) *url.URL {
+	from := p.endpoints[0].url
+	out := *to
+	out.Path = strings.TrimRight(to.Path, "/") + strings.TrimPrefix(u.Path, strings.TrimRight(from.Path, "/"))
+	out.RawPath = ""
+	q := u.Query()
+	for k := range from.Query() {
+		q.Del(k)
+	}
+	for k, v := range to.Query() {
+		q[k] = v
+	}
+	out.RawQuery = q.Encode()
+	return &out
+}
+
+// failed reports whether the endpoint, rather than the request, failed:
+// on the network, with a server error or by rate limiting.
+func failed(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
+
+func describe(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
@@ -0,0 +1,77 @@
+package failover
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ExecutionProbe returns the latest block number of a JSON-RPC endpoint.
+func ExecutionProbe(ctx context.Context, client *http.Client, url string) (uint64, error) {
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var resp struct {
+		Result *hexutil.Uint64 `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := do(client, req, &resp); err != nil {
+		return 0, err
+	}
+	switch {
+	case resp.Error != nil:
+		return 0, fmt.Errorf("eth_blockNumber: %s", resp.Error.Message)
+	case resp.Result == nil:
+		return 0, fmt.Errorf("eth_blockNumber: no result")
+	}
+	return uint64(*resp.Result), nil
+}
+
+// BeaconProbe returns the head slot of a beacon API endpoint.
+func BeaconProbe(ctx context.Context, client *http.Client, url string) (uint64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(url, "/")+"/eth/v1/beacon/headers/head", nil)
+	if err != nil {
+		return 0, err
+	}
+	var resp struct {
+		Data struct {
+			Header struct {
+				Message struct {
+					Slot string `json:"slot"`
+				} `json:"message"`
+			} `json:"header"`
+		} `json:"data"`
+	}
+	if err := do(client, req, &resp); err != nil {
+		return 0, err
+	}
+	slot, err := strconv.ParseUint(resp.Data.Header.Message.Slot, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid head slot %q", resp.Data.Header.Message.Slot)
+	}
+	return slot, nil
+}
+
+// do sends req and decodes a 200 response's JSON body into v.
+func do(client *http.Client, req *http.Request, v any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
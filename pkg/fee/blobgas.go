@@ -0,0 +1,92 @@
+package fee
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/params"
+
+	"kzg-blob-poc/pkg/chain"
+)
+
+// ExcessBlobGas returns the excess blob gas of a block whose parent had
+// parentExcess excess blob gas and used parentUsed blob gas, under the blob
+// schedule of the block (calc_excess_blob_gas of EIP-4844, with the target of
+// EIP-7691 from Prague).
+func ExcessBlobGas(bp chain.BlobParams, parentExcess, parentUsed uint64) uint64 {
+	if parentExcess+parentUsed < bp.TargetBlobGas() {
+		return 0
+	}
+	return parentExcess + parentUsed - bp.TargetBlobGas()
+}
+
+// BlobBaseFeeAt returns the blob base fee of a block with excess blob gas
+// excess under schedule bp (get_base_fee_per_blob_gas of EIP-4844).
+func BlobBaseFeeAt(bp chain.BlobParams, excess uint64) *big.Int {
+	return fakeExponential(big.NewInt(params.BlobTxMinBlobGasprice), new(big.Int).SetUint64(excess), new(big.Int).SetUint64(bp.UpdateFraction))
+}
+
+// fakeExponential approximates factor * e ** (numerator / denominator) with
+// integer math, exactly as the EIP-4844 reference does.
+func fakeExponential(factor, numerator, denominator *big.Int) *big.Int {
+	output := new(big.Int)
+	accum := new(big.Int).Mul(factor, denominator)
+	for i := int64(1); accum.Sign() > 0; i++ {
+		output.Add(output, accum)
+		accum.Mul(accum, numerator)
+		accum.Div(accum, denominator)
+		accum.Div(accum, big.NewInt(i))
+	}
+	return output.Div(output, denominator)
+}
+
+// MaxIncrease returns the factor the blob base fee rises by after a full
+// block under bp: 12.5% on Cancun, about 8.2% on Prague.
+func MaxIncrease(bp chain.BlobParams) float64 {
+	return math.Exp(float64(bp.MaxBlobGas()-bp.TargetBlobGas()) / float64(bp.UpdateFraction))
+}
+
+// HeadroomBlocks returns how many consecutive full blocks a fee cap of
+// multiplier times the current blob base fee outlasts under bp.
+func HeadroomBlocks(bp chain.BlobParams, multiplier float64) float64 {
+	return math.Log(multiplier) / math.Log(MaxIncrease(bp))
+}
+
+// MultiplierFor returns the multiplier of the current blob base fee that
+// outlasts blocks consecutive full blocks under bp.
+func MultiplierFor(bp chain.BlobParams, blocks float64) float64 {
+	return math.Pow(MaxIncrease(bp), blocks)
+}
+
+// BlobFees are the blob gas fields of a block and the blob fees they imply
+// for it and its child.
+type BlobFees struct {
+	ExcessBlobGas     uint64   `json:"excess_blob_gas"`
+	BlobGasUsed       uint64   `json:"blob_gas_used"`
+	BlobBaseFee       *big.Int `json:"blob_base_fee"`
+	NextExcessBlobGas uint64   `json:"next_excess_blob_gas"`
+	NextBlobBaseFee   *big.Int `json:"next_blob_base_fee"`
+}
+
+// CalcBlobFees computes the blob base fee of a block from its excessBlobGas
+// and blobGasUsed header fields under its schedule bp, and the excess blob
+// gas and blob base fee of its child under the child's schedule next, which
+// differs from bp only across a fork. It fails if used is not a whole number
+// of blobs within the block limit.
+func CalcBlobFees(bp, next chain.BlobParams, excess, used uint64) (*BlobFees, error) {
+	if used%params.BlobTxBlobGasPerBlob != 0 {
+		return nil, fmt.Errorf("blob gas used %d is not a multiple of %d", used, params.BlobTxBlobGasPerBlob)
+	}
+	if used > bp.MaxBlobGas() {
+		return nil, fmt.Errorf("blob gas used %d exceeds the block limit %d", used, bp.MaxBlobGas())
+	}
+	f := &BlobFees{
+		ExcessBlobGas:     excess,
+		BlobGasUsed:       used,
+		BlobBaseFee:       BlobBaseFeeAt(bp, excess),
+		NextExcessBlobGas: ExcessBlobGas(next, excess, used),
+	}
+	f.NextBlobBaseFee = BlobBaseFeeAt(next, f.NextExcessBlobGas)
+	return f, nil
+}
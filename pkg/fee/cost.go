@@ -0,0 +1,18 @@
+package fee
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// BlobGas returns the blob gas consumed by the given number of blobs.
+func BlobGas(blobs int) uint64 {
+	return uint64(blobs) * params.BlobTxBlobGasPerBlob
+}
+
+// BlobCost returns the blob fee in wei paid for the given number of blobs at
+// blobBaseFee. The execution gas of the carrying transaction is not included.
+func BlobCost(blobs int, blobBaseFee *big.Int) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(BlobGas(blobs)), blobBaseFee)
+}
@@ -0,0 +1,81 @@
+package fee
+
+import (
+	"context"
+	"math/big"
+)
+
+// Estimator recommends a maxFeePerBlobGas from the current and recent blob
+// base fees.
+type Estimator struct {
+	// Multiplier is the safety margin applied to the observed blob base fee.
+	Multiplier float64
+	// Blocks is the number of recent blocks whose fees are considered.
+	Blocks uint64
+}
+
+// DefaultEstimator doubles the highest blob base fee of the last 20 blocks,
+// which covers roughly six blocks of maximal blob base fee increases on
+// Cancun and nine on Prague (see HeadroomBlocks).
+var DefaultEstimator = Estimator{Multiplier: 2, Blocks: 20}
+
+// Estimate is a blob fee recommendation.
+type Estimate struct {
+	// BlobBaseFee is the blob base fee of the next block.
+	BlobBaseFee *big.Int `json:"blob_base_fee"`
+	// PeakBlobBaseFee is the highest blob base fee over the history window,
+	// including the next block.
+	PeakBlobBaseFee *big.Int `json:"peak_blob_base_fee"`
+	// Multiplier is the safety margin applied to PeakBlobBaseFee.
+	Multiplier float64 `json:"multiplier"`
+	// MaxFeePerBlobGas is the recommended blob fee cap.
+	MaxFeePerBlobGas *big.Int `json:"max_fee_per_blob_gas"`
+}
+
+// EstimateBlobFee recommends a maxFeePerBlobGas using DefaultEstimator.
+func EstimateBlobFee(ctx context.Context, client Caller) (*Estimate, error) {
+	return DefaultEstimator.Estimate(ctx, client)
+}
+
+// Estimate queries the current blob base fee and fee history and applies the
+// safety multiplier to the highest fee observed.
+func (e Estimator) Estimate(ctx context.Context, client Caller) (*Estimate, error) {
+	current, err := BlobBaseFee(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	peak := new(big.Int).Set(current)
+	if e.Blocks > 0 {
+		history, err := FetchHistory(ctx, client, e.Blocks)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range history.BlobBaseFees {
+			if f.Cmp(peak) > 0 {
+				peak.Set(f)
+			}
+		}
+	}
+
+	return &Estimate{
+		BlobBaseFee:      current,
+		PeakBlobBaseFee:  peak,
+		Multiplier:       e.Multiplier,
+		MaxFeePerBlobGas: applyMultiplier(peak, e.Multiplier),
+	}, nil
+}
+
+// applyMultiplier scales fee by m, rounding up and never returning less
+// than 1 wei.
+func applyMultiplier(fee *big.Int, m float64) *big.Int {
+	scaled := new(big.Float).Mul(new(big.Float).SetInt(fee), big.NewFloat(m))
+	result, acc := scaled.Int(nil)
+	if acc == big.Below {
+		result.Add(result, big.NewInt(1))
+	}
+	if result.Sign() <= 0 {
+		result.SetInt64(1)
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+// Package fee estimates blob gas fees from an execution client.
+package fee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"kzg-blob-poc/pkg/metrics"
+)
+
+// Caller is the JSON-RPC interface used to query fee data. *rpc.Client
+// satisfies it, as does the value returned by ethclient.Client.Client.
+type Caller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// History is the blob fee history of a range of consecutive blocks.
+type History struct {
+	// OldestBlock is the number of the first block in the range.
+	OldestBlock uint64
+	// BlobBaseFees holds the blob base fee of every block in the range plus
+	// the fee of the block following the newest one.
+	BlobBaseFees []*big.Int
+	// BlobGasUsedRatio is the blob gas used divided by the max blob gas of
+	// every block in the range.
+	BlobGasUsedRatio []float64
+}
+
+// MaxHistoryBlocks is the most blocks requested in one eth_feeHistory call,
+// the default limit of geth and most other clients.
+const MaxHistoryBlocks = 1024
+
+// FetchHistory queries eth_feeHistory for the blob fees of the latest blocks.
+// Longer ranges than the node serves in one call are fetched in pages,
+// walking back from the latest block; the range ends early at genesis.
+func FetchHistory(ctx context.Context, client Caller, blocks uint64) (*History, error) {
+	var (
+		h      *History
+		newest = "latest"
+	)
+	for blocks > 0 {
+		page, err := fetchHistoryPage(ctx, client, min(blocks, MaxHistoryBlocks), newest)
+		if err != nil {
+			return nil, err
+		}
+		n := uint64(len(page.BlobGasUsedRatio))
+		if n == 0 {
+			break
+		}
+		if h != nil {
+			// The trailing fee of an earlier page is the first fee of
+			// the page after it.
+			page.BlobBaseFees = append(page.BlobBaseFees[:n], h.BlobBaseFees...)
+			page.BlobGasUsedRatio = append(page.BlobGasUsedRatio, h.BlobGasUsedRatio...)
+		}
+		h = page
+		blocks -= min(blocks, n)
+		if h.OldestBlock == 0 {
+			break
+		}
+		newest = hexutil.EncodeUint64(h.OldestBlock - 1)
+	}
+	if h == nil {
+		return nil, errors.New("eth_feeHistory returned no blob fee data")
+	}
+	return h, nil
+}
+
+// fetchHistoryPage performs a single eth_feeHistory call for the blocks
+// ending at newest.
+func fetchHistoryPage(ctx context.Context, client Caller, blocks uint64, newest string) (*History, error) {
+	var result struct {
+		OldestBlock       *hexutil.Big   `json:"oldestBlock"`
+		BaseFeePerBlobGas []*hexutil.Big `json:"baseFeePerBlobGas"`
+		BlobGasUsedRatio  []float64      `json:"blobGasUsedRatio"`
+	}
+	if err := client.CallContext(ctx, &result, "eth_feeHistory", hexutil.Uint64(blocks), newest, []float64{}); err != nil {
+		metrics.RPCError("eth_feeHistory")
+		return nil, fmt.Errorf("eth_feeHistory: %w", err)
+	}
+	if result.OldestBlock == nil || len(result.BaseFeePerBlobGas) == 0 {
+		return nil, errors.New("eth_feeHistory returned no blob fee data")
+	}
+	if len(result.BaseFeePerBlobGas) != len(result.BlobGasUsedRatio)+1 {
+		return nil, fmt.Errorf("eth_feeHistory returned %d blob base fees for %d blocks", len(result.BaseFeePerBlobGas), len(result.BlobGasUsedRatio))
+	}
+
+	h := &History{
+		OldestBlock:      result.OldestBlock.ToInt().Uint64(),
+		BlobBaseFees:     make([]*big.Int, len(result.BaseFeePerBlobGas)),
+		BlobGasUsedRatio: result.BlobGasUsedRatio,
+	}
+	for i, f := range result.BaseFeePerBlobGas {
+		h.BlobBaseFees[i] = f.ToInt()
+	}
+	return h, nil
+}
+
+// BlobBaseFee queries eth_blobBaseFee for the blob base fee of the next block.
+func BlobBaseFee(ctx context.Context, client Caller) (*big.Int, error) {
+	var result hexutil.Big
+	if err := client.CallContext(ctx, &result, "eth_blobBaseFee"); err != nil {
+		metrics.RPCError("eth_blobBaseFee")
+		return nil, fmt.Errorf("eth_blobBaseFee: %w", err)
+	}
+	return result.ToInt(), nil
+}
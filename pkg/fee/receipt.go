@@ -0,0 +1,88 @@
+package fee
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+
+	"kzg-blob-poc/pkg/chain"
+)
+
+// ReceiptCost is what a mined blob transaction paid, from its receipt and
+// the header of its block.
+type ReceiptCost struct {
+	Blobs        int      `json:"blobs"`
+	BlobGasUsed  uint64   `json:"blob_gas_used"`
+	BlobGasPrice *big.Int `json:"blob_gas_price"`
+	// BlockBlobBaseFee is the blob base fee computed from the header's
+	// excess blob gas under the block's schedule. BlobGasPrice equals it
+	// unless the schedule is not the one the chain uses.
+	BlockBlobBaseFee *big.Int `json:"block_blob_base_fee"`
+	ExcessBlobGas    uint64   `json:"excess_blob_gas"`
+	BlockBlobGasUsed uint64   `json:"block_blob_gas_used"`
+
+	GasUsed           uint64   `json:"gas_used"`
+	EffectiveGasPrice *big.Int `json:"effective_gas_price"`
+	BaseFee           *big.Int `json:"base_fee"`
+
+	// BlobCost is BlobGasUsed * BlobGasPrice, all of it burned.
+	BlobCost *big.Int `json:"blob_cost_wei"`
+	// ExecutionCost is GasUsed * EffectiveGasPrice, of which PriorityFee
+	// goes to the block's fee recipient and the rest is burned.
+	ExecutionCost *big.Int `json:"execution_cost_wei"`
+	PriorityFee   *big.Int `json:"priority_fee_wei"`
+	TotalCost     *big.Int `json:"total_cost_wei"`
+	// BlobShare is BlobCost / TotalCost.
+	BlobShare float64 `json:"blob_share"`
+}
+
+// ReceiptCosts splits the cost of the blob transaction with receipt r,
+// mined in the block with header h under blob schedule bp, into its blob
+// and execution parts. A receipt without blobGasPrice, as some nodes
+// return, is priced at the blob base fee computed from h.
+func ReceiptCosts(r *types.Receipt, h *types.Header, bp chain.BlobParams) (*ReceiptCost, error) {
+	if r.Type != types.BlobTxType {
+		return nil, errors.New("not a blob transaction")
+	}
+	if h.ExcessBlobGas == nil || h.BlobGasUsed == nil || h.BaseFee == nil {
+		return nil, errors.New("block has no blob gas fields: chain is not on Cancun yet")
+	}
+	c := &ReceiptCost{
+		Blobs:             int(r.BlobGasUsed / params.BlobTxBlobGasPerBlob),
+		BlobGasUsed:       r.BlobGasUsed,
+		BlobGasPrice:      r.BlobGasPrice,
+		BlockBlobBaseFee:  BlobBaseFeeAt(bp, *h.ExcessBlobGas),
+		ExcessBlobGas:     *h.ExcessBlobGas,
+		BlockBlobGasUsed:  *h.BlobGasUsed,
+		GasUsed:           r.GasUsed,
+		EffectiveGasPrice: r.EffectiveGasPrice,
+		BaseFee:           h.BaseFee,
+	}
+	if c.BlobGasPrice == nil {
+		c.BlobGasPrice = c.BlockBlobBaseFee
+	}
+	if c.EffectiveGasPrice == nil {
+		return nil, errors.New("receipt has no effectiveGasPrice")
+	}
+	gas := new(big.Int).SetUint64(r.GasUsed)
+	c.BlobCost = new(big.Int).Mul(new(big.Int).SetUint64(r.BlobGasUsed), c.BlobGasPrice)
+	c.ExecutionCost = new(big.Int).Mul(gas, c.EffectiveGasPrice)
+	tip := new(big.Int).Sub(c.EffectiveGasPrice, h.BaseFee)
+	c.PriorityFee = tip.Mul(tip, gas)
+	c.TotalCost = new(big.Int).Add(c.BlobCost, c.ExecutionCost)
+	if c.TotalCost.Sign() > 0 {
+		c.BlobShare, _ = new(big.Rat).SetFrac(c.BlobCost, c.TotalCost).Float64()
+	}
+	return c, nil
+}
+
+// PerByte returns cost divided by size bytes, in wei, or 0 for size 0.
+func PerByte(cost *big.Int, size int) float64 {
+	if size <= 0 {
+		return 0
+	}
+	f, _ := new(big.Rat).SetFrac(cost, big.NewInt(int64(size))).Float64()
+	return f
+}
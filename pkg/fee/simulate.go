@@ -0,0 +1,60 @@
+package fee
+
+import (
+	"errors"
+	"math/big"
+	"slices"
+)
+
+// CostStats summarizes what posting a number of blobs would have cost over
+// a range of blocks, paying each block's blob base fee.
+type CostStats struct {
+	// Blobs is the number of blobs priced.
+	Blobs int `json:"blobs"`
+	// BlobGas is the blob gas they consume.
+	BlobGas uint64 `json:"blob_gas"`
+	// FromBlock and ToBlock bound the block range replayed.
+	FromBlock uint64 `json:"from_block"`
+	ToBlock   uint64 `json:"to_block"`
+	// Min, Median, P95, Max and Mean are blob costs in wei.
+	Min    *big.Int `json:"min_wei"`
+	Median *big.Int `json:"median_wei"`
+	P95    *big.Int `json:"p95_wei"`
+	Max    *big.Int `json:"max_wei"`
+	Mean   *big.Int `json:"mean_wei"`
+}
+
+// SimulateCost replays posting the given number of blobs in every block of
+// h. The trailing fee of the history, for the block that is not yet built,
+// is not included. Percentiles use the nearest-rank method.
+func SimulateCost(h *History, blobs int) (*CostStats, error) {
+	n := len(h.BlobGasUsedRatio)
+	if n == 0 || len(h.BlobBaseFees) < n {
+		return nil, errors.New("empty fee history")
+	}
+	costs := make([]*big.Int, n)
+	sum := new(big.Int)
+	for i, f := range h.BlobBaseFees[:n] {
+		costs[i] = BlobCost(blobs, f)
+		sum.Add(sum, costs[i])
+	}
+	slices.SortFunc(costs, (*big.Int).Cmp)
+
+	return &CostStats{
+		Blobs:     blobs,
+		BlobGas:   BlobGas(blobs),
+		FromBlock: h.OldestBlock,
+		ToBlock:   h.OldestBlock + uint64(n) - 1,
+		Min:       costs[0],
+		Median:    percentile(costs, 50),
+		P95:       percentile(costs, 95),
+		Max:       costs[n-1],
+		Mean:      sum.Div(sum, big.NewInt(int64(n))),
+	}, nil
+}
+
+// percentile returns the nearest-rank p-th percentile of the sorted values.
+func percentile(sorted []*big.Int, p int) *big.Int {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
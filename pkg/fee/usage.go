@@ -0,0 +1,236 @@
+package fee
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"kzg-blob-poc/pkg/chain"
+)
+
+// BlockUsage is the blob usage of one block.
+type BlockUsage struct {
+	Number uint64 `json:"number"`
+	Time   uint64 `json:"timestamp"`
+	Fork   string `json:"fork"`
+	// BlobTxs and Blobs count the block's blob transactions and blobs.
+	BlobTxs       int    `json:"blob_txs"`
+	Blobs         int    `json:"blobs"`
+	BlobGasUsed   uint64 `json:"blob_gas_used"`
+	TargetBlobGas uint64 `json:"target_blob_gas"`
+	MaxBlobGas    uint64 `json:"max_blob_gas"`
+	ExcessBlobGas uint64 `json:"excess_blob_gas"`
+	// BlobBaseFee is the blob base fee the block's blobs paid, computed
+	// from its excess blob gas, and BaseFee its execution base fee.
+	BlobBaseFee *big.Int `json:"blob_base_fee_wei"`
+	BaseFee     *big.Int `json:"base_fee_wei"`
+}
+
+// SenderUsage is the blob usage of one sender over a range of blocks.
+type SenderUsage struct {
+	From    common.Address `json:"from"`
+	Txs     int            `json:"txs"`
+	Blobs   int            `json:"blobs"`
+	BlobGas uint64         `json:"blob_gas"`
+	// BlobFees is what the sender's blobs paid at the blob base fee of
+	// their blocks, all of it burned.
+	BlobFees *big.Int `json:"blob_fees_wei"`
+}
+
+// UsageStats aggregates the blob usage of a range of blocks. Add the
+// blocks in order, then call Finish.
+type UsageStats struct {
+	FromBlock uint64       `json:"from_block"`
+	ToBlock   uint64       `json:"to_block"`
+	Blocks    []BlockUsage `json:"blocks"`
+	BlobTxs   int          `json:"blob_txs"`
+	Blobs     int          `json:"blobs"`
+	// BlobGasUsed and TargetBlobGas are summed over the blocks, so their
+	// ratio is the range's use of the target.
+	BlobGasUsed   uint64 `json:"blob_gas_used"`
+	TargetBlobGas uint64 `json:"target_blob_gas"`
+	// AboveTarget and Full count the blocks that used more than their
+	// target and all of their maximum, which raise the blob base fee.
+	AboveTarget int `json:"blocks_above_target"`
+	Full        int `json:"blocks_full"`
+	// BlobFees is what all blobs paid, all of it burned.
+	BlobFees *big.Int `json:"blob_fees_wei"`
+	// MinBlobBaseFee, MedianBlobBaseFee and MaxBlobBaseFee are taken over
+	// the blocks; the first and last block give the fee's change.
+	MinBlobBaseFee    *big.Int `json:"min_blob_base_fee_wei"`
+	MedianBlobBaseFee *big.Int `json:"median_blob_base_fee_wei"`
+	MaxBlobBaseFee    *big.Int `json:"max_blob_base_fee_wei"`
+	// Senders are the senders with the most blobs, most first.
+	Senders []SenderUsage `json:"top_senders"`
+	// SenderCount is the number of distinct senders, of which Senders may
+	// list only the top ones.
+	SenderCount int `json:"sender_count"`
+
+	senders map[common.Address]*SenderUsage
+}
+
+// Add adds the blob usage of b, a block of fork. It fails for a block
+// without blob gas fields, such as one before Cancun.
+func (s *UsageStats) Add(b *types.Block, fork chain.Fork) error {
+	h := b.Header()
+	if h.BlobGasUsed == nil || h.ExcessBlobGas == nil {
+		return fmt.Errorf("block %d has no blob gas fields", b.NumberU64())
+	}
+	u := BlockUsage{
+		Number:        b.NumberU64(),
+		Time:          h.Time,
+		Fork:          fork.Name,
+		BlobGasUsed:   *h.BlobGasUsed,
+		TargetBlobGas: fork.Blobs.TargetBlobGas(),
+		MaxBlobGas:    fork.Blobs.MaxBlobGas(),
+		ExcessBlobGas: *h.ExcessBlobGas,
+		BlobBaseFee:   BlobBaseFeeAt(fork.Blobs, *h.ExcessBlobGas),
+		BaseFee:       new(big.Int),
+	}
+	if h.BaseFee != nil {
+		u.BaseFee.Set(h.BaseFee)
+	}
+	if s.senders == nil {
+		s.senders = make(map[common.Address]*SenderUsage)
+		s.BlobFees = new(big.Int)
+	}
+	for _, tx := range b.Transactions() {
+		n := len(tx.BlobHashes())
+		if tx.Type() != types.BlobTxType || n == 0 {
+			continue
+		}
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return fmt.Errorf("transaction %s: failed to recover sender: %w", tx.Hash(), err)
+		}
+		su, ok := s.senders[from]
+		if !ok {
+			su = &SenderUsage{From: from, BlobFees: new(big.Int)}
+			s.senders[from] = su
+		}
+		su.Txs++
+		su.Blobs += n
+		su.BlobGas += BlobGas(n)
+		su.BlobFees.Add(su.BlobFees, BlobCost(n, u.BlobBaseFee))
+		u.BlobTxs++
+		u.Blobs += n
+	}
+
+	if len(s.Blocks) == 0 {
+		s.FromBlock = u.Number
+	}
+	s.ToBlock = u.Number
+	s.Blocks = append(s.Blocks, u)
+	s.BlobTxs += u.BlobTxs
+	s.Blobs += u.Blobs
+	s.BlobGasUsed += u.BlobGasUsed
+	s.TargetBlobGas += u.TargetBlobGas
+	if u.BlobGasUsed > u.TargetBlobGas {
+		s.AboveTarget++
+	}
+	if u.BlobGasUsed >= u.MaxBlobGas {
+		s.Full++
+	}
+	s.BlobFees.Add(s.BlobFees, new(big.Int).Mul(new(big.Int).SetUint64(u.BlobGasUsed), u.BlobBaseFee))
+	return nil
+}
+
+// Finish computes the blob base fee statistics and keeps the top senders,
+// or all of them if top is zero or less. Senders with as many blobs are
+// ordered by address.
+func (s *UsageStats) Finish(top int) error {
+	if len(s.Blocks) == 0 {
+		return errors.New("no blocks")
+	}
+	fees := make([]*big.Int, len(s.Blocks))
+	for i, u := range s.Blocks {
+		fees[i] = u.BlobBaseFee
+	}
+	slices.SortFunc(fees, (*big.Int).Cmp)
+	s.MinBlobBaseFee = fees[0]
+	s.MedianBlobBaseFee = percentile(fees, 50)
+	s.MaxBlobBaseFee = fees[len(fees)-1]
+
+	s.Senders = make([]SenderUsage, 0, len(s.senders))
+	for _, su := range s.senders {
+		s.Senders = append(s.Senders, *su)
+	}
+	slices.SortFunc(s.Senders, func(a, b SenderUsage) int {
+		if a.Blobs != b.Blobs {
+			return b.Blobs - a.Blobs
+		}
+		return bytes.Compare(a.From[:], b.From[:])
+	})
+	s.SenderCount = len(s.Senders)
+	if top > 0 && len(s.Senders) > top {
+		s.Senders = s.Senders[:top]
+	}
+	return nil
+}
+
+// UsedTargetRatio returns the blob gas used as a fraction of the target
+// over the range: above 1 the blob base fee rose, below 1 it fell.
+func (s *UsageStats) UsedTargetRatio() float64 {
+	if s.TargetBlobGas == 0 {
+		return 0
+	}
+	return float64(s.BlobGasUsed) / float64(s.TargetBlobGas)
+}
+
+// WriteBlocksCSV writes the blocks of s as CSV with a header row.
+func (s *UsageStats) WriteBlocksCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"number", "timestamp", "fork", "blob_txs", "blobs", "blob_gas_used", "target_blob_gas", "max_blob_gas", "excess_blob_gas", "blob_base_fee_wei", "base_fee_wei"}); err != nil {
+		return err
+	}
+	for _, u := range s.Blocks {
+		record := []string{
+			strconv.FormatUint(u.Number, 10),
+			strconv.FormatUint(u.Time, 10),
+			u.Fork,
+			strconv.Itoa(u.BlobTxs),
+			strconv.Itoa(u.Blobs),
+			strconv.FormatUint(u.BlobGasUsed, 10),
+			strconv.FormatUint(u.TargetBlobGas, 10),
+			strconv.FormatUint(u.MaxBlobGas, 10),
+			strconv.FormatUint(u.ExcessBlobGas, 10),
+			u.BlobBaseFee.String(),
+			u.BaseFee.String(),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteSendersCSV writes the top senders of s as CSV with a header row.
+func (s *UsageStats) WriteSendersCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"from", "txs", "blobs", "blob_gas", "blob_fees_wei"}); err != nil {
+		return err
+	}
+	for _, su := range s.Senders {
+		record := []string{
+			su.From.Hex(),
+			strconv.Itoa(su.Txs),
+			strconv.Itoa(su.Blobs),
+			strconv.FormatUint(su.BlobGas, 10),
+			su.BlobFees.String(),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,121 @@
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/metrics"
+)
+
+// DefaultBlobscanURL is the Blobscan API of mainnet.
+const DefaultBlobscanURL = "https://api.blobscan.com"
+
+// Blobscan is a Source and Indexer backed by the API of a Blobscan instance, which
+// keeps every blob it indexed after beacon nodes prune them.
+type Blobscan struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewBlobscan returns a client for the Blobscan API at baseURL. A nil
+// httpClient uses http.DefaultClient.
+func NewBlobscan(baseURL string, httpClient *http.Client) *Blobscan {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Blobscan{baseURL: strings.TrimRight(baseURL, "/"), http: httpClient}
+}
+
+// Name returns the API's base URL.
+func (c *Blobscan) Name() string { return c.baseURL }
+
+// Blob fetches the blob with versioned hash h from /blobs/{h}.
+func (c *Blobscan) Blob(ctx context.Context, h common.Hash) (*kzg4844.Blob, error) {
+	var res struct {
+		Data hexutil.Bytes `json:"data"`
+	}
+	path := "/blobs/" + h.Hex()
+	if err := c.get(ctx, "/blobs/{id}", path, &res); err != nil {
+		return nil, err
+	}
+	b := new(kzg4844.Blob)
+	if len(res.Data) != len(b) {
+		return nil, fmt.Errorf("GET %s: blob data has %d bytes, want %d", path, len(res.Data), len(b))
+	}
+	copy(b[:], res.Data)
+	return b, nil
+}
+
+// Locate returns the transactions Blobscan lists for the blob with
+// versioned hash h. Blobscan does not report their senders.
+func (c *Blobscan) Locate(ctx context.Context, h common.Hash) ([]Location, error) {
+	var res struct {
+		Transactions []struct {
+			Hash        common.Hash `json:"hash"`
+			Index       int         `json:"index"`
+			BlockHash   common.Hash `json:"blockHash"`
+			BlockNumber uint64      `json:"blockNumber"`
+		} `json:"transactions"`
+	}
+	err := c.get(ctx, "/blobs/{id}", "/blobs/"+h.Hex(), &res)
+	var serr *statusError
+	if errors.As(err, &serr) && serr.code == http.StatusNotFound || err == nil && len(res.Transactions) == 0 {
+		return nil, fmt.Errorf("%w %s in %s", ErrHashNotFound, h, c.baseURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	locs := make([]Location, len(res.Transactions))
+	for i, t := range res.Transactions {
+		locs[i] = Location{
+			VersionedHash: h, TxHash: t.Hash, BlockNumber: t.BlockNumber, BlockHash: t.BlockHash,
+			Index: t.Index, Source: c.baseURL,
+		}
+	}
+	return locs, nil
+}
+
+// statusError is the error get returns for a response other than 200 OK.
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string { return e.msg }
+
+// get decodes the JSON response to a GET of path into v. route is the path
+// pattern recorded in the metrics.
+func (c *Blobscan) get(ctx context.Context, route, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		metrics.RPCError(route)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		metrics.RPCError(route)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		msg := fmt.Sprintf("GET %s: %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+		return &statusError{code: resp.StatusCode, msg: msg}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("GET %s: failed to decode response: %w", path, err)
+	}
+	return nil
+}
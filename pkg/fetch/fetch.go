@@ -0,0 +1,213 @@
+// Package fetch retrieves blobs from a beacon node and cross-checks them
+// against the versioned hashes committed to on the execution layer.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"kzg-blob-poc/pkg/beacon"
+)
+
+// ExecutionClient is the subset of ethclient.Client used to locate a blob
+// transaction and its block.
+type ExecutionClient interface {
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+}
+
+// TxBlobs is the result of fetching the blobs of a mined blob transaction.
+type TxBlobs struct {
+	Tx       *types.Transaction
+	Block    *types.Header
+	Slot     uint64
+	Sidecars []*beacon.BlobSidecar // in the order of Tx.BlobHashes()
+	// Sourced holds, in the same order, the blobs the beacon node no longer
+	// had and a Source provided instead. Where it has a blob, the Sidecars
+	// entry is nil. It is nil when every blob has a sidecar.
+	Sourced []*SourcedBlob
+}
+
+// BlobsForTx locates the block that included txHash, downloads the blob
+// sidecars of the corresponding beacon block, and returns those whose
+// versioned hashes match the transaction's blobVersionedHashes. The sidecars
+// are not verified; call Verify on each. Blobs without a sidecar, usually
+// because the beacon node pruned them, are retrieved from sources with
+// FromSources, which does check them against their versioned hashes.
+func BlobsForTx(ctx context.Context, el ExecutionClient, cl *beacon.Client, txHash common.Hash, sources ...Source) (*TxBlobs, error) {
+	tx, pending, err := el.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if pending {
+		return nil, errors.New("transaction is still pending")
+	}
+	hashes := tx.BlobHashes()
+	if len(hashes) == 0 {
+		return nil, errors.New("not a blob transaction")
+	}
+
+	receipt, err := el.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt: %w", err)
+	}
+	header, err := el.HeaderByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block header: %w", err)
+	}
+	slot, err := cl.SlotAt(ctx, header.Time)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine slot: %w", err)
+	}
+
+	sidecars, err := cl.BlobSidecars(ctx, strconv.FormatUint(slot, 10))
+	if errors.Is(err, beacon.ErrNotFound) && len(sources) > 0 {
+		sidecars, err = nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob sidecars for slot %d: %w", slot, err)
+	}
+	res := &TxBlobs{Tx: tx, Block: header, Slot: slot}
+	if len(sources) == 0 {
+		if res.Sidecars, err = MatchVersionedHashes(sidecars, hashes); err != nil {
+			return nil, fmt.Errorf("slot %d: %w", slot, err)
+		}
+		return res, nil
+	}
+
+	byHash := make(map[common.Hash]*beacon.BlobSidecar, len(sidecars))
+	for _, sc := range sidecars {
+		byHash[sc.VersionedHash()] = sc
+	}
+	res.Sidecars = make([]*beacon.BlobSidecar, len(hashes))
+	for i, h := range hashes {
+		if sc, ok := byHash[h]; ok {
+			res.Sidecars[i] = sc
+			continue
+		}
+		if res.Sourced == nil {
+			res.Sourced = make([]*SourcedBlob, len(hashes))
+		}
+		if res.Sourced[i], err = FromSources(ctx, sources, h); err != nil {
+			return nil, fmt.Errorf("slot %d: %w", slot, err)
+		}
+	}
+	return res, nil
+}
+
+// MatchVersionedHashes returns the sidecars whose commitments hash to the
+// given versioned hashes, in the same order. It fails if any hash has no
+// sidecar, which usually means the blob was pruned.
+func MatchVersionedHashes(sidecars []*beacon.BlobSidecar, hashes []common.Hash) ([]*beacon.BlobSidecar, error) {
+	byHash := make(map[common.Hash]*beacon.BlobSidecar, len(sidecars))
+	for _, sc := range sidecars {
+		byHash[sc.VersionedHash()] = sc
+	}
+
+	matched := make([]*beacon.BlobSidecar, len(hashes))
+	for i, h := range hashes {
+		sc, ok := byHash[h]
+		if !ok {
+			return nil, fmt.Errorf("no sidecar for versioned hash %s", h)
+		}
+		matched[i] = sc
+	}
+	return matched, nil
+}
+
+// BlockBlobs is the result of fetching the blobs of a beacon block.
+type BlockBlobs struct {
+	Slot        uint64
+	Root        common.Hash
+	BlockNumber uint64 // of the execution payload
+	Blobs       []BlockBlob
+}
+
+// BlockBlob is a blob sidecar with the transaction of the block's execution
+// payload that carried it. Tx and From are zero if no transaction lists the
+// blob's versioned hash.
+type BlockBlob struct {
+	Sidecar *beacon.BlobSidecar
+	Tx      common.Hash
+	From    common.Address
+}
+
+// BlobsForBlock downloads the blob sidecars of the beacon block identified
+// by blockID and the block itself, and attributes each sidecar to the blob
+// transaction and sender that carried it. The block's root is the one the
+// node reports for its header, and the block and sidecars are fetched by
+// it. The sidecars are not verified; call Verify on each. A block without
+// blobs yields no sidecar request.
+func BlobsForBlock(ctx context.Context, cl *beacon.Client, blockID string) (*BlockBlobs, error) {
+	// Resolve blockID to a root first, so the block and sidecars belong to
+	// the same block even if the head moves on in between.
+	root, header, err := cl.BlockHeader(ctx, blockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block header %s: %w", blockID, err)
+	}
+	block, err := cl.Block(ctx, root.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %s: %w", blockID, err)
+	}
+	msg := &block.Message
+	if msg.Slot != header.Message.Slot {
+		return nil, fmt.Errorf("block %s is at slot %d, its header at %d", root, msg.Slot, header.Message.Slot)
+	}
+	res := &BlockBlobs{Slot: msg.Slot, Root: root, BlockNumber: msg.Body.ExecutionPayload.BlockNumber}
+	if len(msg.Body.BlobKZGCommitments) == 0 {
+		return res, nil
+	}
+
+	sidecars, err := cl.BlobSidecars(ctx, root.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob sidecars for slot %d: %w", res.Slot, err)
+	}
+	senders, err := blobSenders(msg.Body.ExecutionPayload.Transactions)
+	if err != nil {
+		return nil, fmt.Errorf("slot %d: %w", res.Slot, err)
+	}
+	res.Blobs = make([]BlockBlob, len(sidecars))
+	for i, sc := range sidecars {
+		res.Blobs[i] = BlockBlob{Sidecar: sc}
+		if s, ok := senders[sc.VersionedHash()]; ok {
+			res.Blobs[i].Tx, res.Blobs[i].From = s.tx, s.from
+		}
+	}
+	return res, nil
+}
+
+// blobSender is the transaction that lists a versioned hash, and its sender.
+type blobSender struct {
+	tx   common.Hash
+	from common.Address
+}
+
+// blobSenders decodes the blob transactions of an execution payload and
+// maps each versioned hash they list to the transaction and its sender.
+func blobSenders(txs []hexutil.Bytes) (map[common.Hash]blobSender, error) {
+	senders := make(map[common.Hash]blobSender)
+	for i, raw := range txs {
+		if len(raw) == 0 || raw[0] != types.BlobTxType {
+			continue
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s: failed to recover sender: %w", tx.Hash(), err)
+		}
+		for _, h := range tx.BlobHashes() {
+			senders[h] = blobSender{tx: tx.Hash(), from: from}
+		}
+	}
+	return senders, nil
+}
@@ -0,0 +1,182 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrHashNotFound is returned when no blob transaction that lists a
+// versioned hash is found.
+var ErrHashNotFound = errors.New("no blob transaction found for versioned hash")
+
+// SourceChain is the Source of a Location found by ScanBlocks.
+const SourceChain = "chain"
+
+// Location is a blob transaction that listed a versioned hash.
+type Location struct {
+	VersionedHash common.Hash    `json:"versioned_hash"`
+	TxHash        common.Hash    `json:"tx_hash"`
+	BlockNumber   uint64         `json:"block_number"`
+	BlockHash     common.Hash    `json:"block_hash"`
+	From          common.Address `json:"from,omitzero"`
+	// Index is the position of the hash in the transaction's
+	// blobVersionedHashes.
+	Index int `json:"index"`
+	// Source is SourceChain, or the name of the Indexer that reported the
+	// location.
+	Source string `json:"source"`
+	// Confirmed reports that the execution client has the transaction,
+	// mined and listing the hash. Locations from ScanBlocks always are.
+	Confirmed bool `json:"confirmed"`
+}
+
+// Indexer looks up the transactions that carried a blob by its versioned
+// hash, such as a blob explorer does. Indexers are not trusted: check what
+// they report with ConfirmLocation.
+type Indexer interface {
+	// Name identifies the indexer in reports and errors.
+	Name() string
+	// Locate returns the transactions that listed h, or ErrHashNotFound.
+	Locate(ctx context.Context, h common.Hash) ([]Location, error)
+}
+
+// BlockReader is the subset of ethclient.Client used to scan blocks.
+type BlockReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+}
+
+// scanWorkers is how many blocks ScanBlocks requests at once.
+const scanWorkers = 8
+
+// ScanBlocks searches the blocks from to down to from for a blob
+// transaction that lists h, and returns the newest. It fails with
+// ErrHashNotFound if none of them has one.
+func ScanBlocks(ctx context.Context, el BlockReader, h common.Hash, from, to uint64) (*Location, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid block range %d-%d", from, to)
+	}
+	for hi := to; ; {
+		lo := from
+		if hi-from >= scanWorkers {
+			lo = hi - scanWorkers + 1
+		}
+		blocks, err := blockRange(ctx, el, lo, hi)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(blocks) - 1; i >= 0; i-- {
+			loc, err := findInBlock(blocks[i], h)
+			if loc != nil || err != nil {
+				return loc, err
+			}
+		}
+		if lo == from {
+			return nil, fmt.Errorf("%w %s in blocks %d-%d", ErrHashNotFound, h, from, to)
+		}
+		hi = lo - 1
+	}
+}
+
+// Blocks calls fn with each block from from to to, in order. The blocks are
+// fetched as ScanBlocks fetches them, several at a time.
+func Blocks(ctx context.Context, el BlockReader, from, to uint64, fn func(*types.Block) error) error {
+	if from > to {
+		return fmt.Errorf("invalid block range %d-%d", from, to)
+	}
+	for lo := from; ; lo += scanWorkers {
+		hi := to
+		if to-lo >= scanWorkers {
+			hi = lo + scanWorkers - 1
+		}
+		blocks, err := blockRange(ctx, el, lo, hi)
+		if err != nil {
+			return err
+		}
+		for _, b := range blocks {
+			if err := fn(b); err != nil {
+				return err
+			}
+		}
+		if hi == to {
+			return nil
+		}
+	}
+}
+
+// blockRange fetches the blocks lo to hi concurrently, in order.
+func blockRange(ctx context.Context, el BlockReader, lo, hi uint64) ([]*types.Block, error) {
+	blocks := make([]*types.Block, hi-lo+1)
+	errs := make([]error, len(blocks))
+	var wg sync.WaitGroup
+	for i := range blocks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := lo + uint64(i)
+			if blocks[i], errs[i] = el.BlockByNumber(ctx, new(big.Int).SetUint64(n)); errs[i] != nil {
+				errs[i] = fmt.Errorf("failed to get block %d: %w", n, errs[i])
+			}
+		}()
+	}
+	wg.Wait()
+	return blocks, errors.Join(errs...)
+}
+
+// findInBlock returns the blob transaction of block that lists h, or nil.
+func findInBlock(block *types.Block, h common.Hash) (*Location, error) {
+	for _, tx := range block.Transactions() {
+		index := slices.Index(tx.BlobHashes(), h)
+		if index < 0 {
+			continue
+		}
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s: failed to recover sender: %w", tx.Hash(), err)
+		}
+		return &Location{
+			VersionedHash: h, TxHash: tx.Hash(), BlockNumber: block.NumberU64(), BlockHash: block.Hash(),
+			From: from, Index: index, Source: SourceChain, Confirmed: true,
+		}, nil
+	}
+	return nil, nil
+}
+
+// ConfirmLocation checks loc, as reported by an Indexer, against the
+// execution client: the transaction must be mined, in loc's block if it
+// names one, and list loc's versioned hash. It then fills in the block,
+// sender and index from the chain and sets Confirmed.
+func ConfirmLocation(ctx context.Context, el ExecutionClient, loc *Location) error {
+	tx, pending, err := el.TransactionByHash(ctx, loc.TxHash)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction %s: %w", loc.TxHash, err)
+	}
+	if pending {
+		return fmt.Errorf("transaction %s is still pending", loc.TxHash)
+	}
+	index := slices.Index(tx.BlobHashes(), loc.VersionedHash)
+	if index < 0 {
+		return fmt.Errorf("transaction %s does not list versioned hash %s", loc.TxHash, loc.VersionedHash)
+	}
+	receipt, err := el.TransactionReceipt(ctx, loc.TxHash)
+	if err != nil {
+		return fmt.Errorf("failed to get receipt of %s: %w", loc.TxHash, err)
+	}
+	if loc.BlockHash != (common.Hash{}) && receipt.BlockHash != loc.BlockHash {
+		return fmt.Errorf("transaction %s is in block %s, not %s", loc.TxHash, receipt.BlockHash, loc.BlockHash)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return fmt.Errorf("transaction %s: failed to recover sender: %w", loc.TxHash, err)
+	}
+	loc.BlockNumber, loc.BlockHash = receipt.BlockNumber.Uint64(), receipt.BlockHash
+	loc.From, loc.Index, loc.Confirmed = from, index, true
+	return nil
+}
@@ -0,0 +1,79 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/archive"
+	"kzg-blob-poc/pkg/blob"
+)
+
+// Source retrieves blobs by versioned hash from outside the beacon node,
+// such as a blob explorer or archive that keeps them past the pruning
+// window. Sources are not trusted: FromSources checks every blob against
+// its versioned hash.
+type Source interface {
+	// Name identifies the source in reports and errors.
+	Name() string
+	// Blob returns the blob with versioned hash h.
+	Blob(ctx context.Context, h common.Hash) (*kzg4844.Blob, error)
+}
+
+// SourcedBlob is a blob retrieved from a Source whose commitment hashes to
+// the requested versioned hash. Its proof is computed locally.
+type SourcedBlob struct {
+	Source        string
+	VersionedHash common.Hash
+	Blob          *kzg4844.Blob
+	Commitment    kzg4844.Commitment
+	Proof         kzg4844.Proof
+}
+
+// FromSources asks each source in turn for the blob with versioned hash h
+// and returns the first blob that commits to h. A source that fails or
+// returns another blob is skipped; the error lists why each one was.
+func FromSources(ctx context.Context, sources []Source, h common.Hash) (*SourcedBlob, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no sidecar for versioned hash %s and no fallback source", h)
+	}
+	var errs []error
+	for _, src := range sources {
+		b, err := src.Blob(ctx, h)
+		if err == nil {
+			var art *blob.BlobArtifacts
+			if art, err = blob.NewArtifacts(b, false); err == nil {
+				err = blob.CheckVersionedHash(art.Commitment, h)
+			}
+			if err == nil {
+				return &SourcedBlob{Source: src.Name(), VersionedHash: h, Blob: b, Commitment: art.Commitment, Proof: art.Proof}, nil
+			}
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", src.Name(), err))
+	}
+	return nil, fmt.Errorf("blob %s: %w", h, errors.Join(errs...))
+}
+
+// archiveSource is a Source backed by a blob archive.
+type archiveSource struct {
+	name string
+	a    *archive.Archive
+}
+
+// ArchiveSource returns a Source that reads blobs from a, reported as name.
+func ArchiveSource(name string, a *archive.Archive) Source {
+	return &archiveSource{name: name, a: a}
+}
+
+func (s *archiveSource) Name() string { return s.name }
+
+func (s *archiveSource) Blob(ctx context.Context, h common.Hash) (*kzg4844.Blob, error) {
+	b, _, err := s.a.Get(ctx, h)
+	return b, err
+}
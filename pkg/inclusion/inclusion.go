@@ -0,0 +1,331 @@
+// Package inclusion proves that a blob transaction is part of an execution
+// layer chain, so that the versioned hashes it carries can be trusted by a
+// client that knows only one recent block hash, such as an L2 light client
+// or a bridge. A proof holds the transaction, its Merkle proof in the
+// transactions trie of its block, the headers from that block to the
+// trusted one and, optionally, the KZG artifacts of its blobs.
+package inclusion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+
+	"kzg-blob-poc/pkg/beacon"
+	"kzg-blob-poc/pkg/blob"
+)
+
+// Version is the proof format version written by this package. Parse
+// rejects other versions.
+const Version = 1
+
+// DefaultMaxHeaders is the default limit on the headers of a proof, which
+// keeps it compact: a header is about 600 bytes.
+const DefaultMaxHeaders = 1024
+
+// ErrProofMismatch is returned when a proof does not tie its transaction to
+// the trusted block. It wraps blob.ErrProofMismatch.
+var ErrProofMismatch = fmt.Errorf("transaction inclusion %w", blob.ErrProofMismatch)
+
+// Proof is a proof that a blob transaction was included in a chain ending
+// at a trusted block.
+type Proof struct {
+	Version int         `json:"version"`
+	TxHash  common.Hash `json:"tx_hash"`
+	// Tx is the transaction in its canonical encoding, as the transactions
+	// trie holds it.
+	Tx      hexutil.Bytes `json:"tx"`
+	TxIndex uint64        `json:"tx_index"`
+	// TxProof is the trie nodes on the path from the transactions root of
+	// the block to the transaction.
+	TxProof []hexutil.Bytes `json:"tx_proof"`
+	// Headers are the RLP-encoded headers from the block of the transaction
+	// to the trusted block, oldest first.
+	Headers []hexutil.Bytes `json:"headers"`
+	// Blobs are the artifacts of the transaction's blobs, in the order of
+	// its versioned hashes, if they were available.
+	Blobs []blob.BlobArtifacts `json:"blobs,omitempty"`
+}
+
+// Backend is the subset of ethclient.Client used to build a proof.
+type Backend interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error)
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Options control Build.
+type Options struct {
+	// To is the number of the trusted block the header chain ends at. Nil
+	// means the latest block.
+	To *big.Int
+	// MaxHeaders limits the length of the header chain. Zero means
+	// DefaultMaxHeaders.
+	MaxHeaders int
+}
+
+// Build proves that the blob transaction txHash is part of the chain ending
+// at block opts.To. The header chain is followed from that block back
+// through parent hashes, so it is consistent even if the node reorganizes
+// meanwhile; a transaction no longer on that chain is an error.
+func Build(ctx context.Context, client Backend, txHash common.Hash, opts Options) (*Proof, error) {
+	receipt, err := client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt: %w", err)
+	}
+	block, err := client.BlockByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %s: %w", receipt.BlockHash, err)
+	}
+	txs := block.Transactions()
+	index := uint64(receipt.TransactionIndex)
+	if index >= uint64(len(txs)) || txs[index].Hash() != txHash {
+		return nil, fmt.Errorf("block %d does not hold transaction %s at index %d", block.NumberU64(), txHash, index)
+	}
+	if len(txs[index].BlobHashes()) == 0 {
+		return nil, errors.New("not a blob transaction")
+	}
+
+	p := &Proof{Version: Version, TxHash: txHash, TxIndex: index}
+	if p.Tx, err = txs[index].MarshalBinary(); err != nil {
+		return nil, err
+	}
+	if p.TxProof, err = proveTx(txs, index, block.TxHash()); err != nil {
+		return nil, fmt.Errorf("block %d: %w", block.NumberU64(), err)
+	}
+	if p.Headers, err = headerChain(ctx, client, block.Header(), opts); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// proveTx returns the Merkle proof of txs[index] in the transactions trie,
+// checking that the trie has the root the block header commits to.
+func proveTx(txs types.Transactions, index uint64, root common.Hash) ([]hexutil.Bytes, error) {
+	// The trie is built and proven in memory, so it needs no database.
+	tr := trie.NewEmpty(nil)
+	for i, tx := range txs {
+		enc, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		if err := tr.Update(rlp.AppendUint64(nil, uint64(i)), enc); err != nil {
+			return nil, err
+		}
+	}
+	if h := tr.Hash(); h != root {
+		return nil, fmt.Errorf("the node's transactions give root %s, header has %s", h, root)
+	}
+	var nodes trienode.ProofList
+	if err := tr.Prove(rlp.AppendUint64(nil, index), &nodes); err != nil {
+		return nil, err
+	}
+	proof := make([]hexutil.Bytes, len(nodes))
+	for i, n := range nodes {
+		proof[i] = hexutil.Bytes(n)
+	}
+	return proof, nil
+}
+
+// headerChain returns the encoded headers from first to the block of opts,
+// oldest first.
+func headerChain(ctx context.Context, client Backend, first *types.Header, opts Options) ([]hexutil.Bytes, error) {
+	maxHeaders := opts.MaxHeaders
+	if maxHeaders == 0 {
+		maxHeaders = DefaultMaxHeaders
+	}
+	head, err := client.HeaderByNumber(ctx, opts.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trusted block header: %w", err)
+	}
+	if head.Number.Cmp(first.Number) < 0 {
+		return nil, fmt.Errorf("trusted block %d is before block %d of the transaction", head.Number, first.Number)
+	}
+	if n := new(big.Int).Sub(head.Number, first.Number); !n.IsInt64() || n.Int64() >= int64(maxHeaders) {
+		return nil, fmt.Errorf("block %d is more than %d headers back from block %d", first.Number, maxHeaders, head.Number)
+	}
+
+	chain := []*types.Header{head}
+	for h := head; h.Number.Cmp(first.Number) > 0; {
+		if h, err = client.HeaderByHash(ctx, h.ParentHash); err != nil {
+			return nil, fmt.Errorf("failed to get header %s: %w", chain[len(chain)-1].ParentHash, err)
+		}
+		chain = append(chain, h)
+	}
+	if last := chain[len(chain)-1]; last.Hash() != first.Hash() {
+		return nil, fmt.Errorf("block %s of the transaction is not an ancestor of block %d; the chain reorganized", first.Hash(), head.Number)
+	}
+
+	encoded := make([]hexutil.Bytes, len(chain))
+	for i, h := range chain {
+		enc, err := rlp.EncodeToBytes(h)
+		if err != nil {
+			return nil, err
+		}
+		encoded[len(chain)-1-i] = enc
+	}
+	return encoded, nil
+}
+
+// AttachSidecars adds the artifacts of the transaction's blobs to p from
+// their sidecars, given in the order of its versioned hashes, after
+// checking each sidecar's KZG proof. With includeBlobs the blobs
+// themselves are added too, which lets Verify check the proofs.
+func (p *Proof) AttachSidecars(sidecars []*beacon.BlobSidecar, includeBlobs bool) error {
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(p.Tx); err != nil {
+		return fmt.Errorf("invalid transaction: %w", err)
+	}
+	hashes := tx.BlobHashes()
+	if len(sidecars) != len(hashes) {
+		return fmt.Errorf("have %d sidecars for %d blobs", len(sidecars), len(hashes))
+	}
+	p.Blobs = make([]blob.BlobArtifacts, len(sidecars))
+	for i, sc := range sidecars {
+		if err := sc.Verify(); err != nil {
+			return fmt.Errorf("blob %d: %w", i, err)
+		}
+		if vh := sc.VersionedHash(); vh != hashes[i] {
+			return fmt.Errorf("blob %d: %w: sidecar has %s, transaction %s", i, blob.ErrVersionedHashMismatch, vh, hashes[i])
+		}
+		p.Blobs[i] = blob.BlobArtifacts{Commitment: sc.KZGCommitment, Proof: sc.KZGProof, VersionedHash: hashes[i]}
+		if includeBlobs {
+			p.Blobs[i].BlobHex = hexutil.Encode(sc.Blob[:])
+		}
+	}
+	return nil
+}
+
+// Verified is what a valid proof shows.
+type Verified struct {
+	// Tx is the included transaction; its BlobHashes are the versioned
+	// hashes the proof vouches for.
+	Tx *types.Transaction
+	// Block is the header of the block that included Tx.
+	Block *types.Header
+	// Head is the header of the trusted block.
+	Head *types.Header
+}
+
+// Verify checks that p ties its transaction to the trusted block: the
+// transaction proof leads to the transactions root of the first header,
+// each header is the parent of the next, and the last header hashes to
+// trusted. The blob artifacts, if any, must be those of the transaction's
+// versioned hashes, and their proofs are checked where the blobs are
+// included. Failures wrap ErrProofMismatch or a blob package error.
+func (p *Proof) Verify(trusted common.Hash) (*Verified, error) {
+	if p.Version != Version {
+		return nil, fmt.Errorf("unsupported inclusion proof version %d, want %d", p.Version, Version)
+	}
+	if len(p.Headers) == 0 {
+		return nil, errors.New("proof has no headers")
+	}
+	headers := make([]*types.Header, len(p.Headers))
+	for i, enc := range p.Headers {
+		headers[i] = new(types.Header)
+		if err := rlp.DecodeBytes(enc, headers[i]); err != nil {
+			return nil, fmt.Errorf("header %d: %w", i, err)
+		}
+		if i == 0 {
+			continue
+		}
+		parent := headers[i-1]
+		if headers[i].ParentHash != parent.Hash() || headers[i].Number.Cmp(new(big.Int).Add(parent.Number, common.Big1)) != 0 {
+			return nil, fmt.Errorf("%w: header %d (block %d) is not the child of block %d", ErrProofMismatch, i, headers[i].Number, parent.Number)
+		}
+	}
+	head := headers[len(headers)-1]
+	if h := head.Hash(); h != trusted {
+		return nil, fmt.Errorf("%w: chain ends at block %d with hash %s, trusted hash is %s", ErrProofMismatch, head.Number, h, trusted)
+	}
+
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(p.Tx); err != nil {
+		return nil, fmt.Errorf("invalid transaction: %w", err)
+	}
+	if tx.Hash() != p.TxHash {
+		return nil, fmt.Errorf("%w: transaction hashes to %s, proof has %s", ErrProofMismatch, tx.Hash(), p.TxHash)
+	}
+	nodes := make(trienode.ProofList, len(p.TxProof))
+	for i, n := range p.TxProof {
+		nodes[i] = []byte(n)
+	}
+	block := headers[0]
+	value, err := trie.VerifyProof(block.TxHash, rlp.AppendUint64(nil, p.TxIndex), nodes.Set())
+	if err != nil || !bytes.Equal(value, p.Tx) {
+		return nil, fmt.Errorf("%w: transaction %d is not in the transactions trie of block %d", ErrProofMismatch, p.TxIndex, block.Number)
+	}
+
+	hashes := tx.BlobHashes()
+	if len(hashes) == 0 {
+		return nil, errors.New("not a blob transaction")
+	}
+	if len(p.Blobs) != 0 && len(p.Blobs) != len(hashes) {
+		return nil, fmt.Errorf("proof has artifacts of %d blobs, the transaction has %d", len(p.Blobs), len(hashes))
+	}
+	for i, a := range p.Blobs {
+		if a.VersionedHash != hashes[i] {
+			return nil, fmt.Errorf("blob %d: %w: artifacts have %s, transaction %s", i, blob.ErrVersionedHashMismatch, a.VersionedHash, hashes[i])
+		}
+		if err := blob.CheckVersionedHash(a.Commitment, hashes[i]); err != nil {
+			return nil, fmt.Errorf("blob %d: %w", i, err)
+		}
+		if a.BlobHex == "" {
+			continue
+		}
+		data, err := hexutil.Decode(a.BlobHex)
+		if err != nil || len(data) != blob.Size {
+			return nil, fmt.Errorf("blob %d: invalid blob hex", i)
+		}
+		if err := blob.Verify((*kzg4844.Blob)(data), a.Commitment, a.Proof); err != nil {
+			return nil, fmt.Errorf("blob %d: %w", i, err)
+		}
+	}
+	return &Verified{Tx: &tx, Block: block, Head: head}, nil
+}
+
+// Head returns the last header of p, that of the block it claims to end at.
+// Only Verify checks that it is.
+func (p *Proof) Head() (*types.Header, error) {
+	if len(p.Headers) == 0 {
+		return nil, errors.New("proof has no headers")
+	}
+	h := new(types.Header)
+	if err := rlp.DecodeBytes(p.Headers[len(p.Headers)-1], h); err != nil {
+		return nil, fmt.Errorf("header %d: %w", len(p.Headers)-1, err)
+	}
+	return h, nil
+}
+
+// Parse decodes a proof written as JSON.
+func Parse(data []byte) (*Proof, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	var p Proof
+	if err := d.Decode(&p); err != nil {
+		return nil, fmt.Errorf("invalid inclusion proof: %w", err)
+	}
+	return &p, nil
+}
+
+// Read reads and decodes the proof at path.
+func Read(path string) (*Proof, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inclusion proof: %w", err)
+	}
+	return Parse(data)
+}
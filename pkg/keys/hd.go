@@ -0,0 +1,73 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// hardenedOffset marks a hardened BIP-32 child index.
+const hardenedOffset = 0x80000000
+
+var errInvalidChild = errors.New("derived key is invalid, try the next index")
+
+// deriveKey derives the private key at path from a BIP-32 seed.
+func deriveKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	key, chainCode, err := splitHMAC([]byte("Bitcoin seed"), seed)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range path {
+		if key, chainCode, err = deriveChild(key, chainCode, index); err != nil {
+			return nil, err
+		}
+	}
+	return crypto.ToECDSA(math.PaddedBigBytes(key, 32))
+}
+
+// deriveChild implements BIP-32 private parent key to private child key
+// derivation.
+func deriveChild(key *big.Int, chainCode []byte, index uint32) (*big.Int, []byte, error) {
+	var data []byte
+	if index >= hardenedOffset {
+		data = append([]byte{0}, math.PaddedBigBytes(key, 32)...)
+	} else {
+		parent, err := crypto.ToECDSA(math.PaddedBigBytes(key, 32))
+		if err != nil {
+			return nil, nil, err
+		}
+		data = crypto.CompressPubkey(&parent.PublicKey)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	tweak, childChainCode, err := splitHMAC(chainCode, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	child := new(big.Int).Add(tweak, key)
+	child.Mod(child, crypto.S256().Params().N)
+	if child.Sign() == 0 {
+		return nil, nil, errInvalidChild
+	}
+	return child, childChainCode, nil
+}
+
+// splitHMAC computes HMAC-SHA512(key, data) and splits it into a scalar and
+// a chain code, rejecting scalars outside the curve order.
+func splitHMAC(key, data []byte) (*big.Int, []byte, error) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	scalar := new(big.Int).SetBytes(sum[:32])
+	if scalar.Sign() == 0 || scalar.Cmp(crypto.S256().Params().N) >= 0 {
+		return nil, nil, errInvalidChild
+	}
+	return scalar, sum[32:], nil
+}
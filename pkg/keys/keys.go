@@ -0,0 +1,82 @@
+// Package keys loads secp256k1 signing keys from a raw hex key file, a geth
+// encrypted keystore or a BIP-39 mnemonic. Keys and passwords are only read
+// from files, so they never appear on a command line or in shell history.
+package keys
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// DefaultHDPath is the derivation path of the first account of a mnemonic,
+// as used by most Ethereum wallets.
+const DefaultHDPath = "m/44'/60'/0'/0/0"
+
+// ReadSecret reads a password, passphrase or mnemonic from a file, dropping
+// the trailing newline.
+func ReadSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// FromHexFile loads a hex-encoded private key from a file.
+func FromHexFile(path string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return key, nil
+}
+
+// FromKeystore decrypts a geth (Web3 Secret Storage) keystore file.
+func FromKeystore(path, password string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	key, err := keystore.DecryptKey(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+	return key.PrivateKey, nil
+}
+
+// FromMnemonic derives the key at hdPath (BIP-32) from a BIP-39 mnemonic and
+// optional passphrase.
+func FromMnemonic(mnemonic, passphrase, hdPath string) (*ecdsa.PrivateKey, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, errors.New("invalid mnemonic")
+	}
+	path, err := accounts.ParseDerivationPath(hdPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid derivation path: %w", err)
+	}
+	seed := bip39.NewSeed(mnemonic, passphrase)
+	return deriveKey(seed, path)
+}
+
+// FromMnemonicFile reads a mnemonic from a file and derives the key at
+// hdPath.
+func FromMnemonicFile(path, passphrase, hdPath string) (*ecdsa.PrivateKey, error) {
+	mnemonic, err := ReadSecret(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mnemonic file: %w", err)
+	}
+	return FromMnemonic(mnemonic, passphrase, hdPath)
+}
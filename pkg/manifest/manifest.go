@@ -0,0 +1,380 @@
+// Package manifest defines the artifact manifest: a JSON description of a
+// payload encoded into blobs, with the encoding parameters and the
+// commitment, proof and versioned hash of every blob. Whoever holds the
+// manifest and the blobs can check both independently of the producer.
+//
+// A manifest is deterministic: encoding the same payload with the same
+// tool version and options yields the same bytes, so two parties can also
+// compare manifests directly.
+package manifest
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// Version is the manifest format version written by this package. Read
+// rejects other versions.
+const Version = 1
+
+// FileName is the conventional name of a manifest next to its blobs.
+const FileName = "manifest.json"
+
+// Layouts of the payload in the blobs.
+const (
+	// LayoutChunked cuts the payload into chunks of at most ChunkSize bytes
+	// and packs each into a blob, UsableBytesPerElement bytes per field
+	// element, as blob.SplitIntoBlobs and the split command do.
+	LayoutChunked = "chunked"
+	// LayoutFramed stores the payload in a single blob behind a frame
+	// header, after the named compression, as blob.EncodeFramedCompressed
+	// and the encode command do.
+	LayoutFramed = "framed"
+	// LayoutCodec stores the payload in the format of the codec named by
+	// Encoding.Codec, such as op-stack, which package codec decodes.
+	LayoutCodec = "codec"
+)
+
+// ErrPayloadMismatch is returned when the payload reassembled from the
+// blobs does not have the recorded size or SHA-256.
+var ErrPayloadMismatch = errors.New("payload mismatch")
+
+// Manifest describes a payload and the blobs it was encoded into.
+type Manifest struct {
+	Version  int      `json:"version"`
+	Tool     Tool     `json:"tool"`
+	Encoding Encoding `json:"encoding"`
+	Payload  Payload  `json:"payload"`
+	// ChunkRoot is the Merkle root over the chunks of the chunked layout;
+	// see ChunkRoot and ProveChunk. It is zero in older manifests.
+	ChunkRoot common.Hash `json:"chunk_root,omitzero"`
+	Blobs     []Blob      `json:"blobs"`
+	// Signature, if any, authenticates whoever wrote the manifest; see
+	// Sign and VerifySignature.
+	Signature *Signature `json:"signature,omitempty"`
+}
+
+// Tool identifies the program that wrote a manifest.
+type Tool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Encoding holds the parameters needed to recover the payload from the
+// blobs.
+type Encoding struct {
+	Layout string `json:"layout"`
+	// Compression is the codec of a framed payload, such as zstd.
+	Compression string `json:"compression,omitempty"`
+	// Codec names the codec of LayoutCodec, as registered in package
+	// codec.
+	Codec                 string `json:"codec,omitempty"`
+	FieldElementsPerBlob  int    `json:"field_elements_per_blob"`
+	UsableBytesPerElement int    `json:"usable_bytes_per_element"`
+	// ChunkSize is the most payload bytes in one blob of the chunked
+	// layout.
+	ChunkSize int `json:"chunk_size,omitempty"`
+}
+
+// NewEncoding returns the parameters of layout with this package's blob
+// format. compression only applies to LayoutFramed; for LayoutCodec, it is
+// the name of the codec.
+func NewEncoding(layout, compression string) Encoding {
+	e := Encoding{
+		Layout:                layout,
+		FieldElementsPerBlob:  blob.FieldElementsPerBlob,
+		UsableBytesPerElement: blob.UsableBytesPerFieldElement,
+	}
+	switch layout {
+	case LayoutChunked:
+		e.ChunkSize = blob.MaxPackedSize
+	case LayoutFramed:
+		if compression != blob.CompressionNone.String() {
+			e.Compression = compression
+		}
+	case LayoutCodec:
+		e.Codec = compression
+	}
+	return e
+}
+
+// Payload identifies the encoded payload.
+type Payload struct {
+	Size   int         `json:"size"`
+	SHA256 common.Hash `json:"sha256"`
+}
+
+// NewPayload returns the size and SHA-256 of data.
+func NewPayload(data []byte) Payload {
+	return Payload{Size: len(data), SHA256: sha256.Sum256(data)}
+}
+
+// Blob is one blob of the payload with its artifacts. Proof is zero, and
+// left out of the JSON, when it was not computed.
+type Blob struct {
+	blob.Chunk
+	// File is the blob file, relative to the manifest.
+	File          string             `json:"file,omitempty"`
+	Commitment    kzg4844.Commitment `json:"commitment"`
+	Proof         kzg4844.Proof      `json:"proof,omitzero"`
+	VersionedHash common.Hash        `json:"versioned_hash"`
+	// ChunkHash is the SHA-256 of the chunk's bytes, set with ChunkRoot.
+	ChunkHash common.Hash `json:"chunk_sha256,omitzero"`
+}
+
+// Marshal encodes m as indented JSON with a trailing newline. Fields are
+// written in a fixed order, so equal manifests encode to equal bytes.
+func (m *Manifest) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Write writes m to path.
+func (m *Manifest) Write(path string) error {
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Parse decodes a manifest and checks it with Check.
+func Parse(data []byte) (*Manifest, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	m := new(Manifest)
+	if err := dec.Decode(m); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if err := m.Check(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Read reads and parses the manifest at path.
+func Read(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return Parse(data)
+}
+
+// Check validates the structure of m without looking at any blob: the
+// version and encoding are supported, the chunks are contiguous and cover
+// the payload, each versioned hash is that of its commitment, and the chunk
+// root, if any, is that of the chunk hashes.
+func (m *Manifest) Check() error {
+	if m.Version != Version {
+		return fmt.Errorf("unsupported manifest version %d, want %d", m.Version, Version)
+	}
+	e := m.Encoding
+	if e.FieldElementsPerBlob != blob.FieldElementsPerBlob || e.UsableBytesPerElement != blob.UsableBytesPerFieldElement {
+		return fmt.Errorf("unsupported blob format: %d field elements of %d usable bytes", e.FieldElementsPerBlob, e.UsableBytesPerElement)
+	}
+	switch e.Layout {
+	case LayoutChunked:
+		if e.ChunkSize != blob.MaxPackedSize {
+			return fmt.Errorf("unsupported chunk size %d, want %d", e.ChunkSize, blob.MaxPackedSize)
+		}
+		if e.Compression != "" {
+			return errors.New("the chunked layout has no compression")
+		}
+		layout := blob.ChunkLayout(m.Payload.Size)
+		if len(m.Blobs) != len(layout) {
+			return fmt.Errorf("a %d-byte payload fills %d blobs, manifest lists %d", m.Payload.Size, len(layout), len(m.Blobs))
+		}
+		for i, c := range layout {
+			if m.Blobs[i].Chunk != c {
+				return fmt.Errorf("blob %d: chunk %+v, want %+v", i, m.Blobs[i].Chunk, c)
+			}
+		}
+	case LayoutFramed:
+		if _, err := blob.ParseCompression(cmp.Or(e.Compression, "none")); err != nil {
+			return err
+		}
+		if len(m.Blobs) != 1 {
+			return fmt.Errorf("the framed layout has one blob, manifest lists %d", len(m.Blobs))
+		}
+		if c := (blob.Chunk{Size: m.Payload.Size}); m.Blobs[0].Chunk != c {
+			return fmt.Errorf("blob 0: chunk %+v, want %+v", m.Blobs[0].Chunk, c)
+		}
+	case LayoutCodec:
+		if e.Codec == "" {
+			return errors.New("the codec layout needs the name of its codec")
+		}
+		if e.Compression != "" {
+			return errors.New("the codec layout has no compression; the codec applies its own")
+		}
+		if len(m.Blobs) == 0 {
+			return errors.New("manifest lists no blobs")
+		}
+	default:
+		return fmt.Errorf("unknown layout %q", e.Layout)
+	}
+	if e.Codec != "" && e.Layout != LayoutCodec {
+		return fmt.Errorf("the %s layout has no codec", e.Layout)
+	}
+	for i, b := range m.Blobs {
+		if b.VersionedHash != blob.VersionedHash(b.Commitment) {
+			return fmt.Errorf("blob %d: %w: commitment hashes to %s, manifest has %s", i, blob.ErrVersionedHashMismatch, blob.VersionedHash(b.Commitment), b.VersionedHash)
+		}
+		if (b.ChunkHash == common.Hash{}) != (m.ChunkRoot == common.Hash{}) {
+			return fmt.Errorf("blob %d: chunk hashes and the chunk root must be given together", i)
+		}
+	}
+	if m.Signature != nil {
+		if err := m.Signature.check(); err != nil {
+			return err
+		}
+	}
+	if m.ChunkRoot != (common.Hash{}) {
+		if e.Layout != LayoutChunked {
+			return fmt.Errorf("the %s layout has no chunk root", e.Layout)
+		}
+		if root := ChunkRoot(m.Blobs); root != m.ChunkRoot {
+			return fmt.Errorf("%w: chunk hashes have root %s, manifest has %s", ErrChunkMismatch, root, m.ChunkRoot)
+		}
+	}
+	return nil
+}
+
+// VerifyBlob checks that b is blob i of the manifest: it commits to the
+// recorded commitment and, if the manifest has one, the recorded proof
+// verifies. The error wraps blob.ErrCommitmentMismatch or
+// blob.ErrProofMismatch.
+func (m *Manifest) VerifyBlob(i int, b *kzg4844.Blob) error {
+	if i < 0 || i >= len(m.Blobs) {
+		return fmt.Errorf("blob %d: manifest has %d blobs", i, len(m.Blobs))
+	}
+	entry := m.Blobs[i]
+	commitment, err := blob.Commit(b)
+	if err != nil {
+		return fmt.Errorf("blob %d: %w", i, err)
+	}
+	if err := m.CompareArtifacts(i, commitment, kzg4844.Proof{}); err != nil {
+		return err
+	}
+	if entry.Proof == (kzg4844.Proof{}) {
+		return nil
+	}
+	if err := blob.Verify(b, entry.Commitment, entry.Proof); err != nil {
+		return fmt.Errorf("blob %d: %w", i, err)
+	}
+	return nil
+}
+
+// CompareArtifacts checks the commitment and proof computed from blob i,
+// such as by blob.NewArtifacts, against the manifest. Blob proofs are
+// deterministic, so a recomputed proof equals the recorded one exactly when
+// the recorded one verifies. Proofs are not compared if either is zero. The
+// error wraps blob.ErrCommitmentMismatch or blob.ErrProofMismatch.
+func (m *Manifest) CompareArtifacts(i int, commitment kzg4844.Commitment, proof kzg4844.Proof) error {
+	if i < 0 || i >= len(m.Blobs) {
+		return fmt.Errorf("blob %d: manifest has %d blobs", i, len(m.Blobs))
+	}
+	entry := m.Blobs[i]
+	if commitment != entry.Commitment {
+		return fmt.Errorf("blob %d: %w: blob commits to %x, manifest has %x", i, blob.ErrCommitmentMismatch, commitment[:], entry.Commitment[:])
+	}
+	if proof != (kzg4844.Proof{}) && entry.Proof != (kzg4844.Proof{}) && proof != entry.Proof {
+		return fmt.Errorf("blob %d: %w: blob's proof is %x, manifest has %x", i, blob.ErrProofMismatch, proof[:], entry.Proof[:])
+	}
+	return nil
+}
+
+// Decode recovers the payload from blobs, given in manifest order, and
+// checks its size and SHA-256 against the manifest. It does not verify the
+// blobs' commitments; see VerifyBlob. A payload of LayoutCodec is decoded
+// by package codec instead.
+func (m *Manifest) Decode(blobs []kzg4844.Blob) ([]byte, error) {
+	if len(blobs) != len(m.Blobs) {
+		return nil, fmt.Errorf("have %d blobs, manifest lists %d", len(blobs), len(m.Blobs))
+	}
+	var (
+		payload []byte
+		err     error
+	)
+	switch m.Encoding.Layout {
+	case LayoutChunked:
+		chunks := make([]blob.Chunk, len(m.Blobs))
+		for i, b := range m.Blobs {
+			chunks[i] = b.Chunk
+		}
+		payload, err = blob.JoinBlobs(blobs, chunks)
+	case LayoutFramed:
+		payload, err = blob.DecodeFramed(blobs[0])
+	case LayoutCodec:
+		err = m.errCodec()
+	default:
+		err = fmt.Errorf("unknown layout %q", m.Encoding.Layout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	if err := m.CheckPayload(NewPayload(payload)); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// CheckPayload compares the size and SHA-256 of a decoded payload with the
+// manifest's. The error wraps ErrPayloadMismatch.
+func (m *Manifest) CheckPayload(got Payload) error {
+	if got != m.Payload {
+		return fmt.Errorf("%w: decoded %d bytes with SHA-256 %s, manifest has %d bytes with %s", ErrPayloadMismatch, got.Size, got.SHA256, m.Payload.Size, m.Payload.SHA256)
+	}
+	return nil
+}
+
+// Unpack returns the part of the payload that blob i holds, so a large
+// payload can be checked one blob at a time. For the framed layout it is
+// the whole payload, decompressed. If the manifest has chunk hashes, the
+// part is checked against its hash and the error wraps ErrChunkMismatch.
+func (m *Manifest) Unpack(i int, b *kzg4844.Blob) ([]byte, error) {
+	if i < 0 || i >= len(m.Blobs) {
+		return nil, fmt.Errorf("blob %d: manifest has %d blobs", i, len(m.Blobs))
+	}
+	var (
+		data []byte
+		err  error
+	)
+	switch m.Encoding.Layout {
+	case LayoutChunked:
+		data, err = blob.Unpack(b, m.Blobs[i].Size)
+	case LayoutFramed:
+		data, err = blob.DecodeFramed(*b)
+	case LayoutCodec:
+		err = m.errCodec()
+	default:
+		err = fmt.Errorf("unknown layout %q", m.Encoding.Layout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("blob %d: failed to decode payload: %w", i, err)
+	}
+	if want := m.Blobs[i].ChunkHash; want != (common.Hash{}) {
+		if got := common.Hash(sha256.Sum256(data)); got != want {
+			return nil, fmt.Errorf("blob %d: %w: chunk hashes to %s, manifest has %s", i, ErrChunkMismatch, got, want)
+		}
+	}
+	return data, nil
+}
+
+// errCodec is the error of decoding a LayoutCodec payload in this package.
+func (m *Manifest) errCodec() error {
+	return fmt.Errorf("the payload is encoded with the %s codec: decode it with package codec", m.Encoding.Codec)
+}
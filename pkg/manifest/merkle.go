@@ -0,0 +1,157 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// The chunk tree is built like a Certificate Transparency log (RFC 9162):
+// leaves and inner nodes are hashed with distinct prefixes, and a tree of n
+// leaves splits after the largest power of two below n, so it needs no
+// padding and its shape is fixed by n.
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// ErrChunkMismatch is returned when a chunk does not hash to its manifest
+// entry or is not part of the chunk root.
+var ErrChunkMismatch = errors.New("chunk mismatch")
+
+// ChunkProof shows that a chunk of a payload is part of the manifest with
+// a given chunk root, so a light client holding only the root can check one
+// blob without downloading the others.
+type ChunkProof struct {
+	// Chunk is where the chunk sits in the payload; both its index and its
+	// offset and size are committed to by the root.
+	blob.Chunk
+	// Count is the number of chunks of the payload.
+	Count int `json:"count"`
+	// Path holds the sibling hashes from the chunk's leaf up to the root.
+	Path []common.Hash `json:"path"`
+}
+
+// ChunkRoot returns the Merkle root over the chunk hashes of blobs, which
+// must be the chunks of one payload in order with ChunkHash set.
+func ChunkRoot(blobs []Blob) common.Hash {
+	return treeRoot(chunkLeaves(blobs))
+}
+
+// ProveChunk returns the proof that chunk i is part of m's chunk root.
+func (m *Manifest) ProveChunk(i int) (*ChunkProof, error) {
+	if i < 0 || i >= len(m.Blobs) {
+		return nil, fmt.Errorf("blob %d: manifest has %d blobs", i, len(m.Blobs))
+	}
+	if m.ChunkRoot == (common.Hash{}) {
+		return nil, errors.New("manifest has no chunk root")
+	}
+	leaves := chunkLeaves(m.Blobs)
+	return &ChunkProof{Chunk: m.Blobs[i].Chunk, Count: len(leaves), Path: treePath(leaves, i)}, nil
+}
+
+// VerifyChunk checks that b holds the chunk described by proof and that the
+// chunk is part of the payload with chunk root root. It unpacks the chunk
+// but does not compute the blob's commitment. The error wraps
+// ErrChunkMismatch.
+func VerifyChunk(root common.Hash, b *kzg4844.Blob, proof *ChunkProof) error {
+	if proof.Count <= 0 || proof.Index < 0 || proof.Index >= proof.Count {
+		return fmt.Errorf("invalid chunk proof: index %d of %d chunks", proof.Index, proof.Count)
+	}
+	data, err := blob.Unpack(b, proof.Size)
+	if err != nil {
+		return fmt.Errorf("chunk %d: %w", proof.Index, err)
+	}
+	leaf := chunkLeaf(proof.Chunk, sha256.Sum256(data))
+	if !verifyPath(leaf, proof.Index, proof.Count, proof.Path, root) {
+		return fmt.Errorf("chunk %d: %w: not part of chunk root %s", proof.Index, ErrChunkMismatch, root)
+	}
+	return nil
+}
+
+func chunkLeaves(blobs []Blob) []common.Hash {
+	leaves := make([]common.Hash, len(blobs))
+	for i, b := range blobs {
+		leaves[i] = chunkLeaf(b.Chunk, b.ChunkHash)
+	}
+	return leaves
+}
+
+// chunkLeaf hashes a chunk's position and the SHA-256 of its bytes into its
+// leaf.
+func chunkLeaf(c blob.Chunk, hash common.Hash) common.Hash {
+	var buf [1 + 8 + 8 + common.HashLength]byte
+	buf[0] = leafPrefix
+	binary.BigEndian.PutUint64(buf[1:], uint64(c.Offset))
+	binary.BigEndian.PutUint64(buf[9:], uint64(c.Size))
+	copy(buf[17:], hash[:])
+	return sha256.Sum256(buf[:])
+}
+
+func hashNode(left, right common.Hash) common.Hash {
+	var buf [1 + 2*common.HashLength]byte
+	buf[0] = nodePrefix
+	copy(buf[1:], left[:])
+	copy(buf[1+common.HashLength:], right[:])
+	return sha256.Sum256(buf[:])
+}
+
+// split returns the size of the left subtree of a tree of n > 1 leaves: the
+// largest power of two below n.
+func split(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+func treeRoot(leaves []common.Hash) common.Hash {
+	switch len(leaves) {
+	case 0:
+		return sha256.Sum256(nil)
+	case 1:
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return hashNode(treeRoot(leaves[:k]), treeRoot(leaves[k:]))
+}
+
+// treePath returns the audit path of leaf i, ordered from the leaf upwards.
+func treePath(leaves []common.Hash, i int) []common.Hash {
+	if len(leaves) <= 1 {
+		return []common.Hash{}
+	}
+	k := split(len(leaves))
+	if i < k {
+		return append(treePath(leaves[:k], i), treeRoot(leaves[k:]))
+	}
+	return append(treePath(leaves[k:], i-k), treeRoot(leaves[:k]))
+}
+
+// verifyPath checks the audit path of leaf i of a tree of n leaves, as in
+// RFC 9162 section 2.1.3.2.
+func verifyPath(leaf common.Hash, i, n int, path []common.Hash, root common.Hash) bool {
+	fn, sn := i, n-1
+	r := leaf
+	for _, p := range path {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = hashNode(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = hashNode(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && r == root
+}
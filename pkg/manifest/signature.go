@@ -0,0 +1,122 @@
+package manifest
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signature schemes.
+const (
+	// SchemeEIP191 signs the manifest as an EIP-191 personal message
+	// ("\x19Ethereum Signed Message:\n" + length + manifest), as wallets
+	// and personal_sign do.
+	SchemeEIP191 = "eip191"
+	// SchemeSecp256k1 signs the Keccak-256 of the manifest directly.
+	SchemeSecp256k1 = "secp256k1"
+)
+
+var (
+	// ErrUnsigned is returned when verifying the signature of a manifest
+	// that has none.
+	ErrUnsigned = errors.New("manifest is not signed")
+	// ErrSignatureMismatch is returned when a manifest's signature was not
+	// made by the signer it names or expected, or the manifest was changed
+	// after it was signed.
+	ErrSignatureMismatch = errors.New("signature mismatch")
+)
+
+// Signature authenticates the producer of a manifest. It signs the
+// manifest's canonical encoding without the signature, so that a manifest
+// is signed by adding the field and checked by removing it.
+type Signature struct {
+	Scheme string         `json:"scheme"`
+	Signer common.Address `json:"signer"`
+	// Value is the 65-byte [R || S || V] signature, with V 0 or 1.
+	Value hexutil.Bytes `json:"value"`
+}
+
+// check validates the form of s, not whether it verifies.
+func (s *Signature) check() error {
+	if _, err := signingHash(s.Scheme, nil); err != nil {
+		return err
+	}
+	if len(s.Value) != crypto.SignatureLength {
+		return fmt.Errorf("signature is %d bytes, want %d", len(s.Value), crypto.SignatureLength)
+	}
+	return nil
+}
+
+// SigningHash returns the hash a signature of m under scheme signs: that
+// of m's encoding without its signature.
+func (m *Manifest) SigningHash(scheme string) (common.Hash, error) {
+	unsigned := *m
+	unsigned.Signature = nil
+	data, err := unsigned.Marshal()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return signingHash(scheme, data)
+}
+
+func signingHash(scheme string, data []byte) (common.Hash, error) {
+	switch scheme {
+	case SchemeEIP191:
+		return common.BytesToHash(accounts.TextHash(data)), nil
+	case SchemeSecp256k1:
+		return crypto.Keccak256Hash(data), nil
+	default:
+		return common.Hash{}, fmt.Errorf("unknown signature scheme %q (want %s or %s)", scheme, SchemeEIP191, SchemeSecp256k1)
+	}
+}
+
+// Sign signs m with key under scheme, replacing any signature it had.
+func (m *Manifest) Sign(key *ecdsa.PrivateKey, scheme string) error {
+	h, err := m.SigningHash(scheme)
+	if err != nil {
+		return err
+	}
+	sig, err := crypto.Sign(h[:], key)
+	if err != nil {
+		return err
+	}
+	m.Signature = &Signature{Scheme: scheme, Signer: crypto.PubkeyToAddress(key.PublicKey), Value: sig}
+	return nil
+}
+
+// VerifySignature recovers the signer of m and checks that it is the
+// signer the signature names and, unless want is the zero address, want.
+// It returns the signer, or the zero address if the signature does not
+// verify at all. The error is ErrUnsigned for a manifest without a
+// signature and wraps ErrSignatureMismatch for a wrong one.
+func (m *Manifest) VerifySignature(want common.Address) (common.Address, error) {
+	s := m.Signature
+	if s == nil {
+		return common.Address{}, ErrUnsigned
+	}
+	if err := s.check(); err != nil {
+		return common.Address{}, err
+	}
+	h, err := m.SigningHash(s.Scheme)
+	if err != nil {
+		return common.Address{}, err
+	}
+	pub, err := crypto.SigToPub(h[:], s.Value)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %w", ErrSignatureMismatch, err)
+	}
+	got := crypto.PubkeyToAddress(*pub)
+	if got != s.Signer {
+		// Any change to the manifest recovers some other key.
+		return common.Address{}, fmt.Errorf("%w: the manifest was changed after %s signed it, or another key signed it", ErrSignatureMismatch, s.Signer)
+	}
+	if want != (common.Address{}) && got != want {
+		return got, fmt.Errorf("%w: signed by %s, want %s", ErrSignatureMismatch, got, want)
+	}
+	return got, nil
+}
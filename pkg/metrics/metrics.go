@@ -0,0 +1,103 @@
+// Package metrics defines the Prometheus metrics of blob-poc. They are
+// registered with the default registry, so Handler serves them together with
+// the Go runtime and process collectors.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "blobpoc"
+
+var (
+	// BlobsEncoded counts payloads packed into blobs.
+	BlobsEncoded = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blobs_encoded_total",
+		Help:      "Number of payloads packed into blobs.",
+	})
+
+	// Commitments counts KZG commitments computed.
+	Commitments = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kzg_commitments_total",
+		Help:      "Number of KZG blob commitments computed.",
+	})
+
+	// VerifyDuration observes the latency of KZG blob proof verification,
+	// labelled by whether the proofs were valid. A batch verification is one
+	// observation.
+	VerifyDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "kzg_verify_duration_seconds",
+		Help:      "Latency of KZG blob proof verification.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"result"})
+
+	// CacheHits, CacheMisses and CacheErrors count lookups in the artifact
+	// cache and entries that could not be read or written.
+	CacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_hits_total",
+		Help:      "Number of blobs whose artifacts were found in the cache.",
+	})
+	CacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_misses_total",
+		Help:      "Number of blobs whose artifacts were not in the cache.",
+	})
+	CacheErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_errors_total",
+		Help:      "Number of cache entries that could not be read or written.",
+	})
+
+	// KZGTimeouts counts KZG computations abandoned because they ran past
+	// their per-blob timeout.
+	KZGTimeouts = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kzg_timeouts_total",
+		Help:      "Number of KZG computations abandoned after their per-blob timeout.",
+	})
+
+	// ServerRejected counts requests the server turned away because its
+	// queue was full or their API key was over its rate limit.
+	ServerRejected = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "server_rejected_total",
+		Help:      "Number of requests turned away because the server was saturated or a key was over its rate limit.",
+	})
+
+	// RPCErrors counts failed calls to execution and beacon nodes, labelled
+	// by JSON-RPC method or beacon API route.
+	RPCErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_errors_total",
+		Help:      "Number of failed execution JSON-RPC and beacon API calls.",
+	}, []string{"method"})
+)
+
+// ObserveVerify records a verification that started at start and returned
+// err.
+func ObserveVerify(start time.Time, err error) {
+	result := "valid"
+	if err != nil {
+		result = "invalid"
+	}
+	VerifyDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+}
+
+// RPCError counts a failed call of method.
+func RPCError(method string) {
+	RPCErrors.WithLabelValues(method).Inc()
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,84 @@
+// Package mmap maps files read-only into memory, so a large payload can be
+// packed into blobs without reading it into the heap first.
+package mmap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrUnsupported is returned by Open on platforms without mmap.
+var ErrUnsupported = errors.New("memory mapping is not supported on this platform")
+
+// File is a read-only memory-mapped file. Pages are read in from the file
+// as they are touched and count towards the resident set until the kernel
+// evicts them or ReleaseTo drops them.
+type File struct {
+	data     []byte
+	released int
+}
+
+// Open maps the whole of the regular file at path.
+func Open(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+	if info.Size() == 0 {
+		// Empty mappings are invalid, and there is nothing to map anyway.
+		return &File{data: []byte{}}, nil
+	}
+	if info.Size() != int64(int(info.Size())) {
+		return nil, fmt.Errorf("%s is too large to map (%d bytes)", path, info.Size())
+	}
+	data, err := mmap(f, int(info.Size()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to map %s: %w", path, err)
+	}
+	return &File{data: data}, nil
+}
+
+// Bytes returns the contents of the file. They must not be used after Close.
+func (m *File) Bytes() []byte {
+	return m.data
+}
+
+// Len returns the size of the file.
+func (m *File) Len() int {
+	return len(m.data)
+}
+
+// ReleaseTo tells the kernel that the file up to offset end will not be
+// read again, so its pages can leave the resident set now rather than when
+// memory runs short. Reading them afterwards is still valid and faults them
+// back in. A page straddling end is kept until a later call covers it.
+func (m *File) ReleaseTo(end int) {
+	if end >= len(m.data) {
+		end = len(m.data)
+	} else {
+		end -= end % os.Getpagesize()
+	}
+	if end > m.released {
+		release(m.data[m.released:end])
+		m.released = end
+	}
+}
+
+// Close unmaps the file.
+func (m *File) Close() error {
+	if len(m.data) == 0 {
+		return nil
+	}
+	data := m.data
+	m.data = nil
+	return munmap(data)
+}
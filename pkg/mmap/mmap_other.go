@@ -0,0 +1,15 @@
+//go:build !unix
+
+package mmap
+
+import "os"
+
+func mmap(*os.File, int) ([]byte, error) {
+	return nil, ErrUnsupported
+}
+
+func release([]byte) {}
+
+func munmap([]byte) error {
+	return nil
+}
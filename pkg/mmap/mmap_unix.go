@@ -0,0 +1,27 @@
+//go:build unix
+
+package mmap
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func mmap(f *os.File, size int) ([]byte, error) {
+	data, err := unix.Mmap(int(f.Fd()), 0, size, unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	// Payloads are read front to back, once. The advice is only a hint.
+	_ = unix.Madvise(data, unix.MADV_SEQUENTIAL)
+	return data, nil
+}
+
+func release(b []byte) {
+	_ = unix.Madvise(b, unix.MADV_DONTNEED)
+}
+
+func munmap(b []byte) error {
+	return unix.Munmap(b)
+}
@@ -0,0 +1,204 @@
+package opstack
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+
+	"github.com/andybalholm/brotli"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// MaxRLPBytesPerChannel caps the decompressed size of a channel, as of
+	// the Fjord upgrade.
+	MaxRLPBytesPerChannel = 100_000_000
+
+	// MaxSpanBatchElementCount caps the blocks and transactions of a span
+	// batch.
+	MaxSpanBatchElementCount = 10_000_000
+
+	// channelVersionBrotli marks a brotli-compressed channel. Channels
+	// without a version byte start with a zlib header instead.
+	channelVersionBrotli = 0x01
+)
+
+// Batch types, the first byte of an encoded batch.
+const (
+	SingularBatchType = 0
+	SpanBatchType     = 1
+)
+
+// Batch is one L2 batch decoded from a channel. Exactly one of Singular and
+// Span is set.
+type Batch struct {
+	Type     string         `json:"type"`
+	Singular *SingularBatch `json:"singular,omitempty"`
+	Span     *SpanBatch     `json:"span,omitempty"`
+}
+
+// SingularBatch is the metadata of a batch holding one L2 block.
+type SingularBatch struct {
+	ParentHash  common.Hash `json:"parent_hash"`
+	EpochNumber uint64      `json:"epoch_number"`
+	EpochHash   common.Hash `json:"epoch_hash"`
+	Timestamp   uint64      `json:"timestamp"`
+	TxCount     int         `json:"tx_count"`
+}
+
+// SpanBatch is the metadata of a batch holding a range of L2 blocks. The
+// transactions themselves are counted but not decoded.
+type SpanBatch struct {
+	// RelTimestamp is the first block's timestamp relative to L2 genesis.
+	RelTimestamp uint64 `json:"rel_timestamp"`
+	// L1OriginNumber is the L1 origin block of the last L2 block.
+	L1OriginNumber uint64 `json:"l1_origin_number"`
+	// ParentCheck is the first 20 bytes of the first block's parent hash.
+	ParentCheck hexutil.Bytes `json:"parent_check"`
+	// L1OriginCheck is the first 20 bytes of the last block's L1 origin
+	// hash.
+	L1OriginCheck hexutil.Bytes `json:"l1_origin_check"`
+	BlockCount    uint64        `json:"block_count"`
+	// OriginChanges is the number of blocks whose L1 origin differs from
+	// their parent's.
+	OriginChanges int    `json:"origin_changes"`
+	TxCount       uint64 `json:"tx_count"`
+}
+
+// DecodeChannel decompresses the data of a complete channel and decodes the
+// batches in it. It returns the compression used and, on error, the batches
+// decoded before it.
+func DecodeChannel(data []byte) (string, []Batch, error) {
+	if len(data) == 0 {
+		return "", nil, errors.New("empty channel")
+	}
+	var (
+		compression string
+		r           io.Reader
+	)
+	switch {
+	case data[0]&0x0f == 8 || data[0]&0x0f == 15:
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return "zlib", nil, fmt.Errorf("zlib decompression failed: %w", err)
+		}
+		defer zr.Close()
+		compression, r = "zlib", zr
+	case data[0] == channelVersionBrotli:
+		compression, r = "brotli", brotli.NewReader(bytes.NewReader(data[1:]))
+	default:
+		return "", nil, fmt.Errorf("unknown channel compression 0x%02x", data[0])
+	}
+
+	var batches []Batch
+	s := rlp.NewStream(r, MaxRLPBytesPerChannel)
+	for {
+		enc, err := s.Bytes()
+		if errors.Is(err, io.EOF) {
+			return compression, batches, nil
+		}
+		if err != nil {
+			return compression, batches, fmt.Errorf("batch %d: %w", len(batches), err)
+		}
+		b, err := decodeBatch(enc)
+		if err != nil {
+			return compression, batches, fmt.Errorf("batch %d: %w", len(batches), err)
+		}
+		batches = append(batches, b)
+	}
+}
+
+func decodeBatch(enc []byte) (Batch, error) {
+	if len(enc) == 0 {
+		return Batch{}, errors.New("empty batch")
+	}
+	switch enc[0] {
+	case SingularBatchType:
+		b, err := decodeSingularBatch(enc[1:])
+		return Batch{Type: "singular", Singular: b}, err
+	case SpanBatchType:
+		b, err := decodeSpanBatch(enc[1:])
+		return Batch{Type: "span", Span: b}, err
+	default:
+		return Batch{}, fmt.Errorf("unknown batch type %d", enc[0])
+	}
+}
+
+func decodeSingularBatch(enc []byte) (*SingularBatch, error) {
+	var b struct {
+		ParentHash   common.Hash
+		EpochNum     uint64
+		EpochHash    common.Hash
+		Timestamp    uint64
+		Transactions [][]byte
+	}
+	if err := rlp.DecodeBytes(enc, &b); err != nil {
+		return nil, fmt.Errorf("invalid singular batch: %w", err)
+	}
+	return &SingularBatch{
+		ParentHash:  b.ParentHash,
+		EpochNumber: b.EpochNum,
+		EpochHash:   b.EpochHash,
+		Timestamp:   b.Timestamp,
+		TxCount:     len(b.Transactions),
+	}, nil
+}
+
+// decodeSpanBatch decodes the prefix of a span batch and its per-block
+// fields, up to the transactions.
+func decodeSpanBatch(enc []byte) (*SpanBatch, error) {
+	r := bytes.NewReader(enc)
+	var (
+		b   SpanBatch
+		err error
+	)
+	if b.RelTimestamp, err = binary.ReadUvarint(r); err != nil {
+		return nil, fmt.Errorf("invalid span batch timestamp: %w", err)
+	}
+	if b.L1OriginNumber, err = binary.ReadUvarint(r); err != nil {
+		return nil, fmt.Errorf("invalid span batch L1 origin number: %w", err)
+	}
+	b.ParentCheck, b.L1OriginCheck = make([]byte, 20), make([]byte, 20)
+	if _, err := io.ReadFull(r, b.ParentCheck); err != nil {
+		return nil, fmt.Errorf("invalid span batch parent check: %w", err)
+	}
+	if _, err := io.ReadFull(r, b.L1OriginCheck); err != nil {
+		return nil, fmt.Errorf("invalid span batch L1 origin check: %w", err)
+	}
+
+	if b.BlockCount, err = binary.ReadUvarint(r); err != nil {
+		return nil, fmt.Errorf("invalid span batch block count: %w", err)
+	}
+	if b.BlockCount == 0 || b.BlockCount > MaxSpanBatchElementCount {
+		return nil, fmt.Errorf("invalid span batch block count %d", b.BlockCount)
+	}
+	originBits := make([]byte, (b.BlockCount+7)/8)
+	if _, err := io.ReadFull(r, originBits); err != nil {
+		return nil, fmt.Errorf("invalid span batch origin bits: %w", err)
+	}
+	// The bitlist is a big-endian integer, so unused bits are at the top of
+	// the first byte.
+	if rem := b.BlockCount % 8; rem != 0 && originBits[0]>>rem != 0 {
+		return nil, errors.New("invalid span batch origin bits: bits set past block count")
+	}
+	for _, c := range originBits {
+		b.OriginChanges += bits.OnesCount8(c)
+	}
+	for i := range b.BlockCount {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid span batch tx count of block %d: %w", i, err)
+		}
+		if n > MaxSpanBatchElementCount-b.TxCount {
+			return nil, fmt.Errorf("span batch has more than %d transactions", MaxSpanBatchElementCount)
+		}
+		b.TxCount += n
+	}
+	return &b, nil
+}
@@ -0,0 +1,102 @@
+// Package opstack decodes the blobs OP Stack batchers post to L1: the blob
+// encoding, the frames it carries, the channels the frames make up and the
+// L2 batches compressed into each channel.
+package opstack
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+const (
+	// BlobEncodingVersion is the only OP Stack blob encoding version.
+	BlobEncodingVersion = 0
+
+	// roundSize is the data held by a round of 4 field elements: 31 bytes
+	// in the low bytes of each plus 3 bytes split across the low 6 bits of
+	// their high bytes.
+	roundSize = 4*blob.UsableBytesPerFieldElement + 3
+
+	rounds = blob.FieldElementsPerBlob / 4
+
+	// headerSize is the version byte and 3-byte big-endian data length at
+	// the start of the first field element.
+	headerSize = 4
+
+	// MaxBlobDataSize is the largest payload an OP Stack blob holds.
+	MaxBlobDataSize = roundSize*rounds - headerSize
+)
+
+// DecodeBlob recovers the data an OP Stack batcher encoded in b. Unlike
+// blob.Pack, the encoding also uses the low 6 bits of each field element's
+// high byte, and it records the data length after a version byte.
+func DecodeBlob(b *kzg4844.Blob) ([]byte, error) {
+	if b[1] != BlobEncodingVersion {
+		return nil, fmt.Errorf("unsupported OP Stack blob encoding version %d", b[1])
+	}
+	size := int(b[2])<<16 | int(b[3])<<8 | int(b[4])
+	if size > MaxBlobDataSize {
+		return nil, fmt.Errorf("OP Stack blob data length %d exceeds %d bytes", size, MaxBlobDataSize)
+	}
+
+	// Each round is laid out as 31 bytes of element 0, byte x, 31 bytes of
+	// element 1, byte y, 31 bytes of element 2, byte z, 31 bytes of element
+	// 3, where x, y and z are reassembled from the four high bytes.
+	out := make([]byte, roundSize*rounds)
+	var high [4]byte
+	for r := range rounds {
+		opos := r * roundSize
+		for j := range high {
+			ipos := (r*4 + j) * blob.BytesPerFieldElement
+			if b[ipos]&0b1100_0000 != 0 {
+				return nil, fmt.Errorf("%w: element %d has high byte 0x%02x", blob.ErrInvalidFieldElement, r*4+j, b[ipos])
+			}
+			high[j] = b[ipos]
+			copy(out[opos+j*32:], b[ipos+1:ipos+blob.BytesPerFieldElement])
+		}
+		out[opos+31] = high[0] | (high[1]&0b0011_0000)<<2
+		out[opos+63] = high[1]&0b0000_1111 | (high[3]&0b0000_1111)<<4
+		out[opos+95] = high[2] | (high[3]&0b0011_0000)<<2
+	}
+
+	data := out[headerSize:]
+	for i, c := range data[size:] {
+		if c != 0 {
+			return nil, fmt.Errorf("OP Stack blob has non-zero byte at data offset %d, past its length %d", size+i, size)
+		}
+	}
+	return data[:size], nil
+}
+
+// EncodeBlob encodes data the way an OP Stack batcher does, the inverse of
+// DecodeBlob.
+func EncodeBlob(data []byte) (kzg4844.Blob, error) {
+	var b kzg4844.Blob
+	if len(data) > MaxBlobDataSize {
+		return b, fmt.Errorf("%w: %d bytes, max %d bytes", blob.ErrBlobTooLarge, len(data), MaxBlobDataSize)
+	}
+	in := make([]byte, roundSize*rounds)
+	in[0] = BlobEncodingVersion
+	in[1], in[2], in[3] = byte(len(data)>>16), byte(len(data)>>8), byte(len(data))
+	copy(in[headerSize:], data)
+
+	for r := range rounds {
+		opos := r * roundSize
+		x, y, z := in[opos+31], in[opos+63], in[opos+95]
+		high := [4]byte{
+			x & 0b0011_1111,
+			y&0b0000_1111 | (x&0b1100_0000)>>2,
+			z & 0b0011_1111,
+			(z&0b1100_0000)>>2 | (y&0b1111_0000)>>4,
+		}
+		for j, h := range high {
+			ipos := (r*4 + j) * blob.BytesPerFieldElement
+			b[ipos] = h
+			copy(b[ipos+1:ipos+blob.BytesPerFieldElement], in[opos+j*32:])
+		}
+	}
+	return b, nil
+}
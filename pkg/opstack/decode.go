@@ -0,0 +1,78 @@
+package opstack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// Result is the decoded content of the blobs of one or more batcher
+// transactions.
+type Result struct {
+	Frames   int              `json:"frames"`
+	Channels []ChannelSummary `json:"channels"`
+	// Skipped lists the blobs that are not OP Stack batcher data, such as
+	// those of other rollups in the same block, with the reason.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// ChannelSummary describes one channel found in the blobs. Batches are only
+// decoded for complete channels; a channel whose other frames are in blobs
+// that were not given is reported as incomplete.
+type ChannelSummary struct {
+	ID          ChannelID `json:"id"`
+	Frames      int       `json:"frames"`
+	Complete    bool      `json:"complete"`
+	Size        int       `json:"size"`
+	Compression string    `json:"compression,omitempty"`
+	Batches     []Batch   `json:"batches,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Decode decodes the frames in blobs, in order, and the batches of every
+// channel they complete. Blobs that are not OP Stack batcher data are
+// skipped, and Decode only fails if no blob holds frames. Errors decoding a
+// channel's batches are reported in its summary.
+func Decode(blobs []*kzg4844.Blob) (*Result, error) {
+	var (
+		res    = new(Result)
+		frames []Frame
+	)
+	for i, b := range blobs {
+		f, err := decodeFrames(b)
+		if err != nil {
+			res.Skipped = append(res.Skipped, fmt.Sprintf("blob %d: %v", i, err))
+			continue
+		}
+		frames = append(frames, f...)
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no OP Stack frames in %d blobs: %s", len(blobs), strings.Join(res.Skipped, "; "))
+	}
+
+	res.Frames = len(frames)
+	for _, c := range Channels(frames) {
+		s := ChannelSummary{ID: c.ID, Frames: len(c.Frames), Complete: c.Complete()}
+		for _, f := range c.Frames {
+			s.Size += len(f.Data)
+		}
+		if s.Complete {
+			data, _ := c.Data()
+			var err error
+			if s.Compression, s.Batches, err = DecodeChannel(data); err != nil {
+				s.Error = err.Error()
+			}
+		}
+		res.Channels = append(res.Channels, s)
+	}
+	return res, nil
+}
+
+func decodeFrames(b *kzg4844.Blob) ([]Frame, error) {
+	data, err := DecodeBlob(b)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFrames(data)
+}
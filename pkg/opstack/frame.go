@@ -0,0 +1,135 @@
+package opstack
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+const (
+	// DerivationVersion0 is the version byte ahead of the frames in batcher
+	// transaction data, including the data of a blob.
+	DerivationVersion0 = 0
+
+	// MaxFrameLen is the largest frame data length derivation accepts.
+	MaxFrameLen = 1_000_000
+
+	// frameOverhead is the size of a frame's fields besides its data:
+	// channel ID, frame number, data length and is_last.
+	frameOverhead = 16 + 2 + 4 + 1
+)
+
+// ChannelID identifies a channel.
+type ChannelID [16]byte
+
+func (id ChannelID) String() string { return hex.EncodeToString(id[:]) }
+
+// MarshalText encodes the ID as hex, without a 0x prefix, as op-node logs
+// it.
+func (id ChannelID) MarshalText() ([]byte, error) { return []byte(id.String()), nil }
+
+// Frame is a chunk of a channel.
+type Frame struct {
+	ChannelID ChannelID
+	Number    uint16
+	Data      []byte
+	IsLast    bool
+}
+
+// ParseFrames parses batcher transaction data: a derivation version byte
+// followed by one or more frames.
+func ParseFrames(data []byte) ([]Frame, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty batcher data")
+	}
+	if data[0] != DerivationVersion0 {
+		return nil, fmt.Errorf("unsupported derivation version %d", data[0])
+	}
+	data = data[1:]
+
+	var frames []Frame
+	for len(data) > 0 {
+		if len(data) < frameOverhead {
+			return nil, fmt.Errorf("frame %d: truncated header: %d bytes", len(frames), len(data))
+		}
+		var f Frame
+		copy(f.ChannelID[:], data)
+		f.Number = binary.BigEndian.Uint16(data[16:])
+		size := binary.BigEndian.Uint32(data[18:])
+		if size > MaxFrameLen {
+			return nil, fmt.Errorf("frame %d: data length %d exceeds %d bytes", len(frames), size, MaxFrameLen)
+		}
+		end := 22 + int(size)
+		if len(data) < end+1 {
+			return nil, fmt.Errorf("frame %d: truncated: want %d data bytes, have %d", len(frames), size, len(data)-22)
+		}
+		f.Data = data[22:end]
+		switch data[end] {
+		case 0:
+		case 1:
+			f.IsLast = true
+		default:
+			return nil, fmt.Errorf("frame %d: invalid is_last byte %d", len(frames), data[end])
+		}
+		frames = append(frames, f)
+		data = data[end+1:]
+	}
+	if len(frames) == 0 {
+		return nil, errors.New("batcher data holds no frames")
+	}
+	return frames, nil
+}
+
+// Channel is the frames of one channel, in frame number order.
+type Channel struct {
+	ID     ChannelID
+	Frames []Frame
+}
+
+// Complete reports whether the channel has its last frame and every frame
+// before it.
+func (c *Channel) Complete() bool {
+	n := len(c.Frames)
+	return n > 0 && c.Frames[n-1].IsLast && int(c.Frames[n-1].Number) == n-1
+}
+
+// Data concatenates the frame data of a complete channel.
+func (c *Channel) Data() ([]byte, error) {
+	if !c.Complete() {
+		return nil, fmt.Errorf("channel %s is incomplete: have %d frames", c.ID, len(c.Frames))
+	}
+	var data []byte
+	for _, f := range c.Frames {
+		data = append(data, f.Data...)
+	}
+	return data, nil
+}
+
+// Channels groups frames by channel, in the order each channel first
+// appears. Frames repeating a number already seen are dropped, and frames
+// past the last one are ignored, as derivation does.
+func Channels(frames []Frame) []*Channel {
+	var (
+		channels []*Channel
+		byID     = map[ChannelID]*Channel{}
+	)
+	for _, f := range frames {
+		c := byID[f.ChannelID]
+		if c == nil {
+			c = &Channel{ID: f.ChannelID}
+			byID[f.ChannelID] = c
+			channels = append(channels, c)
+		}
+		dup := slices.ContainsFunc(c.Frames, func(g Frame) bool { return g.Number == f.Number })
+		if dup || slices.ContainsFunc(c.Frames, func(g Frame) bool { return g.IsLast && g.Number < f.Number }) {
+			continue
+		}
+		c.Frames = append(c.Frames, f)
+	}
+	for _, c := range channels {
+		slices.SortFunc(c.Frames, func(a, b Frame) int { return int(a.Number) - int(b.Number) })
+	}
+	return channels
+}
@@ -0,0 +1,196 @@
+// Package progress reports how far a long operation has got: as a status
+// line redrawn in place on a terminal, or as JSON lines for programs that
+// wrap the CLI.
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Format selects how a Tracker renders its reports.
+type Format int
+
+const (
+	// Text redraws a single status line with a carriage return, so it
+	// suits a terminal only.
+	Text Format = iota
+	// JSON writes one Update per line.
+	JSON
+)
+
+// interval is how often a Tracker reports while running.
+const interval = 500 * time.Millisecond
+
+// Update is one progress report. Percent and ETA are only set when the
+// total is known.
+type Update struct {
+	Operation string  `json:"operation"`
+	Done      int64   `json:"done"`
+	Total     int64   `json:"total,omitempty"`
+	Percent   float64 `json:"percent,omitempty"`
+	// Rate is blobs per second since the start.
+	Rate    float64 `json:"rate"`
+	Elapsed float64 `json:"elapsed_seconds"`
+	ETA     float64 `json:"eta_seconds,omitempty"`
+	// Finished is set on the last report, written by Finish.
+	Finished bool `json:"finished"`
+}
+
+// Tracker counts the blobs an operation has processed and reports its
+// progress periodically until Finish. A nil *Tracker is valid and reports
+// nothing, so callers can thread an optional tracker through.
+type Tracker struct {
+	w         io.Writer
+	format    Format
+	operation string
+	start     time.Time
+	done      atomic.Int64
+	total     atomic.Int64
+
+	mu       sync.Mutex // serializes writes
+	width    int        // of the last Text status line
+	stop     chan struct{}
+	stopped  chan struct{}
+	finished bool
+}
+
+// New starts a tracker reporting the progress of operation to w. A total
+// of zero or less means the number of blobs is not known in advance.
+func New(w io.Writer, format Format, operation string, total int64) *Tracker {
+	t := &Tracker{
+		w:         w,
+		format:    format,
+		operation: operation,
+		start:     time.Now(),
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	t.total.Store(max(total, 0))
+	go t.loop()
+	return t
+}
+
+func (t *Tracker) loop() {
+	defer close(t.stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.report(false)
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// Add records n more processed blobs.
+func (t *Tracker) Add(n int) {
+	if t == nil {
+		return
+	}
+	t.done.Add(int64(n))
+}
+
+// SetTotal sets the number of blobs the operation will process, once it is
+// known.
+func (t *Tracker) SetTotal(total int64) {
+	if t == nil {
+		return
+	}
+	t.total.Store(max(total, 0))
+}
+
+// Snapshot returns the current progress.
+func (t *Tracker) Snapshot() Update {
+	if t == nil {
+		return Update{}
+	}
+	elapsed := time.Since(t.start).Seconds()
+	u := Update{
+		Operation: t.operation,
+		Done:      t.done.Load(),
+		Total:     t.total.Load(),
+		Elapsed:   elapsed,
+	}
+	if elapsed > 0 {
+		u.Rate = float64(u.Done) / elapsed
+	}
+	if u.Total > 0 {
+		u.Percent = 100 * float64(min(u.Done, u.Total)) / float64(u.Total)
+		if u.Rate > 0 {
+			u.ETA = float64(max(u.Total-u.Done, 0)) / u.Rate
+		}
+	}
+	return u
+}
+
+// Finish stops the periodic reports and writes the final one. It is safe
+// to call more than once.
+func (t *Tracker) Finish() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	if t.finished {
+		t.mu.Unlock()
+		return
+	}
+	t.finished = true
+	t.mu.Unlock()
+
+	close(t.stop)
+	<-t.stopped
+	t.report(true)
+}
+
+func (t *Tracker) report(final bool) {
+	u := t.Snapshot()
+	u.Finished = final
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.format == JSON {
+		data, _ := json.Marshal(u)
+		t.w.Write(append(data, '\n'))
+		return
+	}
+	line := formatText(u)
+	pad := max(t.width-len(line), 0)
+	t.width = len(line)
+	end := ""
+	if final {
+		end = "\n"
+	}
+	fmt.Fprintf(t.w, "\r%s%s%s", line, strings.Repeat(" ", pad), end)
+}
+
+// formatText renders u as a status line, such as
+// "split: 42/160 blobs (26.3%), 8.1 blobs/s, ETA 14s".
+func formatText(u Update) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d", u.Operation, u.Done)
+	if u.Total > 0 {
+		fmt.Fprintf(&b, "/%d blobs (%.1f%%)", u.Total, u.Percent)
+	} else {
+		b.WriteString(" blobs")
+	}
+	fmt.Fprintf(&b, ", %.1f blobs/s", u.Rate)
+	switch {
+	case u.Finished:
+		fmt.Fprintf(&b, ", took %s", roundDuration(u.Elapsed))
+	case u.Total > 0 && u.Rate > 0:
+		fmt.Fprintf(&b, ", ETA %s", roundDuration(u.ETA))
+	}
+	return b.String()
+}
+
+func roundDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second)
+}
@@ -0,0 +1,191 @@
+// Package retry makes HTTP calls to execution, beacon and archival endpoints
+// tolerate flaky servers: every attempt has its own timeout, and attempts
+// that fail on the network or with a transient status are retried with
+// exponential backoff and jitter.
+package retry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Policy controls the timeouts and retries of HTTP requests.
+type Policy struct {
+	// Timeout bounds each attempt, including reading the response body.
+	// Zero leaves attempts bounded only by the request context.
+	Timeout time.Duration
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry. It doubles with
+	// each further retry up to MaxDelay.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff, including a server's Retry-After.
+	MaxDelay time.Duration
+}
+
+// DefaultPolicy is used when no other policy is configured.
+var DefaultPolicy = Policy{
+	Timeout:    30 * time.Second,
+	MaxRetries: 3,
+	BaseDelay:  250 * time.Millisecond,
+	MaxDelay:   10 * time.Second,
+}
+
+// Validate checks that the policy's settings are usable.
+func (p Policy) Validate() error {
+	switch {
+	case p.Timeout < 0:
+		return fmt.Errorf("invalid request timeout %s", p.Timeout)
+	case p.MaxRetries < 0:
+		return fmt.Errorf("invalid max retries %d", p.MaxRetries)
+	case p.BaseDelay < 0 || p.MaxDelay < 0:
+		return fmt.Errorf("invalid retry backoff %s, max %s", p.BaseDelay, p.MaxDelay)
+	}
+	return nil
+}
+
+// Backoff returns the delay before retry n, counting from 0: BaseDelay
+// doubled n times, capped at MaxDelay, with the upper half drawn at random
+// so that clients failing together do not retry together.
+func (p Policy) Backoff(n int) time.Duration {
+	d := p.BaseDelay
+	for i := 0; i < n && d > 0 && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, p.MaxDelay)
+	if d <= 1 {
+		return d
+	}
+	return d/2 + rand.N(d/2)
+}
+
+// Client returns an HTTP client that applies the policy to every request.
+func (p Policy) Client() *http.Client {
+	return &http.Client{Transport: p.Transport(http.DefaultTransport)}
+}
+
+// Transport wraps base so that each request is sent under the policy.
+// Request bodies are buffered so they can be sent again.
+func (p Policy) Transport(base http.RoundTripper) http.RoundTripper {
+	return &transport{policy: p, base: base}
+}
+
+type transport struct {
+	policy Policy
+	base   http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.GetBody == nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	}
+
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		resp, err := t.attempt(req)
+		if attempt == t.policy.MaxRetries || ctx.Err() != nil || !retryable(resp, err) {
+			return resp, err
+		}
+
+		delay := t.policy.Backoff(attempt)
+		if after, ok := retryAfter(resp); ok {
+			delay = min(max(delay, after), t.policy.MaxDelay)
+		}
+		failure := lastFailure(resp, err)
+		slog.Debug("Retrying request", "host", req.URL.Host, "attempt", attempt+1, "delay", delay, "failure", failure)
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w (after %d attempts, last: %s)", ctx.Err(), attempt+1, failure)
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends one copy of req under the per-attempt timeout. The timeout
+// keeps running while the caller reads the body and is released when the
+// body is closed.
+func (t *transport) attempt(req *http.Request) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.policy.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.policy.Timeout)
+	}
+	r := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		r.Body = body
+	}
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// retryable reports whether a failed attempt may succeed if repeated:
+// network errors and timeouts, rate limiting and gateway errors.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header given in seconds.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0, false
+	}
+	return time.Duration(secs) * time.Second, true
+}
+
+// lastFailure describes why an attempt failed.
+func lastFailure(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
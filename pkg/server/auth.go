@@ -0,0 +1,261 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"kzg-blob-poc/pkg/metrics"
+)
+
+// Errors of Auth, which the HTTP API answers with 401 and 429 and the gRPC
+// service with codes.Unauthenticated and codes.ResourceExhausted.
+var (
+	ErrUnauthorized = errors.New("missing or invalid API key or token")
+	ErrRateLimited  = errors.New("rate limit exceeded")
+)
+
+// JWTIssuedAtWindow is how far the iat claim of a JWT without an exp claim
+// may be from the current time, as the engine API allows, so a leaked
+// token stops working within a minute.
+const JWTIssuedAtWindow = 60 * time.Second
+
+// Key is an API key and the rate limit of its holder.
+type Key struct {
+	Name string
+	Key  string
+	// Rate is the requests per second allowed, or zero for no limit.
+	Rate float64
+	// Burst is how many requests beyond Rate may be made at once; it is
+	// at least one.
+	Burst int
+}
+
+// Auth authenticates requests by an API key, sent as a bearer token or in
+// an X-API-Key header, or by a JWT bearer token signed with HMAC-SHA256,
+// and limits the rate of each key or JWT subject. A nil *Auth lets every
+// request through.
+type Auth struct {
+	keys map[[32]byte]*client
+	// secret is the JWT secret, or nil to accept API keys only.
+	secret []byte
+	// rate and burst apply to JWT subjects.
+	rate  float64
+	burst int
+
+	mu       sync.Mutex
+	subjects map[string]*client
+}
+
+// client is an authenticated key or JWT subject.
+type client struct {
+	name    string
+	limiter *rate.Limiter // nil for no limit
+}
+
+func newClient(name string, r float64, burst int) *client {
+	c := &client{name: name}
+	if r > 0 {
+		c.limiter = rate.NewLimiter(rate.Limit(r), max(burst, 1))
+	}
+	return c
+}
+
+// NewAuth returns an Auth accepting keys and, unless jwtSecret is empty,
+// JWTs signed with it. Each JWT subject may make jwtRate requests per
+// second, with bursts of jwtBurst, or any number if jwtRate is zero.
+func NewAuth(keys []Key, jwtSecret []byte, jwtRate float64, jwtBurst int) (*Auth, error) {
+	if len(keys) == 0 && len(jwtSecret) == 0 {
+		return nil, errors.New("auth needs API keys or a JWT secret")
+	}
+	a := &Auth{
+		keys:     make(map[[32]byte]*client, len(keys)),
+		secret:   jwtSecret,
+		rate:     jwtRate,
+		burst:    jwtBurst,
+		subjects: make(map[string]*client),
+	}
+	for i, k := range keys {
+		name := k.Name
+		if name == "" {
+			name = fmt.Sprintf("key %d", i)
+		}
+		// Keys are looked up by their hash, so the lookup takes no longer
+		// for a near miss than for a wild guess.
+		h := sha256.Sum256([]byte(k.Key))
+		if _, ok := a.keys[h]; ok {
+			return nil, fmt.Errorf("%s: duplicate API key", name)
+		}
+		a.keys[h] = newClient(name, k.Rate, k.Burst)
+	}
+	return a, nil
+}
+
+// ReadJWTSecret reads a JWT secret file: 32 bytes as hex, with or without
+// 0x, as execution and consensus clients share it.
+func ReadJWTSecret(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT secret: %w", err)
+	}
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil || len(secret) != 32 {
+		return nil, fmt.Errorf("%s: invalid JWT secret: want 32 bytes of hex", path)
+	}
+	return secret, nil
+}
+
+// authorize charges the holder of token one request. It returns
+// ErrUnauthorized if token is neither a key nor a valid JWT, and
+// ErrRateLimited with the time to wait if its holder has no request left.
+func (a *Auth) authorize(token string) (time.Duration, error) {
+	if token == "" {
+		return 0, ErrUnauthorized
+	}
+	c, ok := a.keys[sha256.Sum256([]byte(token))]
+	if !ok && a.secret != nil {
+		c, ok = a.subject(token)
+	}
+	if !ok {
+		return 0, ErrUnauthorized
+	}
+	if c.limiter == nil {
+		return 0, nil
+	}
+	r := c.limiter.Reserve()
+	if d := r.Delay(); d > 0 {
+		r.Cancel()
+		metrics.ServerRejected.Inc()
+		return d, fmt.Errorf("%s: %w", c.name, ErrRateLimited)
+	}
+	return 0, nil
+}
+
+// subject returns the client of the subject of a valid JWT.
+func (a *Auth) subject(token string) (*client, bool) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return a.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !fresh(&claims, time.Now()) {
+		return nil, false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	c, ok := a.subjects[claims.Subject]
+	if !ok {
+		name := "jwt"
+		if claims.Subject != "" {
+			name += ":" + claims.Subject
+		}
+		c = newClient(name, a.rate, a.burst)
+		a.subjects[claims.Subject] = c
+	}
+	return c, true
+}
+
+// fresh reports whether claims bound the token's lifetime: by an exp claim,
+// which parsing has checked, or else by an iat claim within
+// JWTIssuedAtWindow of now. A token with neither would be valid forever.
+func fresh(claims *jwt.RegisteredClaims, now time.Time) bool {
+	if claims.ExpiresAt != nil {
+		return true
+	}
+	if claims.IssuedAt == nil {
+		return false
+	}
+	d := now.Sub(claims.IssuedAt.Time)
+	return d <= JWTIssuedAtWindow && d >= -JWTIssuedAtWindow
+}
+
+// bearer returns the token of an Authorization header value, or "".
+func bearer(header string) string {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// retryAfter returns d in whole seconds, rounded up, for a Retry-After
+// header.
+func retryAfter(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// authenticate lets requests to h through once a authorizes them,
+// answering 401 or 429 otherwise.
+func (a *Auth) authenticate(h http.Handler) http.Handler {
+	if a == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("X-API-Key")
+		if token == "" {
+			token = bearer(r.Header.Get("Authorization"))
+		}
+		wait, err := a.authorize(token)
+		switch {
+		case errors.Is(err, ErrRateLimited):
+			w.Header().Set("Retry-After", retryAfter(wait))
+			writeError(w, http.StatusTooManyRequests, err)
+		case err != nil:
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, err)
+		default:
+			h.ServeHTTP(w, r)
+		}
+	})
+}
+
+// check authorizes a gRPC call by the token in its authorization or
+// x-api-key metadata.
+func (a *Auth) check(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	if v := md.Get("x-api-key"); len(v) > 0 {
+		token = v[0]
+	} else if v := md.Get("authorization"); len(v) > 0 {
+		token = bearer(v[0])
+	}
+	_, err := a.authorize(token)
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case err != nil:
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return nil
+}
+
+// unaryInterceptor authorizes each unary call.
+func (a *Auth) unaryInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := a.check(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamInterceptor authorizes each stream.
+func (a *Auth) streamInterceptor(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.check(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"kzg-blob-poc/pkg/blob"
+	"kzg-blob-poc/pkg/blobpb"
+)
+
+// NewGRPCServer returns a gRPC server with the BlobService registered. With
+// opts.Limiter, calls that find its queue full fail with
+// codes.ResourceExhausted. With opts.Auth, calls must carry an API key or
+// JWT in their authorization or x-api-key metadata.
+func NewGRPCServer(opts Options, serverOpts ...grpc.ServerOption) *grpc.Server {
+	if opts.Auth != nil {
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(opts.Auth.unaryInterceptor),
+			grpc.ChainStreamInterceptor(opts.Auth.streamInterceptor))
+	}
+	if opts.Limiter != nil {
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(opts.Limiter.unaryInterceptor),
+			grpc.ChainStreamInterceptor(opts.Limiter.streamInterceptor))
+	}
+	s := grpc.NewServer(serverOpts...)
+	blobpb.RegisterBlobServiceServer(s, BlobService{Options: opts})
+	return s
+}
+
+// BlobService implements blobpb.BlobServiceServer. Calls that run out of
+// time for their KZG work fail with codes.DeadlineExceeded.
+type BlobService struct {
+	blobpb.UnimplementedBlobServiceServer
+	Options
+}
+
+func (s BlobService) Commit(ctx context.Context, req *blobpb.BlobRequest) (*blobpb.Artifacts, error) {
+	b, err := blob.NewBlobFromBytes(req.GetBlob())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	commitment, err := compute(ctx, s.Options, &b, blob.Commit)
+	if isTimeout(ctx, err) {
+		return nil, timeoutStatus(ctx, err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to generate KZG commitment: %v", err)
+	}
+	vh := blob.VersionedHash(commitment)
+	return &blobpb.Artifacts{Commitment: commitment[:], VersionedHash: vh[:]}, nil
+}
+
+func (s BlobService) Prove(ctx context.Context, req *blobpb.BlobRequest) (*blobpb.Artifacts, error) {
+	b, err := blob.NewBlobFromBytes(req.GetBlob())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	a, err := s.artifacts(ctx, &b, true, false)
+	if isTimeout(ctx, err) {
+		return nil, timeoutStatus(ctx, err)
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return newPBArtifacts(a), nil
+}
+
+func (s BlobService) Verify(ctx context.Context, req *blobpb.VerifyRequest) (*blobpb.VerifyResponse, error) {
+	b, err := blob.NewBlobFromBytes(req.GetBlob())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var (
+		commitment kzg4844.Commitment
+		proof      kzg4844.Proof
+	)
+	if err := copyFixed("commitment", commitment[:], req.GetCommitment()); err != nil {
+		return nil, err
+	}
+	if err := copyFixed("proof", proof[:], req.GetProof()); err != nil {
+		return nil, err
+	}
+	var versionedHash *common.Hash
+	if len(req.GetVersionedHash()) > 0 {
+		versionedHash = new(common.Hash)
+		if err := copyFixed("versioned hash", versionedHash[:], req.GetVersionedHash()); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = compute(ctx, s.Options, &b, func(b *kzg4844.Blob) (struct{}, error) {
+		return struct{}{}, blob.Verify(b, commitment, proof)
+	})
+	if isTimeout(ctx, err) {
+		return nil, timeoutStatus(ctx, err)
+	}
+	if err == nil && versionedHash != nil {
+		err = blob.CheckVersionedHash(commitment, *versionedHash)
+	}
+	resp := &blobpb.VerifyResponse{Valid: err == nil}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp, nil
+}
+
+// Split packs the streamed payload into blobs following blob.ChunkLayout,
+// sending each blob as soon as MaxPackedSize bytes have been received.
+func (s BlobService) Split(stream grpc.BidiStreamingServer[blobpb.PayloadChunk, blobpb.SplitBlob]) error {
+	var (
+		buf    []byte
+		chunk  blob.Chunk
+		closed bool
+	)
+	for !closed {
+		msg, err := stream.Recv()
+		switch {
+		case errors.Is(err, io.EOF):
+			closed = true
+		case err != nil:
+			return err
+		default:
+			buf = append(buf, msg.GetData()...)
+		}
+
+		// Flush full blobs, and on EOF whatever remains. An empty payload
+		// still produces a single empty blob.
+		for len(buf) >= blob.MaxPackedSize || (closed && (len(buf) > 0 || chunk.Index == 0)) {
+			chunk.Size = min(len(buf), blob.MaxPackedSize)
+			if err := s.sendSplitBlob(stream, chunk, buf[:chunk.Size]); err != nil {
+				return err
+			}
+			buf = buf[chunk.Size:]
+			chunk = blob.Chunk{Index: chunk.Index + 1, Offset: chunk.Offset + chunk.Size}
+		}
+	}
+	return nil
+}
+
+func (s BlobService) sendSplitBlob(stream grpc.BidiStreamingServer[blobpb.PayloadChunk, blobpb.SplitBlob], c blob.Chunk, data []byte) error {
+	b, err := blob.Pack(data)
+	if err != nil {
+		return status.Errorf(codes.Internal, "chunk %d: %v", c.Index, err)
+	}
+	a, err := s.artifacts(stream.Context(), &b, true, false)
+	if isTimeout(stream.Context(), err) {
+		return timeoutStatus(stream.Context(), fmt.Errorf("chunk %d: %w", c.Index, err))
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "chunk %d: %v", c.Index, err)
+	}
+	return stream.Send(&blobpb.SplitBlob{
+		Index:     int64(c.Index),
+		Offset:    int64(c.Offset),
+		Size:      int64(c.Size),
+		Blob:      b[:],
+		Artifacts: newPBArtifacts(a),
+	})
+}
+
+func newPBArtifacts(a *blob.BlobArtifacts) *blobpb.Artifacts {
+	return &blobpb.Artifacts{
+		Commitment:    a.Commitment[:],
+		Proof:         a.Proof[:],
+		VersionedHash: a.VersionedHash[:],
+	}
+}
+
+// timeoutStatus returns the status of a call whose KZG work was cut short:
+// DeadlineExceeded when it ran out of time, or the status of ctx when the
+// client cancelled it or its own deadline passed.
+func timeoutStatus(ctx context.Context, err error) error {
+	if errors.Is(err, blob.ErrTimeout) {
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return status.FromContextError(ctx.Err()).Err()
+}
+
+// copyFixed copies src into dst, which must be exactly the same length.
+func copyFixed(name string, dst, src []byte) error {
+	if len(src) != len(dst) {
+		return status.Errorf(codes.InvalidArgument, "invalid %s: got %d bytes, want %d", name, len(src), len(dst))
+	}
+	copy(dst, src)
+	return nil
+}
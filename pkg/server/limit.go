@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"kzg-blob-poc/pkg/metrics"
+)
+
+// ErrBusy means a server had no room to queue another request.
+var ErrBusy = errors.New("server busy, retry later")
+
+// RetryAfter is how long a client turned away with ErrBusy is asked to
+// wait, in the Retry-After header of the 429 response.
+const RetryAfter = time.Second
+
+// Limiter bounds the KZG work a server does at once. Up to concurrent
+// operations run at a time, up to queue further requests wait for one of
+// them to finish, and requests beyond that are turned away with ErrBusy
+// before their body is read, so a burst of uploads holds a bounded amount
+// of memory. A nil *Limiter imposes no limit.
+type Limiter struct {
+	slots    chan struct{}
+	admitted chan struct{}
+}
+
+// NewLimiter returns a limiter running up to concurrent KZG operations and
+// queueing up to queue more requests. A concurrent value of zero or less
+// allows one operation at a time.
+func NewLimiter(concurrent, queue int) *Limiter {
+	concurrent = max(concurrent, 1)
+	return &Limiter{
+		slots:    make(chan struct{}, concurrent),
+		admitted: make(chan struct{}, concurrent+max(queue, 0)),
+	}
+}
+
+// admit takes a place for a request, or returns ErrBusy if every slot and
+// queue place is taken. release gives the place back.
+func (l *Limiter) admit() (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.admitted <- struct{}{}:
+		return func() { <-l.admitted }, nil
+	default:
+		metrics.ServerRejected.Inc()
+		return nil, ErrBusy
+	}
+}
+
+// acquire waits for a slot to run a KZG operation in, or returns ctx.Err()
+// if ctx is done first. release gives the slot back.
+func (l *Limiter) acquire(ctx context.Context) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return func() { <-l.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// limit admits each request to h through l, answering 429 with a
+// Retry-After header when l is full.
+func (l *Limiter) limit(h http.Handler) http.Handler {
+	if l == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		release, err := l.admit()
+		if err != nil {
+			w.Header().Set("Retry-After", retryAfter(RetryAfter))
+			writeError(w, http.StatusTooManyRequests, err)
+			return
+		}
+		defer release()
+		h.ServeHTTP(w, r)
+	})
+}
+
+// unaryInterceptor admits each unary call through l, failing it with
+// codes.ResourceExhausted when l is full.
+func (l *Limiter) unaryInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	release, err := l.admit()
+	if err != nil {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	defer release()
+	return handler(ctx, req)
+}
+
+// streamInterceptor admits each stream through l, as unaryInterceptor does
+// for unary calls.
+func (l *Limiter) streamInterceptor(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	release, err := l.admit()
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	defer release()
+	return handler(srv, ss)
+}
@@ -0,0 +1,323 @@
+// Package server exposes the blob operations over HTTP so that services not
+// written in Go can use them without shelling out to the CLI.
+package server
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// maxBodySize bounds request bodies. It leaves room for a full blob sent as
+// hex text with line breaks.
+const maxBodySize = int64(3 * blob.Size)
+
+// NewHandler returns the HTTP API:
+//
+//	POST /commit  blob body               -> {commitment, versioned_hash}
+//	POST /prove   blob body               -> {commitment, proof, versioned_hash}
+//	POST /commit  multipart blobs         -> {blobs: [{index, name, size, commitment, versioned_hash}]}
+//	POST /prove   multipart blobs         -> {blobs: [{index, name, size, commitment, proof, versioned_hash}]}
+//	POST /verify  JSON or blob body       -> {valid, error}
+//	POST /encode  payload body            -> {payload_size, commitment, proof, versioned_hash, blob_hex}
+//
+// /encode frames the payload with blob.EncodeFramed, compressed with the
+// algorithm named by the compress query parameter, or packs it unframed with
+// the raw query parameter.
+//
+// The KZG work of each request is bounded by opts.Timeout and ends early
+// when the client goes away; a request that runs out of time gets 503. With
+// opts.Limiter, a request that finds the queue full gets 429. With
+// opts.Auth, a request without a valid API key or JWT gets 401, and one
+// beyond its key's rate limit 429 with a Retry-After header.
+//
+// Bodies sent as application/octet-stream are raw bytes and bodies sent as
+// text/plain are hex. With any other content type the body is treated as hex
+// when it starts like hex text and as raw bytes otherwise. The same holds
+// for each part of a multipart/form-data body, by its own content type.
+// Blobs are decoded as they arrive, and one larger than blob.Size gets 413
+// without the rest being read.
+func NewHandler(opts Options) http.Handler {
+	h := handler{opts}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /commit", h.handleCommit)
+	mux.HandleFunc("POST /prove", h.handleProve)
+	mux.HandleFunc("POST /verify", h.handleVerify)
+	mux.HandleFunc("POST /encode", h.handleEncode)
+	return h.Auth.authenticate(h.Limiter.limit(mux))
+}
+
+// Options configures the HTTP API and the gRPC service.
+type Options struct {
+	// Timeout, when positive, bounds the KZG work on each blob. See
+	// blob.WithTimeout.
+	Timeout time.Duration
+	// Limiter, when set, bounds the KZG operations run and the requests
+	// queued at once. The HTTP API and the gRPC service may share one.
+	Limiter *Limiter
+	// Auth, when set, admits only requests with a valid API key or JWT,
+	// within the rate limit of their key.
+	Auth *Auth
+	// MaxBlobs is the most blobs a multipart upload may carry, or
+	// DefaultMaxBlobs if zero.
+	MaxBlobs int
+}
+
+// handler serves the HTTP API with its options.
+type handler struct {
+	Options
+}
+
+// CommitResponse is the result of POST /commit.
+type CommitResponse struct {
+	Commitment    kzg4844.Commitment `json:"commitment"`
+	VersionedHash common.Hash        `json:"versioned_hash"`
+}
+
+// VerifyRequest is the JSON body accepted by POST /verify. VersionedHash is
+// optional; when set it must match the commitment.
+type VerifyRequest struct {
+	Blob          kzg4844.Blob       `json:"blob"`
+	Commitment    kzg4844.Commitment `json:"commitment"`
+	Proof         kzg4844.Proof      `json:"proof"`
+	VersionedHash *common.Hash       `json:"versioned_hash,omitempty"`
+}
+
+// VerifyResponse is the result of POST /verify. A proof that does not verify
+// is reported with Valid false and status 200; malformed requests get 400.
+type VerifyResponse struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// EncodeResponse is the result of POST /encode.
+type EncodeResponse struct {
+	PayloadSize int `json:"payload_size"`
+	*blob.BlobArtifacts
+}
+
+// errorResponse is the body of every non-2xx response.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (h handler) handleCommit(w http.ResponseWriter, r *http.Request) {
+	if mediaType(r) == "multipart/form-data" {
+		h.handleUpload(w, r, false)
+		return
+	}
+	b, err := readBlob(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	commitment, err := compute(r.Context(), h.Options, &b, blob.Commit)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Errorf("failed to generate KZG commitment: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, CommitResponse{
+		Commitment:    commitment,
+		VersionedHash: blob.VersionedHash(commitment),
+	})
+}
+
+func (h handler) handleProve(w http.ResponseWriter, r *http.Request) {
+	if mediaType(r) == "multipart/form-data" {
+		h.handleUpload(w, r, true)
+		return
+	}
+	b, err := readBlob(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	a, err := h.artifacts(r.Context(), &b, true, r.URL.Query().Has("include_blob"))
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, a)
+}
+
+// handleVerify accepts either a VerifyRequest as JSON or a blob body with
+// the commitment, proof and optional versioned_hash as query parameters.
+func (h handler) handleVerify(w http.ResponseWriter, r *http.Request) {
+	var req VerifyRequest
+	if mediaType(r) == "application/json" {
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err := dec.Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+			return
+		}
+	} else {
+		var err error
+		if req, err = verifyRequestFromQuery(r); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if req.Blob, err = readBlob(w, r); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	_, err := compute(r.Context(), h.Options, &req.Blob, func(b *kzg4844.Blob) (struct{}, error) {
+		return struct{}{}, blob.Verify(b, req.Commitment, req.Proof)
+	})
+	if isTimeout(r.Context(), err) {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	if err == nil && req.VersionedHash != nil {
+		err = blob.CheckVersionedHash(req.Commitment, *req.VersionedHash)
+	}
+	resp := VerifyResponse{Valid: err == nil}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h handler) handleEncode(w http.ResponseWriter, r *http.Request) {
+	payload, err := readBody(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	c, err := blob.ParseCompression(r.URL.Query().Get("compress"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var b kzg4844.Blob
+	if r.URL.Query().Has("raw") {
+		b, err = blob.Pack(payload)
+	} else {
+		b, err = blob.EncodeFramedCompressed(payload, c)
+	}
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	a, err := h.artifacts(r.Context(), &b, true, true)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, EncodeResponse{PayloadSize: len(payload), BlobArtifacts: a})
+}
+
+// compute runs f on b in a slot of o.Limiter and within o.Timeout. The
+// slot is held until f returns, even when the caller has stopped waiting
+// for it, so abandoned computations still count against the limit.
+func compute[T any](ctx context.Context, o Options, b *kzg4844.Blob, f func(*kzg4844.Blob) (T, error)) (T, error) {
+	release, err := o.Limiter.acquire(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return blob.WithTimeout(ctx, o.Timeout, b, func(b *kzg4844.Blob) (T, error) {
+		defer release()
+		return f(b)
+	})
+}
+
+// isTimeout reports whether err ended a computation for ctx, because it
+// ran out of time or ctx is done, rather than the computation failing.
+func isTimeout(ctx context.Context, err error) bool {
+	return errors.Is(err, blob.ErrTimeout) || (err != nil && ctx.Err() != nil)
+}
+
+// verifyRequestFromQuery reads the verification parameters of a non-JSON
+// POST /verify from the query string.
+func verifyRequestFromQuery(r *http.Request) (VerifyRequest, error) {
+	var req VerifyRequest
+	q := r.URL.Query()
+	if q.Get("commitment") == "" || q.Get("proof") == "" {
+		return req, errors.New("commitment and proof query parameters are required")
+	}
+	if err := hexutil.UnmarshalFixedText("commitment", []byte(q.Get("commitment")), req.Commitment[:]); err != nil {
+		return req, fmt.Errorf("invalid commitment: %w", err)
+	}
+	if err := hexutil.UnmarshalFixedText("proof", []byte(q.Get("proof")), req.Proof[:]); err != nil {
+		return req, fmt.Errorf("invalid proof: %w", err)
+	}
+	if s := q.Get("versioned_hash"); s != "" {
+		var h common.Hash
+		if err := h.UnmarshalText([]byte(s)); err != nil {
+			return req, fmt.Errorf("invalid versioned hash: %w", err)
+		}
+		req.VersionedHash = &h
+	}
+	return req, nil
+}
+
+// readBlob reads a blob from the request body as it arrives, as
+// decodeBlob does.
+func readBlob(w http.ResponseWriter, r *http.Request) (kzg4844.Blob, error) {
+	b, _, err := decodeBlob(http.MaxBytesReader(w, r.Body, maxBodySize), r.ContentLength, mediaType(r))
+	return b, err
+}
+
+// readBody returns the request body, decoding it from hex when it was sent
+// as hex text.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	switch mediaType(r) {
+	case "application/octet-stream":
+		return data, nil
+	case "text/plain":
+	default:
+		if !blob.IsHexText(data) {
+			return data, nil
+		}
+	}
+	hexStr := strings.TrimPrefix(strings.Join(strings.Fields(string(data)), ""), "0x")
+	decoded, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode hex body: %w", err)
+	}
+	return decoded, nil
+}
+
+// mediaType returns the media type of the request's Content-Type header.
+func mediaType(r *http.Request) string {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mt
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	var maxErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxErr), errors.Is(err, blob.ErrBlobTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, blob.ErrTimeout):
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
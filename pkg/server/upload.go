@@ -0,0 +1,201 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// DefaultMaxBlobs is the number of blobs a multipart upload may carry when
+// Options.MaxBlobs is not set.
+const DefaultMaxBlobs = 16
+
+// sniffLen is how much of a body of unknown type is looked at to tell hex
+// text from raw bytes.
+const sniffLen = 512
+
+// UploadManifest is the result of a multipart POST /commit or /prove: the
+// artifacts of each uploaded blob, in the order of the parts.
+type UploadManifest struct {
+	Blobs []UploadedBlob `json:"blobs"`
+}
+
+// UploadedBlob is one blob of a multipart upload. Name is the file name of
+// its part, or the form field name if it has none, and Size the number of
+// bytes received, before the blob was zero-padded.
+type UploadedBlob struct {
+	Index int    `json:"index"`
+	Name  string `json:"name,omitempty"`
+	Size  int    `json:"size"`
+	*blob.BlobArtifacts
+}
+
+// handleUpload computes the artifacts of every part of a multipart body, with
+// the proofs if prove is set. The parts are read one at a time as they
+// arrive, so only one blob is held at once, and a part larger than a blob
+// fails the request as soon as its excess arrives.
+func (h handler) handleUpload(w http.ResponseWriter, r *http.Request, prove bool) {
+	maxBlobs := h.MaxBlobs
+	if maxBlobs <= 0 {
+		maxBlobs = DefaultMaxBlobs
+	}
+	// Each part may be hex text, and the headers and boundaries of all of
+	// them fit in a further blob's worth.
+	limit := int64(maxBlobs+1) * maxBodySize
+	if r.ContentLength > limit {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("%d-byte body, max %d bytes for %d blobs", r.ContentLength, limit, maxBlobs))
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	includeBlob := r.URL.Query().Has("include_blob")
+	m := UploadManifest{Blobs: []UploadedBlob{}}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid multipart body: %w", err))
+			return
+		}
+		i := len(m.Blobs)
+		name := part.FileName()
+		if name == "" {
+			name = part.FormName()
+		}
+		if i == maxBlobs {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("more than %d blobs", maxBlobs))
+			return
+		}
+		mt, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		b, size, err := decodeBlob(part, -1, mt)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("part %d (%s): %w", i, name, err))
+			return
+		}
+		a, err := h.artifacts(r.Context(), &b, prove, includeBlob)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Errorf("part %d (%s): %w", i, name, err))
+			return
+		}
+		m.Blobs = append(m.Blobs, UploadedBlob{Index: i, Name: name, Size: size, BlobArtifacts: a})
+	}
+	if len(m.Blobs) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("no blobs in the multipart body"))
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+// decodeBlob reads a blob of media type mt from r as it arrives,
+// zero-padding short input. size is the declared length of the body, or -1
+// if unknown. A body declared larger than a blob is rejected before any of
+// it is read, and one that turns out larger as soon as the excess arrives.
+// It returns the blob and the number of bytes received.
+func decodeBlob(r io.Reader, size int64, mt string) (kzg4844.Blob, int, error) {
+	var b kzg4844.Blob
+	br := bufio.NewReaderSize(r, sniffLen)
+	isHex := mt == "text/plain"
+	if mt != "application/octet-stream" && !isHex {
+		head, _ := br.Peek(sniffLen)
+		isHex = blob.IsHexText(head)
+	}
+	limit := int64(blob.Size)
+	if isHex {
+		limit = maxBodySize
+	}
+	if size > limit {
+		return b, 0, fmt.Errorf("%w: %d-byte body, max %d bytes", blob.ErrBlobTooLarge, size, limit)
+	}
+
+	var (
+		src    io.Reader = br
+		digits *hexDigits
+	)
+	if isHex {
+		digits = &hexDigits{r: br}
+		src = hex.NewDecoder(digits)
+	}
+	n, err := io.ReadFull(src, b[:])
+	if err == nil {
+		// A full blob: anything after it is too much.
+		var extra [1]byte
+		var m int
+		if m, err = io.ReadFull(src, extra[:]); m > 0 {
+			return b, n, fmt.Errorf("%w: more than %d bytes", blob.ErrBlobTooLarge, blob.Size)
+		}
+	}
+	if digits != nil && digits.n%2 != 0 {
+		return b, n, errors.New("failed to decode hex body: odd number of hex digits")
+	}
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		if isHex {
+			return b, n, fmt.Errorf("failed to decode hex body: %w", err)
+		}
+		return b, n, fmt.Errorf("failed to read body: %w", err)
+	}
+	return b, n, nil
+}
+
+// hexDigits passes on the hex digits of hex text, dropping whitespace and
+// a leading 0x, and counts them.
+type hexDigits struct {
+	r    *bufio.Reader
+	n    int
+	seen bool // whether a character other than whitespace was read
+}
+
+func (h *hexDigits) Read(p []byte) (int, error) {
+	i := 0
+	for i < len(p) {
+		c, err := h.r.ReadByte()
+		if err != nil {
+			if i > 0 {
+				return i, nil
+			}
+			return 0, err
+		}
+		switch c {
+		case ' ', '\n', '\r', '\t':
+			continue
+		case '0':
+			if next, _ := h.r.Peek(1); !h.seen && len(next) == 1 && next[0] == 'x' {
+				h.r.ReadByte()
+				h.seen = true
+				continue
+			}
+		}
+		h.seen = true
+		p[i] = c
+		i++
+		h.n++
+	}
+	return i, nil
+}
+
+// artifacts returns the artifacts of b, without the proof unless prove is
+// set, computed as compute does.
+func (o Options) artifacts(ctx context.Context, b *kzg4844.Blob, prove, includeBlob bool) (*blob.BlobArtifacts, error) {
+	f := blob.CommitOnly
+	if prove {
+		f = blob.NewArtifacts
+	}
+	return compute(ctx, o, b, func(b *kzg4844.Blob) (*blob.BlobArtifacts, error) {
+		return f(b, includeBlob)
+	})
+}
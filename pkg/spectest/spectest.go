@@ -0,0 +1,379 @@
+// Package spectest runs the KZG reference test vectors published with the
+// consensus specs (tests/general/deneb/kzg) and c-kzg-4844 (tests/) against
+// pkg/blob.
+//
+// Every case is a data.yaml (or data.json) file of the form
+//
+//	input: {...}
+//	output: <value, or null when the input must be rejected>
+//
+// in a directory laid out as <handler>/<suite>/<case>/data.yaml, where the
+// handler names the function under test.
+package spectest
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"gopkg.in/yaml.v3"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// Status is the outcome of a test case.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	// StatusSkip marks cases of handlers this package does not run.
+	StatusSkip Status = "skip"
+)
+
+// Case is a single test vector file.
+type Case struct {
+	Handler string `json:"handler"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+}
+
+// Result is the outcome of running a Case.
+type Result struct {
+	Case
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// handler computes the output of a test case from its input. An error means
+// the input was rejected, which the vector expects when its output is null.
+type handler func(input *yaml.Node) (any, error)
+
+var handlers = map[string]handler{
+	"blob_to_kzg_commitment":      blobToCommitment,
+	"compute_blob_kzg_proof":      computeBlobProof,
+	"verify_blob_kzg_proof":       verifyBlobProof,
+	"verify_blob_kzg_proof_batch": verifyBlobProofBatch,
+	"compute_kzg_proof":           computeProof,
+	"verify_kzg_proof":            verifyProof,
+}
+
+// Handlers returns the names of the handlers Run supports, sorted.
+func Handlers() []string {
+	names := make([]string, 0, len(handlers))
+	for name := range handlers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Discover finds the test cases under root, in lexical order. The handler of
+// a case is the nearest directory above it, up to and including root, that
+// names a supported handler, so root can be a whole test tree or a single
+// handler's directory. Cases under no such directory are attributed to the
+// directory two levels up, the handler in the usual layout, and skipped.
+func Discover(root string) ([]Case, error) {
+	root = filepath.Clean(root)
+	var cases []Case
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || (d.Name() != "data.yaml" && d.Name() != "data.json") {
+			return nil
+		}
+		dir := filepath.Dir(path)
+		c := Case{
+			Name:    filepath.Base(dir),
+			Path:    path,
+			Handler: filepath.Base(filepath.Dir(filepath.Dir(dir))),
+		}
+		for p := filepath.Dir(dir); len(p) >= len(root); p = filepath.Dir(p) {
+			if _, ok := handlers[filepath.Base(p)]; ok {
+				c.Handler = filepath.Base(p)
+				break
+			}
+			if p == root {
+				break
+			}
+		}
+		cases = append(cases, c)
+		return nil
+	})
+	return cases, err
+}
+
+// Run executes c and compares the result with the expected output.
+func Run(c Case) Result {
+	r := Result{Case: c}
+	h, ok := handlers[c.Handler]
+	if !ok {
+		r.Status = StatusSkip
+		return r
+	}
+	if err := run(c.Path, h); err != nil {
+		r.Status, r.Error = StatusFail, err.Error()
+		return r
+	}
+	r.Status = StatusPass
+	return r
+}
+
+func run(path string, h handler) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var vector struct {
+		Input  yaml.Node `yaml:"input"`
+		Output yaml.Node `yaml:"output"`
+	}
+	if err := yaml.Unmarshal(data, &vector); err != nil {
+		return fmt.Errorf("failed to parse test vector: %w", err)
+	}
+
+	got, err := h(&vector.Input)
+	if vector.Output.Kind == 0 || vector.Output.Tag == "!!null" {
+		if err == nil {
+			return fmt.Errorf("expected the input to be rejected, got %v", got)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+	var want any
+	if err := vector.Output.Decode(&want); err != nil {
+		return fmt.Errorf("failed to parse expected output: %w", err)
+	}
+	if !reflect.DeepEqual(got, normalize(want)) {
+		return fmt.Errorf("got %v, want %v", got, want)
+	}
+	return nil
+}
+
+// normalize lowercases the hex strings of an expected output so they
+// compare equal to hexutil.Encode results.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case string:
+		return strings.ToLower(v)
+	case []any:
+		for i := range v {
+			v[i] = normalize(v[i])
+		}
+	}
+	return v
+}
+
+func blobToCommitment(input *yaml.Node) (any, error) {
+	var in struct {
+		Blob string `yaml:"blob"`
+	}
+	if err := input.Decode(&in); err != nil {
+		return nil, err
+	}
+	b, err := parseBlob(in.Blob)
+	if err != nil {
+		return nil, err
+	}
+	commitment, err := blob.Commit(&b)
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.Encode(commitment[:]), nil
+}
+
+func computeBlobProof(input *yaml.Node) (any, error) {
+	var in struct {
+		Blob       string `yaml:"blob"`
+		Commitment string `yaml:"commitment"`
+	}
+	if err := input.Decode(&in); err != nil {
+		return nil, err
+	}
+	b, err := parseBlob(in.Blob)
+	if err != nil {
+		return nil, err
+	}
+	var commitment kzg4844.Commitment
+	if err := parseFixed("commitment", in.Commitment, commitment[:]); err != nil {
+		return nil, err
+	}
+	proof, err := blob.Prove(&b, commitment)
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.Encode(proof[:]), nil
+}
+
+func verifyBlobProof(input *yaml.Node) (any, error) {
+	var in struct {
+		Blob       string `yaml:"blob"`
+		Commitment string `yaml:"commitment"`
+		Proof      string `yaml:"proof"`
+	}
+	if err := input.Decode(&in); err != nil {
+		return nil, err
+	}
+	b, err := parseBlob(in.Blob)
+	if err != nil {
+		return nil, err
+	}
+	commitment, err := parseG1[kzg4844.Commitment]("commitment", in.Commitment)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := parseG1[kzg4844.Proof]("proof", in.Proof)
+	if err != nil {
+		return nil, err
+	}
+	return blob.Verify(&b, commitment, proof) == nil, nil
+}
+
+func verifyBlobProofBatch(input *yaml.Node) (any, error) {
+	var in struct {
+		Blobs       []string `yaml:"blobs"`
+		Commitments []string `yaml:"commitments"`
+		Proofs      []string `yaml:"proofs"`
+	}
+	if err := input.Decode(&in); err != nil {
+		return nil, err
+	}
+	if len(in.Blobs) != len(in.Commitments) || len(in.Blobs) != len(in.Proofs) {
+		return nil, fmt.Errorf("mismatched lengths: %d blobs, %d commitments, %d proofs",
+			len(in.Blobs), len(in.Commitments), len(in.Proofs))
+	}
+	var (
+		blobs       = make([]kzg4844.Blob, len(in.Blobs))
+		commitments = make([]kzg4844.Commitment, len(in.Blobs))
+		proofs      = make([]kzg4844.Proof, len(in.Blobs))
+		err         error
+	)
+	for i := range in.Blobs {
+		if blobs[i], err = parseBlob(in.Blobs[i]); err != nil {
+			return nil, fmt.Errorf("blob %d: %w", i, err)
+		}
+		if commitments[i], err = parseG1[kzg4844.Commitment]("commitment", in.Commitments[i]); err != nil {
+			return nil, fmt.Errorf("blob %d: %w", i, err)
+		}
+		if proofs[i], err = parseG1[kzg4844.Proof]("proof", in.Proofs[i]); err != nil {
+			return nil, fmt.Errorf("blob %d: %w", i, err)
+		}
+	}
+	if len(blobs) == 0 {
+		return true, nil
+	}
+	return blob.VerifyBlobProofBatch(blobs, commitments, proofs) == nil, nil
+}
+
+func computeProof(input *yaml.Node) (any, error) {
+	var in struct {
+		Blob string `yaml:"blob"`
+		Z    string `yaml:"z"`
+	}
+	if err := input.Decode(&in); err != nil {
+		return nil, err
+	}
+	b, err := parseBlob(in.Blob)
+	if err != nil {
+		return nil, err
+	}
+	z, err := parseScalar("z", in.Z)
+	if err != nil {
+		return nil, err
+	}
+	proof, y, err := blob.ProveAt(&b, z)
+	if err != nil {
+		return nil, err
+	}
+	return []any{hexutil.Encode(proof[:]), hexutil.Encode(y[:])}, nil
+}
+
+func verifyProof(input *yaml.Node) (any, error) {
+	var in struct {
+		Commitment string `yaml:"commitment"`
+		Z          string `yaml:"z"`
+		Y          string `yaml:"y"`
+		Proof      string `yaml:"proof"`
+	}
+	if err := input.Decode(&in); err != nil {
+		return nil, err
+	}
+	commitment, err := parseG1[kzg4844.Commitment]("commitment", in.Commitment)
+	if err != nil {
+		return nil, err
+	}
+	z, err := parseScalar("z", in.Z)
+	if err != nil {
+		return nil, err
+	}
+	y, err := parseScalar("y", in.Y)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := parseG1[kzg4844.Proof]("proof", in.Proof)
+	if err != nil {
+		return nil, err
+	}
+	return blob.VerifyAt(commitment, z, kzg4844.Claim(y), proof) == nil, nil
+}
+
+// parseBlob decodes a hex blob of exactly blob.Size bytes with canonical
+// field elements.
+func parseBlob(s string) (kzg4844.Blob, error) {
+	var b kzg4844.Blob
+	if err := parseFixed("blob", s, b[:]); err != nil {
+		return b, err
+	}
+	return b, blob.ValidateBlob(&b)
+}
+
+// parseScalar decodes a canonical 32-byte big-endian field element.
+func parseScalar(name, s string) (kzg4844.Point, error) {
+	var z kzg4844.Point
+	if err := parseFixed(name, s, z[:]); err != nil {
+		return z, err
+	}
+	if string(z[:]) >= string(blob.BLSModulus[:]) {
+		return z, fmt.Errorf("%w: %s is not below the BLS12-381 modulus", blob.ErrInvalidFieldElement, name)
+	}
+	return z, nil
+}
+
+// parseG1 decodes a compressed G1 point as the spec's validate_kzg_g1 does:
+// it must be on the curve and in the subgroup, or the point at infinity.
+// The verify handlers check this up front because the KZG libraries report
+// a rejected point and a proof that fails to verify with the same error.
+func parseG1[T ~[48]byte](name, s string) (T, error) {
+	var p T
+	if err := parseFixed(name, s, p[:]); err != nil {
+		return p, err
+	}
+	var point bls12381.G1Affine
+	if _, err := point.SetBytes(p[:]); err != nil {
+		return p, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return p, nil
+}
+
+// parseFixed decodes 0x-prefixed hex into out, which it must fill exactly.
+func parseFixed(name, s string, out []byte) error {
+	data, err := hexutil.Decode(s)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if len(data) != len(out) {
+		return fmt.Errorf("invalid %s: %d bytes, want %d", name, len(data), len(out))
+	}
+	copy(out, data)
+	return nil
+}
@@ -0,0 +1,98 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// DefaultPriceBump is the fee increase, in percent, that geth's blob pool
+// requires of a replacement blob transaction: every fee cap and the tip must
+// double.
+const DefaultPriceBump = 100
+
+// ReplacementParams returns the parameters of a transaction replacing old:
+// the same nonce, recipient, value, calldata and gas, with the tip and both
+// fee caps raised by percent. The fee caps are raised further when needed to
+// match what Fill would pick at the current base fees, so a replacement for
+// a transaction stuck behind a fee spike is includable again.
+func ReplacementParams(ctx context.Context, client Backend, old *types.Transaction, percent uint64) (Params, error) {
+	if old.Type() != types.BlobTxType {
+		return Params{}, fmt.Errorf("not a blob transaction (type %d)", old.Type())
+	}
+	market := Params{GasTipCap: bumpFee(old.GasTipCap(), percent)}
+	if err := fillFees(ctx, client, &market); err != nil {
+		return Params{}, err
+	}
+	return Params{
+		ChainID:    old.ChainId(),
+		Nonce:      old.Nonce(),
+		To:         *old.To(),
+		Value:      old.Value(),
+		Data:       old.Data(),
+		Gas:        old.Gas(),
+		GasTipCap:  market.GasTipCap,
+		GasFeeCap:  bigMax(bumpFee(old.GasFeeCap(), percent), market.GasFeeCap),
+		BlobFeeCap: bigMax(bumpFee(old.BlobGasFeeCap(), percent), market.BlobFeeCap),
+	}, nil
+}
+
+// Replace builds the unsigned replacement of old with the given parameters,
+// reattaching sidecar. The sidecar must hold the blobs old commits to, since
+// nodes do not return blobs with pending transactions.
+func Replace(old *types.Transaction, p Params, sidecar *types.BlobTxSidecar) (*types.Transaction, error) {
+	hashes := sidecar.BlobHashes()
+	want := old.BlobHashes()
+	if len(hashes) != len(want) {
+		return nil, fmt.Errorf("%w: sidecar has %d blobs, transaction %d", blob.ErrVersionedHashMismatch, len(hashes), len(want))
+	}
+	for i := range hashes {
+		if hashes[i] != want[i] {
+			return nil, fmt.Errorf("%w: blob %d hashes to %s, transaction has %s", blob.ErrVersionedHashMismatch, i, hashes[i], want[i])
+		}
+	}
+	if p.Nonce != old.Nonce() {
+		return nil, errors.New("a replacement must keep the nonce")
+	}
+	return NewBlobTx(p, sidecar)
+}
+
+// CheckReplacement returns an error wrapping ErrFeeCap if the tip or a fee
+// cap of p, the parameters of old's replacement, is below the old one raised
+// by DefaultPriceBump, as when FeeCaps.Apply has lowered it: geth's blob
+// pool would reject the replacement as underpriced.
+func CheckReplacement(old *types.Transaction, p Params) error {
+	fees := []struct {
+		name     string
+		old, new *big.Int
+	}{
+		{"priority fee", old.GasTipCap(), p.GasTipCap},
+		{"max fee", old.GasFeeCap(), p.GasFeeCap},
+		{"max blob fee", old.BlobGasFeeCap(), p.BlobFeeCap},
+	}
+	for _, f := range fees {
+		if least := bumpFee(f.old, DefaultPriceBump); f.new.Cmp(least) < 0 {
+			return fmt.Errorf("%w: %s of %s wei is below the %s wei the pool requires of a replacement", ErrFeeCap, f.name, f.new, least)
+		}
+	}
+	return nil
+}
+
+// bumpFee raises fee by percent, rounding up.
+func bumpFee(fee *big.Int, percent uint64) *big.Int {
+	bumped := new(big.Int).Mul(fee, new(big.Int).SetUint64(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func bigMax(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,85 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"kzg-blob-poc/pkg/fee"
+	"kzg-blob-poc/pkg/metrics"
+)
+
+// ErrFeeCap is wrapped by the error of FeeCaps.Apply when current prices
+// are above a limit.
+var ErrFeeCap = errors.New("fees above limit")
+
+// FeeCaps are hard limits on what a transaction may pay, so that an
+// unattended sender does not post during a fee spike. A nil *FeeCaps
+// imposes none.
+type FeeCaps struct {
+	// MaxFeePerGas, if set, is the most the transaction may pay per gas:
+	// its gas fee cap is lowered to it, and the latest base fee plus the
+	// tip must not be above it.
+	MaxFeePerGas *big.Int
+	// MaxFeePerBlobGas, if set, is the most the transaction may pay per
+	// blob gas: its blob fee cap is lowered to it, and the current blob base
+	// fee must not be above it.
+	MaxFeePerBlobGas *big.Int
+	// MaxTotalCost, if set, is the most the transaction may pay in fees,
+	// execution and blob gas together, at its fee caps.
+	MaxTotalCost *big.Int
+}
+
+// Apply lowers the fee caps of p, filled as by Fill for a transaction with
+// blobs blobs, to the limits. It returns an error wrapping ErrFeeCap if
+// current prices are above a limit, so the transaction would not be mined
+// without breaking it, or if it could cost more than MaxTotalCost.
+func (c *FeeCaps) Apply(ctx context.Context, client Backend, p *Params, blobs int) error {
+	if c == nil {
+		return nil
+	}
+	if c.MaxFeePerGas != nil {
+		head, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			metrics.RPCError("eth_getBlockByNumber")
+			return fmt.Errorf("failed to get latest header: %w", err)
+		}
+		if head.BaseFee == nil {
+			return errors.New("chain does not support EIP-1559")
+		}
+		if price := new(big.Int).Add(head.BaseFee, p.GasTipCap); price.Cmp(c.MaxFeePerGas) > 0 {
+			return fmt.Errorf("%w: base fee %s wei plus tip %s wei is above the limit of %s wei per gas", ErrFeeCap, head.BaseFee, p.GasTipCap, c.MaxFeePerGas)
+		}
+		if p.GasFeeCap.Cmp(c.MaxFeePerGas) > 0 {
+			p.GasFeeCap = new(big.Int).Set(c.MaxFeePerGas)
+		}
+	}
+	if c.MaxFeePerBlobGas != nil {
+		blobBaseFee, err := client.BlobBaseFee(ctx)
+		if err != nil {
+			metrics.RPCError("eth_blobBaseFee")
+			return fmt.Errorf("failed to get blob base fee: %w", err)
+		}
+		if blobBaseFee.Cmp(c.MaxFeePerBlobGas) > 0 {
+			return fmt.Errorf("%w: blob base fee %s wei is above the limit of %s wei per blob gas", ErrFeeCap, blobBaseFee, c.MaxFeePerBlobGas)
+		}
+		if p.BlobFeeCap.Cmp(c.MaxFeePerBlobGas) > 0 {
+			p.BlobFeeCap = new(big.Int).Set(c.MaxFeePerBlobGas)
+		}
+	}
+	if c.MaxTotalCost != nil {
+		if cost := MaxCost(*p, blobs); cost.Cmp(c.MaxTotalCost) > 0 {
+			return fmt.Errorf("%w: the transaction may cost up to %s wei in fees, above the limit of %s wei", ErrFeeCap, cost, c.MaxTotalCost)
+		}
+	}
+	return nil
+}
+
+// MaxCost returns the most a transaction filled as p with blobs blobs can
+// pay in fees: its gas limit at its gas fee cap plus its blob gas at its
+// blob fee cap.
+func MaxCost(p Params, blobs int) *big.Int {
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(p.Gas), p.GasFeeCap)
+	return cost.Add(cost, fee.BlobCost(blobs, p.BlobFeeCap))
+}
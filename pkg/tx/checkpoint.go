@@ -0,0 +1,115 @@
+package tx
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"kzg-blob-poc/internal/atomicfile"
+)
+
+// Checkpoint records the transactions a run has broadcast and those since
+// mined, so that an interrupted run can resume without sending their blobs
+// again. A nil *Checkpoint records nothing.
+type Checkpoint struct {
+	Sent []SentTx
+	save func([]SentTx) error
+}
+
+// SentTx is a broadcast transaction, identified by the blobs it carries.
+type SentTx struct {
+	BlobHashes []common.Hash `json:"blob_hashes"`
+	Hash       common.Hash   `json:"hash"`
+	Nonce      uint64        `json:"nonce"`
+	// BlockNumber and Status are set once the transaction is mined.
+	BlockNumber uint64  `json:"block_number,omitempty"`
+	Status      *uint64 `json:"status,omitempty"`
+}
+
+// Mined reports whether the transaction was seen mined.
+func (s SentTx) Mined() bool { return s.Status != nil }
+
+// NewCheckpoint returns a checkpoint holding sent that calls save with all
+// transactions after each change, for a caller that keeps them in a state
+// of its own.
+func NewCheckpoint(sent []SentTx, save func([]SentTx) error) *Checkpoint {
+	return &Checkpoint{Sent: sent, save: save}
+}
+
+// checkpointFile is the file format of OpenCheckpoint.
+type checkpointFile struct {
+	Sent []SentTx `json:"sent"`
+}
+
+// OpenCheckpoint reads the checkpoint at path, or returns an empty one if
+// the file does not exist yet. Each change replaces the file atomically, so
+// an interruption leaves the previous state.
+func OpenCheckpoint(path string) (*Checkpoint, error) {
+	var f checkpointFile
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	default:
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+		}
+	}
+	return NewCheckpoint(f.Sent, func(sent []SentTx) error {
+		data, err := json.MarshalIndent(checkpointFile{Sent: sent}, "", "  ")
+		if err != nil {
+			return err
+		}
+		return atomicfile.Write(path, append(data, '\n'))
+	}), nil
+}
+
+// Lookup returns the recorded transaction carrying exactly blobHashes. It
+// fails if a recorded transaction carries only some of them, as when the
+// blobs are packed differently than in the run that sent it, since sending
+// them again would post those blobs twice.
+func (c *Checkpoint) Lookup(blobHashes []common.Hash) (SentTx, bool, error) {
+	if c == nil {
+		return SentTx{}, false, nil
+	}
+	for _, s := range c.Sent {
+		if slices.Equal(s.BlobHashes, blobHashes) {
+			return s, true, nil
+		}
+		for _, h := range blobHashes {
+			if slices.Contains(s.BlobHashes, h) {
+				return SentTx{}, false, fmt.Errorf("blob %s was sent in transaction %s with other blobs: resume with the same packing of blobs into transactions", h, s.Hash)
+			}
+		}
+	}
+	return SentTx{}, false, nil
+}
+
+// Record adds sent, replacing any transaction with the same blobs, and saves
+// the checkpoint.
+func (c *Checkpoint) Record(sent SentTx) error {
+	if c == nil {
+		return nil
+	}
+	c.Sent = slices.DeleteFunc(c.Sent, func(s SentTx) bool { return slices.Equal(s.BlobHashes, sent.BlobHashes) })
+	c.Sent = append(c.Sent, sent)
+	return c.save(c.Sent)
+}
+
+// Mine records that the transaction hash was mined in block with status,
+// and saves the checkpoint. An unrecorded hash is ignored.
+func (c *Checkpoint) Mine(hash common.Hash, block uint64, status uint64) error {
+	if c == nil {
+		return nil
+	}
+	i := slices.IndexFunc(c.Sent, func(s SentTx) bool { return s.Hash == hash })
+	if i < 0 {
+		return nil
+	}
+	c.Sent[i].BlockNumber, c.Sent[i].Status = block, &status
+	return c.save(c.Sent)
+}
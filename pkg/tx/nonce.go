@@ -0,0 +1,50 @@
+package tx
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NonceTracker wraps a Backend and hands out consecutive nonces, so several
+// transactions from one account can be filled and sent before the first is
+// in the node's pending state. Passing it to Fill in place of the backend is
+// enough.
+type NonceTracker struct {
+	Backend
+
+	mu   sync.Mutex
+	next map[common.Address]uint64
+}
+
+// NewNonceTracker returns a tracker forwarding to client.
+func NewNonceTracker(client Backend) *NonceTracker {
+	return &NonceTracker{Backend: client, next: make(map[common.Address]uint64)}
+}
+
+// PendingNonceAt returns the next nonce of account and reserves it: the
+// node's pending nonce, or one past the last nonce handed out if that is
+// higher.
+func (t *NonceTracker) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	pending, err := t.Backend.PendingNonceAt(ctx, account)
+	if err != nil {
+		return 0, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	nonce := max(pending, t.next[account])
+	t.next[account] = nonce + 1
+	return nonce, nil
+}
+
+// Release returns nonce to the tracker after the transaction using it could
+// not be sent. Only the most recently reserved nonce can be released; older
+// ones would leave a gap that the node fills on its own.
+func (t *NonceTracker) Release(account common.Address, nonce uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.next[account] == nonce+1 {
+		t.next[account] = nonce
+	}
+}
@@ -0,0 +1,96 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/fee"
+)
+
+// DefaultMaxBlobsPerTx is the most blobs one transaction can carry on
+// Cancun, where it is bounded by the block limit. Later forks raise the
+// block limit and cap transactions separately, so pass their value to Pack
+// instead.
+const DefaultMaxBlobsPerTx = 6
+
+// PackedTx describes one transaction of a packing plan.
+type PackedTx struct {
+	// Index is the position of the transaction in the plan.
+	Index int `json:"index"`
+	// FirstBlob is the index of its first blob in the packed list.
+	FirstBlob int `json:"first_blob"`
+	// Blobs is the number of blobs it carries.
+	Blobs int `json:"blobs"`
+	// BlobGas is the blob gas those blobs consume.
+	BlobGas uint64 `json:"blob_gas"`
+}
+
+// Plan splits n blobs across the fewest transactions carrying at most
+// maxPerTx blobs each. Blobs keep their order: every transaction but the last
+// is full.
+func Plan(n, maxPerTx int) ([]PackedTx, error) {
+	if n <= 0 {
+		return nil, errors.New("a blob transaction needs at least one blob")
+	}
+	if maxPerTx <= 0 {
+		return nil, fmt.Errorf("invalid max blobs per transaction %d", maxPerTx)
+	}
+	plan := make([]PackedTx, 0, (n+maxPerTx-1)/maxPerTx)
+	for first := 0; first < n; first += maxPerTx {
+		count := min(maxPerTx, n-first)
+		plan = append(plan, PackedTx{
+			Index:     len(plan),
+			FirstBlob: first,
+			Blobs:     count,
+			BlobGas:   fee.BlobGas(count),
+		})
+	}
+	return plan, nil
+}
+
+// Pack plans blobs as Plan does and builds the sidecar of each transaction,
+// in plan order. Computing the proofs takes a while for many blobs, so Pack
+// returns ctx.Err() if ctx is done before the next sidecar.
+func Pack(ctx context.Context, blobs []kzg4844.Blob, maxPerTx int) ([]PackedTx, []*types.BlobTxSidecar, error) {
+	plan, err := Plan(len(blobs), maxPerTx)
+	if err != nil {
+		return nil, nil, err
+	}
+	sidecars := make([]*types.BlobTxSidecar, len(plan))
+	for i, p := range plan {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		if sidecars[i], err = NewSidecar(blobs[p.FirstBlob : p.FirstBlob+p.Blobs]); err != nil {
+			return nil, nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+	}
+	return plan, sidecars, nil
+}
+
+// PackComputed is Pack for blobs whose commitments and proofs are already
+// known, such as from a split, so nothing is computed. They are not checked
+// against the blobs; a node rejects a transaction with wrong ones.
+func PackComputed(blobs []kzg4844.Blob, commitments []kzg4844.Commitment, proofs []kzg4844.Proof, maxPerTx int) ([]PackedTx, []*types.BlobTxSidecar, error) {
+	if len(commitments) != len(blobs) || len(proofs) != len(blobs) {
+		return nil, nil, fmt.Errorf("have %d blobs but %d commitments and %d proofs", len(blobs), len(commitments), len(proofs))
+	}
+	plan, err := Plan(len(blobs), maxPerTx)
+	if err != nil {
+		return nil, nil, err
+	}
+	sidecars := make([]*types.BlobTxSidecar, len(plan))
+	for i, p := range plan {
+		end := p.FirstBlob + p.Blobs
+		sidecars[i] = &types.BlobTxSidecar{
+			Blobs:       blobs[p.FirstBlob:end],
+			Commitments: commitments[p.FirstBlob:end],
+			Proofs:      proofs[p.FirstBlob:end],
+		}
+	}
+	return plan, sidecars, nil
+}
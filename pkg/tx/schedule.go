@@ -0,0 +1,106 @@
+package tx
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"kzg-blob-poc/pkg/metrics"
+)
+
+// DefaultSchedulePoll is how often a Scheduler checks the chain again while
+// it holds a transaction back.
+const DefaultSchedulePoll = 4 * time.Second
+
+// Scheduler paces the submission of many blob transactions, so that
+// publishing a large dataset neither crowds the blob space of a block nor
+// pays a fee spike. A nil *Scheduler lets every transaction through.
+type Scheduler struct {
+	// MaxBlobsPerBlock caps the blobs submitted while the chain head stays
+	// at one block; 0 means no cap. The first transaction at a head always
+	// goes through, so a transaction with more blobs is not held forever.
+	MaxBlobsPerBlock int
+	// Interval is the least time between two submissions.
+	Interval time.Duration
+	// MaxBlobBaseFee, if set, holds submissions while the blob base fee is
+	// above it, in wei.
+	MaxBlobBaseFee *big.Int
+	// Poll is how often the chain is checked while waiting; 0 means
+	// DefaultSchedulePoll.
+	Poll time.Duration
+	// OnWait, if set, is called with the reason whenever Wait starts
+	// holding a transaction back for a reason it did not report last.
+	OnWait func(reason string)
+
+	head   uint64
+	inHead int
+	last   time.Time
+	reason string
+}
+
+// Wait blocks until a transaction with blobs blobs may be submitted, or ctx
+// is done, and then counts it as submitted.
+func (s *Scheduler) Wait(ctx context.Context, client Backend, blobs int) error {
+	if s == nil {
+		return nil
+	}
+	for {
+		reason, delay, err := s.hold(ctx, client, blobs)
+		if err != nil {
+			return err
+		}
+		if reason == "" {
+			break
+		}
+		if reason != s.reason && s.OnWait != nil {
+			s.OnWait(reason)
+		}
+		s.reason = reason
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	s.reason = ""
+	s.inHead += blobs
+	s.last = time.Now()
+	return nil
+}
+
+// hold returns why a transaction with blobs blobs must wait and for how
+// long before checking again, or an empty reason if it may go now.
+func (s *Scheduler) hold(ctx context.Context, client Backend, blobs int) (string, time.Duration, error) {
+	poll := s.Poll
+	if poll <= 0 {
+		poll = DefaultSchedulePoll
+	}
+	if wait := s.Interval - time.Since(s.last); !s.last.IsZero() && wait > 0 {
+		return fmt.Sprintf("spacing transactions %s apart", s.Interval), wait, nil
+	}
+	if s.MaxBlobsPerBlock > 0 {
+		head, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			metrics.RPCError("eth_getBlockByNumber")
+			return "", 0, fmt.Errorf("failed to get latest header: %w", err)
+		}
+		if n := head.Number.Uint64(); n != s.head {
+			s.head, s.inHead = n, 0
+		}
+		if s.inHead > 0 && s.inHead+blobs > s.MaxBlobsPerBlock {
+			return fmt.Sprintf("%d blobs already submitted at block %d, at most %d per block", s.inHead, s.head, s.MaxBlobsPerBlock), poll, nil
+		}
+	}
+	if s.MaxBlobBaseFee != nil {
+		fee, err := client.BlobBaseFee(ctx)
+		if err != nil {
+			metrics.RPCError("eth_blobBaseFee")
+			return "", 0, fmt.Errorf("failed to get blob base fee: %w", err)
+		}
+		if fee.Cmp(s.MaxBlobBaseFee) > 0 {
+			return fmt.Sprintf("blob base fee %s wei is above %s wei", fee, s.MaxBlobBaseFee), poll, nil
+		}
+	}
+	return "", 0, nil
+}
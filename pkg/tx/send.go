@@ -0,0 +1,157 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"kzg-blob-poc/pkg/metrics"
+)
+
+// Backend is the subset of ethclient.Client needed to fill in, send and
+// confirm blob transactions.
+type Backend interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	BlobBaseFee(ctx context.Context) (*big.Int, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// Fill completes p from the connected execution client. The nonce is always
+// set to the pending nonce of from, once everything else is filled, so a
+// NonceTracker client only hands one out when Fill succeeds; the chain ID
+// and fee caps are filled when nil and the gas limit when zero. The gas fee cap defaults to twice the
+// latest base fee plus the tip, and the blob fee cap to twice the current
+// blob base fee, leaving headroom for a few blocks of fee increases.
+func Fill(ctx context.Context, client Backend, from common.Address, p *Params, blobHashes []common.Hash) error {
+	var err error
+	if p.ChainID == nil {
+		if p.ChainID, err = client.ChainID(ctx); err != nil {
+			metrics.RPCError("eth_chainId")
+			return fmt.Errorf("failed to get chain ID: %w", err)
+		}
+	}
+	if err := fillFees(ctx, client, p); err != nil {
+		return err
+	}
+	if p.Gas == 0 {
+		to := p.To
+		p.Gas, err = client.EstimateGas(ctx, ethereum.CallMsg{
+			From:          from,
+			To:            &to,
+			Value:         p.Value,
+			Data:          p.Data,
+			GasTipCap:     p.GasTipCap,
+			GasFeeCap:     p.GasFeeCap,
+			BlobGasFeeCap: p.BlobFeeCap,
+			BlobHashes:    blobHashes,
+		})
+		if err != nil {
+			metrics.RPCError("eth_estimateGas")
+			return fmt.Errorf("failed to estimate gas: %w", err)
+		}
+	}
+	if p.Nonce, err = client.PendingNonceAt(ctx, from); err != nil {
+		metrics.RPCError("eth_getTransactionCount")
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+	return nil
+}
+
+// Simulate runs the transaction described by p as an eth_call from from on
+// the latest state of client, so a transaction that would revert is caught
+// before it is broadcast. client may be a different node than the one the
+// transaction is filled from, such as a fork or simulation endpoint.
+func Simulate(ctx context.Context, client ethereum.ContractCaller, from common.Address, p Params, blobHashes []common.Hash) error {
+	to := p.To
+	_, err := client.CallContract(ctx, ethereum.CallMsg{
+		From:          from,
+		To:            &to,
+		Gas:           p.Gas,
+		Value:         p.Value,
+		Data:          p.Data,
+		GasTipCap:     p.GasTipCap,
+		GasFeeCap:     p.GasFeeCap,
+		BlobGasFeeCap: p.BlobFeeCap,
+		BlobHashes:    blobHashes,
+	}, nil)
+	if err != nil {
+		metrics.RPCError("eth_call")
+		return fmt.Errorf("simulation failed: %w", err)
+	}
+	return nil
+}
+
+// fillFees sets the tip and fee caps of p that are nil, as described on
+// Fill.
+func fillFees(ctx context.Context, client Backend, p *Params) error {
+	var err error
+	if p.GasTipCap == nil {
+		if p.GasTipCap, err = client.SuggestGasTipCap(ctx); err != nil {
+			metrics.RPCError("eth_maxPriorityFeePerGas")
+			return fmt.Errorf("failed to get gas tip cap: %w", err)
+		}
+	}
+	if p.GasFeeCap == nil {
+		head, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			metrics.RPCError("eth_getBlockByNumber")
+			return fmt.Errorf("failed to get latest header: %w", err)
+		}
+		if head.BaseFee == nil {
+			return errors.New("chain does not support EIP-1559")
+		}
+		p.GasFeeCap = new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), p.GasTipCap)
+	}
+	if p.BlobFeeCap == nil {
+		blobBaseFee, err := client.BlobBaseFee(ctx)
+		if err != nil {
+			metrics.RPCError("eth_blobBaseFee")
+			return fmt.Errorf("failed to get blob base fee: %w", err)
+		}
+		p.BlobFeeCap = new(big.Int).Mul(blobBaseFee, big.NewInt(2))
+	}
+	return nil
+}
+
+// WaitMined polls for the receipt of txHash until it is available or ctx is
+// done.
+func WaitMined(ctx context.Context, client Backend, txHash common.Hash, interval time.Duration) (*types.Receipt, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := client.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			metrics.RPCError("eth_getTransactionReceipt")
+			return nil, fmt.Errorf("failed to get receipt: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// IsAlreadyKnown reports whether a node rejected a transaction because its
+// pool already holds it. When a send was retried, this means an earlier
+// attempt reached the node, so the transaction was sent.
+func IsAlreadyKnown(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "already known")
+}
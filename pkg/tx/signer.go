@@ -0,0 +1,171 @@
+package tx
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Signer signs transactions on behalf of one account.
+type Signer interface {
+	// Address returns the account the signer signs for.
+	Address() common.Address
+	// SignTx returns tx signed by the account, with its blob sidecar kept.
+	SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error)
+}
+
+// KeySigner signs with an in-process private key.
+type KeySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewKeySigner returns a signer for key.
+func NewKeySigner(key *ecdsa.PrivateKey) *KeySigner {
+	return &KeySigner{key: key}
+}
+
+// Address returns the address of the key.
+func (s *KeySigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+// SignTx signs tx with the key.
+func (s *KeySigner) SignTx(_ context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	return Sign(tx, s.key)
+}
+
+// Remote signer flavours accepted by DialRemoteSigner.
+const (
+	// RemoteClef is go-ethereum's Clef, which is sent the blobs so it can
+	// check them against the versioned hashes before asking for approval.
+	RemoteClef = "clef"
+	// RemoteWeb3Signer is a web3signer-compatible eth_signTransaction
+	// endpoint, which is only sent the versioned hashes.
+	RemoteWeb3Signer = "web3signer"
+)
+
+// RemoteSigner signs through an external signer over JSON-RPC, so the key
+// never enters this process.
+type RemoteSigner struct {
+	client  *rpc.Client
+	kind    string
+	address common.Address
+}
+
+// DialRemoteSigner connects to a remote signer of the given kind. A zero
+// address selects the signer's only account, and fails if it holds more
+// than one.
+func DialRemoteSigner(ctx context.Context, kind, url string, address common.Address) (*RemoteSigner, error) {
+	if kind != RemoteClef && kind != RemoteWeb3Signer {
+		return nil, fmt.Errorf("unknown remote signer %q (want %s or %s)", kind, RemoteClef, RemoteWeb3Signer)
+	}
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to signer %s: %w", url, err)
+	}
+	s := &RemoteSigner{client: client, kind: kind, address: address}
+	if address == (common.Address{}) {
+		if s.address, err = s.onlyAccount(ctx); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// onlyAccount returns the single account managed by the signer.
+func (s *RemoteSigner) onlyAccount(ctx context.Context) (common.Address, error) {
+	method := "eth_accounts"
+	if s.kind == RemoteClef {
+		method = "account_list"
+	}
+	var accounts []common.Address
+	if err := s.client.CallContext(ctx, &accounts, method); err != nil {
+		return common.Address{}, fmt.Errorf("failed to list signer accounts: %w", err)
+	}
+	if len(accounts) != 1 {
+		return common.Address{}, fmt.Errorf("signer has %d accounts, select one explicitly", len(accounts))
+	}
+	return accounts[0], nil
+}
+
+// Close closes the connection to the signer.
+func (s *RemoteSigner) Close() {
+	s.client.Close()
+}
+
+// Address returns the account the remote signer signs for.
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx asks the remote signer to sign tx. The returned transaction is
+// checked to be tx itself, signed by the expected account, so a misbehaving
+// signer cannot substitute another transaction.
+func (s *RemoteSigner) SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	if tx.Type() != types.BlobTxType {
+		return nil, fmt.Errorf("unsupported transaction type %d", tx.Type())
+	}
+	input := hexutil.Bytes(tx.Data())
+	to := common.NewMixedcaseAddress(*tx.To())
+	args := apitypes.SendTxArgs{
+		From:                 common.NewMixedcaseAddress(s.address),
+		To:                   &to,
+		Gas:                  hexutil.Uint64(tx.Gas()),
+		MaxFeePerGas:         (*hexutil.Big)(tx.GasFeeCap()),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tx.GasTipCap()),
+		Value:                hexutil.Big(*tx.Value()),
+		Nonce:                hexutil.Uint64(tx.Nonce()),
+		Input:                &input,
+		ChainID:              (*hexutil.Big)(tx.ChainId()),
+		BlobFeeCap:           (*hexutil.Big)(tx.BlobGasFeeCap()),
+		BlobHashes:           tx.BlobHashes(),
+	}
+
+	var raw hexutil.Bytes
+	switch s.kind {
+	case RemoteClef:
+		if sidecar := tx.BlobTxSidecar(); sidecar != nil {
+			args.Blobs, args.Commitments, args.Proofs = sidecar.Blobs, sidecar.Commitments, sidecar.Proofs
+		}
+		var res struct {
+			Raw hexutil.Bytes `json:"raw"`
+		}
+		if err := s.client.CallContext(ctx, &res, "account_signTransaction", &args); err != nil {
+			return nil, fmt.Errorf("remote signer: %w", err)
+		}
+		raw = res.Raw
+	default:
+		if err := s.client.CallContext(ctx, &raw, "eth_signTransaction", &args); err != nil {
+			return nil, fmt.Errorf("remote signer: %w", err)
+		}
+	}
+
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("remote signer returned an invalid transaction: %w", err)
+	}
+	signer := types.NewCancunSigner(tx.ChainId())
+	if signer.Hash(signed) != signer.Hash(tx) {
+		return nil, errors.New("remote signer returned a different transaction")
+	}
+	sender, err := types.Sender(signer, signed)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned an invalid signature: %w", err)
+	}
+	if sender != s.address {
+		return nil, fmt.Errorf("remote signer signed as %s, want %s", sender, s.address)
+	}
+	if signed.BlobTxSidecar() == nil && tx.BlobTxSidecar() != nil {
+		signed = signed.WithBlobTxSidecar(tx.BlobTxSidecar())
+	}
+	return signed, nil
+}
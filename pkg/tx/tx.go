@@ -0,0 +1,117 @@
+// Package tx builds and signs EIP-4844 (type 0x03) blob transactions.
+package tx
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/holiman/uint256"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// Params holds the fields of a blob transaction that are not derived from
+// the blobs themselves. Nil amounts are treated as zero.
+type Params struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	To         common.Address
+	Value      *big.Int
+	Data       []byte
+	Gas        uint64
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	BlobFeeCap *big.Int
+}
+
+// NewSidecar computes the commitment and proof of every blob and assembles
+// them into a transaction sidecar.
+func NewSidecar(blobs []kzg4844.Blob) (*types.BlobTxSidecar, error) {
+	if len(blobs) == 0 {
+		return nil, errors.New("a blob transaction needs at least one blob")
+	}
+
+	sidecar := &types.BlobTxSidecar{
+		Blobs:       blobs,
+		Commitments: make([]kzg4844.Commitment, len(blobs)),
+		Proofs:      make([]kzg4844.Proof, len(blobs)),
+	}
+	for i := range blobs {
+		commitment, err := blob.Commit(&blobs[i])
+		if err != nil {
+			return nil, fmt.Errorf("blob %d: failed to generate KZG commitment: %w", i, err)
+		}
+		proof, err := blob.Prove(&blobs[i], commitment)
+		if err != nil {
+			return nil, fmt.Errorf("blob %d: failed to generate KZG proof: %w", i, err)
+		}
+		sidecar.Commitments[i] = commitment
+		sidecar.Proofs[i] = proof
+	}
+	return sidecar, nil
+}
+
+// NewBlobTx builds an unsigned blob transaction carrying sidecar, with the
+// versioned hashes derived from the sidecar commitments.
+func NewBlobTx(p Params, sidecar *types.BlobTxSidecar) (*types.Transaction, error) {
+	if p.ChainID == nil || p.ChainID.Sign() <= 0 {
+		return nil, errors.New("chain ID is required")
+	}
+
+	chainID, err := toUint256("chain ID", p.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	value, err := toUint256("value", p.Value)
+	if err != nil {
+		return nil, err
+	}
+	tipCap, err := toUint256("gas tip cap", p.GasTipCap)
+	if err != nil {
+		return nil, err
+	}
+	feeCap, err := toUint256("gas fee cap", p.GasFeeCap)
+	if err != nil {
+		return nil, err
+	}
+	blobFeeCap, err := toUint256("blob fee cap", p.BlobFeeCap)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewTx(&types.BlobTx{
+		ChainID:    chainID,
+		Nonce:      p.Nonce,
+		GasTipCap:  tipCap,
+		GasFeeCap:  feeCap,
+		Gas:        p.Gas,
+		To:         p.To,
+		Value:      value,
+		Data:       p.Data,
+		BlobFeeCap: blobFeeCap,
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	}), nil
+}
+
+// Sign signs tx with key using the Cancun signer for the transaction's chain.
+func Sign(tx *types.Transaction, key *ecdsa.PrivateKey) (*types.Transaction, error) {
+	return types.SignTx(tx, types.NewCancunSigner(tx.ChainId()), key)
+}
+
+// toUint256 converts an optional non-negative amount to its uint256 form.
+func toUint256(name string, v *big.Int) (*uint256.Int, error) {
+	if v == nil {
+		return new(uint256.Int), nil
+	}
+	u, overflow := uint256.FromBig(v)
+	if overflow || v.Sign() < 0 {
+		return nil, fmt.Errorf("%s out of range: %v", name, v)
+	}
+	return u, nil
+}
@@ -0,0 +1,128 @@
+package tx
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+// ErrNoSidecar means a blob transaction is in its canonical encoding, without
+// the blobs, commitments and proofs of the network encoding.
+var ErrNoSidecar = errors.New("blob transaction has no sidecar")
+
+// PooledTransactionsMsg is the eth/68 message code of PooledTransactions,
+// the reply to GetPooledTransactions and the only message that carries
+// blob transactions with their sidecars.
+const PooledTransactionsMsg = 0x0a
+
+// Wrap attaches sidecar to signed, a signed blob transaction, for the
+// network encoding, after checking it as CheckSidecar does. signed may
+// already carry a sidecar, which sidecar replaces.
+func Wrap(signed *types.Transaction, sidecar *types.BlobTxSidecar) (*types.Transaction, error) {
+	if signed.Type() != types.BlobTxType {
+		return nil, fmt.Errorf("transaction %s is of type %d, not a blob transaction", signed.Hash(), signed.Type())
+	}
+	wrapped := signed.WithBlobTxSidecar(sidecar)
+	if err := CheckSidecar(wrapped); err != nil {
+		return nil, err
+	}
+	return wrapped, nil
+}
+
+// CheckSidecar checks that the sidecar of the blob transaction tx has a
+// blob, commitment and proof for each of its versioned hashes, that each
+// commitment hashes to its versioned hash, and that the proofs verify. The
+// errors wrap ErrNoSidecar, blob.ErrVersionedHashMismatch or
+// blob.ErrProofMismatch.
+func CheckSidecar(tx *types.Transaction) error {
+	sc := tx.BlobTxSidecar()
+	if sc == nil {
+		return fmt.Errorf("transaction %s: %w", tx.Hash(), ErrNoSidecar)
+	}
+	hashes := tx.BlobHashes()
+	if len(sc.Blobs) != len(hashes) || len(sc.Proofs) != len(hashes) {
+		return fmt.Errorf("transaction %s: %d versioned hashes but %d blobs and %d proofs", tx.Hash(), len(hashes), len(sc.Blobs), len(sc.Proofs))
+	}
+	if err := sc.ValidateBlobCommitmentHashes(hashes); err != nil {
+		return fmt.Errorf("transaction %s: %w: %v", tx.Hash(), blob.ErrVersionedHashMismatch, err)
+	}
+	if err := blob.VerifyBlobProofBatch(sc.Blobs, sc.Commitments, sc.Proofs); err != nil {
+		return fmt.Errorf("transaction %s: %w", tx.Hash(), err)
+	}
+	return nil
+}
+
+// MarshalNetwork returns the network encoding of the blob transaction tx,
+// 0x03 || rlp([tx_payload_body, blobs, commitments, proofs]), in which
+// clients gossip it and accept it in eth_sendRawTransaction. Its hash is that
+// of the canonical encoding, without the sidecar.
+func MarshalNetwork(tx *types.Transaction) ([]byte, error) {
+	if tx.Type() != types.BlobTxType {
+		return nil, fmt.Errorf("transaction %s is of type %d, not a blob transaction", tx.Hash(), tx.Type())
+	}
+	if tx.BlobTxSidecar() == nil {
+		return nil, fmt.Errorf("transaction %s: %w", tx.Hash(), ErrNoSidecar)
+	}
+	return tx.MarshalBinary()
+}
+
+// UnmarshalNetwork parses the network encoding of a blob transaction, as
+// MarshalNetwork writes it, and checks its sidecar with CheckSidecar.
+func UnmarshalNetwork(data []byte) (*types.Transaction, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("invalid transaction: %w", err)
+	}
+	if tx.Type() != types.BlobTxType {
+		return nil, fmt.Errorf("transaction %s is of type %d, not a blob transaction", tx.Hash(), tx.Type())
+	}
+	if err := CheckSidecar(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// pooledTransactions is the RLP layout of a PooledTransactions message.
+// Typed transactions are RLP strings holding their binary encoding, the
+// network encoding for blob transactions with a sidecar.
+type pooledTransactions struct {
+	RequestID    uint64
+	Transactions []*types.Transaction
+}
+
+// EncodePooledTransactions returns the eth/68 PooledTransactions message
+// answering request requestID with txs, rlp([request-id, [tx, ...]]). It is
+// the message body only: devp2p prefixes PooledTransactionsMsg and
+// snappy-compresses it. Blob transactions must carry their sidecars.
+func EncodePooledTransactions(requestID uint64, txs []*types.Transaction) ([]byte, error) {
+	for _, tx := range txs {
+		if tx.Type() == types.BlobTxType && tx.BlobTxSidecar() == nil {
+			return nil, fmt.Errorf("transaction %s: %w", tx.Hash(), ErrNoSidecar)
+		}
+	}
+	return rlp.EncodeToBytes(&pooledTransactions{RequestID: requestID, Transactions: txs})
+}
+
+// DecodePooledTransactions parses a PooledTransactions message body, as
+// EncodePooledTransactions writes it, and checks the sidecar of each blob
+// transaction with CheckSidecar. It returns the request ID and the
+// transactions.
+func DecodePooledTransactions(data []byte) (uint64, []*types.Transaction, error) {
+	var msg pooledTransactions
+	if err := rlp.DecodeBytes(data, &msg); err != nil {
+		return 0, nil, fmt.Errorf("invalid PooledTransactions message: %w", err)
+	}
+	for _, tx := range msg.Transactions {
+		if tx.Type() != types.BlobTxType {
+			continue
+		}
+		if err := CheckSidecar(tx); err != nil {
+			return 0, nil, err
+		}
+	}
+	return msg.RequestID, msg.Transactions, nil
+}
@@ -0,0 +1,34 @@
+// blobpoc.js loads blobpoc.wasm and returns its functions, throwing the
+// errors the Go side returns. Load wasm_exec.js from the Go distribution
+// first; it defines the global Go class.
+//
+//   const blobpoc = await loadBlobPoc(fetch("blobpoc.wasm"));
+//   const blob = blobpoc.encode(new TextEncoder().encode("hello"), { compress: "zstd" });
+//
+// In Node.js, pass the bytes of the file instead of a fetch response.
+async function loadBlobPoc(wasm) {
+  const go = new Go();
+  const streaming = typeof Response !== "undefined" && (wasm instanceof Response || wasm instanceof Promise);
+  const { instance } = streaming
+    ? await WebAssembly.instantiateStreaming(wasm, go.importObject)
+    : await WebAssembly.instantiate(wasm, go.importObject);
+  // run only settles when the program exits, which it never does; the
+  // global is installed before main blocks.
+  go.run(instance);
+
+  const api = {};
+  for (const [name, fn] of Object.entries(globalThis.blobpoc)) {
+    api[name] = (...args) => {
+      const res = fn(...args);
+      if (res instanceof Error) {
+        throw res;
+      }
+      return res;
+    };
+  }
+  return api;
+}
+
+if (typeof module !== "undefined") {
+  module.exports = { loadBlobPoc };
+}
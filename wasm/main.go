@@ -0,0 +1,429 @@
+//go:build js && wasm
+
+// Command wasm exposes the blob encoding of pkg/blob to JavaScript, so a
+// browser can lay out a payload, pack it and hash commitments before
+// anything is sent. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o blobpoc.wasm ./wasm
+//
+// and run it with wasm_exec.js from the Go distribution. It installs a
+// global blobpoc object whose functions return a JavaScript Error instead
+// of throwing; blobpoc.js wraps them to throw.
+//
+// Packing and hashing are plain Go. Commitments and proofs need the KZG
+// backend, whose trusted setup may not load in every environment, so
+// commit, prove and verify first check capabilities().kzg.
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"syscall/js"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"kzg-blob-poc/pkg/blob"
+)
+
+func main() {
+	api := map[string]any{
+		"capabilities":  capabilities,
+		"analyze":       analyze,
+		"layout":        layout,
+		"encode":        encode,
+		"decode":        decode,
+		"roundtrip":     roundtrip,
+		"split":         split,
+		"versionedHash": versionedHash,
+		"commit":        commit,
+		"prove":         prove,
+		"verify":        verify,
+	}
+	obj := js.Global().Get("Object").New()
+	for name, fn := range api {
+		obj.Set(name, export(fn.(func([]js.Value) (any, error))))
+	}
+	js.Global().Set("blobpoc", obj)
+	select {}
+}
+
+// export wraps fn as a JavaScript function that returns fn's result, or an
+// Error with its error. Panics are reported the same way.
+func export(fn func(args []js.Value) (any, error)) js.Func {
+	return js.FuncOf(func(_ js.Value, args []js.Value) (res any) {
+		defer func() {
+			if r := recover(); r != nil {
+				res = jsError(fmt.Errorf("%v", r))
+			}
+		}()
+		v, err := fn(args)
+		if err != nil {
+			return jsError(err)
+		}
+		return v
+	})
+}
+
+func jsError(err error) js.Value {
+	return js.Global().Get("Error").New(err.Error())
+}
+
+var (
+	kzgOnce sync.Once
+	kzgErr  error
+)
+
+// kzgAvailable loads the KZG backend once by committing to the zero blob,
+// and returns why it is unavailable, if it is.
+func kzgAvailable() error {
+	kzgOnce.Do(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				kzgErr = fmt.Errorf("KZG backend unavailable: %v", r)
+			}
+		}()
+		var b kzg4844.Blob
+		if _, err := blob.Commit(&b); err != nil {
+			kzgErr = fmt.Errorf("KZG backend unavailable: %w", err)
+		}
+	})
+	return kzgErr
+}
+
+// capabilities() returns {kzg, kzg_error}. The first call loads the KZG
+// trusted setup, which takes a few seconds in a browser.
+func capabilities(args []js.Value) (any, error) {
+	caps := struct {
+		KZG      bool   `json:"kzg"`
+		KZGError string `json:"kzg_error,omitempty"`
+	}{KZG: true}
+	if err := kzgAvailable(); err != nil {
+		caps.KZG, caps.KZGError = false, err.Error()
+	}
+	return toJS(caps)
+}
+
+// analyze(size) returns the blob utilization of a payload of size bytes.
+func analyze(args []js.Value) (any, error) {
+	size, err := intArg(args, 0, "size")
+	if err != nil {
+		return nil, err
+	}
+	return toJS(blob.Analyze(size))
+}
+
+// layout(size) returns the chunks split places a payload of size bytes in.
+func layout(args []js.Value) (any, error) {
+	size, err := intArg(args, 0, "size")
+	if err != nil {
+		return nil, err
+	}
+	return toJS(blob.ChunkLayout(size))
+}
+
+// encodeOptions are the options of encode and roundtrip, as by the encode
+// command.
+type encodeOptions struct {
+	Compress string `json:"compress"`
+	Raw      bool   `json:"raw"`
+}
+
+// encode(payload, {compress, raw}) packs payload into a framed blob, or an
+// unframed one with raw, and returns its 131,072 bytes.
+func encode(args []js.Value) (any, error) {
+	payload, err := bytesArg(args, 0, "payload")
+	if err != nil {
+		return nil, err
+	}
+	var opts encodeOptions
+	if err := optionsArg(args, 1, &opts); err != nil {
+		return nil, err
+	}
+	b, err := encodeBlob(payload, opts)
+	if err != nil {
+		return nil, err
+	}
+	return toUint8Array(b[:]), nil
+}
+
+func encodeBlob(payload []byte, opts encodeOptions) (kzg4844.Blob, error) {
+	c, err := blob.ParseCompression(strings.ToLower(opts.Compress))
+	if opts.Compress == "" {
+		c, err = blob.CompressionNone, nil
+	}
+	if err != nil {
+		return kzg4844.Blob{}, err
+	}
+	if opts.Raw {
+		if c != blob.CompressionNone {
+			return kzg4844.Blob{}, errors.New("compress needs a frame header and cannot be combined with raw")
+		}
+		return blob.Pack(payload)
+	}
+	return blob.EncodeFramedCompressed(payload, c)
+}
+
+// decode(blob, {raw, auto}) recovers the payload of a blob written by
+// encode. raw returns all packed bytes of an unframed blob and auto detects
+// the layout and compression, as the decode command does.
+func decode(args []js.Value) (any, error) {
+	b, err := blobArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	var opts struct {
+		Raw  bool `json:"raw"`
+		Auto bool `json:"auto"`
+	}
+	if err := optionsArg(args, 1, &opts); err != nil {
+		return nil, err
+	}
+	var payload []byte
+	switch {
+	case opts.Raw && opts.Auto:
+		return nil, errors.New("raw and auto cannot be combined")
+	case opts.Raw:
+		payload, err = blob.Unpack(&b, blob.MaxPackedSize)
+	case opts.Auto:
+		var res *blob.SniffResult
+		if res, err = blob.Sniff(&b); err == nil {
+			payload = res.Payload
+		}
+	default:
+		payload, err = blob.DecodeFramed(b)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toUint8Array(payload), nil
+}
+
+// roundtrip(payload, {compress, raw}) encodes payload, decodes the blob and
+// checks it gives the payload back. It returns {payload_size, stored_size,
+// compression, blob}.
+func roundtrip(args []js.Value) (any, error) {
+	payload, err := bytesArg(args, 0, "payload")
+	if err != nil {
+		return nil, err
+	}
+	var opts encodeOptions
+	if err := optionsArg(args, 1, &opts); err != nil {
+		return nil, err
+	}
+	b, err := encodeBlob(payload, opts)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		decoded []byte
+		frame   blob.Frame
+	)
+	if opts.Raw {
+		decoded, err = blob.Unpack(&b, len(payload))
+		frame.StoredSize = len(payload)
+	} else if frame, err = blob.ReadFrame(&b); err == nil {
+		decoded, err = blob.DecodeFramed(b)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the encoded blob: %w", err)
+	}
+	if !bytes.Equal(decoded, payload) {
+		return nil, errors.New("decoded payload differs from the input")
+	}
+	res, err := toJS(struct {
+		PayloadSize int    `json:"payload_size"`
+		StoredSize  int    `json:"stored_size"`
+		Compression string `json:"compression"`
+	}{len(payload), frame.StoredSize, frame.Compression.String()})
+	if err != nil {
+		return nil, err
+	}
+	res.Set("blob", toUint8Array(b[:]))
+	return res, nil
+}
+
+// split(payload) packs a payload of any size into blobs as the split
+// command does and returns them as an array.
+func split(args []js.Value) (any, error) {
+	payload, err := bytesArg(args, 0, "payload")
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := blob.SplitIntoBlobs(payload)
+	if err != nil {
+		return nil, err
+	}
+	arr := js.Global().Get("Array").New(len(blobs))
+	for i := range blobs {
+		arr.SetIndex(i, toUint8Array(blobs[i][:]))
+	}
+	return arr, nil
+}
+
+// versionedHash(commitment) returns the versioned hash of a commitment,
+// given as bytes or hex.
+func versionedHash(args []js.Value) (any, error) {
+	c, err := commitmentArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	return blob.VersionedHash(c).Hex(), nil
+}
+
+// commit(blob) returns {commitment, versioned_hash}.
+func commit(args []js.Value) (any, error) {
+	if err := kzgAvailable(); err != nil {
+		return nil, err
+	}
+	b, err := blobArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	a, err := blob.CommitOnly(&b, false)
+	if err != nil {
+		return nil, err
+	}
+	return toJS(a)
+}
+
+// prove(blob) returns {commitment, proof, versioned_hash}.
+func prove(args []js.Value) (any, error) {
+	if err := kzgAvailable(); err != nil {
+		return nil, err
+	}
+	b, err := blobArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	a, err := blob.NewArtifacts(&b, false)
+	if err != nil {
+		return nil, err
+	}
+	return toJS(a)
+}
+
+// verify(blob, commitment, proof) reports whether proof binds blob to
+// commitment, each given as bytes or hex.
+func verify(args []js.Value) (any, error) {
+	if err := kzgAvailable(); err != nil {
+		return nil, err
+	}
+	b, err := blobArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	c, err := commitmentArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	var proof kzg4844.Proof
+	data, err := fixedArg(args, 2, "proof", len(proof))
+	if err != nil {
+		return nil, err
+	}
+	copy(proof[:], data)
+	err = blob.Verify(&b, c, proof)
+	if errors.Is(err, blob.ErrProofMismatch) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// toJS converts v to a JavaScript value through its JSON encoding, so
+// hashes and commitments arrive as hex strings.
+func toJS(v any) (js.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return js.Value{}, err
+	}
+	return js.Global().Get("JSON").Call("parse", string(data)), nil
+}
+
+func toUint8Array(b []byte) js.Value {
+	arr := js.Global().Get("Uint8Array").New(len(b))
+	js.CopyBytesToJS(arr, b)
+	return arr
+}
+
+// bytesArg returns argument i, a Uint8Array or a hex string.
+func bytesArg(args []js.Value, i int, name string) ([]byte, error) {
+	if i >= len(args) {
+		return nil, fmt.Errorf("missing %s", name)
+	}
+	v := args[i]
+	if v.Type() == js.TypeString {
+		data, err := hex.DecodeString(strings.TrimPrefix(v.String(), "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		return data, nil
+	}
+	if !v.InstanceOf(js.Global().Get("Uint8Array")) {
+		return nil, fmt.Errorf("%s must be a Uint8Array or a hex string", name)
+	}
+	data := make([]byte, v.Length())
+	js.CopyBytesToGo(data, v)
+	return data, nil
+}
+
+func fixedArg(args []js.Value, i int, name string, size int) ([]byte, error) {
+	data, err := bytesArg(args, i, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != size {
+		return nil, fmt.Errorf("%s must be %d bytes, got %d", name, size, len(data))
+	}
+	return data, nil
+}
+
+func blobArg(args []js.Value, i int) (kzg4844.Blob, error) {
+	var b kzg4844.Blob
+	data, err := fixedArg(args, i, "blob", len(b))
+	if err != nil {
+		return b, err
+	}
+	copy(b[:], data)
+	return b, nil
+}
+
+func commitmentArg(args []js.Value, i int) (kzg4844.Commitment, error) {
+	var c kzg4844.Commitment
+	data, err := fixedArg(args, i, "commitment", len(c))
+	if err != nil {
+		return c, err
+	}
+	copy(c[:], data)
+	return c, nil
+}
+
+func intArg(args []js.Value, i int, name string) (int, error) {
+	if i >= len(args) || args[i].Type() != js.TypeNumber {
+		return 0, fmt.Errorf("%s must be a number", name)
+	}
+	n := args[i].Int()
+	if n < 0 {
+		return 0, fmt.Errorf("%s must not be negative", name)
+	}
+	return n, nil
+}
+
+// optionsArg decodes the optional options object at argument i into v
+// through JSON, rejecting unknown options.
+func optionsArg(args []js.Value, i int, v any) error {
+	if i >= len(args) || args[i].IsUndefined() || args[i].IsNull() {
+		return nil
+	}
+	s := js.Global().Get("JSON").Call("stringify", args[i]).String()
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+	return nil
+}